@@ -0,0 +1,44 @@
+package mapping
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// Lint describes a definite problem found within a mapping by statically
+// inferring the types of values produced by its queries.
+type Lint struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	What   string `json:"what"`
+}
+
+// Lint walks the statements of the mapping and returns any definite type
+// errors found within them. Types are inferred from literals, functions and
+// methods, and the type of the context (`this`) of the mapping can optionally
+// be described with a schema. Maps defined within the mapping are not
+// analysed.
+func (e *Executor) Lint(this *query.TypeSchema) []Lint {
+	ctx := query.NewTypeContext(this)
+
+	var lints []Lint
+	for _, stmt := range e.statements {
+		t, errs := query.InferType(ctx, stmt.query)
+		if len(errs) > 0 {
+			line, col := 1, 1
+			if len(e.input) > 0 && len(stmt.input) > 0 {
+				line, col = LineAndColOf(e.input, stmt.input)
+			}
+			for _, err := range errs {
+				lints = append(lints, Lint{
+					Line:   line,
+					Column: col,
+					What:   err.Error(),
+				})
+			}
+		}
+		if v, isVar := stmt.assignment.(*VarAssignment); isVar && t != query.ValueNothing {
+			ctx.SetVarType(v.name, t)
+		}
+	}
+	return lints
+}
@@ -0,0 +1,141 @@
+package mapping
+
+import (
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// StatementTrace describes the execution of a single statement of a mapping,
+// including the value its query produced and the state of the new document and
+// variables after it was assigned.
+type StatementTrace struct {
+	Line     int                 `json:"line"`
+	Column   int                 `json:"column"`
+	Mapping  string              `json:"mapping"`
+	Value    any                 `json:"value"`
+	Skipped  bool                `json:"skipped,omitempty"`
+	Deleted  bool                `json:"deleted,omitempty"`
+	Root     any                 `json:"root"`
+	Vars     map[string]any      `json:"vars"`
+	Branches []query.BranchTrace `json:"branches,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// ExecOntoTraced executes the mapping onto a provided assignment context in the
+// same way as ExecOnto, but also returns a trace of each statement executed. If
+// a statement fails then the traces up to and including the failed statement
+// are returned along with the error.
+func (e *Executor) ExecOntoTraced(ctx query.FunctionContext, onto AssignmentContext) ([]StatementTrace, error) {
+	var branches []query.BranchTrace
+	ctx = ctx.WithBranchTracer(func(b query.BranchTrace) {
+		branches = append(branches, b)
+	})
+
+	traces := make([]StatementTrace, 0, len(e.statements))
+	for i, stmt := range e.statements {
+		branches = nil
+
+		t := StatementTrace{Mapping: e.statementText(i)}
+		if len(e.input) > 0 && len(stmt.input) > 0 {
+			t.Line, t.Column = LineAndColOf(e.input, stmt.input)
+		}
+
+		res, err := stmt.query.Exec(ctx)
+		t.Branches = branches
+		if err != nil {
+			err = formatExecErr(err, true, e.input, stmt.input)
+			t.Error = err.Error()
+			traces = append(traces, e.snapshot(t, onto))
+			return traces, err
+		}
+
+		switch res.(type) {
+		case query.Nothing:
+			// Skip assignment entirely
+			t.Skipped = true
+			traces = append(traces, e.snapshot(t, onto))
+			continue
+		case query.Delete:
+			t.Deleted = true
+		default:
+			t.Value = traceValue(res)
+		}
+
+		if err = stmt.assignment.Apply(res, onto); err != nil {
+			err = formatExecErr(err, false, e.input, stmt.input)
+			t.Error = err.Error()
+			traces = append(traces, e.snapshot(t, onto))
+			return traces, err
+		}
+		traces = append(traces, e.snapshot(t, onto))
+	}
+	return traces, nil
+}
+
+// snapshot captures the state of the new document and variables of an
+// assignment context into a statement trace.
+func (e *Executor) snapshot(t StatementTrace, onto AssignmentContext) StatementTrace {
+	if onto.Value != nil {
+		t.Root = traceValue(*onto.Value)
+	}
+	t.Vars = make(map[string]any, len(onto.Vars))
+	for k, v := range onto.Vars {
+		t.Vars[k] = traceValue(v)
+	}
+	return t
+}
+
+// statementText returns the section of the mapping input that describes a
+// statement, which spans until the beginning of the next statement or the next
+// map, function or import definition, excluding trailing comments.
+func (e *Executor) statementText(index int) string {
+	input := e.statements[index].input
+	if index+1 < len(e.statements) {
+		if next := e.statements[index+1].input; len(next) <= len(input) {
+			input = input[:len(input)-len(next)]
+		}
+	}
+
+	lines := strings.Split(string(input), "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "map ") ||
+			strings.HasPrefix(lines[i], "func ") ||
+			strings.HasPrefix(lines[i], "import ") {
+			lines = lines[:i]
+			break
+		}
+	}
+	for len(lines) > 1 {
+		if last := strings.TrimSpace(lines[len(lines)-1]); last != "" && !strings.HasPrefix(last, "#") {
+			break
+		}
+		lines = lines[:len(lines)-1]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// traceValue returns a deep copy of a value that is safe to serialise as JSON,
+// where byte slices are converted into strings and deleted or skipped values
+// are removed.
+func traceValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		newMap := make(map[string]any, len(t))
+		for k, v := range t {
+			newMap[k] = traceValue(v)
+		}
+		return newMap
+	case []any:
+		newSlice := make([]any, len(t))
+		for i, v := range t {
+			newSlice[i] = traceValue(v)
+		}
+		return newSlice
+	case []byte:
+		return string(t)
+	case query.Delete, query.Nothing:
+		return nil
+	}
+	return v
+}
@@ -16,6 +16,8 @@ type Context struct {
 	Methods      *query.MethodSet
 	namedContext *namedContext
 	importer     Importer
+
+	userFunctions map[string]*query.UserFunction
 }
 
 // EmptyContext returns a parser context with no functions, methods or import
@@ -62,9 +64,31 @@ func (pCtx Context) HasNamedContext(name string) bool {
 	return false
 }
 
-// InitFunction attempts to initialise a function from the available
+// withUserFunctions returns a Context where functions defined within the
+// mapping being parsed are added to the provided map, and are callable from
+// subsequent queries.
+func (pCtx Context) withUserFunctions(funcs map[string]*query.UserFunction) Context {
+	pCtx.userFunctions = funcs
+	return pCtx
+}
+
+// FunctionParams attempts to obtain the parameters of a function, where user
+// defined functions take precedence over the constructors of the parser
+// context.
+func (pCtx Context) FunctionParams(name string) (query.Params, error) {
+	if uFn, exists := pCtx.userFunctions[name]; exists {
+		return uFn.Params(), nil
+	}
+	return pCtx.Functions.Params(name)
+}
+
+// InitFunction attempts to initialise a function from the user defined
+// functions of the mapping being parsed, or otherwise the available
 // constructors of the parser context.
 func (pCtx Context) InitFunction(name string, args *query.ParsedParams) (query.Function, error) {
+	if uFn, exists := pCtx.userFunctions[name]; exists {
+		return uFn.Init(args)
+	}
 	return pCtx.Functions.Init(name, args)
 }
 
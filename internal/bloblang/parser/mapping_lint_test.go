@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+func TestMappingLint(t *testing.T) {
+	schema, err := query.TypeSchemaFromJSONSchema(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string"},
+			"age":   map[string]any{"type": "integer"},
+			"admin": map[string]any{"type": "boolean"},
+			"tags": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+			"nested": map[string]any{
+				"properties": map[string]any{
+					"count": map[string]any{"type": "number"},
+				},
+			},
+			"either": map[string]any{"type": []any{"string", "number"}},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		mapping string
+		schema  *query.TypeSchema
+		lints   []mapping.Lint
+	}{
+		"no problems": {
+			mapping: `root.a = "foo".uppercase()
+root.b = this.name.uppercase()
+root.c = this.age.floor() + 10
+root.d = this.tags.0.trim()
+root.e = this.nested.count.abs()`,
+			schema: schema,
+		},
+		"unknown types without schema": {
+			mapping: `root.a = this.name.uppercase()
+root.b = this.age.floor()
+root.c = this.name + this.age`,
+		},
+		"method on literal": {
+			mapping: `root.a = 10.uppercase()
+root.b = "foo".floor()`,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "method uppercase: expected string or bytes value, got number from number literal"},
+				{Line: 2, Column: 1, What: "method floor: expected number value, got string from string literal"},
+			},
+		},
+		"method chains": {
+			mapping: `root.a = this.foo.length().uppercase()
+root.b = now().round()
+root.c = this.foo.string().keys()`,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "method uppercase: expected string or bytes value, got number from method length"},
+				{Line: 2, Column: 1, What: "method round: expected number value, got string from function now"},
+				{Line: 3, Column: 1, What: "method keys: expected object value, got string from method string"},
+			},
+		},
+		"schema fields": {
+			mapping: `root.a = this.age.uppercase()
+root.b = this.name.floor()
+root.c = this.tags.0.abs()
+root.d = this.nested.count.trim()
+root.e = this.either.uppercase()
+root.f = this.unknown.uppercase()`,
+			schema: schema,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "method uppercase: expected string or bytes value, got number from field `this.age`"},
+				{Line: 2, Column: 1, What: "method floor: expected number value, got string from field `this.name`"},
+				{Line: 3, Column: 1, What: "method abs: expected number value, got string from field `this.tags.0`"},
+				{Line: 4, Column: 1, What: "method trim: expected string or bytes value, got number from field `this.nested.count`"},
+			},
+		},
+		"arithmetic": {
+			mapping: `root.a = this.name + this.age
+root.b = this.age - this.name.length()
+root.c = this.admin && this.age
+root.d = this.name > 10
+root.e = this.name == 10
+root.f = !this.name`,
+			schema: schema,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "cannot add types string (from field `this.name`) and number (from field `this.age`)"},
+				{Line: 3, Column: 1, What: "cannot boolean and types bool (from field `this.admin`) and number (from field `this.age`)"},
+				{Line: 4, Column: 1, What: "cannot compare types string (from field `this.name`) and number (from number literal)"},
+				{Line: 6, Column: 1, What: "cannot negate string (from field `this.name`)"},
+			},
+		},
+		"variables": {
+			mapping: `let count = this.tags.length()
+let name = this.name
+let name = 10
+root.a = $count.uppercase()
+root.b = $name.uppercase()`,
+			schema: schema,
+			lints: []mapping.Lint{
+				{Line: 4, Column: 1, What: "method uppercase: expected string or bytes value, got number from variable count"},
+			},
+		},
+		"nested queries": {
+			mapping: `root.a = [ this.age.uppercase() ]
+root.b = { "foo": this.age.uppercase() }
+root.c = this.tags.map_each(t -> t.uppercase().floor())
+root.d = this.name.replace_all(this.age.trim(), "bar")`,
+			schema: schema,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "method uppercase: expected string or bytes value, got number from field `this.age`"},
+				{Line: 2, Column: 1, What: "method uppercase: expected string or bytes value, got number from field `this.age`"},
+				{Line: 4, Column: 1, What: "method trim: expected string or bytes value, got number from field `this.age`"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			exec, perr := ParseMapping(GlobalContext(), test.mapping)
+			require.Nil(t, perr)
+			assert.Equal(t, test.lints, exec.Lint(test.schema))
+		})
+	}
+}
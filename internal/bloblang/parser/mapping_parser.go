@@ -3,6 +3,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/Jeffail/gabs/v2"
@@ -43,6 +44,14 @@ func ParseMapping(pCtx Context, expr string) (*mapping.Executor, *Error) {
 //------------------------------------------------------------------------------'
 
 func parseExecutor(pCtx Context) Func {
+	return func(input []rune) Result {
+		return executorParser(pCtx, map[string]*query.UserFunction{})(input)
+	}
+}
+
+// executorParser parses a mapping where any functions it defines are added to
+// the provided map.
+func executorParser(pCtx Context, funcs map[string]*query.UserFunction) Func {
 	newline := NewlineAllowComment()
 	whitespace := SpacesAndTabs()
 	allWhitespace := DiscardAll(OneOf(whitespace, newline))
@@ -51,9 +60,11 @@ func parseExecutor(pCtx Context) Func {
 		maps := map[string]query.Function{}
 		statements := []mapping.Statement{}
 
+		pCtx := pCtx.withUserFunctions(funcs)
 		statement := OneOf(
-			importParser(maps, pCtx),
+			importParser(maps, funcs, pCtx),
 			mapParser(maps, pCtx),
+			funcParser(maps, funcs, pCtx),
 			letStatementParser(pCtx),
 			metaStatementParser(false, pCtx),
 			plainMappingStatementParser(pCtx),
@@ -181,7 +192,7 @@ func varNameParser() Func {
 	)
 }
 
-func importParser(maps map[string]query.Function, pCtx Context) Func {
+func importParser(maps map[string]query.Function, funcs map[string]*query.UserFunction, pCtx Context) Func {
 	p := Sequence(
 		Term("import"),
 		SpacesAndTabs(),
@@ -208,14 +219,15 @@ func importParser(maps map[string]query.Function, pCtx Context) Func {
 		nextCtx := pCtx.WithImporterRelativeToFile(fpath)
 
 		importContent := []rune(string(contents))
-		execRes := parseExecutor(nextCtx)(importContent)
+		importFuncs := map[string]*query.UserFunction{}
+		execRes := executorParser(nextCtx, importFuncs)(importContent)
 		if execRes.Err != nil {
 			return Fail(NewFatalError(input, NewImportError(fpath, importContent, execRes.Err)), input)
 		}
 
 		exec := execRes.Payload.(*mapping.Executor)
-		if len(exec.Maps()) == 0 {
-			err := fmt.Errorf("no maps to import from '%v'", fpath)
+		if len(exec.Maps()) == 0 && len(importFuncs) == 0 {
+			err := fmt.Errorf("no maps or functions to import from '%v'", fpath)
 			return Fail(NewFatalError(input, err), input)
 		}
 
@@ -232,6 +244,19 @@ func importParser(maps map[string]query.Function, pCtx Context) Func {
 			return Fail(NewFatalError(input, err), input)
 		}
 
+		for k, v := range importFuncs {
+			if _, exists := funcs[k]; exists {
+				collisions = append(collisions, k)
+			} else {
+				funcs[k] = v
+			}
+		}
+		if len(collisions) > 0 {
+			sort.Strings(collisions)
+			err := fmt.Errorf("function name collisions from import '%v': %v", fpath, collisions)
+			return Fail(NewFatalError(input, err), input)
+		}
+
 		return Success(fpath, res.Remaining)
 	}
 }
@@ -303,6 +328,112 @@ func mapParser(maps map[string]query.Function, pCtx Context) Func {
 	}
 }
 
+// funcReturn is the final expression of a function body, which provides the
+// return value of the function.
+type funcReturn struct {
+	stmt mapping.Statement
+}
+
+func funcReturnParser(pCtx Context) Func {
+	return func(input []rune) Result {
+		res := queryParser(pCtx)(input)
+		if res.Err != nil {
+			return res
+		}
+		stmt := mapping.NewStatement(input, mapping.NewJSONAssignment(), res.Payload.(query.Function))
+		return Success(funcReturn{stmt: stmt}, res.Remaining)
+	}
+}
+
+func funcParser(maps map[string]query.Function, funcs map[string]*query.UserFunction, pCtx Context) Func {
+	newline := NewlineAllowComment()
+	whitespace := SpacesAndTabs()
+	allWhitespace := DiscardAll(OneOf(whitespace, newline))
+
+	p := Sequence(
+		Term("func"),
+		whitespace,
+		Expect(SnakeCase(), "function name"),
+		Discard(whitespace),
+		MustBe(DelimitedPattern(
+			Expect(Sequence(Char('('), Discard(whitespace)), "function parameters"),
+			Expect(varNameParser(), "parameter name"),
+			Sequence(Discard(whitespace), Char(','), Discard(whitespace)),
+			Sequence(Discard(whitespace), Char(')')),
+			true,
+		)),
+		Discard(whitespace),
+		MustBe(DelimitedPattern(
+			Expect(Sequence(
+				Char('{'),
+				allWhitespace,
+			), "function body"),
+			OneOf(
+				letStatementParser(pCtx),
+				funcReturnParser(pCtx),
+			),
+			Sequence(
+				Discard(whitespace),
+				newline,
+				allWhitespace,
+			),
+			Sequence(
+				allWhitespace,
+				Char('}'),
+			),
+			true,
+		)),
+	)
+
+	return func(input []rune) Result {
+		res := p(input)
+		if res.Err != nil {
+			return res
+		}
+
+		seqSlice := res.Payload.([]any)
+		ident := seqSlice[2].(string)
+		paramSlice := seqSlice[4].([]any)
+		stmtSlice := seqSlice[6].([]any)
+
+		if _, exists := funcs[ident]; exists {
+			return Fail(NewFatalError(input, fmt.Errorf("function name collision: %v", ident)), input)
+		}
+		if _, err := pCtx.Functions.Params(ident); err == nil {
+			return Fail(NewFatalError(input, fmt.Errorf("function name collision with global function: %v", ident)), input)
+		}
+
+		params := make([]string, len(paramSlice))
+		for i, v := range paramSlice {
+			params[i] = v.(string)
+		}
+
+		if len(stmtSlice) == 0 {
+			return Fail(NewFatalError(input, fmt.Errorf("function %v: expected a body that ends with a query", ident)), input)
+		}
+		statements := make([]mapping.Statement, len(stmtSlice))
+		for i, v := range stmtSlice {
+			ret, isReturn := v.(funcReturn)
+			if isReturn != (i == len(stmtSlice)-1) {
+				return Fail(NewFatalError(input, fmt.Errorf("function %v: expected a body of zero or more let statements followed by a single query", ident)), input)
+			}
+			if isReturn {
+				statements[i] = ret.stmt
+			} else {
+				statements[i] = v.(mapping.Statement)
+			}
+		}
+
+		uFn, err := query.NewUserFunction(ident, params, mapping.NewExecutor("func "+ident, input, maps, statements...))
+		if err != nil {
+			return Fail(NewFatalError(input, err), input)
+		}
+		funcs[ident] = uFn
+
+		return Success(ident, res.Remaining)
+	}
+}
+
 func letStatementParser(pCtx Context) Func {
 	p := Sequence(
 		Expect(Term("let"), "assignment"),
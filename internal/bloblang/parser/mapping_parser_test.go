@@ -23,6 +23,9 @@ func TestMappingErrors(t *testing.T) {
 	require.NoError(t, os.WriteFile(noMapsFile, []byte(`foo = "this is valid but has no maps"`), 0o777))
 	require.NoError(t, os.WriteFile(goodMapFile, []byte(`map foo { foo = "this is valid" }`), 0o777))
 
+	goodFuncFile := filepath.Join(dir, "good_func.blobl")
+	require.NoError(t, os.WriteFile(goodFuncFile, []byte(`func foo(a) { $a }`), 0o777))
+
 	tests := map[string]struct {
 		mapping     string
 		errContains string
@@ -37,12 +40,12 @@ func TestMappingErrors(t *testing.T) {
 		},
 		"no mappings": {
 			mapping:     ``,
-			errContains: `line 1 char 1: expected import, map, or assignment`,
+			errContains: `line 1 char 1: expected import, map, func, or assignment`,
 		},
 		"no mappings 2": {
 			mapping: `
    `,
-			errContains: `line 2 char 4: expected import, map, or assignment`,
+			errContains: `line 2 char 4: expected import, map, func, or assignment`,
 		},
 		"double mapping": {
 			mapping:     `foo = bar bar = baz`,
@@ -83,13 +86,13 @@ meta foo = "bar"`,
 		"bad char 2": {
 			mapping: `let foo = bar
 !foo = bar`,
-			errContains: `line 2 char 1: expected import, map, or assignment`,
+			errContains: `line 2 char 1: expected import, map, func, or assignment`,
 		},
 		"bad char 3": {
 			mapping: `let foo = bar
 !foo = bar
 this = that`,
-			errContains: `line 2 char 1: expected import, map, or assignment`,
+			errContains: `line 2 char 1: expected import, map, func, or assignment`,
 		},
 		"bad query": {
 			mapping:     `foo = blah.`,
@@ -139,7 +142,7 @@ foo = bar.apply("from_import")`, badMapFile),
 			mapping: fmt.Sprintf(`import "%v"
 
 foo = bar.apply("from_import")`, noMapsFile),
-			errContains: fmt.Sprintf(`line 1 char 1: no maps to import from '%v'`, noMapsFile),
+			errContains: fmt.Sprintf(`line 1 char 1: no maps or functions to import from '%v'`, noMapsFile),
 		},
 		"colliding maps file import": {
 			mapping: fmt.Sprintf(`map "foo" { this = that }			
@@ -152,7 +155,59 @@ foo = bar.apply("foo")`, goodMapFile),
 		"quotes at root": {
 			mapping: `
 "root.something" = 5 + 2`,
-			errContains: "line 2 char 1: expected import, map, or assignment",
+			errContains: "line 2 char 1: expected import, map, func, or assignment",
+		},
+		"function wrong arity": {
+			mapping: `func add(a, b) { $a + $b }
+root = add(1, 2, 3)`,
+			errContains: "line 2 char 8: wrong number of arguments, expected 2, got 3",
+		},
+		"function missing arg": {
+			mapping: `func add(a, b) { $a + $b }
+root = add(1)`,
+			errContains: "line 2 char 8: missing parameter: b",
+		},
+		"function unknown named arg": {
+			mapping: `func add(a, b) { $a + $b }
+root = add(a: 1, c: 2)`,
+			errContains: "line 2 char 8: unknown parameter c",
+		},
+		"function called before definition": {
+			mapping: `root = add(1, 2)
+func add(a, b) { $a + $b }`,
+			errContains: "line 1 char 8: unrecognised function 'add'",
+		},
+		"function name collision": {
+			mapping: `func add(a, b) { $a + $b }
+func add(a) { $a }`,
+			errContains: "line 2 char 1: function name collision: add",
+		},
+		"function global name collision": {
+			mapping:     `func uuid_v4() { "nope" }`,
+			errContains: "line 1 char 1: function name collision with global function: uuid_v4",
+		},
+		"function duplicate params": {
+			mapping:     `func add(a, a) { $a + $a }`,
+			errContains: "line 1 char 1: function add: duplicate parameter name: a",
+		},
+		"function empty body": {
+			mapping:     `func add(a, b) { }`,
+			errContains: "line 1 char 1: function add: expected a body that ends with a query",
+		},
+		"function body without return": {
+			mapping: `func add(a, b) {
+  let c = $a + $b
+}`,
+			errContains: "line 1 char 1: function add: expected a body of zero or more let statements followed by a single query",
+		},
+		"function missing body": {
+			mapping:     `func add(a, b)`,
+			errContains: "line 1 char 15: required: expected function body",
+		},
+		"function import collision": {
+			mapping: fmt.Sprintf(`func foo(b) { $b }
+import "%v"`, goodFuncFile),
+			errContains: fmt.Sprintf(`line 2 char 1: function name collisions from import '%v': [foo]`, goodFuncFile),
 		},
 	}
 
@@ -179,6 +234,12 @@ func TestMappings(t *testing.T) {
 	directMapFile := filepath.Join(dir, "direct_map.blobl")
 	require.NoError(t, os.WriteFile(directMapFile, []byte(`root.nested = this`), 0o777))
 
+	goodFuncFile := filepath.Join(dir, "foo_func.blobl")
+	require.NoError(t, os.WriteFile(goodFuncFile, []byte(`func greet(name, greeting) {
+  let prefix = $greeting.or("hello")
+  $prefix + " " + $name
+}`), 0o777))
+
 	type part struct {
 		Content string
 		Meta    map[string]any
@@ -466,6 +527,64 @@ root = this.apply("foo")`, goodMapFile),
 				Content: `{"nested":{"inner":"hello world"}}`,
 			},
 		},
+		"function single line": {
+			mapping: `func add(a, b) { $a + $b }
+root.sum = add(this.foo, this.bar)
+root.named = add(b: 1, a: this.foo)`,
+			input:  []part{{Content: `{"foo":5,"bar":3}`}},
+			output: part{Content: `{"named":6,"sum":8}`},
+		},
+		"function with let statements": {
+			mapping: `func describe(thing) {
+  let name = $thing.name.uppercase()
+
+  # Comments are allowed within bodies
+  $name + " is " + this.mood
+}
+root = describe(this.animal)`,
+			input:  []part{{Content: `{"animal":{"name":"sam"},"mood":"happy"}`}},
+			output: part{Content: `SAM is happy`},
+		},
+		"function isolated variables": {
+			mapping: `func get_thing(thing) {
+  let other = "function value"
+  $thing + " " + $other
+}
+let other = "mapping value"
+root.a = get_thing("foo")
+root.b = $other`,
+			input:  []part{{Content: `{}`}},
+			output: part{Content: `{"a":"foo function value","b":"mapping value"}`},
+		},
+		"function calls another function": {
+			mapping: `func double(v) { $v * 2 }
+func quadruple(v) { double(double($v)) }
+root = quadruple(this.value)`,
+			input:  []part{{Content: `{"value":3}`}},
+			output: part{Content: `12`},
+		},
+		"function within map": {
+			mapping: `func wrap(v) { { "wrapped": $v } }
+map foo {
+  root.inner = wrap(this.value)
+}
+root = this.apply("foo")`,
+			input:  []part{{Content: `{"value":"hello"}`}},
+			output: part{Content: `{"inner":{"wrapped":"hello"}}`},
+		},
+		"function without params": {
+			mapping: `func answer() { 42 }
+root = answer() + 1`,
+			input:  []part{{Content: `{}`}},
+			output: part{Content: `43`},
+		},
+		"function imported": {
+			mapping: fmt.Sprintf(`import "%v"
+root.a = greet(this.name, this.greeting)
+root.b = greet(name: this.name, greeting: null)`, goodFuncFile),
+			input:  []part{{Content: `{"name":"sam","greeting":"hey"}`}},
+			output: part{Content: `{"a":"hey sam","b":"hello sam"}`},
+		},
 	}
 
 	for name, test := range tests {
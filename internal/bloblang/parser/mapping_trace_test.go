@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestMappingTrace(t *testing.T) {
+	tests := map[string]struct {
+		mapping string
+		input   string
+		traces  []mapping.StatementTrace
+		err     string
+	}{
+		"assignments and variables": {
+			mapping: `let name = this.name.uppercase()
+# A comment about the next statement
+root.greeting = "hello " + $name
+
+root.deleted = deleted()`,
+			input: `{"name":"foo"}`,
+			traces: []mapping.StatementTrace{
+				{
+					Line: 1, Column: 1,
+					Mapping: `let name = this.name.uppercase()`,
+					Value:   "FOO",
+					Vars:    map[string]any{"name": "FOO"},
+				},
+				{
+					Line: 3, Column: 1,
+					Mapping: `root.greeting = "hello " + $name`,
+					Value:   "hello FOO",
+					Root:    map[string]any{"greeting": "hello FOO"},
+					Vars:    map[string]any{"name": "FOO"},
+				},
+				{
+					Line: 5, Column: 1,
+					Mapping: `root.deleted = deleted()`,
+					Deleted: true,
+					Root:    map[string]any{"greeting": "hello FOO"},
+					Vars:    map[string]any{"name": "FOO"},
+				},
+			},
+		},
+		"match and if branches": {
+			mapping: `root.a = match this.type {
+  "foo" => "was foo"
+  "bar" => "was bar"
+}
+map nope {
+  root = "nope"
+}
+root.b = if this.type == "foo" { "if" } else if this.type == "bar" { "else if" }
+root.c = if this.type == "baz" { "nah" }`,
+			input: `{"type":"bar"}`,
+			traces: []mapping.StatementTrace{
+				{
+					Line: 1, Column: 1,
+					Mapping: "root.a = match this.type {\n  \"foo\" => \"was foo\"\n  \"bar\" => \"was bar\"\n}",
+					Value:   "was bar",
+					Root:    map[string]any{"a": "was bar"},
+					Vars:    map[string]any{},
+					Branches: []query.BranchTrace{
+						{Expression: "match expression", Branch: "case 1"},
+					},
+				},
+				{
+					Line: 8, Column: 1,
+					Mapping: `root.b = if this.type == "foo" { "if" } else if this.type == "bar" { "else if" }`,
+					Value:   "else if",
+					Root:    map[string]any{"a": "was bar", "b": "else if"},
+					Vars:    map[string]any{},
+					Branches: []query.BranchTrace{
+						{Expression: "if expression", Branch: "else if 1"},
+					},
+				},
+				{
+					Line: 9, Column: 1,
+					Mapping: `root.c = if this.type == "baz" { "nah" }`,
+					Skipped: true,
+					Root:    map[string]any{"a": "was bar", "b": "else if"},
+					Vars:    map[string]any{},
+					Branches: []query.BranchTrace{
+						{Expression: "if expression", Branch: "none"},
+					},
+				},
+			},
+		},
+		"failed statement": {
+			mapping: `root.a = "foo"
+root.b = this.nope.number()
+root.c = "bar"`,
+			input: `{}`,
+			traces: []mapping.StatementTrace{
+				{
+					Line: 1, Column: 1,
+					Mapping: `root.a = "foo"`,
+					Value:   "foo",
+					Root:    map[string]any{"a": "foo"},
+					Vars:    map[string]any{},
+				},
+				{
+					Line: 2, Column: 1,
+					Mapping: `root.b = this.nope.number()`,
+					Error:   "failed assignment (line 2): expected number value, got null from field `this.nope`",
+					Root:    map[string]any{"a": "foo"},
+					Vars:    map[string]any{},
+				},
+			},
+			err: "failed assignment (line 2): expected number value, got null from field `this.nope`",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			exec, perr := ParseMapping(GlobalContext(), test.mapping)
+			require.Nil(t, perr)
+
+			msg := message.QuickBatch([][]byte{[]byte(test.input)})
+			vars := map[string]any{}
+
+			var result any = query.Nothing(nil)
+			traces, err := exec.ExecOntoTraced(query.FunctionContext{
+				Maps:     exec.Maps(),
+				Vars:     vars,
+				MsgBatch: msg,
+				NewMeta:  msg.Get(0),
+				NewValue: &result,
+			}.WithValueFunc(func() *any {
+				v, _ := msg.Get(0).AsStructured()
+				return &v
+			}), mapping.AssignmentContext{
+				Vars:  vars,
+				Meta:  msg.Get(0),
+				Value: &result,
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.traces, traces)
+		})
+	}
+}
@@ -388,7 +388,7 @@ func functionParser(pCtx Context) Func {
 		seqSlice := res.Payload.([]any)
 
 		targetFunc := seqSlice[0].(string)
-		params, err := pCtx.Functions.Params(targetFunc)
+		params, err := pCtx.FunctionParams(targetFunc)
 		if err != nil {
 			return Fail(NewFatalError(input, err), input)
 		}
@@ -56,14 +56,14 @@ func (o ArithmeticOperator) String() string {
 
 type arithmeticOpFunc func(lhs, rhs Function, l, r any) (any, error)
 
-func arithmeticFunc(lhs, rhs Function, op arithmeticOpFunc) (Function, error) {
+func arithmeticFunc(lhs, rhs Function, op ArithmeticOperator, opFn arithmeticOpFunc) (Function, error) {
 	annotation := rhs.Annotation()
 
 	var litL, litR *Literal
 	var isLit bool
 	if litL, isLit = lhs.(*Literal); isLit {
 		if litR, isLit = rhs.(*Literal); isLit {
-			res, err := op(lhs, rhs, litL.Value, litR.Value)
+			res, err := opFn(lhs, rhs, litL.Value, litR.Value)
 			if err != nil {
 				return nil, err
 			}
@@ -71,7 +71,7 @@ func arithmeticFunc(lhs, rhs Function, op arithmeticOpFunc) (Function, error) {
 		}
 	}
 
-	return ClosureFunction(annotation, func(ctx FunctionContext) (any, error) {
+	return arithmeticWithTypeInference(op, lhs, rhs, ClosureFunction(annotation, func(ctx FunctionContext) (any, error) {
 		var err error
 		var leftV, rightV any
 		if leftV, err = lhs.Exec(ctx); err == nil {
@@ -80,8 +80,8 @@ func arithmeticFunc(lhs, rhs Function, op arithmeticOpFunc) (Function, error) {
 		if err != nil {
 			return nil, err
 		}
-		return op(lhs, rhs, leftV, rightV)
-	}, aggregateTargetPaths(lhs, rhs)), nil
+		return opFn(lhs, rhs, leftV, rightV)
+	}, aggregateTargetPaths(lhs, rhs))), nil
 }
 
 //------------------------------------------------------------------------------
@@ -444,11 +444,11 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isProd := prodOp(op); isProd {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, op, opFunc); err != nil {
 				return nil, err
 			}
 		} else if op == ArithmeticPipe {
-			fnsNew[len(fnsNew)-1] = coalesce(leftFn, rightFn)
+			fnsNew[len(fnsNew)-1] = coalesceWithTypeInference(leftFn, rightFn, coalesce(leftFn, rightFn))
 		} else {
 			fnsNew = append(fnsNew, rightFn)
 			opsNew = append(opsNew, op)
@@ -464,7 +464,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isSum := sumOp(op); isSum {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, op, opFunc); err != nil {
 				return nil, err
 			}
 		} else {
@@ -482,7 +482,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isCompare := compareOp(op); isCompare {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, op, opFunc); err != nil {
 				return nil, err
 			}
 		} else {
@@ -501,9 +501,9 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		switch op {
 		case ArithmeticAnd:
-			fnsNew[len(fnsNew)-1] = boolAnd(leftFn, rightFn)
+			fnsNew[len(fnsNew)-1] = arithmeticWithTypeInference(op, leftFn, rightFn, boolAnd(leftFn, rightFn))
 		case ArithmeticOr:
-			fnsNew[len(fnsNew)-1] = boolOr(leftFn, rightFn)
+			fnsNew[len(fnsNew)-1] = arithmeticWithTypeInference(op, leftFn, rightFn, boolOr(leftFn, rightFn))
 		default:
 			fnsNew = append(fnsNew, rightFn)
 			opsNew = append(opsNew, op)
@@ -75,6 +75,10 @@ type FunctionSpec struct {
 
 	// Version is the Benthos version this component was introduced.
 	Version string `json:"version,omitempty"`
+
+	// ReturnType is the type of value the function produces when executed
+	// successfully, used for statically inferring the types of queries.
+	ReturnType ValueType `json:"-"`
 }
 
 // NewFunctionSpec creates a new function spec.
@@ -120,6 +124,12 @@ func (s FunctionSpec) Param(def ParamDefinition) FunctionSpec {
 	return s
 }
 
+// Returns sets the type of value the function produces.
+func (s FunctionSpec) Returns(t ValueType) FunctionSpec {
+	s.ReturnType = t
+	return s
+}
+
 // NewDeprecatedFunctionSpec creates a new function spec that is deprecated.
 func NewDeprecatedFunctionSpec(name, description string, examples ...ExampleSpec) FunctionSpec {
 	return FunctionSpec{
@@ -192,6 +202,14 @@ type MethodSpec struct {
 
 	// Version is the Benthos version this component was introduced.
 	Version string `json:"version,omitempty"`
+
+	// TargetTypes are the types of values the method can be executed upon,
+	// when empty the method is assumed to accept values of any type.
+	TargetTypes []ValueType `json:"-"`
+
+	// ReturnType is the type of value the method produces when executed
+	// successfully, used for statically inferring the types of queries.
+	ReturnType ValueType `json:"-"`
 }
 
 // NewMethodSpec creates a new method spec.
@@ -257,6 +275,18 @@ func (m MethodSpec) Param(def ParamDefinition) MethodSpec {
 	return m
 }
 
+// Accepts sets the types of values the method can be executed upon.
+func (m MethodSpec) Accepts(types ...ValueType) MethodSpec {
+	m.TargetTypes = types
+	return m
+}
+
+// Returns sets the type of value the method produces.
+func (m MethodSpec) Returns(t ValueType) MethodSpec {
+	m.ReturnType = t
+	return m
+}
+
 // VariadicParams configures the method spec to allow variadic parameters.
 func (m MethodSpec) VariadicParams() MethodSpec {
 	m.Params = VariadicParams()
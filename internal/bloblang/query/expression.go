@@ -44,9 +44,11 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 				return nil, fmt.Errorf("failed to check match case %v: %w", i, err)
 			}
 			if matched, _ := caseVal.(bool); matched {
+				ctx.traceBranch("match expression", fmt.Sprintf("case %v", i))
 				return c.queryFn.Exec(caseCtx)
 			}
 		}
+		ctx.traceBranch("match expression", "none")
 		return Nothing(nil), nil
 	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
 		contextCtx, contextTargets := contextFn.QueryTargets(ctx)
@@ -90,6 +92,7 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 			return nil, fmt.Errorf("failed to check if condition: %w", err)
 		}
 		if queryRes, _ := queryVal.(bool); queryRes {
+			ctx.traceBranch("if expression", "if")
 			return ifFn.Exec(ctx)
 		}
 
@@ -99,13 +102,16 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 				return nil, fmt.Errorf("failed to check if condition %v: %w", i+1, err)
 			}
 			if queryRes, _ := queryVal.(bool); queryRes {
+				ctx.traceBranch("if expression", fmt.Sprintf("else if %v", i+1))
 				return eFn.MapFn.Exec(ctx)
 			}
 		}
 
 		if elseFn != nil {
+			ctx.traceBranch("if expression", "else")
 			return elseFn.Exec(ctx)
 		}
+		ctx.traceBranch("if expression", "none")
 		return Nothing(nil), nil
 	}, aggregateTargetPaths(allFns...))
 }
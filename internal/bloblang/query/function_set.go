@@ -72,9 +72,13 @@ func (f *FunctionSet) Init(name string, args *ParsedParams) (Function, error) {
 		return nil, badFunctionErr(name)
 	}
 	if f.disableCtors {
-		return disabledFunction(name), nil
+		return functionWithTypeInference(f.specs[name], args, disabledFunction(name)), nil
 	}
-	return wrapCtorWithDynamicArgs(name, args, ctor)
+	fn, err := wrapCtorWithDynamicArgs(name, args, ctor)
+	if err != nil {
+		return nil, err
+	}
+	return functionWithTypeInference(f.specs[name], args, fn), nil
 }
 
 // Without creates a clone of the function set that can be mutated in isolation,
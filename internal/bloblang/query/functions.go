@@ -187,7 +187,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root = if batch_index() > 0 { deleted() }`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(ctx FunctionContext) (any, error) {
 		return int64(ctx.Index), nil
 	},
@@ -202,7 +202,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.foo = batch_size()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(ctx FunctionContext) (any, error) {
 		return int64(ctx.MsgBatch.Len()), nil
 	},
@@ -219,7 +219,7 @@ var _ = registerSimpleFunction(
 			`{"foo":"bar"}`,
 			`{"doc":"{\"foo\":\"bar\"}"}`,
 		),
-	),
+	).Returns(ValueBytes),
 	func(ctx FunctionContext) (any, error) {
 		return ctx.MsgBatch.Get(ctx.Index).AsBytes(), nil
 	},
@@ -276,7 +276,7 @@ root.id = count("bloblang_function_example")`,
 			`{"message":"bar"}`,
 			`{"id":2,"message":"bar"}`,
 		),
-	).Param(ParamString("name", "An identifier for the counter.")).MarkImpure(),
+	).Returns(ValueNumber).Param(ParamString("name", "An identifier for the counter.")).MarkImpure(),
 	countFunction,
 )
 
@@ -358,7 +358,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.doc.status = if errored() { 400 } else { 200 }`,
 		),
-	),
+	).Returns(ValueBool),
 	func(ctx FunctionContext) (any, error) {
 		return ctx.MsgBatch.Get(ctx.Index).ErrorGet() != nil, nil
 	},
@@ -377,7 +377,7 @@ root.c = range(0, -this.max, -2)`,
 			`{"max":10}`,
 			`{"a":[0,1,2,3,4,5,6,7,8,9],"b":[0,2,4,6,8],"c":[0,-2,-4,-6,-8]}`,
 		),
-	).
+	).Returns(ValueArray).
 		Param(ParamInt64("start", "The start value.")).
 		Param(ParamInt64("stop", "The stop value.")).
 		Param(ParamInt64("step", "The step value.").Default(1)),
@@ -640,7 +640,7 @@ root.sixth = random_int(seed:timestamp_unix_nano(), max:20)
 			`root.first = random_int(timestamp_unix_nano())`,
 			`root.second = random_int(timestamp_unix_nano(), 5, 20)`,
 		),
-	).
+	).Returns(ValueNumber).
 		Param(ParamQuery(
 			"seed",
 			"A seed to use, if a query is provided it will only be resolved once during the lifetime of the mapping.",
@@ -711,7 +711,7 @@ var _ = registerFunction(
 		NewExampleSpec("",
 			`root.received_at = now().ts_format("Mon Jan 2 15:04:05 -0700 MST 2006", "UTC")`,
 		),
-	),
+	).Returns(ValueString),
 	func(args *ParsedParams) (Function, error) {
 		return ClosureFunction("function now", func(_ FunctionContext) (any, error) {
 			return time.Now().Format(time.RFC3339Nano), nil
@@ -726,7 +726,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(_ FunctionContext) (any, error) {
 		return time.Now().Unix(), nil
 	},
@@ -739,7 +739,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix_milli()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(_ FunctionContext) (any, error) {
 		return time.Now().UnixMilli(), nil
 	},
@@ -752,7 +752,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix_micro()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(_ FunctionContext) (any, error) {
 		return time.Now().UnixMicro(), nil
 	},
@@ -765,7 +765,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix_nano()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(_ FunctionContext) (any, error) {
 		return time.Now().UnixNano(), nil
 	},
@@ -808,7 +808,7 @@ var _ = registerSimpleFunction(
 		FunctionCategoryGeneral, "uuid_v4",
 		"Generates a new RFC-4122 UUID each time it is invoked and prints a string representation.",
 		NewExampleSpec("", `root.id = uuid_v4()`),
-	),
+	).Returns(ValueString),
 	func(_ FunctionContext) (any, error) {
 		u4, err := uuid.NewV4()
 		if err != nil {
@@ -827,7 +827,7 @@ var _ = registerFunction(
 		NewExampleSpec("", `root.id = nanoid()`),
 		NewExampleSpec("It is possible to specify an optional length parameter.", `root.id = nanoid(54)`),
 		NewExampleSpec("It is also possible to specify an optional custom alphabet after the length parameter.", `root.id = nanoid(54, "abcde")`),
-	).
+	).Returns(ValueString).
 		Param(ParamInt64("length", "An optional length.").Optional()).
 		Param(ParamString("alphabet", "An optional custom alphabet to use for generating IDs. When specified the field `length` must also be present.").Optional()),
 	nanoidFunction,
@@ -863,7 +863,7 @@ var _ = registerSimpleFunction(
 		FunctionCategoryGeneral, "ksuid",
 		"Generates a new ksuid each time it is invoked and prints a string representation.",
 		NewExampleSpec("", `root.id = ksuid()`),
-	),
+	).Returns(ValueString),
 	func(_ FunctionContext) (any, error) {
 		return ksuid.New().String(), nil
 	},
@@ -884,7 +884,7 @@ var _ = registerFunction(
 
 // NewVarFunction creates a new variable function.
 func NewVarFunction(name string) Function {
-	return ClosureFunction("variable "+name, func(ctx FunctionContext) (any, error) {
+	return withTypeInference(ClosureFunction("variable "+name, func(ctx FunctionContext) (any, error) {
 		if ctx.Vars == nil {
 			return nil, errors.New("variables were undefined")
 		}
@@ -898,5 +898,10 @@ func NewVarFunction(name string) Function {
 		}
 		ctx = ctx.WithValues(paths)
 		return ctx, paths
+	}), func(ctx TypeContext) ValueType {
+		if t, exists := ctx.vars[name]; exists {
+			return t
+		}
+		return ValueUnknown
 	})
 }
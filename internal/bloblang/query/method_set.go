@@ -66,9 +66,13 @@ func (m *MethodSet) Init(name string, target Function, args *ParsedParams) (Func
 		return nil, badMethodErr(name)
 	}
 	if m.disableCtors {
-		return disabledMethod(name), nil
+		return methodWithTypeInference(m.specs[name], target, args, disabledMethod(name)), nil
 	}
-	return wrapMethodCtorWithDynamicArgs(name, target, args, ctor)
+	fn, err := wrapMethodCtorWithDynamicArgs(name, target, args, ctor)
+	if err != nil {
+		return nil, err
+	}
+	return methodWithTypeInference(m.specs[name], target, args, fn), nil
 }
 
 // Without creates a clone of the method set that can be mutated in isolation,
@@ -7,7 +7,7 @@ import (
 )
 
 var _ = registerSimpleMethod(
-	NewMethodSpec("abs", "Returns the absolute value of a number.").InCategory(
+	NewMethodSpec("abs", "Returns the absolute value of a number.").Accepts(ValueNumber).Returns(ValueNumber).InCategory(
 		MethodCategoryNumbers, "",
 		NewExampleSpec("",
 			`root.new_value = this.value.abs()`,
@@ -33,7 +33,7 @@ var _ = registerSimpleMethod(
 )
 
 var _ = registerSimpleMethod(
-	NewMethodSpec("ceil", "Returns the least integer value greater than or equal to a number. If the resulting value fits within a 64-bit integer then that is returned, otherwise a new floating point number is returned.").InCategory(
+	NewMethodSpec("ceil", "Returns the least integer value greater than or equal to a number. If the resulting value fits within a 64-bit integer then that is returned, otherwise a new floating point number is returned.").Accepts(ValueNumber).Returns(ValueNumber).InCategory(
 		MethodCategoryNumbers, "",
 		NewExampleSpec("",
 			`root.new_value = this.value.ceil()`,
@@ -63,7 +63,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"floor", "Returns the greatest integer value less than or equal to the target number. If the resulting value fits within a 64-bit integer then that is returned, otherwise a new floating point number is returned.",
-	).InCategory(
+	).Accepts(ValueNumber).Returns(ValueNumber).InCategory(
 		MethodCategoryNumbers,
 		"",
 		NewExampleSpec("",
@@ -90,7 +90,7 @@ var _ = registerSimpleMethod(
 )
 
 var _ = registerSimpleMethod(
-	NewMethodSpec("log", "Returns the natural logarithm of a number.").InCategory(
+	NewMethodSpec("log", "Returns the natural logarithm of a number.").Accepts(ValueNumber).Returns(ValueNumber).InCategory(
 		MethodCategoryNumbers, "",
 		NewExampleSpec("",
 			`root.new_value = this.value.log().round()`,
@@ -116,7 +116,7 @@ var _ = registerSimpleMethod(
 )
 
 var _ = registerSimpleMethod(
-	NewMethodSpec("log10", "Returns the decimal logarithm of a number.").InCategory(
+	NewMethodSpec("log10", "Returns the decimal logarithm of a number.").Accepts(ValueNumber).Returns(ValueNumber).InCategory(
 		MethodCategoryNumbers, "",
 		NewExampleSpec("",
 			`root.new_value = this.value.log10()`,
@@ -145,7 +145,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"max",
 		"Returns the largest numerical value found within an array. All values must be numerical and the array must not be empty, otherwise an error is returned.",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueNumber).InCategory(
 		MethodCategoryNumbers, "",
 		NewExampleSpec("",
 			`root.biggest = this.values.max()`,
@@ -188,7 +188,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"min",
 		"Returns the smallest numerical value found within an array. All values must be numerical and the array must not be empty, otherwise an error is returned.",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueNumber).InCategory(
 		MethodCategoryNumbers, "",
 		NewExampleSpec("",
 			`root.smallest = this.values.min()`,
@@ -230,7 +230,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"round", "Rounds numbers to the nearest integer, rounding half away from zero. If the resulting value fits within a 64-bit integer then that is returned, otherwise a new floating point number is returned.",
-	).InCategory(
+	).Accepts(ValueNumber).Returns(ValueNumber).InCategory(
 		MethodCategoryNumbers,
 		"",
 		NewExampleSpec("",
@@ -37,7 +37,7 @@ import (
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"bytes", "",
-	).InCategory(
+	).Returns(ValueBytes).InCategory(
 		MethodCategoryCoercion,
 		"Marshal a value into a byte array. If the value is already a byte array it is unchanged.",
 		NewExampleSpec("",
@@ -58,7 +58,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"capitalize", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Takes a string value and returns a copy with all Unicode letters that begin words mapped to their Unicode title case.",
 		NewExampleSpec("",
@@ -85,7 +85,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"encode", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryEncoding,
 		"Encodes a string or byte array target according to a chosen scheme and returns a string result. Available schemes are: `base64`, `base64url`, `hex`, `ascii85`.",
 		// NOTE: z85 has been removed from the list until we can support
@@ -183,7 +183,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"decode", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryEncoding,
 		"Decodes an encoded string target according to a chosen scheme and returns the result as a byte array. When mapping the result to a JSON field the value should be cast to a string using the method [`string`][methods.string], or encoded using the method [`encode`][methods.encode], otherwise it will be base64 encoded by default.\n\nAvailable schemes are: `base64`, `base64url`, `hex`, `ascii85`.",
 		// NOTE: z85 has been removed from the list until we can support
@@ -264,7 +264,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"encrypt_aes", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryEncoding,
 		"Encrypts a string or byte array target according to a chosen AES encryption method and returns a string result. The algorithms require a key and an initialization vector / nonce. Available schemes are: `ctr`, `ofb`, `cbc`.",
 		NewExampleSpec("",
@@ -352,7 +352,7 @@ root.encrypted = this.value.encrypt_aes("ctr", $key, $vector).encode("hex")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"decrypt_aes", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryEncoding,
 		"Decrypts an encrypted string or byte array target according to a chosen AES encryption method and returns the result as a byte array. The algorithms require a key and an initialization vector / nonce. Available schemes are: `ctr`, `ofb`, `cbc`.",
 		NewExampleSpec("",
@@ -439,7 +439,7 @@ root.decrypted = this.value.decode("hex").decrypt_aes("ctr", $key, $vector).stri
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"escape_html", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueString).InCategory(
 		MethodCategoryStrings,
 		"Escapes a string so that special characters like `<` to become `&lt;`. It escapes only five such characters: `<`, `>`, `&`, `'` and `\"` so that it can be safely placed within an HTML entity.",
 		NewExampleSpec("",
@@ -460,7 +460,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"index_of", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueNumber).InCategory(
 		MethodCategoryStrings,
 		"Returns the starting index of the argument substring in a string target, or `-1` if the target doesn't contain the argument.",
 		NewExampleSpec("",
@@ -496,7 +496,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"unescape_html", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueString).InCategory(
 		MethodCategoryStrings,
 		"Unescapes a string so that entities like `&lt;` become `<`. It unescapes a larger range of entities than `escape_html` escapes. For example, `&aacute;` unescapes to `á`, as does `&#225;` and `&xE1;`.",
 		NewExampleSpec("",
@@ -517,7 +517,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"escape_url_query", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueString).InCategory(
 		MethodCategoryStrings,
 		"Escapes a string so that it can be safely placed within a URL query.",
 		NewExampleSpec("",
@@ -538,7 +538,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"unescape_url_query", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueString).InCategory(
 		MethodCategoryStrings,
 		"Expands escape sequences from a URL query string.",
 		NewExampleSpec("",
@@ -559,7 +559,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"filepath_join", "",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueString).InCategory(
 		MethodCategoryStrings,
 		"Joins an array of path elements into a single file path. The separator depends on the operating system of the machine.",
 		NewExampleSpec("",
@@ -590,7 +590,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"filepath_split", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueArray).InCategory(
 		MethodCategoryStrings,
 		"Splits a file path immediately following the final Separator, separating it into a directory and file name component returned as a two element array of strings. If there is no Separator in the path, the first element will be empty and the second will contain the path. The separator depends on the operating system of the machine.",
 		NewExampleSpec("",
@@ -614,7 +614,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"format", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueString).InCategory(
 		MethodCategoryStrings,
 		"Use a value string as a format specifier in order to produce a new string, using any number of provided arguments. Please refer to the Go [`fmt` package documentation](https://pkg.go.dev/fmt) for the list of valid format verbs.",
 		NewExampleSpec("",
@@ -635,7 +635,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"has_prefix", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueBool).InCategory(
 		MethodCategoryStrings,
 		"Checks whether a string has a prefix argument and returns a bool.",
 		NewExampleSpec("",
@@ -668,7 +668,7 @@ root.t2 = this.v2.has_prefix("foo")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"has_suffix", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueBool).InCategory(
 		MethodCategoryStrings,
 		"Checks whether a string has a suffix argument and returns a bool.",
 		NewExampleSpec("",
@@ -701,7 +701,7 @@ root.t2 = this.v2.has_suffix("foo")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"hash", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryEncoding,
 		`
 Hashes a string or byte array according to a chosen algorithm and returns the result as a byte array. When mapping the result to a JSON field the value should be cast to a string using the method `+"[`string`][methods.string], or encoded using the method [`encode`][methods.encode]"+`, otherwise it will be base64 encoded by default.
@@ -841,7 +841,7 @@ root.h2 = this.value.hash(algorithm: "crc32", polynomial: "Koopman").encode("hex
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"join", "",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueString).InCategory(
 		MethodCategoryObjectAndArray,
 		"Join an array of strings with an optional delimiter into a single string.",
 		NewExampleSpec("",
@@ -890,7 +890,7 @@ root.joined_numbers = this.numbers.map_each(this.string()).join(",")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"uppercase", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Convert a string value into uppercase.",
 		NewExampleSpec("",
@@ -918,7 +918,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"lowercase", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Convert a string value into lowercase.",
 		NewExampleSpec("",
@@ -946,7 +946,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"parse_csv", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueArray).InCategory(
 		MethodCategoryParsing,
 		"Attempts to parse a string into an array of objects by following the CSV format described in RFC 4180.",
 		NewExampleSpec("Parses CSV data with a header row",
@@ -1060,7 +1060,7 @@ func parseCSVMethod(args *ParsedParams) (simpleMethod, error) {
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"parse_json", "",
-	).Param(
+	).Accepts(ValueString, ValueBytes).Param(
 		ParamBool("use_number", "An optional flag that when set makes parsing numbers as json.Number instead of the default float64.").Optional(),
 	).InCategory(
 		MethodCategoryParsing,
@@ -1107,7 +1107,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"parse_yaml", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryParsing,
 		"Attempts to parse a string as a single YAML document and returns the result.",
 		NewExampleSpec("",
@@ -1228,7 +1228,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"parse_url", "Attempts to parse a URL from a string value, returning a structured result that describes the various facets of the URL. The fields returned within the structured result roughly follow https://pkg.go.dev/net/url#URL, and may be expanded in future in order to present more information.",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueObject).InCategory(
 		MethodCategoryParsing, "",
 		NewExampleSpec("",
 			`root.foo_url = this.foo_url.parse_url()`,
@@ -1278,7 +1278,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"reverse", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Returns the target string in reverse order.",
 		NewExampleSpec("",
@@ -1319,7 +1319,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"quote", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueString).InCategory(
 		MethodCategoryStrings,
 		"Quotes a target string using escape sequences (`\\t`, `\\n`, `\\xFF`, `\\u0100`) for control characters and non-printable characters.",
 		NewExampleSpec("",
@@ -1340,7 +1340,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"unquote", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueTimestamp).Returns(ValueString).InCategory(
 		MethodCategoryStrings,
 		"Unquotes a target string, expanding any escape sequences (`\\t`, `\\n`, `\\xFF`, `\\u0100`) for control characters and non-printable characters.",
 		NewExampleSpec("",
@@ -1368,7 +1368,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"replace_all", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Replaces all occurrences of the first argument in a target string with the second argument.",
 		NewExampleSpec("",
@@ -1414,7 +1414,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"replace_all_many", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"For each pair of strings in an argument array, replaces all occurrences of the first item of the pair with the second. This is a more compact way of chaining a series of `replace_all` methods.",
 		NewExampleSpec("",
@@ -1478,7 +1478,7 @@ func replaceAllManyImpl(args *ParsedParams) (simpleMethod, error) {
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"re_find_all", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueArray).InCategory(
 		MethodCategoryRegexp,
 		"Returns an array containing all successive matches of a regular expression in a string.",
 		NewExampleSpec("",
@@ -1524,7 +1524,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"re_find_all_submatch", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueArray).InCategory(
 		MethodCategoryRegexp,
 		"Returns an array of arrays containing all successive matches of the regular expression in a string and the matches, if any, of its subexpressions.",
 		NewExampleSpec("",
@@ -1578,7 +1578,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"re_find_object", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueObject).InCategory(
 		MethodCategoryRegexp,
 		"Returns an object containing the first match of the regular expression and the matches of its subexpressions. The key of each match value is the name of the group when specified, otherwise it is the index of the matching group, starting with the expression as a whole at 0.",
 		NewExampleSpec("",
@@ -1635,7 +1635,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"re_find_all_object", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueArray).InCategory(
 		MethodCategoryRegexp,
 		"Returns an array of objects containing all matches of the regular expression and the matches of its subexpressions. The key of each match value is the name of the group when specified, otherwise it is the index of the matching group, starting with the expression as a whole at 0.",
 		NewExampleSpec("",
@@ -1702,7 +1702,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"re_match", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueBool).InCategory(
 		MethodCategoryRegexp,
 		"Checks whether a regular expression matches against any part of a string and returns a boolean.",
 		NewExampleSpec("",
@@ -1749,7 +1749,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"re_replace_all", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryRegexp,
 		"Replaces all occurrences of the argument regular expression in a string with a value. Inside the value $ signs are interpreted as submatch expansions, e.g. `$1` represents the text of the first submatch.",
 		NewExampleSpec("",
@@ -1796,7 +1796,7 @@ func reReplaceAllImpl(args *ParsedParams) (simpleMethod, error) {
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"split", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).Returns(ValueArray).InCategory(
 		MethodCategoryStrings,
 		"Split a string value into an array of strings by splitting it on a string separator.",
 		NewExampleSpec("",
@@ -1838,7 +1838,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"string", "",
-	).InCategory(
+	).Returns(ValueString).InCategory(
 		MethodCategoryCoercion,
 		"Marshal a value into a string. If the value is already a string it is unchanged.",
 		NewExampleSpec("",
@@ -1864,7 +1864,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"strip_html", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Attempts to remove all HTML tags from a target string.",
 		NewExampleSpec("",
@@ -1911,7 +1911,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"trim", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Remove all leading and trailing characters from a string that are contained within an argument cutset. If no arguments are provided then whitespace is removed.",
 		NewExampleSpec("",
@@ -1947,7 +1947,7 @@ root.description = this.description.trim()`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"trim_prefix", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Remove the provided leading prefix substring from a string. If the string does not have the prefix substring, it is returned unchanged.",
 		NewExampleSpec("",
@@ -1978,7 +1978,7 @@ root.description = this.description.trim_prefix("foobar_")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"trim_suffix", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes).InCategory(
 		MethodCategoryStrings,
 		"Remove the provided trailing suffix substring from a string. If the string does not have the suffix substring, it is returned unchanged.",
 		NewExampleSpec("",
@@ -16,7 +16,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"all",
 		"Checks each element of an array against a query and returns true if all elements passed. An error occurs if the target is not an array, or if any element results in the provided query returning a non-boolean result. Returns false if the target array is empty.",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueBool).InCategory(
 		MethodCategoryObjectAndArray,
 		"",
 		NewExampleSpec("",
@@ -62,7 +62,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"any",
 		"Checks the elements of an array against a query and returns true if any element passes. An error occurs if the target is not an array, or if an element results in the provided query returning a non-boolean result. Returns false if the target array is empty.",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueBool).InCategory(
 		MethodCategoryObjectAndArray,
 		"",
 		NewExampleSpec("",
@@ -113,7 +113,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"append",
 		"Returns an array with new elements appended to the end.",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueArray).InCategory(
 		MethodCategoryObjectAndArray,
 		"",
 		NewExampleSpec("",
@@ -176,7 +176,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"contains", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueArray, ValueObject).Returns(ValueBool).InCategory(
 		MethodCategoryObjectAndArray,
 		"Checks whether an array contains an element matching the argument, or an object contains a value matching the argument, and returns a boolean result. Numerical comparisons are made irrespective of the representation type (float versus integer).",
 		NewExampleSpec("",
@@ -243,7 +243,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"enumerated",
 		"Converts an array into a new array of objects, where each object has a field index containing the `index` of the element and a field `value` containing the original value of the element.",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueArray).InCategory(
 		MethodCategoryObjectAndArray, "",
 		NewExampleSpec("",
 			`root.foo = this.foo.enumerated()`,
@@ -545,7 +545,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"flatten",
 		"Iterates an array and any element that is itself an array is removed and has its elements inserted directly in the resulting array.",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueArray).InCategory(
 		MethodCategoryObjectAndArray, "",
 		NewExampleSpec(``,
 			`root.result = this.flatten()`,
@@ -748,7 +748,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"keys",
 		"Returns the keys of an object as an array.",
-	).InCategory(
+	).Accepts(ValueObject).Returns(ValueArray).InCategory(
 		MethodCategoryObjectAndArray, "",
 		NewExampleSpec("",
 			`root.foo_keys = this.foo.keys()`,
@@ -777,7 +777,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"key_values",
 		"Returns the key/value pairs of an object as an array, where each element is an object with a `key` field and a `value` field. The order of the resulting array will be random.",
-	).InCategory(
+	).Accepts(ValueObject).Returns(ValueArray).InCategory(
 		MethodCategoryObjectAndArray, "",
 		NewExampleSpec("",
 			`root.foo_key_values = this.foo.key_values().sort_by(pair -> pair.key)`,
@@ -808,7 +808,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"length", "",
-	).InCategory(
+	).Accepts(ValueString, ValueBytes, ValueArray, ValueObject).Returns(ValueNumber).InCategory(
 		MethodCategoryStrings, "Returns the length of a string.",
 		NewExampleSpec("",
 			`root.foo_len = this.foo.length()`,
@@ -932,7 +932,7 @@ Apply a mapping to each value of an object and replace the value with the result
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"map_each_key", "",
-	).InCategory(
+	).Accepts(ValueObject).Returns(ValueObject).InCategory(
 		MethodCategoryObjectAndArray, `Apply a mapping to each key of an object, and replace the key with the result, which must be a string.`,
 		NewExampleSpec(``,
 			`root.new_dict = this.dict.map_each_key(key -> key.uppercase())`,
@@ -1141,7 +1141,7 @@ var _ = registerSimpleMethod(
 var _ = registerMethod(
 	NewMethodSpec(
 		"sort", "",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueArray).InCategory(
 		MethodCategoryObjectAndArray,
 		"Attempts to sort the values of an array in increasing order. The type of all values must match in order for the ordering to succeed. Supports string and number values.",
 		NewExampleSpec("",
@@ -1247,7 +1247,7 @@ func sortMethod(target Function, args *ParsedParams) (Function, error) {
 var _ = registerMethod(
 	NewMethodSpec(
 		"sort_by", "",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueArray).InCategory(
 		MethodCategoryObjectAndArray,
 		"Attempts to sort the elements of an array, in increasing order, by a value emitted by an argument query applied to each element. The type of all values must match in order for the ordering to succeed. Supports string and number values.",
 		NewExampleSpec("",
@@ -1438,7 +1438,7 @@ func sliceMethod(args *ParsedParams) (simpleMethod, error) {
 var _ = registerMethod(
 	NewMethodSpec(
 		"sum", "",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueNumber).InCategory(
 		MethodCategoryObjectAndArray,
 		"Sum the numerical values of an array.",
 		NewExampleSpec("",
@@ -1483,7 +1483,7 @@ func sumMethod(target Function, _ *ParsedParams) (Function, error) {
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"unique", "",
-	).InCategory(
+	).Accepts(ValueArray).Returns(ValueArray).InCategory(
 		MethodCategoryObjectAndArray,
 		"Attempts to remove duplicate values from an array. The array may contain a combination of different value types, but numbers and strings are checked separately (`\"5\"` is a different element to `5`).",
 		NewExampleSpec("",
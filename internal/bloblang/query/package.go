@@ -59,6 +59,9 @@ type FunctionContext struct {
 
 	// Used to track how many maps we've entered.
 	stackCount int
+
+	// Optionally reports the branches taken by match and if expressions.
+	branchTracer func(b BranchTrace)
 }
 
 type namedContextValue struct {
@@ -140,6 +143,26 @@ func (ctx FunctionContext) PopValue() (*any, FunctionContext) {
 	return retValue, ctx
 }
 
+// BranchTrace describes a branch of a match or if expression that was taken
+// during the execution of a query.
+type BranchTrace struct {
+	Expression string `json:"expression"`
+	Branch     string `json:"branch"`
+}
+
+// WithBranchTracer returns a function context where the branches taken by match
+// and if expressions are reported to a provided closure.
+func (ctx FunctionContext) WithBranchTracer(fn func(b BranchTrace)) FunctionContext {
+	ctx.branchTracer = fn
+	return ctx
+}
+
+func (ctx FunctionContext) traceBranch(expression, branch string) {
+	if ctx.branchTracer != nil {
+		ctx.branchTracer(BranchTrace{Expression: expression, Branch: branch})
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // ExecToString returns a string from a function execution.
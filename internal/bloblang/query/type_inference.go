@@ -0,0 +1,368 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TypeSchema describes the types of a structured value, and is used in order
+// to infer the types of fields referenced by queries.
+type TypeSchema struct {
+	Type       ValueType
+	Properties map[string]*TypeSchema
+	Items      *TypeSchema
+}
+
+// TypeSchemaFromJSONSchema creates a TypeSchema from a parsed JSON Schema
+// document. Only the `type`, `properties` and `items` keywords are considered,
+// and values that might be one of several types are treated as unknown.
+func TypeSchemaFromJSONSchema(schema any) (*TypeSchema, error) {
+	obj, ok := schema.(map[string]any)
+	if !ok {
+		if b, isBool := schema.(bool); isBool && b {
+			return &TypeSchema{Type: ValueUnknown}, nil
+		}
+		return nil, fmt.Errorf("expected schema object, got %v", ITypeOf(schema))
+	}
+
+	s := &TypeSchema{Type: ValueUnknown}
+	switch t := obj["type"].(type) {
+	case string:
+		switch t {
+		case "string":
+			s.Type = ValueString
+		case "integer", "number":
+			s.Type = ValueNumber
+		case "boolean":
+			s.Type = ValueBool
+		case "array":
+			s.Type = ValueArray
+		case "object":
+			s.Type = ValueObject
+		case "null":
+			s.Type = ValueNull
+		default:
+			return nil, fmt.Errorf("unrecognised schema type: %v", t)
+		}
+	case nil:
+		if _, hasProps := obj["properties"]; hasProps {
+			s.Type = ValueObject
+		}
+	}
+
+	if props, ok := obj["properties"].(map[string]any); ok {
+		s.Properties = make(map[string]*TypeSchema, len(props))
+		for k, v := range props {
+			propSchema, err := TypeSchemaFromJSONSchema(v)
+			if err != nil {
+				return nil, fmt.Errorf("property %v: %w", k, err)
+			}
+			s.Properties[k] = propSchema
+		}
+	}
+
+	if items, exists := obj["items"]; exists {
+		itemsSchema, err := TypeSchemaFromJSONSchema(items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+		s.Items = itemsSchema
+	}
+	return s, nil
+}
+
+// TypeOf returns the type of a value at a path within the described value, or
+// ValueUnknown if the type cannot be determined.
+func (s *TypeSchema) TypeOf(path ...string) ValueType {
+	for _, seg := range path {
+		if s == nil {
+			return ValueUnknown
+		}
+		switch s.Type {
+		case ValueObject:
+			s = s.Properties[seg]
+		case ValueArray:
+			if _, err := strconv.Atoi(seg); err != nil {
+				return ValueUnknown
+			}
+			s = s.Items
+		default:
+			return ValueUnknown
+		}
+	}
+	if s == nil || s.Type == "" {
+		return ValueUnknown
+	}
+	return s.Type
+}
+
+//------------------------------------------------------------------------------
+
+// TypeContext contains information about the context of a query that is used
+// in order to statically infer the types of values it produces.
+type TypeContext struct {
+	this *TypeSchema
+	vars map[string]ValueType
+	errs *[]error
+}
+
+// NewTypeContext creates a type context where the type of the context (`this`)
+// of queries is described by an optional schema.
+func NewTypeContext(this *TypeSchema) TypeContext {
+	return TypeContext{
+		this: this,
+		vars: map[string]ValueType{},
+	}
+}
+
+// SetVarType sets the type of a variable that was assigned to, a variable that
+// is assigned values of differing types becomes unknown.
+func (ctx TypeContext) SetVarType(name string, t ValueType) {
+	if existing, exists := ctx.vars[name]; exists && existing != t {
+		t = ValueUnknown
+	}
+	ctx.vars[name] = t
+}
+
+// withUnknownContext returns a type context where the context of queries is
+// unknown, which is the case for queries that are executed by methods and
+// functions on values of their choosing.
+func (ctx TypeContext) withUnknownContext() TypeContext {
+	ctx.this = nil
+	return ctx
+}
+
+func (ctx TypeContext) report(err error) {
+	if ctx.errs != nil {
+		*ctx.errs = append(*ctx.errs, err)
+	}
+}
+
+// typeInferrer is implemented by functions that are able to statically infer
+// the type of values they produce, and report definite type errors within
+// themselves and their children.
+type typeInferrer interface {
+	inferType(ctx TypeContext) ValueType
+}
+
+// InferType attempts to statically infer the type of the value produced by a
+// function, along with any definite type errors found within it. When the type
+// cannot be determined ValueUnknown is returned.
+func InferType(ctx TypeContext, fn Function) (ValueType, []error) {
+	var errs []error
+	ctx.errs = &errs
+	return inferType(ctx, fn), errs
+}
+
+func inferType(ctx TypeContext, fn Function) ValueType {
+	if t, ok := fn.(typeInferrer); ok {
+		return t.inferType(ctx)
+	}
+	return ValueUnknown
+}
+
+// inferArgTypes walks the arguments of a function or method in order to report
+// type errors within them. Dynamic arguments are resolved within the context of
+// the caller, whereas query arguments are executed on values of the function
+// or method's choosing.
+func inferArgTypes(ctx TypeContext, args *ParsedParams) {
+	if args == nil {
+		return
+	}
+	dynIndexes := map[int]struct{}{}
+	for _, dyn := range args.dynArgs {
+		dynIndexes[dyn.index] = struct{}{}
+		_ = inferType(ctx, dyn.fn)
+	}
+	unknownCtx := ctx.withUnknownContext()
+	for i, v := range args.values {
+		if _, isDyn := dynIndexes[i]; isDyn {
+			continue
+		}
+		if fn, ok := v.(Function); ok {
+			_ = inferType(unknownCtx, fn)
+		}
+	}
+}
+
+// isKnownType returns true if a type describes a concrete value.
+func isKnownType(t ValueType) bool {
+	switch t {
+	case ValueString, ValueBytes, ValueNumber, ValueBool, ValueTimestamp,
+		ValueArray, ValueObject, ValueNull:
+		return true
+	}
+	return false
+}
+
+func typeIn(t ValueType, types ...ValueType) bool {
+	for _, v := range types {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+func typesStr(types []ValueType) string {
+	strs := make([]string, len(types))
+	for i, t := range types {
+		strs[i] = string(t)
+	}
+	if len(strs) == 1 {
+		return strs[0]
+	}
+	return strings.Join(strs[:len(strs)-1], ", ") + " or " + strs[len(strs)-1]
+}
+
+//------------------------------------------------------------------------------
+
+// typedFunction wraps a function with a closure that infers the type of the
+// values it produces.
+type typedFunction struct {
+	Function
+	infer func(ctx TypeContext) ValueType
+}
+
+func (t typedFunction) inferType(ctx TypeContext) ValueType {
+	return t.infer(ctx)
+}
+
+// withTypeInference wraps a function with type inference unless it already
+// supports it, as some functions are expanded based on their concrete type.
+func withTypeInference(fn Function, infer func(ctx TypeContext) ValueType) Function {
+	if _, isTyped := fn.(typeInferrer); isTyped {
+		return fn
+	}
+	return typedFunction{Function: fn, infer: infer}
+}
+
+func methodWithTypeInference(spec MethodSpec, target Function, args *ParsedParams, fn Function) Function {
+	return withTypeInference(fn, func(ctx TypeContext) ValueType {
+		targetType := inferType(ctx, target)
+		inferArgTypes(ctx, args)
+		if len(spec.TargetTypes) > 0 && isKnownType(targetType) && !typeIn(targetType, spec.TargetTypes...) {
+			ctx.report(fmt.Errorf("method %v: expected %v value, got %v from %v", spec.Name, typesStr(spec.TargetTypes), targetType, target.Annotation()))
+		}
+		if spec.ReturnType == "" {
+			return ValueUnknown
+		}
+		return spec.ReturnType
+	})
+}
+
+func functionWithTypeInference(spec FunctionSpec, args *ParsedParams, fn Function) Function {
+	return withTypeInference(fn, func(ctx TypeContext) ValueType {
+		inferArgTypes(ctx, args)
+		if spec.ReturnType == "" {
+			return ValueUnknown
+		}
+		return spec.ReturnType
+	})
+}
+
+func arithmeticTypeErr(op ArithmeticOperator, lhs, rhs Function, lType, rType ValueType) error {
+	return fmt.Errorf("cannot %v types %v (from %v) and %v (from %v)", op, lType, lhs.Annotation(), rType, rhs.Annotation())
+}
+
+func arithmeticWithTypeInference(op ArithmeticOperator, lhs, rhs, fn Function) Function {
+	return withTypeInference(fn, func(ctx TypeContext) ValueType {
+		lType, rType := inferType(ctx, lhs), inferType(ctx, rhs)
+
+		isStr := typeIn(lType, ValueString, ValueBytes) || typeIn(rType, ValueString, ValueBytes)
+
+		var resType ValueType
+		var valid []ValueType
+		switch op {
+		case ArithmeticAdd:
+			resType, valid = ValueNumber, []ValueType{ValueNumber}
+			if isStr {
+				resType, valid = ValueString, []ValueType{ValueString, ValueBytes}
+			} else if !isKnownType(lType) && !isKnownType(rType) {
+				resType = ValueUnknown
+			}
+		case ArithmeticSub, ArithmeticMul, ArithmeticDiv, ArithmeticMod:
+			resType, valid = ValueNumber, []ValueType{ValueNumber}
+		case ArithmeticGt, ArithmeticGte, ArithmeticLt, ArithmeticLte:
+			resType, valid = ValueBool, []ValueType{ValueNumber}
+			if isStr {
+				valid = []ValueType{ValueString, ValueBytes}
+			}
+		case ArithmeticEq, ArithmeticNeq:
+			return ValueBool
+		case ArithmeticAnd, ArithmeticOr:
+			resType, valid = ValueBool, []ValueType{ValueBool}
+		default:
+			return ValueUnknown
+		}
+
+		if (isKnownType(lType) && !typeIn(lType, valid...)) ||
+			(isKnownType(rType) && !typeIn(rType, valid...)) {
+			if lType == ValueTimestamp || rType == ValueTimestamp {
+				// Timestamps are compared and serialised in flexible ways.
+				return ValueUnknown
+			}
+			ctx.report(arithmeticTypeErr(op, lhs, rhs, lType, rType))
+		}
+		return resType
+	})
+}
+
+func coalesceWithTypeInference(lhs, rhs, fn Function) Function {
+	return withTypeInference(fn, func(ctx TypeContext) ValueType {
+		lType, rType := inferType(ctx, lhs), inferType(ctx, rhs)
+		if lType == rType {
+			return lType
+		}
+		if lType == ValueNull {
+			return rType
+		}
+		return ValueUnknown
+	})
+}
+
+//------------------------------------------------------------------------------
+
+func (l *Literal) inferType(ctx TypeContext) ValueType {
+	return ITypeOf(l.Value)
+}
+
+func (m *mapLiteral) inferType(ctx TypeContext) ValueType {
+	for _, kv := range m.keyValues {
+		for _, v := range kv {
+			if fn, ok := v.(Function); ok {
+				_ = inferType(ctx, fn)
+			}
+		}
+	}
+	return ValueObject
+}
+
+func (a *arrayLiteral) inferType(ctx TypeContext) ValueType {
+	for _, v := range a.values {
+		if fn, ok := v.(Function); ok {
+			_ = inferType(ctx, fn)
+		}
+	}
+	return ValueArray
+}
+
+func (f *fieldFunction) inferType(ctx TypeContext) ValueType {
+	if f.fromRoot || f.namedContext != "" || ctx.this == nil {
+		return ValueUnknown
+	}
+	return ctx.this.TypeOf(f.path...)
+}
+
+func (g *getMethod) inferType(ctx TypeContext) ValueType {
+	_ = inferType(ctx, g.fn)
+	return ValueUnknown
+}
+
+func (n *notMethod) inferType(ctx TypeContext) ValueType {
+	if t := inferType(ctx, n.fn); isKnownType(t) && t != ValueBool {
+		ctx.report(fmt.Errorf("cannot negate %v (from %v)", t, n.fn.Annotation()))
+	}
+	return ValueBool
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeSchemaFromJSONSchema(t *testing.T) {
+	schema, err := TypeSchemaFromJSONSchema(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"a": map[string]any{"type": "string"},
+			"b": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "integer"},
+			},
+			"c": map[string]any{"type": []any{"string", "null"}},
+			"d": true,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ValueObject, schema.TypeOf())
+	assert.Equal(t, ValueString, schema.TypeOf("a"))
+	assert.Equal(t, ValueUnknown, schema.TypeOf("a", "b"))
+	assert.Equal(t, ValueArray, schema.TypeOf("b"))
+	assert.Equal(t, ValueNumber, schema.TypeOf("b", "5"))
+	assert.Equal(t, ValueUnknown, schema.TypeOf("b", "nope"))
+	assert.Equal(t, ValueUnknown, schema.TypeOf("c"))
+	assert.Equal(t, ValueUnknown, schema.TypeOf("d"))
+	assert.Equal(t, ValueUnknown, schema.TypeOf("e"))
+
+	_, err = TypeSchemaFromJSONSchema(map[string]any{
+		"properties": map[string]any{
+			"a": map[string]any{"type": "nope"},
+		},
+	})
+	require.EqualError(t, err, "property a: unrecognised schema type: nope")
+}
+
+func TestInferTypeLiterals(t *testing.T) {
+	tests := []struct {
+		fn  Function
+		exp ValueType
+	}{
+		{fn: NewLiteralFunction("", "foo"), exp: ValueString},
+		{fn: NewLiteralFunction("", int64(5)), exp: ValueNumber},
+		{fn: NewFieldFunction("foo"), exp: ValueUnknown},
+		{fn: NewVarFunction("foo"), exp: ValueUnknown},
+	}
+
+	for _, test := range tests {
+		tType, errs := InferType(NewTypeContext(nil), test.fn)
+		assert.Empty(t, errs)
+		assert.Equal(t, test.exp, tType, test.fn.Annotation())
+	}
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"fmt"
+)
+
+// UserFunction is a function defined within a mapping with a list of named
+// parameters. When called the arguments are resolved and provided to the body
+// of the function as variables, with all other variables of the calling
+// context isolated from the body.
+type UserFunction struct {
+	name   string
+	params Params
+	body   Function
+}
+
+// NewUserFunction creates a user defined function with a name, a list of
+// parameter names and a body that is executed when the function is called.
+func NewUserFunction(name string, paramNames []string, body Function) (*UserFunction, error) {
+	params := NewParams()
+	for _, n := range paramNames {
+		params = params.Add(ParamQuery(n, "", true))
+	}
+	if err := params.validate(); err != nil {
+		return nil, fmt.Errorf("function %v: %w", name, err)
+	}
+	return &UserFunction{
+		name:   name,
+		params: params,
+		body:   body,
+	}, nil
+}
+
+// Name returns the name of the function.
+func (u *UserFunction) Name() string {
+	return u.name
+}
+
+// Params returns the parameters of the function.
+func (u *UserFunction) Params() Params {
+	return u.params
+}
+
+// Init returns a Function that calls the user defined function with the
+// provided arguments, which must have been populated from the parameters of
+// the function.
+func (u *UserFunction) Init(args *ParsedParams) (Function, error) {
+	argFns := make([]Function, len(u.params.Definitions))
+	for i, p := range u.params.Definitions {
+		var err error
+		if argFns[i], err = args.FieldQuery(p.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return withTypeInference(ClosureFunction("function "+u.name, func(ctx FunctionContext) (any, error) {
+		vars := make(map[string]any, len(argFns))
+		for i, fn := range argFns {
+			v, err := fn.Exec(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("function %v argument %v: %w", u.name, u.params.Definitions[i].Name, err)
+			}
+			vars[u.params.Definitions[i].Name] = v
+		}
+
+		// ISOLATED VARIABLES
+		ctx.Vars = vars
+		return u.body.Exec(ctx)
+	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
+		var paths []TargetPath
+		for _, fn := range argFns {
+			_, argPaths := fn.QueryTargets(ctx)
+			paths = append(paths, argPaths...)
+		}
+		_, bodyPaths := u.body.QueryTargets(ctx)
+		for _, p := range bodyPaths {
+			// Variables within the body refer to the parameters of the
+			// function and are therefore already covered by the arguments.
+			if p.Type != TargetVariable {
+				paths = append(paths, p)
+			}
+		}
+		return ctx.WithValues(paths), paths
+	}), func(ctx TypeContext) ValueType {
+		for _, fn := range argFns {
+			_ = inferType(ctx, fn)
+		}
+		return ValueUnknown
+	}), nil
+}
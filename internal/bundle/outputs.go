@@ -1,9 +1,11 @@
 package bundle
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -81,10 +83,45 @@ func (s *OutputSet) Init(
 		return nil, component.ErrInvalidType("output", conf.Type)
 	}
 	c, err := spec.constructor(conf, mgr, pipelines...)
+	if err == nil && conf.DeadLetter != nil {
+		if c, err = wrapDeadLetter(c, *conf.DeadLetter, mgr); err != nil {
+			err = fmt.Errorf("dead_letter: %w", err)
+		}
+	}
 	err = wrapComponentErr(mgr, "output", err)
 	return c, err
 }
 
+func wrapDeadLetter(out output.Streamed, conf output.DeadLetterConfig, mgr NewManagement) (output.Streamed, error) {
+	if conf.Output == nil {
+		out.TriggerCloseNow()
+		return nil, errors.New("an output must be specified")
+	}
+
+	var exec *mapping.Executor
+	if conf.Mapping != "" {
+		var err error
+		if exec, err = mgr.BloblEnvironment().NewMapping(conf.Mapping); err != nil {
+			out.TriggerCloseNow()
+			return nil, fmt.Errorf("failed to parse mapping: %w", err)
+		}
+	}
+
+	deadLetter, err := mgr.IntoPath("dead_letter", "output").NewOutput(*conf.Output)
+	if err != nil {
+		out.TriggerCloseNow()
+		return nil, err
+	}
+
+	d, err := output.WrapWithDeadLetter(out, deadLetter, exec, mgr.Label(), conf.MaxAttempts, mgr.Logger())
+	if err != nil {
+		out.TriggerCloseNow()
+		deadLetter.TriggerCloseNow()
+		return nil, err
+	}
+	return d, nil
+}
+
 // Docs returns a slice of output specs, which document each method.
 func (s *OutputSet) Docs() []docs.ComponentSpec {
 	var docs []docs.ComponentSpec
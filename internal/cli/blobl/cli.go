@@ -2,6 +2,7 @@ package blobl
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -56,6 +57,10 @@ Find out more about Bloblang at: https://benthos.dev/docs/guides/bloblang/about`
 				Aliases: []string{"f"},
 				Usage:   "execute a mapping from a file.",
 			},
+			&cli.BoolFlag{
+				Name:  "trace",
+				Usage: "print a JSON trace of each statement executed for each document instead of the result, including the state of the result and variables after each statement.",
+			},
 			&cli.IntFlag{
 				Name:  "max-token-length",
 				Usage: "Set the buffer size for document lines.",
@@ -99,6 +104,12 @@ Find out more about Bloblang at: https://benthos.dev/docs/guides/bloblang/about`
 						Aliases: []string{"i"},
 						Usage:   "an optional path to an input file to load as the initial input to the mapping within the app.",
 					},
+					&cli.StringFlag{
+						Name:    "schema-file",
+						Value:   "",
+						Aliases: []string{"s"},
+						Usage:   "an optional path to a JSON Schema file describing the input document, which is used in order to detect type errors within the mapping.",
+					},
 					&cli.BoolFlag{
 						Name:    "write",
 						Value:   false,
@@ -124,6 +135,19 @@ func newExecCache() *execCache {
 }
 
 func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutput bool, input []byte) (string, error) {
+	return e.execute(exec, rawInput, prettyOutput, nil, input)
+}
+
+// traceMapping executes a mapping and returns a trace of each statement
+// executed along with the result, the trace is returned even when the mapping
+// fails.
+func (e *execCache) traceMapping(exec *mapping.Executor, rawInput, prettyOutput bool, input []byte) (string, []mapping.StatementTrace, error) {
+	traces := []mapping.StatementTrace{}
+	res, err := e.execute(exec, rawInput, prettyOutput, &traces, input)
+	return res, traces, err
+}
+
+func (e *execCache) execute(exec *mapping.Executor, rawInput, prettyOutput bool, traces *[]mapping.StatementTrace, input []byte) (string, error) {
 	e.msg.Get(0).SetBytes(input)
 
 	var valuePtr *any
@@ -154,17 +178,25 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 	}
 
 	var result any = query.Nothing(nil)
-	err := exec.ExecOnto(query.FunctionContext{
+	ctx := query.FunctionContext{
 		Maps:     exec.Maps(),
 		Vars:     e.vars,
 		MsgBatch: e.msg,
 		NewMeta:  e.msg.Get(0),
 		NewValue: &result,
-	}.WithValueFunc(lazyValue), mapping.AssignmentContext{
+	}.WithValueFunc(lazyValue)
+	onto := mapping.AssignmentContext{
 		Vars:  e.vars,
 		Meta:  e.msg.Get(0),
 		Value: &result,
-	})
+	}
+
+	var err error
+	if traces != nil {
+		*traces, err = exec.ExecOntoTraced(ctx, onto)
+	} else {
+		err = exec.ExecOnto(ctx, onto)
+	}
 	if err != nil {
 		var ctxErr query.ErrNoContext
 		if parseErr != nil && errors.As(err, &ctxErr) {
@@ -210,6 +242,35 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 	return resultStr, nil
 }
 
+// traceDocument executes a mapping on a document and returns a JSON encoded
+// trace of the execution.
+func traceDocument(e *execCache, exec *mapping.Executor, rawInput, prettyOutput bool, input []byte) string {
+	resultStr, traces, err := e.traceMapping(exec, rawInput, false, input)
+
+	res := struct {
+		Result string                   `json:"result,omitempty"`
+		Error  string                   `json:"error,omitempty"`
+		Trace  []mapping.StatementTrace `json:"trace"`
+	}{
+		Result: resultStr,
+		Trace:  traces,
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	var resBytes []byte
+	if prettyOutput {
+		resBytes, err = json.MarshalIndent(res, "", "  ")
+	} else {
+		resBytes, err = json.Marshal(res)
+	}
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return string(resBytes)
+}
+
 func run(c *cli.Context) error {
 	t := c.Int("threads")
 	if t < 1 {
@@ -217,6 +278,7 @@ func run(c *cli.Context) error {
 	}
 	raw := c.Bool("raw")
 	pretty := c.Bool("pretty")
+	trace := c.Bool("trace")
 	file := c.String("file")
 	m := c.Args().First()
 
@@ -280,6 +342,11 @@ func run(c *cli.Context) error {
 					return
 				}
 
+				if trace {
+					resultsChan <- traceDocument(execCache, exec, raw, pretty, input)
+					continue
+				}
+
 				resultStr, err := execCache.executeMapping(exec, raw, pretty, input)
 				if err != nil {
 					fmt.Fprintln(os.Stderr, red(fmt.Sprintf("failed to execute map: %v", err)))
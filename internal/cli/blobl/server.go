@@ -21,7 +21,9 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
 	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
 
 	_ "embed"
@@ -140,16 +142,37 @@ func (f *fileSync) mapping() string {
 	return f.mappingString
 }
 
+func readTypeSchema(path string) (*query.TypeSchema, error) {
+	if path == "" {
+		return nil, nil
+	}
+	schemaBytes, err := ifs.ReadFile(ifs.OS(), path)
+	if err != nil {
+		return nil, err
+	}
+	var schema any
+	if err := json.Unmarshal(schemaBytes, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema file: %w", err)
+	}
+	return query.TypeSchemaFromJSONSchema(schema)
+}
+
 func runServer(c *cli.Context) error {
 	fSync := newFileSync(c.String("input-file"), c.String("mapping-file"), c.Bool("write"))
 	defer fSync.write()
 
+	typeSchema, err := readTypeSchema(c.String("schema-file"))
+	if err != nil {
+		return fmt.Errorf("failed to read schema file: %w", err)
+	}
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/execute", func(w http.ResponseWriter, r *http.Request) {
 		req := struct {
 			Mapping string `json:"mapping"`
 			Input   string `json:"input"`
+			Trace   bool   `json:"trace"`
 		}{}
 		dec := json.NewDecoder(r.Body)
 		if err := dec.Decode(&req); err != nil {
@@ -160,9 +183,11 @@ func runServer(c *cli.Context) error {
 		fSync.update(req.Input, req.Mapping)
 
 		res := struct {
-			ParseError   string `json:"parse_error"`
-			MappingError string `json:"mapping_error"`
-			Result       string `json:"result"`
+			ParseError   string                   `json:"parse_error"`
+			MappingError string                   `json:"mapping_error"`
+			Result       string                   `json:"result"`
+			Lints        []mapping.Lint           `json:"lints"`
+			Trace        []mapping.StatementTrace `json:"trace,omitempty"`
 		}{}
 		defer func() {
 			resBytes, err := json.Marshal(res)
@@ -182,9 +207,16 @@ func runServer(c *cli.Context) error {
 			}
 			return
 		}
+		res.Lints = exec.Lint(typeSchema)
 
 		execCache := newExecCache()
-		output, err := execCache.executeMapping(exec, false, true, []byte(req.Input))
+
+		var output string
+		if req.Trace {
+			output, res.Trace, err = execCache.traceMapping(exec, false, true, []byte(req.Input))
+		} else {
+			output, err = execCache.executeMapping(exec, false, true, []byte(req.Input))
+		}
 		if err != nil {
 			res.MappingError = err.Error()
 		} else {
@@ -72,7 +72,12 @@ func RunService(c *cli.Context, version, dateBuilt string, streamsMode bool) int
 	watching := c.Bool("watcher")
 	if streamsMode {
 		enableStreamsAPI := !c.Bool("no-api")
-		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, confReader, stoppableManager.Manager())
+		store, err := newStreamsStore(c, stoppableManager.Manager())
+		if err != nil {
+			logger.Errorf("Failed to create streams store: %v\n", err)
+			return 1
+		}
+		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, store, confReader, stoppableManager.Manager())
 	} else {
 		stoppableStream, dataStreamClosedChan = initNormalMode(conf, strict, watching, confReader, stoppableManager.Manager())
 	}
@@ -104,13 +109,50 @@ func DelayShutdown(ctx context.Context, duration time.Duration) error {
 	return nil
 }
 
+// newStreamsStore creates a persistence backend for streams mode from the
+// flags of the streams subcommand, or returns nil if none were set.
+func newStreamsStore(c *cli.Context, mgr *manager.Type) (strmmgr.Store, error) {
+	storeDir, storeCache := c.String("store-dir"), c.String("store-cache")
+	if storeDir != "" && storeCache != "" {
+		return nil, errors.New("flags --store-dir and --store-cache cannot be set simultaneously")
+	}
+	if storeDir != "" {
+		store, err := strmmgr.NewDirStore(storeDir, mgr.Logger())
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	if storeCache != "" {
+		store, err := strmmgr.NewCacheStore(mgr, storeCache, c.String("store-cache-prefix"))
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	return nil, nil
+}
+
 func initStreamsMode(
 	strict, watching, enableAPI bool,
+	store strmmgr.Store,
 	confReader *config.Reader,
 	mgr *manager.Type,
 ) Stoppable {
 	logger := mgr.Logger()
-	streamMgr := strmmgr.New(mgr, strmmgr.OptAPIEnabled(enableAPI))
+
+	opts := []func(*strmmgr.Type){strmmgr.OptAPIEnabled(enableAPI)}
+	if store != nil {
+		opts = append(opts, strmmgr.OptStore(store))
+	}
+	streamMgr := strmmgr.New(mgr, opts...)
+
+	if store != nil {
+		if err := streamMgr.Load(context.Background()); err != nil {
+			logger.Errorf("Failed to restore persisted streams: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	streamConfs := map[string]stream.Config{}
 	lints, err := confReader.ReadStreams(streamConfs)
@@ -132,7 +174,13 @@ func initStreamsMode(
 	}
 
 	for id, conf := range streamConfs {
-		if err := streamMgr.Create(id, conf); err != nil {
+		err := streamMgr.Create(id, conf)
+		if errors.Is(err, strmmgr.ErrStreamExists) {
+			// Streams provided as config files take precedence over those
+			// restored from a store.
+			err = streamMgr.Update(context.Background(), id, conf)
+		}
+		if err != nil {
 			logger.Errorf("Failed to create stream (%v): %v\n", id, err)
 			os.Exit(1)
 		}
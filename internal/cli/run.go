@@ -28,6 +28,15 @@ var (
 	DateBuilt = "unknown"
 )
 
+var wasmPluginLoader func(paths ...string) error
+
+// SetWASMPluginLoader sets a function used to load WASM plugin modules that are
+// specified with the --wasm-plugins flag. This allows the CLI to support WASM
+// plugins without depending on a WASM runtime itself.
+func SetWASMPluginLoader(fn func(paths ...string) error) {
+	wasmPluginLoader = fn
+}
+
 func init() {
 	if Version != "unknown" {
 		return
@@ -101,6 +110,10 @@ func App() *cli.App {
 			Aliases: []string{"t"},
 			Usage:   "EXPERIMENTAL: import Benthos templates, supports glob patterns (requires quotes)",
 		},
+		&cli.StringSliceFlag{
+			Name:  "wasm-plugins",
+			Usage: "EXPERIMENTAL: import plugin components implemented as WASM modules, supports glob patterns (requires quotes)",
+		},
 		&cli.BoolFlag{
 			Name:  "chilled",
 			Value: false,
@@ -150,6 +163,22 @@ Either run Benthos as a stream processor or choose a command:
 				fmt.Printf("Failed to resolve template glob pattern: %v\n", err)
 				os.Exit(1)
 			}
+			wasmPluginPaths, err := filepath.Globs(ifs.OS(), c.StringSlice("wasm-plugins"))
+			if err != nil {
+				fmt.Printf("Failed to resolve WASM plugin glob pattern: %v\n", err)
+				os.Exit(1)
+			}
+			if len(wasmPluginPaths) > 0 {
+				if wasmPluginLoader == nil {
+					fmt.Fprintln(os.Stderr, "WASM plugins are not supported by this build")
+					os.Exit(1)
+				}
+				if err := wasmPluginLoader(wasmPluginPaths...); err != nil {
+					fmt.Fprintf(os.Stderr, "WASM plugin load error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+
 			lints, err := template.InitTemplates(templatesPaths...)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Template file read error: %v\n", err)
@@ -248,6 +277,21 @@ https://benthos.dev/docs/guides/streams_mode/about`[1:],
 						Value: true,
 						Usage: "Whether HTTP endpoints registered by stream configs should be prefixed with the stream ID",
 					},
+					&cli.StringFlag{
+						Name:  "store-dir",
+						Value: "",
+						Usage: "A directory to persist streams to, allowing streams created via the HTTP API to survive restarts",
+					},
+					&cli.StringFlag{
+						Name:  "store-cache",
+						Value: "",
+						Usage: "The name of a cache resource to persist streams to, allowing streams created via the HTTP API to survive restarts",
+					},
+					&cli.StringFlag{
+						Name:  "store-cache-prefix",
+						Value: "benthos_streams_",
+						Usage: "A prefix added to all keys written to the cache resource of --store-cache",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					os.Exit(common.RunService(c, Version, DateBuilt, true))
@@ -2,8 +2,10 @@ package test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 
 	yaml "gopkg.in/yaml.v3"
 
@@ -68,11 +70,16 @@ type Case struct {
 	Environment      map[string]string    `yaml:"environment"`
 	TargetProcessors string               `yaml:"target_processors"`
 	TargetMapping    string               `yaml:"target_mapping"`
+	TargetStream     bool                 `yaml:"target_stream"`
 	Mocks            map[string]yaml.Node `yaml:"mocks"`
 	InputBatch       []InputPart          `yaml:"input_batch"`
 	InputBatches     [][]InputPart        `yaml:"input_batches"`
 	OutputBatches    [][]ConditionsMap    `yaml:"output_batches"`
 
+	Outputs       map[string][][]ConditionsMap `yaml:"outputs"`
+	Acks          []string                     `yaml:"acks"`
+	SyncResponses [][]ConditionsMap            `yaml:"sync_responses"`
+
 	line int
 }
 
@@ -89,10 +96,14 @@ func NewCase() Case {
 		Environment:      map[string]string{},
 		TargetProcessors: "/pipeline/processors",
 		TargetMapping:    "",
+		TargetStream:     false,
 		Mocks:            map[string]yaml.Node{},
 		InputBatch:       []InputPart{},
 		InputBatches:     [][]InputPart{},
 		OutputBatches:    [][]ConditionsMap{},
+		Outputs:          map[string][][]ConditionsMap{},
+		Acks:             []string{},
+		SyncResponses:    [][]ConditionsMap{},
 	}
 }
 
@@ -134,6 +145,14 @@ type ProcProvider interface {
 // ExecuteFrom executes a test case from the perspective of a given directory,
 // which is used for obtaining relative condition file imports.
 func (c *Case) ExecuteFrom(dir string, provider ProcProvider) (failures []CaseFailure, err error) {
+	if c.TargetStream {
+		streamProvider, ok := provider.(StreamProvider)
+		if !ok {
+			return nil, errors.New("test target does not support executing streams")
+		}
+		return c.executeStreamFrom(dir, streamProvider)
+	}
+
 	var procSet []iprocessor.V1
 	if c.TargetMapping != "" {
 		if procSet, err = provider.ProvideBloblang(c.TargetMapping); err != nil {
@@ -153,20 +172,33 @@ func (c *Case) ExecuteFrom(dir string, provider ProcProvider) (failures []CaseFa
 		})
 	}
 
+	var inputMsg []message.Batch
+	if inputMsg, err = c.inputBatches(dir); err != nil {
+		return
+	}
+
+	outputBatches, result := iprocessor.ExecuteAll(context.Background(), procSet, inputMsg...)
+	if result != nil {
+		reportFailure(fmt.Sprintf("processors resulted in error: %v", result))
+	}
+
+	checkBatches(dir, "", c.OutputBatches, outputBatches, reportFailure)
+	return
+}
+
+func (c *Case) inputBatches(dir string) ([]message.Batch, error) {
 	// append old batch to new batch array.
 	if len(c.InputBatch) > 0 {
 		c.InputBatches = append(c.InputBatches, c.InputBatch)
 	}
 
 	var inputMsg []message.Batch
-
 	for _, inputBatch := range c.InputBatches {
 		parts := make([]*message.Part, len(inputBatch))
 		for i, v := range inputBatch {
-			var content string
-			if content, err = v.getContent(dir); err != nil {
-				err = fmt.Errorf("failed to create mock input %v: %w", i, err)
-				return
+			content, err := v.getContent(dir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create mock input %v: %w", i, err)
 			}
 			part := message.NewPart([]byte(content))
 			for k, v := range v.Metadata {
@@ -178,39 +210,140 @@ func (c *Case) ExecuteFrom(dir string, provider ProcProvider) (failures []CaseFa
 		currentBatch := message.Batch(parts)
 		inputMsg = append(inputMsg, currentBatch)
 	}
+	return inputMsg, nil
+}
 
-	outputBatches, result := iprocessor.ExecuteAll(context.Background(), procSet, inputMsg...)
-	if result != nil {
-		reportFailure(fmt.Sprintf("processors resulted in error: %v", result))
+// checkBatches reports failures for any mismatch between a series of expected
+// batches and the actual batches, where failures are prefixed by a name when it
+// is not empty.
+func checkBatches(dir, name string, expected [][]ConditionsMap, actual []message.Batch, reportFailure func(reason string)) {
+	prefix := ""
+	if name != "" {
+		prefix = name + ": "
 	}
 
-	if lExp, lAct := len(c.OutputBatches), len(outputBatches); lAct < lExp {
-		reportFailure(fmt.Sprintf("wrong batch count, expected %v, got %v", lExp, lAct))
+	if lExp, lAct := len(expected), len(actual); lAct < lExp {
+		reportFailure(fmt.Sprintf("%vwrong batch count, expected %v, got %v", prefix, lExp, lAct))
 	}
 
-	for i, v := range outputBatches {
-		if len(c.OutputBatches) <= i {
-			reportFailure(fmt.Sprintf("unexpected batch: %s", message.GetAllBytes(v)))
+	for i, v := range actual {
+		if len(expected) <= i {
+			reportFailure(fmt.Sprintf("%vunexpected batch: %s", prefix, message.GetAllBytes(v)))
 			continue
 		}
-		expectedBatch := c.OutputBatches[i]
+		expectedBatch := expected[i]
 		if lExp, lAct := len(expectedBatch), v.Len(); lExp != lAct {
-			reportFailure(fmt.Sprintf("mismatch of output batch %v message counts, expected %v, got %v", i, lExp, lAct))
+			reportFailure(fmt.Sprintf("%vmismatch of output batch %v message counts, expected %v, got %v", prefix, i, lExp, lAct))
 		}
 		_ = v.Iter(func(i2 int, part *message.Part) error {
 			if len(expectedBatch) <= i2 {
-				reportFailure(fmt.Sprintf("unexpected message from batch %v: %s", i, part.AsBytes()))
+				reportFailure(fmt.Sprintf("%vunexpected message from batch %v: %s", prefix, i, part.AsBytes()))
 				return nil
 			}
 			condErrs := expectedBatch[i2].CheckAll(dir, part)
 			for _, condErr := range condErrs {
-				reportFailure(fmt.Sprintf("batch %v message %v: %v", i, i2, condErr))
+				reportFailure(fmt.Sprintf("%vbatch %v message %v: %v", prefix, i, i2, condErr))
 			}
 			if procErr := part.ErrorGet(); procErr != nil && len(condErrs) > 0 {
-				reportFailure(fmt.Sprintf("batch %v message %v: %v", i, i2, red(procErr)))
+				reportFailure(fmt.Sprintf("%vbatch %v message %v: %v", prefix, i, i2, red(procErr)))
 			}
 			return nil
 		})
 	}
+}
+
+func (c *Case) executeStreamFrom(dir string, provider StreamProvider) (failures []CaseFailure, err error) {
+	for i, a := range c.Acks {
+		if a != "ack" && a != "nack" {
+			return nil, fmt.Errorf("acks index %v: expected either `ack` or `nack`, got `%v`", i, a)
+		}
+	}
+
+	var harness *StreamHarness
+	if harness, err = provider.ProvideStream(c.Environment, c.Mocks); err != nil {
+		return nil, fmt.Errorf("failed to initialise stream: %v", err)
+	}
+
+	outputNames := make([]string, 0, len(c.Outputs))
+	expectedOutputs := map[*CaptureSink][][]ConditionsMap{}
+	for k, v := range c.Outputs {
+		sink, exists := harness.Sink(k)
+		if !exists {
+			return nil, fmt.Errorf("output '%v' was not found in the stream, outputs must be referenced either by their label or a JSON pointer of an output that is captured", k)
+		}
+		expectedOutputs[sink] = v
+		outputNames = append(outputNames, k)
+	}
+	sort.Strings(outputNames)
+
+	reportFailure := func(reason string) {
+		failures = append(failures, CaseFailure{
+			Name:     c.Name,
+			TestLine: c.line,
+			Reason:   reason,
+		})
+	}
+
+	var inputMsg []message.Batch
+	if inputMsg, err = c.inputBatches(dir); err != nil {
+		return
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), streamTestTimeout)
+	defer done()
+
+	results, runErr := harness.Run(ctx, inputMsg)
+	if runErr != nil {
+		reportFailure(fmt.Sprintf("stream resulted in error: %v", runErr))
+		return
+	}
+
+	for _, name := range outputNames {
+		sink, _ := harness.Sink(name)
+		checkBatches(dir, "output "+name, expectedOutputs[sink], results.Outputs[sink], reportFailure)
+	}
+	for _, sink := range harness.Sinks() {
+		if _, expected := expectedOutputs[sink]; expected {
+			continue
+		}
+		for _, b := range results.Outputs[sink] {
+			reportFailure(fmt.Sprintf("output %v: unexpected batch: %s", sink.Name(), message.GetAllBytes(b)))
+		}
+	}
+
+	if len(c.Acks) > 0 {
+		if lExp, lAct := len(c.Acks), len(results.Acks); lExp != lAct {
+			reportFailure(fmt.Sprintf("mismatch of acks count, expected %v, got %v", lExp, lAct))
+		}
+		for i, ackErr := range results.Acks {
+			if i >= len(c.Acks) {
+				break
+			}
+			if c.Acks[i] == "ack" && ackErr != nil {
+				reportFailure(fmt.Sprintf("input batch %v: expected ack, got nack: %v", i, ackErr))
+			} else if c.Acks[i] == "nack" && ackErr == nil {
+				reportFailure(fmt.Sprintf("input batch %v: expected nack, got ack", i))
+			}
+		}
+	}
+
+	for i, expected := range c.SyncResponses {
+		var actual []*message.Part
+		if i < len(results.Responses) {
+			actual = results.Responses[i]
+		}
+		if lExp, lAct := len(expected), len(actual); lExp != lAct {
+			reportFailure(fmt.Sprintf("input batch %v: mismatch of sync response message counts, expected %v, got %v", i, lExp, lAct))
+		}
+		for j, part := range actual {
+			if j >= len(expected) {
+				reportFailure(fmt.Sprintf("input batch %v: unexpected sync response message: %s", i, part.AsBytes()))
+				continue
+			}
+			for _, condErr := range expected[j].CheckAll(dir, part) {
+				reportFailure(fmt.Sprintf("input batch %v sync response message %v: %v", i, j, condErr))
+			}
+		}
+	}
 	return
 }
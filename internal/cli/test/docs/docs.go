@@ -30,6 +30,50 @@ func DocsMarkdown() ([]byte, error) {
 
 // ConfigSpec returns a configuration spec for a template.
 func ConfigSpec() docs.FieldSpec {
+	outputConditions := []docs.FieldSpec{
+		docs.FieldString("content", "The raw content of the input message.").HasDefault(""),
+		docs.FieldAnything("metadata", "A map of metadata key/values to add to the input message.").Map().Optional(),
+		docs.FieldString(
+			`bloblang`,
+			"Executes a Bloblang mapping on the output message, if the result is anything other than a boolean equalling `true` the test fails.",
+			"this.age > 10 && @foo.length() > 0",
+		).Optional(),
+		docs.FieldString(`content_equals`, "Checks the full raw contents of a message against a value.").Optional(),
+		docs.FieldString(`content_matches`, "Checks whether the full raw contents of a message matches a regular expression (re2).", "^foo [a-z]+ bar$").Optional(),
+		docs.FieldAnything(
+			`metadata_equals`,
+			"Checks a map of metadata keys to values against the metadata stored in the message. If there is a value mismatch between a key of the condition versus the message metadata this condition will fail.",
+			map[string]any{
+				"example_key": "example metadata value",
+			},
+		).Map().Optional(),
+		docs.FieldString(
+			`file_equals`,
+			"Checks that the contents of a message matches the contents of a file. The path of the file should be relative to the path of the test file.",
+			"./foo/bar.txt",
+		).Optional(),
+		docs.FieldString(
+			`file_json_equals`,
+			"Checks that both the message and the file contents are valid JSON documents, and that they are structurally equivalent. Will ignore formatting and ordering differences. The path of the file should be relative to the path of the test file.",
+			"./foo/bar.json",
+		).Optional(),
+		docs.FieldAnything(
+			`json_equals`,
+			"Checks that both the message and the condition are valid JSON documents, and that they are structurally equivalent. Will ignore formatting and ordering differences.",
+			map[string]any{"key": "value"},
+		).Optional(),
+		docs.FieldAnything(
+			`json_contains`,
+			"Checks that both the message and the condition are valid JSON documents, and that the message is a superset of the condition.",
+			map[string]any{"key": "value"},
+		).Optional(),
+		docs.FieldString(
+			`file_json_contains`,
+			"Checks that both the message and the file contents are valid JSON documents, and that the message is a superset of the condition. Will ignore formatting and ordering differences. The path of the file should be relative to the path of the test file.",
+			"./foo/bar.json",
+		).Optional(),
+	}
+
 	return docs.FieldObject("tests", "A list of one or more unit tests to execute.").Array().WithChildren(
 		docs.FieldString("name", "The name of the test, this should be unique and give a rough indication of what behaviour is being tested."),
 		docs.FieldString(
@@ -51,6 +95,10 @@ It is also possible to target processors in a separate file by prefixing the tar
 			"target_mapping",
 			"A file path relative to the test definition path of a Bloblang file to execute as an alternative to testing processors with the `target_processors` field. This allows you to define unit tests for Bloblang mappings directly.",
 		).HasDefault(""),
+		docs.FieldBool(
+			"target_stream",
+			"Whether to execute the entire stream of the config as an alternative to testing processors with the `target_processors` field. The input of the stream is replaced with the messages of the test and outputs are replaced with sinks that capture the messages they receive, which can be checked with the `outputs`, `acks` and `sync_responses` fields.",
+		).HasDefault(false).AtVersion("4.14.0"),
 		docs.FieldAnything(
 			"mocks",
 			"An optional map of processors to mock. Keys should contain either a label or a JSON pointer of a processor that should be mocked. Values should contain a processor definition, which will replace the mocked processor. Most of the time you'll want to use a [`mapping` processor][processors.mapping] here, and use it to create a result that emulates the target processor.",
@@ -99,48 +147,24 @@ It is also possible to target processors in a separate file by prefixing the tar
 		),
 		docs.FieldObject(
 			"output_batches", "List of output batches.",
-		).ArrayOfArrays().Optional().WithChildren(
-			docs.FieldString("content", "The raw content of the input message.").HasDefault(""),
-			docs.FieldAnything("metadata", "A map of metadata key/values to add to the input message.").Map().Optional(),
-			docs.FieldString(
-				`bloblang`,
-				"Executes a Bloblang mapping on the output message, if the result is anything other than a boolean equalling `true` the test fails.",
-				"this.age > 10 && @foo.length() > 0",
-			).Optional(),
-			docs.FieldString(`content_equals`, "Checks the full raw contents of a message against a value.").Optional(),
-			docs.FieldString(`content_matches`, "Checks whether the full raw contents of a message matches a regular expression (re2).", "^foo [a-z]+ bar$").Optional(),
-			docs.FieldAnything(
-				`metadata_equals`,
-				"Checks a map of metadata keys to values against the metadata stored in the message. If there is a value mismatch between a key of the condition versus the message metadata this condition will fail.",
-				map[string]any{
-					"example_key": "example metadata value",
+		).ArrayOfArrays().Optional().WithChildren(outputConditions...),
+		docs.FieldAnything(
+			"outputs", "When `target_stream` is `true` this field defines a map of outputs to the batches of messages they are expected to receive, where each message of a batch defines [output conditions](#output-conditions) in the same form as `output_batches`. Keys should contain either the label or a JSON pointer of an output of the stream. Outputs that are not listed are expected to receive no messages.",
+			map[string]any{
+				"errors_out": []any{
+					[]any{map[string]any{"json_contains": map[string]any{"type": "error"}}},
 				},
-			).Map().Optional(),
-			docs.FieldString(
-				`file_equals`,
-				"Checks that the contents of a message matches the contents of a file. The path of the file should be relative to the path of the test file.",
-				"./foo/bar.txt",
-			).Optional(),
-			docs.FieldString(
-				`file_json_equals`,
-				"Checks that both the message and the file contents are valid JSON documents, and that they are structurally equivalent. Will ignore formatting and ordering differences. The path of the file should be relative to the path of the test file.",
-				"./foo/bar.json",
-			).Optional(),
-			docs.FieldAnything(
-				`json_equals`,
-				"Checks that both the message and the condition are valid JSON documents, and that they are structurally equivalent. Will ignore formatting and ordering differences.",
-				map[string]any{"key": "value"},
-			).Optional(),
-			docs.FieldAnything(
-				`json_contains`,
-				"Checks that both the message and the condition are valid JSON documents, and that the message is a superset of the condition.",
-				map[string]any{"key": "value"},
-			).Optional(),
-			docs.FieldString(
-				`file_json_contains`,
-				"Checks that both the message and the file contents are valid JSON documents, and that the message is a superset of the condition. Will ignore formatting and ordering differences. The path of the file should be relative to the path of the test file.",
-				"./foo/bar.json",
-			).Optional(),
-		),
+				"/output/switch/cases/1/output": []any{
+					[]any{map[string]any{"content_equals": "hello world"}},
+				},
+			},
+		).Map().Optional().AtVersion("4.14.0"),
+		docs.FieldString(
+			"acks", "When `target_stream` is `true` this field optionally defines for each input batch whether it is expected to be acknowledged (`ack`) or rejected (`nack`) by the stream.",
+			[]string{"ack", "nack"},
+		).Array().Optional().AtVersion("4.14.0"),
+		docs.FieldObject(
+			"sync_responses", "When `target_stream` is `true` this field optionally defines for each input batch the messages it is expected to receive as a [synchronous response](/docs/guides/sync_responses).",
+		).ArrayOfArrays().Optional().AtVersion("4.14.0").WithChildren(outputConditions...),
 	)
 }
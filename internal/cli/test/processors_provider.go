@@ -267,6 +267,53 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 	cleanupEnv := setEnvironment(environment)
 	defer cleanupEnv()
 
+	root, labelsToPaths, err := p.readMockedConfig(targetPath, mocks)
+	if err != nil {
+		return confs, err
+	}
+
+	if confs.mgr, err = p.resourcesFromConfig(targetPath, root); err != nil {
+		return confs, err
+	}
+
+	var pathSlice []string
+	if strings.HasPrefix(procPath, "/") {
+		if pathSlice, err = gabs.JSONPointerToSlice(procPath); err != nil {
+			return confs, fmt.Errorf("failed to parse case processors path '%v': %w", procPath, err)
+		}
+	} else {
+		if len(labelsToPaths) == 0 {
+			config.Spec().YAMLLabelsToPaths(docs.DeprecatedProvider, root, labelsToPaths, nil)
+		}
+		if pathSlice, exists = labelsToPaths[procPath]; !exists {
+			return confs, fmt.Errorf("target for label '%v' failed as the label was not found in the test target file, it is not currently possible to target resources imported separate to the test file", procPath)
+		}
+	}
+
+	if root, err = docs.GetYAMLPath(root, pathSlice...); err != nil {
+		return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
+	}
+
+	if root.Kind == yaml.SequenceNode {
+		if err = root.Decode(&confs.procs); err != nil {
+			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
+		}
+	} else {
+		var procConf processor.Config
+		if err = root.Decode(&procConf); err != nil {
+			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
+		}
+		confs.procs = append(confs.procs, procConf)
+	}
+
+	p.cachedConfigs[cacheKey] = confs
+	return confs, nil
+}
+
+// readMockedConfig reads a config file and replaces all mocked components,
+// returning the root node of the config along with any labels to paths that
+// were resolved in the process.
+func (p *ProcessorsProvider) readMockedConfig(targetPath string, mocks map[string]yaml.Node) (*yaml.Node, map[string][]string, error) {
 	remainingMocks := map[string]yaml.Node{}
 	for k, v := range mocks {
 		remainingMocks[k] = v
@@ -274,12 +321,12 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 
 	configBytes, _, _, err := config.ReadFileEnvSwap(ifs.OS(), targetPath)
 	if err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+		return nil, nil, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
 	root := &yaml.Node{}
 	if err = yaml.Unmarshal(configBytes, root); err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+		return nil, nil, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
 	// Replace mock components, starting with all absolute paths in JSON pointer
@@ -291,10 +338,10 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		}
 		mockPathSlice, err := gabs.JSONPointerToSlice(k)
 		if err != nil {
-			return confs, fmt.Errorf("failed to parse mock path '%v': %w", k, err)
+			return nil, nil, fmt.Errorf("failed to parse mock path '%v': %w", k, err)
 		}
 		if err = setMock(confSpec, root, &v, mockPathSlice...); err != nil {
-			return confs, fmt.Errorf("failed to set mock '%v': %w", k, err)
+			return nil, nil, fmt.Errorf("failed to set mock '%v': %w", k, err)
 		}
 		delete(remainingMocks, k)
 	}
@@ -305,65 +352,37 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		for k, v := range remainingMocks {
 			mockPathSlice, exists := labelsToPaths[k]
 			if !exists {
-				return confs, fmt.Errorf("mock for label '%v' could not be applied as the label was not found in the test target file, it is not currently possible to mock resources imported separate to the test file", k)
+				return nil, nil, fmt.Errorf("mock for label '%v' could not be applied as the label was not found in the test target file, it is not currently possible to mock resources imported separate to the test file", k)
 			}
 			if err = setMock(confSpec, root, &v, mockPathSlice...); err != nil {
-				return confs, fmt.Errorf("failed to set mock '%v': %w", k, err)
+				return nil, nil, fmt.Errorf("failed to set mock '%v': %w", k, err)
 			}
 			delete(remainingMocks, k)
 		}
 	}
+	return root, labelsToPaths, nil
+}
 
+// resourcesFromConfig extracts the resources of a config root along with any
+// resources from the resources paths of the provider.
+func (p *ProcessorsProvider) resourcesFromConfig(targetPath string, root *yaml.Node) (manager.ResourceConfig, error) {
 	mgrWrapper := manager.NewResourceConfig()
-	if err = root.Decode(&mgrWrapper); err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+	if err := root.Decode(&mgrWrapper); err != nil {
+		return mgrWrapper, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
 	for _, path := range p.resourcesPaths {
 		resourceBytes, _, _, err := config.ReadFileEnvSwap(ifs.OS(), path)
 		if err != nil {
-			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
+			return mgrWrapper, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
 		}
 		extraMgrWrapper := manager.NewResourceConfig()
 		if err = yaml.Unmarshal(resourceBytes, &extraMgrWrapper); err != nil {
-			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
+			return mgrWrapper, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
 		}
 		if err = mgrWrapper.AddFrom(&extraMgrWrapper); err != nil {
-			return confs, fmt.Errorf("failed to merge resources from '%v': %v", path, err)
-		}
-	}
-	confs.mgr = mgrWrapper
-
-	var pathSlice []string
-	if strings.HasPrefix(procPath, "/") {
-		if pathSlice, err = gabs.JSONPointerToSlice(procPath); err != nil {
-			return confs, fmt.Errorf("failed to parse case processors path '%v': %w", procPath, err)
-		}
-	} else {
-		if len(labelsToPaths) == 0 {
-			confSpec.YAMLLabelsToPaths(docs.DeprecatedProvider, root, labelsToPaths, nil)
-		}
-		if pathSlice, exists = labelsToPaths[procPath]; !exists {
-			return confs, fmt.Errorf("target for label '%v' failed as the label was not found in the test target file, it is not currently possible to target resources imported separate to the test file", procPath)
-		}
-	}
-
-	if root, err = docs.GetYAMLPath(root, pathSlice...); err != nil {
-		return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
-	}
-
-	if root.Kind == yaml.SequenceNode {
-		if err = root.Decode(&confs.procs); err != nil {
-			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
+			return mgrWrapper, fmt.Errorf("failed to merge resources from '%v': %v", path, err)
 		}
-	} else {
-		var procConf processor.Config
-		if err = root.Decode(&procConf); err != nil {
-			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
-		}
-		confs.procs = append(confs.procs, procConf)
 	}
-
-	p.cachedConfigs[cacheKey] = confs
-	return confs, nil
+	return mgrWrapper, nil
 }
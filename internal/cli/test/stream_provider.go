@@ -0,0 +1,339 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+	"github.com/benthosdev/benthos/v4/internal/transaction"
+)
+
+const (
+	streamTestInputPipe  = "benthos_test_input"
+	streamTestOutputPipe = "benthos_test_output_"
+	streamTestTimeout    = time.Second * 30
+)
+
+// Outputs that are never replaced by capture sinks as they either have no side
+// effects outside of the stream or route messages to other outputs that are
+// themselves captured.
+var streamTestUncapturedOutputs = map[string]struct{}{
+	"drop":          {},
+	"reject":        {},
+	"resource":      {},
+	"sync_response": {},
+}
+
+// StreamProvider returns streams extracted from a Benthos config where the
+// input is replaced with a feeder of test messages and outputs are replaced with
+// sinks that capture the messages they receive.
+type StreamProvider interface {
+	ProvideStream(environment map[string]string, mocks map[string]yaml.Node) (*StreamHarness, error)
+}
+
+// CaptureSink describes an output of a stream that was replaced in order to
+// capture the messages it receives.
+type CaptureSink struct {
+	// Path is the JSON Pointer of the replaced output within the config.
+	Path string
+
+	// Label is the label of the replaced output, which may be empty.
+	Label string
+
+	pipe string
+}
+
+// Name returns a human readable identifier of the sink.
+func (c *CaptureSink) Name() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return c.Path
+}
+
+// StreamHarness runs a stream extracted from a Benthos config by feeding it
+// test messages and capturing the messages that reach its outputs.
+type StreamHarness struct {
+	conf     stream.Config
+	mgrConf  manager.ResourceConfig
+	logger   log.Modular
+	sinks    []*CaptureSink
+	pathToID map[string]*CaptureSink
+}
+
+// Sinks returns the capture sinks of the stream.
+func (s *StreamHarness) Sinks() []*CaptureSink {
+	return s.sinks
+}
+
+// Sink attempts to find a capture sink by either its JSON Pointer, when the
+// provided identifier is prefixed with a forward slash, or its label.
+func (s *StreamHarness) Sink(id string) (*CaptureSink, bool) {
+	if strings.HasPrefix(id, "/") {
+		sink, exists := s.pathToID[id]
+		return sink, exists
+	}
+	for _, sink := range s.sinks {
+		if sink.Label == id {
+			return sink, true
+		}
+	}
+	return nil, false
+}
+
+// StreamResults contains the outcome of feeding test messages through a
+// stream.
+type StreamResults struct {
+	// Acks contains the result of each input batch, where a nil error
+	// indicates that the batch was acknowledged.
+	Acks []error
+
+	// Responses contains the messages of each input batch that were provided
+	// as a synchronous response.
+	Responses [][]*message.Part
+
+	// Outputs contains the batches received by each capture sink.
+	Outputs map[*CaptureSink][]message.Batch
+}
+
+// Run the stream until all provided batches have been either acknowledged or
+// rejected, and then shut it down.
+func (s *StreamHarness) Run(ctx context.Context, batches []message.Batch) (*StreamResults, error) {
+	mgr, err := manager.New(s.mgrConf, manager.OptSetLogger(s.logger))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise resources: %v", err)
+	}
+	defer func() {
+		mgr.TriggerStopConsuming()
+		mgr.TriggerCloseNow()
+		_ = mgr.WaitForClose(context.Background())
+	}()
+
+	tranChan := make(chan message.Transaction)
+	mgr.SetPipe(streamTestInputPipe, tranChan)
+
+	results := &StreamResults{
+		Acks:      make([]error, len(batches)),
+		Responses: make([][]*message.Part, len(batches)),
+		Outputs:   map[*CaptureSink][]message.Batch{},
+	}
+	captured := map[*CaptureSink][]message.Batch{}
+
+	strm, err := stream.New(s.conf, mgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise stream: %v", err)
+	}
+	defer func() {
+		_ = strm.Stop(context.Background())
+	}()
+
+	// Each capture sink has its transactions recorded before they are
+	// acknowledged, and therefore once all input batches are acknowledged the
+	// captured batches are complete.
+	var captureMut sync.Mutex
+	captureCtx, captureDone := context.WithCancel(ctx)
+	defer captureDone()
+	for _, sink := range s.sinks {
+		sinkTrans, err := mgr.GetPipe(sink.pipe)
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture output '%v': %v", sink.Name(), err)
+		}
+		go func(sink *CaptureSink, sinkTrans <-chan message.Transaction) {
+			for {
+				select {
+				case t, open := <-sinkTrans:
+					if !open {
+						return
+					}
+					captureMut.Lock()
+					captured[sink] = append(captured[sink], t.Payload.ShallowCopy())
+					captureMut.Unlock()
+					_ = t.Ack(captureCtx, nil)
+				case <-captureCtx.Done():
+					return
+				}
+			}
+		}(sink, sinkTrans)
+	}
+
+	stores := make([]transaction.ResultStore, len(batches))
+	resChans := make([]chan error, len(batches))
+	for i, b := range batches {
+		stores[i] = transaction.NewResultStore()
+		transaction.AddResultStore(b, stores[i])
+
+		resChans[i] = make(chan error, 1)
+		select {
+		case tranChan <- message.NewTransaction(b, resChans[i]):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out feeding input batch %v", i)
+		}
+	}
+
+	for i, resChan := range resChans {
+		select {
+		case results.Acks[i] = <-resChan:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for input batch %v to be acknowledged", i)
+		}
+		for _, resBatch := range stores[i].Get() {
+			results.Responses[i] = append(results.Responses[i], resBatch...)
+		}
+	}
+
+	captureMut.Lock()
+	for k, v := range captured {
+		results.Outputs[k] = v
+	}
+	captureMut.Unlock()
+	return results, nil
+}
+
+//------------------------------------------------------------------------------
+
+// ProvideStream attempts to extract a stream from the target Benthos config,
+// where the input is replaced with a feeder of test messages and all outputs
+// that have side effects are replaced with capture sinks. Supports injected
+// mocked components in the parsed config.
+func (p *ProcessorsProvider) ProvideStream(environment map[string]string, mocks map[string]yaml.Node) (*StreamHarness, error) {
+	cleanupEnv := setEnvironment(environment)
+	defer cleanupEnv()
+
+	root, _, err := p.readMockedConfig(p.targetPath, mocks)
+	if err != nil {
+		return nil, err
+	}
+
+	rootMapping := root
+	if rootMapping.Kind == yaml.DocumentNode {
+		if len(rootMapping.Content) == 0 {
+			return nil, fmt.Errorf("config file '%v' is empty", p.targetPath)
+		}
+		rootMapping = rootMapping.Content[0]
+	}
+
+	inputNode, _ := docs.GetYAMLPath(rootMapping, "input")
+	if inputNode == nil {
+		inputNode = &yaml.Node{}
+		rootMapping.Content = append(rootMapping.Content, yamlStr("input"), inputNode)
+	}
+	if inputNode.Kind != yaml.MappingNode {
+		*inputNode = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	replaceWithInproc(inputNode, streamTestInputPipe)
+
+	type walkedOutput struct {
+		node  *yaml.Node
+		name  string
+		label string
+		path  string
+	}
+
+	nodePaths := map[*yaml.Node]string{}
+	yamlNodePaths(rootMapping, "", nodePaths)
+
+	var outputs []walkedOutput
+	if err := config.Spec().WalkYAML(rootMapping, docs.DeprecatedProvider, func(c docs.WalkedYAMLComponent) error {
+		if c.ComponentType != docs.TypeOutput {
+			return nil
+		}
+		outputs = append(outputs, walkedOutput{
+			node:  c.Conf,
+			name:  c.Name,
+			label: c.Label,
+			path:  nodePaths[c.Conf],
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk outputs of config file '%v': %v", p.targetPath, err)
+	}
+
+	harness := &StreamHarness{
+		logger:   p.logger,
+		pathToID: map[string]*CaptureSink{},
+	}
+
+	for _, o := range outputs {
+		if _, exists := streamTestUncapturedOutputs[o.name]; exists {
+			continue
+		}
+
+		// Outputs that contain child outputs (brokers, switches, etc) are kept
+		// and their children are captured instead.
+		isParent := false
+		for _, other := range outputs {
+			if strings.HasPrefix(other.path, o.path+"/") {
+				isParent = true
+				break
+			}
+		}
+		if isParent {
+			continue
+		}
+
+		sink := &CaptureSink{
+			Path:  o.path,
+			Label: o.label,
+			pipe:  streamTestOutputPipe + strconv.Itoa(len(harness.sinks)),
+		}
+		replaceWithInproc(o.node, sink.pipe)
+		harness.sinks = append(harness.sinks, sink)
+		harness.pathToID[sink.Path] = sink
+	}
+
+	conf := config.New()
+	if err := root.Decode(&conf); err != nil {
+		return nil, fmt.Errorf("failed to parse config file '%v': %v", p.targetPath, err)
+	}
+	harness.conf = conf.Config
+
+	if harness.mgrConf, err = p.resourcesFromConfig(p.targetPath, root); err != nil {
+		return nil, err
+	}
+	return harness, nil
+}
+
+func yamlStr(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
+
+// replaceWithInproc modifies a component config node in place so that it
+// becomes an inproc component, retaining its label and processors.
+func replaceWithInproc(node *yaml.Node, pipe string) {
+	var content []*yaml.Node
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		switch node.Content[i].Value {
+		case "label", "processors":
+			content = append(content, node.Content[i], node.Content[i+1])
+		}
+	}
+	node.Content = append(content, yamlStr("inproc"), yamlStr(pipe))
+}
+
+// yamlNodePaths walks a YAML tree and records the JSON Pointer of each node.
+func yamlNodePaths(node *yaml.Node, path string, paths map[*yaml.Node]string) {
+	paths[node] = path
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key := strings.ReplaceAll(node.Content[i].Value, "~", "~0")
+			key = strings.ReplaceAll(key, "/", "~1")
+			yamlNodePaths(node.Content[i+1], path+"/"+key, paths)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			yamlNodePaths(child, path+"/"+strconv.Itoa(i), paths)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package test_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/cli/test"
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+const streamTestConfig = `
+input:
+  http_server:
+    path: /post
+  processors:
+    - mapping: 'root = content().uppercase()'
+
+output:
+  switch:
+    cases:
+      - check: this.TYPE == "ERROR"
+        output:
+          label: errors
+          file:
+            path: ./errors.jsonl
+      - check: this.TYPE == "REJECT"
+        output:
+          reject: 'rejected ${! this.ID }'
+      - output:
+          broker:
+            pattern: fan_out
+            outputs:
+              - file:
+                  path: ./events.jsonl
+              - resource: archive
+              - sync_response: {}
+
+output_resources:
+  - label: archive
+    file:
+      path: ./archive.jsonl
+`
+
+func runStreamTest(t *testing.T, definition string) []test.CaseFailure {
+	t.Helper()
+
+	testDir, err := initTestFiles(t, map[string]string{
+		"config.yaml": streamTestConfig,
+	})
+	require.NoError(t, err)
+
+	var def test.Definition
+	require.NoError(t, yaml.Unmarshal([]byte(definition), &def))
+
+	failures, err := def.Execute(filepath.Join(testDir, "config.yaml"), nil, log.Noop())
+	require.NoError(t, err)
+	return failures
+}
+
+func TestStreamProviderRouting(t *testing.T) {
+	failures := runStreamTest(t, `
+tests:
+  - name: routes messages
+    target_stream: true
+    input_batches:
+      - - content: '{"type":"error","id":"a"}'
+      - - content: '{"type":"event","id":"b"}'
+      - - content: '{"type":"reject","id":"c"}'
+    outputs:
+      errors:
+        - - json_equals: { "TYPE": "ERROR", "ID": "A" }
+      /output/switch/cases/2/output/broker/outputs/0:
+        - - json_contains: { "ID": "B" }
+      archive:
+        - - json_contains: { "ID": "B" }
+    acks: [ ack, ack, nack ]
+    sync_responses:
+      - []
+      - - json_equals: { "TYPE": "EVENT", "ID": "B" }
+      - []
+`)
+	assert.Empty(t, failures)
+}
+
+func TestStreamProviderFailures(t *testing.T) {
+	failures := runStreamTest(t, `
+tests:
+  - name: routes messages
+    target_stream: true
+    input_batches:
+      - - content: '{"type":"event","id":"b"}'
+      - - content: '{"type":"reject","id":"c"}'
+    outputs:
+      errors:
+        - - json_contains: { "ID": "B" }
+    acks: [ ack, ack ]
+    sync_responses:
+      - - content_equals: nope
+`)
+
+	var reasons []string
+	for _, f := range failures {
+		reasons = append(reasons, f.Reason)
+	}
+	assert.Equal(t, []string{
+		"output errors: wrong batch count, expected 1, got 0",
+		`output /output/switch/cases/2/output/broker/outputs/0: unexpected batch: [{"TYPE":"EVENT","ID":"B"}]`,
+		`output archive: unexpected batch: [{"TYPE":"EVENT","ID":"B"}]`,
+		"input batch 1: expected ack, got nack: rejected C",
+		`input batch 0 sync response message 0: content_equals: content mismatch
+  expected: nope
+  received: {"TYPE":"EVENT","ID":"B"}`,
+	}, reasons)
+}
+
+func TestStreamProviderUnknownOutput(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"config.yaml": streamTestConfig,
+	})
+	require.NoError(t, err)
+
+	var def test.Definition
+	require.NoError(t, yaml.Unmarshal([]byte(`
+tests:
+  - name: unknown output
+    target_stream: true
+    input_batch:
+      - content: '{}'
+    outputs:
+      nope:
+        - - content_equals: '{}'
+`), &def))
+
+	_, err = def.Execute(filepath.Join(testDir, "config.yaml"), nil, log.Noop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output 'nope' was not found")
+}
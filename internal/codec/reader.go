@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -36,8 +37,11 @@ var ReaderDocs = docs.FieldString(
 	"csv:x", "Consume structured rows as values separated by a custom delimiter, the first row must be a header row. The custom delimiter must be a single character, e.g. the codec `\"csv:\\t\"` would consume a tab delimited file.",
 	"delim:x", "Consume the file in segments divided by a custom delimiter.",
 	"gzip", "Decompress a gzip file, this codec should precede another codec, e.g. `gzip/all-bytes`, `gzip/tar`, `gzip/csv`, etc.",
+	"json_array", "Consume a file containing a single top-level JSON array, each element of the array is emitted as a message. The array is decoded incrementally and therefore doesn't need to fit in memory.",
+	"json_path:x", "Consume the elements of a JSON array nested within a top-level object at a dot separated path, e.g. the codec `json_path:data.items` would emit each element of the array at `data.items`. Only the array elements are held in memory.",
 	"lines", "Consume the file in segments divided by linebreaks.",
 	"multipart", "Consumes the output of another codec and batches messages together. A batch ends when an empty message is consumed. For example, the codec `lines/multipart` could be used to consume multipart messages where an empty line indicates the end of each batch.",
+	"parquet", "EXPERIMENTAL: Consume the rows of a Parquet file as structured messages. The file is read in place when the source supports random access, otherwise it is loaded into memory in full.",
 	"regex:(?m)^\\d\\d:\\d\\d:\\d\\d", "Consume the file in segments divided by regular expression.",
 	"tar", "Parse the file as a tar archive, and consume each file of the archive as a message.",
 )
@@ -87,6 +91,21 @@ type ioReaderConstructor func(string, io.ReadCloser) (io.ReadCloser, error)
 // necessary for certain codecs.
 type ReaderConstructor func(string, io.ReadCloser, ReaderAckFn) (Reader, error)
 
+// ReaderCodecCtor creates a reader constructor for a codec that is registered
+// from outside of this package. The params are the characters following the
+// first colon of the codec, or an empty string if there are none.
+type ReaderCodecCtor func(params string, conf ReaderConfig) (ReaderConstructor, error)
+
+var registeredReaders = map[string]ReaderCodecCtor{}
+
+// RegisterReaderCodec adds a reader codec implemented by another package,
+// allowing codecs with heavy dependencies to live alongside the components
+// that share them. This function is not thread safe and should only be called
+// during init.
+func RegisterReaderCodec(name string, ctor ReaderCodecCtor) {
+	registeredReaders[name] = ctor
+}
+
 // readerReaderConstructor is a private constructor for readers that _must_
 // consume from other readers.
 type readerReaderConstructor func(string, Reader) (Reader, error)
@@ -227,6 +246,10 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 		}, true, nil
 	case "tar":
 		return newTarReader, true, nil
+	case "json_array":
+		return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+			return newJSONArrayReader(r, nil, fn)
+		}, true, nil
 	}
 
 	if strings.HasPrefix(codec, "avro-ocf:") {
@@ -245,6 +268,22 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 		}
 	}
 
+	if strings.HasPrefix(codec, "json_path:") {
+		by := strings.TrimPrefix(codec, "json_path:")
+		if by == "" {
+			return nil, false, errors.New("json_path codec requires a non-empty path")
+		}
+		jPath := strings.Split(by, ".")
+		for _, seg := range jPath {
+			if seg == "" {
+				return nil, false, fmt.Errorf("json_path codec path '%v' contains an empty segment", by)
+			}
+		}
+		return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+			return newJSONArrayReader(r, jPath, fn)
+		}, true, nil
+	}
+
 	if strings.HasPrefix(codec, "delim:") {
 		by := strings.TrimPrefix(codec, "delim:")
 		if by == "" {
@@ -286,6 +325,15 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 			return newRexExpSplitReader(conf, r, by, fn)
 		}, true, nil
 	}
+
+	name, params, _ := strings.Cut(codec, ":")
+	if ctor, exists := registeredReaders[name]; exists {
+		rCtor, err := ctor(params, conf)
+		if err != nil {
+			return nil, false, err
+		}
+		return rCtor, true, nil
+	}
 	return nil, false, nil
 }
 
@@ -318,6 +366,8 @@ func autoCodec(conf ReaderConfig) ReaderConstructor {
 			codec = "csv"
 		case ".csv.gz", ".csv.gzip":
 			codec = "gzip/csv"
+		case ".parquet":
+			codec = "parquet"
 		case ".tar":
 			codec = "tar"
 		case ".tgz":
@@ -912,6 +962,190 @@ func (a *tarReader) Close(ctx context.Context) error {
 
 //------------------------------------------------------------------------------
 
+type jsonArrayReader struct {
+	dec       *json.Decoder
+	path      []string
+	r         io.ReadCloser
+	sourceAck ReaderAckFn
+
+	mut      sync.Mutex
+	started  bool
+	finished bool
+	pending  int32
+}
+
+func newJSONArrayReader(r io.ReadCloser, path []string, ackFn ReaderAckFn) (Reader, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &jsonArrayReader{
+		dec:       dec,
+		path:      path,
+		r:         r,
+		sourceAck: ackOnce(ackFn),
+	}, nil
+}
+
+// skipJSONValue consumes the next value from the decoder token by token,
+// which avoids buffering potentially large values that we aren't interested
+// in.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if d, ok := t.(json.Delim); ok {
+			switch d {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func expectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected JSON token '%v', got '%v'", delim, t)
+	}
+	return nil
+}
+
+// seekArray walks the decoder to the opening of the target array, descending
+// through object keys when a path is configured. An empty input is treated
+// as an empty array.
+func (a *jsonArrayReader) seekArray() error {
+	if !a.dec.More() {
+		if _, err := a.dec.Token(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	for i, seg := range a.path {
+		if err := expectJSONDelim(a.dec, '{'); err != nil {
+			return fmt.Errorf("path %v: %w", strings.Join(a.path[:i], "."), err)
+		}
+		found := false
+		for a.dec.More() {
+			t, err := a.dec.Token()
+			if err != nil {
+				return err
+			}
+			if k, _ := t.(string); k == seg {
+				found = true
+				break
+			}
+			if err := skipJSONValue(a.dec); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return fmt.Errorf("path %v not found", strings.Join(a.path[:i+1], "."))
+		}
+	}
+	if err := expectJSONDelim(a.dec, '['); err != nil {
+		if len(a.path) > 0 {
+			return fmt.Errorf("path %v: %w", strings.Join(a.path, "."), err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *jsonArrayReader) ack(ctx context.Context, err error) error {
+	a.mut.Lock()
+	a.pending--
+	doAck := a.pending == 0 && a.finished
+	a.mut.Unlock()
+
+	if err != nil {
+		return a.sourceAck(ctx, err)
+	}
+	if doAck {
+		return a.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+// decodeNext reads the next element of the target array from the decoder,
+// returning io.EOF once the array has been fully consumed.
+func (a *jsonArrayReader) decodeNext() (json.RawMessage, error) {
+	if !a.started {
+		a.started = true
+		if err := a.seekArray(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !a.dec.More() {
+		// Consume the closing bracket, anything following the array is
+		// ignored.
+		if err := expectJSONDelim(a.dec, ']'); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	if err := a.dec.Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (a *jsonArrayReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, error) {
+	a.mut.Lock()
+	finished := a.finished
+	a.mut.Unlock()
+	if finished {
+		return nil, nil, io.EOF
+	}
+
+	raw, err := a.decodeNext()
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			a.finished = true
+		} else {
+			_ = a.sourceAck(ctx, err)
+		}
+		return nil, nil, err
+	}
+
+	a.pending++
+	return []*message.Part{message.NewPart(raw)}, a.ack, nil
+}
+
+func (a *jsonArrayReader) Close(ctx context.Context) error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.finished {
+		_ = a.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if a.pending == 0 {
+		_ = a.sourceAck(ctx, nil)
+	}
+	return a.r.Close()
+}
+
+//------------------------------------------------------------------------------
+
 type multipartReader struct {
 	child Reader
 }
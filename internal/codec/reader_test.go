@@ -771,3 +771,66 @@ func TestRegexpSplitReader(t *testing.T) {
 	data = []byte("")
 	testReaderSuite(t, "regex:split", "", data)
 }
+
+func TestJSONArrayReader(t *testing.T) {
+	data := []byte(`[{"id":1,"name":"foo"}, {"id":2,"name":"bar"} ,"baz",[1,2]]`)
+	testReaderSuite(t, "json_array", "", data, `{"id":1,"name":"foo"}`, `{"id":2,"name":"bar"}`, `"baz"`, `[1,2]`)
+
+	data = []byte("[\n  {\"a\":\"b\"}\n]\n")
+	testReaderSuite(t, "json_array", "", data, `{"a":"b"}`)
+
+	data = []byte(`[]`)
+	testReaderSuite(t, "json_array", "", data)
+
+	data = []byte("")
+	testReaderSuite(t, "json_array", "", data)
+}
+
+func TestJSONPathReader(t *testing.T) {
+	data := []byte(`{"meta":{"items":["nope"],"count":2},"data":{"skip":[{"a":[]}],"items":[{"id":1},{"id":2}]},"after":true}`)
+	testReaderSuite(t, "json_path:data.items", "", data, `{"id":1}`, `{"id":2}`)
+
+	data = []byte(`{"items":[]}`)
+	testReaderSuite(t, "json_path:items", "", data)
+
+	var gzipBuf bytes.Buffer
+	zw := gzip.NewWriter(&gzipBuf)
+	_, _ = zw.Write([]byte(`{"items":["foo","bar"]}`))
+	zw.Close()
+	testReaderSuite(t, "gzip/json_path:items", "", gzipBuf.Bytes(), `"foo"`, `"bar"`)
+}
+
+func TestJSONArrayReaderErrors(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		codec  string
+		data   string
+		errStr string
+	}{
+		{name: "not an array", codec: "json_array", data: `{"foo":"bar"}`, errStr: "expected JSON token '[', got '{'"},
+		{name: "path not found", codec: "json_path:foo.bar", data: `{"foo":{"baz":[]}}`, errStr: "path foo.bar not found"},
+		{name: "path not an array", codec: "json_path:foo", data: `{"foo":"bar"}`, errStr: "path foo: expected JSON token '[', got 'bar'"},
+		{name: "truncated", codec: "json_array", data: `[{"foo":"bar"`, errStr: "unexpected EOF"},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctor, err := GetReader(test.codec, NewReaderConfig())
+			require.NoError(t, err)
+
+			var ackErr error
+			r, err := ctor("", noopCloser{bytes.NewReader([]byte(test.data)), false}, func(ctx context.Context, err error) error {
+				ackErr = err
+				return nil
+			})
+			require.NoError(t, err)
+
+			_, _, err = r.Next(context.Background())
+			require.EqualError(t, err, test.errStr)
+			assert.EqualError(t, ackErr, test.errStr)
+			require.NoError(t, r.Close(context.Background()))
+		})
+	}
+
+	_, err := GetReader("json_path:foo..bar", NewReaderConfig())
+	require.EqualError(t, err, "json_path codec path 'foo..bar' contains an empty segment")
+}
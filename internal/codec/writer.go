@@ -1,25 +1,42 @@
 package codec
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/klauspost/compress/gzip"
+
+	goavro "github.com/linkedin/goavro/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
 // WriterDocs is a static field documentation for output codecs.
 var WriterDocs = docs.FieldString(
-	"codec", "The way in which the bytes of messages should be written out into the output data stream. It's possible to write lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter.", "lines", "delim:\t", "delim:foobar",
+	"codec", "The way in which the bytes of messages should be written out into the output data stream. It's possible to write lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter. The output stream can be compressed by prefixing a codec with `gzip/`, for example a gzip compressed CSV file can be written with the codec `gzip/csv`.", "lines", "delim:\t", "delim:foobar", "gzip/csv",
 ).HasAnnotatedOptions(
 	"all-bytes", "Only applicable to file based outputs. Writes each message to a file in full, if the file already exists the old content is deleted.",
 	"append", "Append each message to the output stream without any delimiter or special encoding.",
+	"avro-ocf:schema=x", "EXPERIMENTAL: Write each message as a datum of an Avro OCF container file, the `schema` parameter is required and contains the Avro schema of the messages in JSON format. The messages of each batch are written as a single OCF block. Only applicable to file based outputs, if the file already exists the old content is deleted.",
+	"csv", "Write structured messages as comma separated values. A header row is written using the sorted keys of the first message, and each subsequent message is written as a row with values for those keys, where missing keys result in empty values. A message containing a key not present in the header is rejected with an error. Only applicable to file based outputs, if the file already exists the old content is deleted.",
+	"csv:x", "Write structured messages as values separated by a custom delimiter, the custom delimiter must be a single character. Otherwise behaves the same as `csv`.",
+	"gzip", "Compress the output stream with gzip, this is equivalent to `gzip/append` and can also precede another codec, e.g. `gzip/lines`, `gzip/csv`, `gzip/tar`, etc.",
 	"lines", "Append each message to the output stream followed by a line break.",
 	"delim:x", "Append each message to the output stream followed by a custom delimiter.",
+	"parquet:schema=x", "EXPERIMENTAL: Write structured messages as rows of a Parquet file, the `schema` parameter is required and contains a list of columns in the same format as the `schema` field of the `parquet_encode` processor, in either JSON or YAML flow syntax. Rows are buffered and each batch is written as a row group. Only applicable to file based outputs, if the file already exists the old content is deleted.",
+	"tar", "Write each message as a file within a tar archive, the name of each file is taken from the metadata field `tar_name` when present, otherwise it is a sequential number. Only applicable to file based outputs, if the file already exists the old content is deleted.",
+	"tar-gzip", "Write each message as a file within a gzip compressed tar archive, this is equivalent to `gzip/tar`.",
 )
 
 //------------------------------------------------------------------------------
@@ -30,6 +47,22 @@ type Writer interface {
 	Close(context.Context) error
 }
 
+// Flusher is implemented by writers that buffer messages internally, outputs
+// should call Flush after writing each batch of messages and before those
+// messages are acknowledged.
+type Flusher interface {
+	Flush(context.Context) error
+}
+
+// FlushWriter flushes a writer if it buffers messages internally, otherwise it
+// does nothing.
+func FlushWriter(ctx context.Context, w Writer) error {
+	if f, ok := w.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
 // WriterConfig contains custom configuration specific to a codec describing how
 // handles should be provided.
 type WriterConfig struct {
@@ -41,8 +74,45 @@ type WriterConfig struct {
 // WriterConstructor creates a writer from an io.WriteCloser.
 type WriterConstructor func(io.WriteCloser) (Writer, error)
 
+// WriterCodecCtor creates a writer constructor for a codec that is registered
+// from outside of this package. The params are the characters following the
+// first colon of the codec, or an empty string if there are none.
+type WriterCodecCtor func(params string) (WriterConstructor, WriterConfig, error)
+
+var registeredWriters = map[string]WriterCodecCtor{}
+
+// RegisterWriterCodec adds a writer codec implemented by another package. This
+// function is not thread safe and should only be called during init.
+func RegisterWriterCodec(name string, ctor WriterCodecCtor) {
+	registeredWriters[name] = ctor
+}
+
+// expandWriterCodecAlias converts shorthand codec names into their chained
+// equivalents.
+func expandWriterCodecAlias(codec string) string {
+	switch codec {
+	case "gzip":
+		return "gzip/append"
+	case "tar-gzip":
+		return "gzip/tar"
+	}
+	return codec
+}
+
 // GetWriter returns a constructor that creates write codecs.
 func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
+	codec = expandWriterCodecAlias(codec)
+	if strings.HasPrefix(codec, "gzip/") {
+		ctor, conf, err := GetWriter(strings.TrimPrefix(codec, "gzip/"))
+		if err != nil {
+			return nil, WriterConfig{}, err
+		}
+		return gzipWriterCtor(ctor), conf, nil
+	}
+	return partWriter(codec)
+}
+
+func partWriter(codec string) (WriterConstructor, WriterConfig, error) {
 	switch codec {
 	case "all-bytes":
 		return func(w io.WriteCloser) (Writer, error) {
@@ -54,6 +124,39 @@ func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
 		}, customDelimConfig, nil
 	case "lines":
 		return newLinesWriter, linesWriterConfig, nil
+	case "csv":
+		return func(w io.WriteCloser) (Writer, error) {
+			return newCSVWriter(w, nil)
+		}, csvWriterConfig, nil
+	case "tar":
+		return newTarWriter, tarWriterConfig, nil
+	}
+	if strings.HasPrefix(codec, "csv:") {
+		by := strings.TrimPrefix(codec, "csv:")
+		if by == "" {
+			return nil, WriterConfig{}, errors.New("csv codec requires a non-empty delimiter")
+		}
+		byRunes := []rune(by)
+		if len(byRunes) != 1 {
+			return nil, WriterConfig{}, errors.New("csv codec requires a single character delimiter")
+		}
+		byRune := byRunes[0]
+		return func(w io.WriteCloser) (Writer, error) {
+			return newCSVWriter(w, &byRune)
+		}, csvWriterConfig, nil
+	}
+	if strings.HasPrefix(codec, "avro-ocf:") {
+		schema := strings.TrimPrefix(codec, "avro-ocf:")
+		if !strings.HasPrefix(schema, "schema=") {
+			return nil, WriterConfig{}, errors.New("avro-ocf codec requires a schema parameter")
+		}
+		avroCodec, err := goavro.NewCodecForStandardJSONFull(strings.TrimPrefix(schema, "schema="))
+		if err != nil {
+			return nil, WriterConfig{}, fmt.Errorf("failed to parse avro-ocf schema: %w", err)
+		}
+		return func(w io.WriteCloser) (Writer, error) {
+			return newAvroOCFWriter(w, avroCodec)
+		}, avroOCFWriterConfig, nil
 	}
 	if strings.HasPrefix(codec, "delim:") {
 		by := strings.TrimPrefix(codec, "delim:")
@@ -64,6 +167,10 @@ func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
 			return newCustomDelimWriter(w, by)
 		}, customDelimConfig, nil
 	}
+	name, params, _ := strings.Cut(codec, ":")
+	if ctor, exists := registeredWriters[name]; exists {
+		return ctor(params)
+	}
 	return nil, WriterConfig{}, fmt.Errorf("codec was not recognised: %v", codec)
 }
 
@@ -148,3 +255,232 @@ func (d *customDelimWriter) Write(ctx context.Context, p *message.Part) error {
 func (d *customDelimWriter) Close(ctx context.Context) error {
 	return d.w.Close()
 }
+
+//------------------------------------------------------------------------------
+
+type gzipWriteCloser struct {
+	*gzip.Writer
+	w io.WriteCloser
+}
+
+func (g *gzipWriteCloser) Close() error {
+	if err := g.Writer.Close(); err != nil {
+		_ = g.w.Close()
+		return err
+	}
+	return g.w.Close()
+}
+
+// gzipWriter flushes the compressor after each message so that written
+// messages reach the underlying stream before they are acknowledged.
+type gzipWriter struct {
+	Writer
+	gw *gzipWriteCloser
+}
+
+func (g *gzipWriter) Write(ctx context.Context, p *message.Part) error {
+	if err := g.Writer.Write(ctx, p); err != nil {
+		return err
+	}
+	return g.gw.Flush()
+}
+
+func (g *gzipWriter) Flush(ctx context.Context) error {
+	if err := FlushWriter(ctx, g.Writer); err != nil {
+		return err
+	}
+	return g.gw.Flush()
+}
+
+func gzipWriterCtor(ctor WriterConstructor) WriterConstructor {
+	return func(w io.WriteCloser) (Writer, error) {
+		gw := &gzipWriteCloser{
+			Writer: gzip.NewWriter(w),
+			w:      w,
+		}
+		inner, err := ctor(gw)
+		if err != nil {
+			return nil, err
+		}
+		return &gzipWriter{Writer: inner, gw: gw}, nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var csvWriterConfig = WriterConfig{
+	Truncate: true,
+}
+
+type csvWriter struct {
+	w         io.WriteCloser
+	csv       *csv.Writer
+	headers   []string
+	headerSet map[string]struct{}
+}
+
+func newCSVWriter(w io.WriteCloser, customComma *rune) (Writer, error) {
+	c := csv.NewWriter(w)
+	if customComma != nil {
+		c.Comma = *customComma
+	}
+	return &csvWriter{w: w, csv: c}, nil
+}
+
+func (c *csvWriter) Write(ctx context.Context, p *message.Part) error {
+	v, err := p.AsStructured()
+	if err != nil {
+		return fmt.Errorf("failed to parse message as structured data: %w", err)
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected message to be an object, got %T", v)
+	}
+
+	if c.headers == nil {
+		c.headers = make([]string, 0, len(obj))
+		for k := range obj {
+			c.headers = append(c.headers, k)
+		}
+		sort.Strings(c.headers)
+		c.headerSet = make(map[string]struct{}, len(c.headers))
+		for _, k := range c.headers {
+			c.headerSet[k] = struct{}{}
+		}
+		if err := c.csv.Write(c.headers); err != nil {
+			return err
+		}
+	}
+
+	row := make([]string, len(c.headers))
+	matched := 0
+	for i, k := range c.headers {
+		if v, exists := obj[k]; exists {
+			matched++
+			if v != nil {
+				row[i] = query.IToString(v)
+			}
+		}
+	}
+	if matched < len(obj) {
+		var unknown []string
+		for k := range obj {
+			if _, exists := c.headerSet[k]; !exists {
+				unknown = append(unknown, k)
+			}
+		}
+		sort.Strings(unknown)
+		return fmt.Errorf("message contains keys not present in the csv header: %v", strings.Join(unknown, ", "))
+	}
+	if err := c.csv.Write(row); err != nil {
+		return err
+	}
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+func (c *csvWriter) Close(ctx context.Context) error {
+	return c.w.Close()
+}
+
+//------------------------------------------------------------------------------
+
+var tarWriterConfig = WriterConfig{
+	Truncate: true,
+}
+
+type tarWriter struct {
+	w     io.WriteCloser
+	tw    *tar.Writer
+	count int
+}
+
+func newTarWriter(w io.WriteCloser) (Writer, error) {
+	return &tarWriter{w: w, tw: tar.NewWriter(w)}, nil
+}
+
+func (t *tarWriter) Write(ctx context.Context, p *message.Part) error {
+	t.count++
+
+	name := p.MetaGetStr("tar_name")
+	if name == "" {
+		name = strconv.Itoa(t.count)
+	}
+
+	partBytes := p.AsBytes()
+	if err := t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(partBytes)),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := t.tw.Write(partBytes); err != nil {
+		return err
+	}
+	return t.tw.Flush()
+}
+
+func (t *tarWriter) Close(ctx context.Context) error {
+	if err := t.tw.Close(); err != nil {
+		_ = t.w.Close()
+		return err
+	}
+	return t.w.Close()
+}
+
+//------------------------------------------------------------------------------
+
+var avroOCFWriterConfig = WriterConfig{
+	Truncate: true,
+}
+
+// avroOCFWriter buffers datums until it is flushed, which results in a single
+// OCF block per batch rather than one for each message.
+type avroOCFWriter struct {
+	w         io.WriteCloser
+	ocf       *goavro.OCFWriter
+	avroCodec *goavro.Codec
+	pending   []any
+}
+
+func newAvroOCFWriter(w io.WriteCloser, avroCodec *goavro.Codec) (Writer, error) {
+	ocf, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:     w,
+		Codec: avroCodec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &avroOCFWriter{w: w, ocf: ocf, avroCodec: avroCodec}, nil
+}
+
+func (a *avroOCFWriter) Write(ctx context.Context, p *message.Part) error {
+	datum, _, err := a.avroCodec.NativeFromTextual(p.AsBytes())
+	if err != nil {
+		return fmt.Errorf("failed to convert message to avro: %w", err)
+	}
+	a.pending = append(a.pending, datum)
+	return nil
+}
+
+func (a *avroOCFWriter) Flush(ctx context.Context) error {
+	if len(a.pending) == 0 {
+		return nil
+	}
+	if err := a.ocf.Append(a.pending); err != nil {
+		return err
+	}
+	a.pending = nil
+	return nil
+}
+
+func (a *avroOCFWriter) Close(ctx context.Context) error {
+	if err := a.Flush(ctx); err != nil {
+		_ = a.w.Close()
+		return err
+	}
+	return a.w.Close()
+}
@@ -0,0 +1,182 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeBuffer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func writeAll(t *testing.T, codec string, parts ...*message.Part) []byte {
+	t.Helper()
+
+	ctor, _, err := GetWriter(codec)
+	require.NoError(t, err)
+
+	buf := &closeBuffer{}
+	w, err := ctor(buf)
+	require.NoError(t, err)
+
+	for _, p := range parts {
+		require.NoError(t, w.Write(context.Background(), p))
+	}
+	require.NoError(t, w.Close(context.Background()))
+	assert.True(t, buf.closed)
+
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, codec string, data []byte) (res []string) {
+	t.Helper()
+
+	ctor, err := GetReader(codec, NewReaderConfig())
+	require.NoError(t, err)
+
+	r, err := ctor("", io.NopCloser(bytes.NewReader(data)), func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	for {
+		parts, ackFn, err := r.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		for _, p := range parts {
+			res = append(res, string(p.AsBytes()))
+		}
+		require.NoError(t, ackFn(context.Background(), nil))
+	}
+	require.NoError(t, r.Close(context.Background()))
+	return
+}
+
+func TestLinesWriter(t *testing.T) {
+	data := writeAll(t, "lines", message.NewPart([]byte("foo")), message.NewPart([]byte("bar\n")))
+	assert.Equal(t, "foo\nbar\n", string(data))
+}
+
+func TestCSVWriter(t *testing.T) {
+	data := writeAll(t, "csv",
+		message.NewPart([]byte(`{"col2":"bar1","col1":"foo1","col3":10}`)),
+		message.NewPart([]byte(`{"col1":"foo2","col3":true}`)),
+		message.NewPart([]byte(`{"col1":"foo,3","col2":"bar3","col3":null}`)),
+	)
+	assert.Equal(t, "col1,col2,col3\nfoo1,bar1,10\nfoo2,,true\n\"foo,3\",bar3,\n", string(data))
+
+	data = writeAll(t, "csv:|", message.NewPart([]byte(`{"a":"b"}`)))
+	assert.Equal(t, "a\nb\n", string(data))
+
+	ctor, _, err := GetWriter("csv")
+	require.NoError(t, err)
+
+	w, err := ctor(&closeBuffer{})
+	require.NoError(t, err)
+	require.EqualError(t, w.Write(context.Background(), message.NewPart([]byte(`["foo"]`))), "expected message to be an object, got []interface {}")
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte(`{"col1":"foo"}`))))
+	require.EqualError(t, w.Write(context.Background(), message.NewPart([]byte(`{"col1":"bar","col3":"baz","col2":"buz"}`))), "message contains keys not present in the csv header: col2, col3")
+
+	_, _, err = GetWriter("csv:ab")
+	require.EqualError(t, err, "csv codec requires a single character delimiter")
+}
+
+func TestTarWriter(t *testing.T) {
+	named := message.NewPart([]byte("first"))
+	named.MetaSetMut("tar_name", "foo.txt")
+
+	for _, codec := range []string{"tar", "gzip/tar", "tar-gzip"} {
+		data := writeAll(t, codec, named, message.NewPart([]byte("second")))
+
+		readCodec := "tar"
+		if codec != "tar" {
+			readCodec = "gzip/tar"
+		}
+		assert.Equal(t, []string{"first", "second"}, readAll(t, readCodec, data), codec)
+	}
+}
+
+func TestGzipWriter(t *testing.T) {
+	data := writeAll(t, "gzip/lines", message.NewPart([]byte("foo")), message.NewPart([]byte("bar")))
+	assert.Equal(t, []string{"foo", "bar"}, readAll(t, "gzip/lines", data))
+
+	data = writeAll(t, "gzip", message.NewPart([]byte("foo")), message.NewPart([]byte("bar")))
+	assert.Equal(t, []string{"foobar"}, readAll(t, "gzip/all-bytes", data))
+
+	_, _, err := GetWriter("gzip/nope")
+	require.EqualError(t, err, "codec was not recognised: nope")
+}
+
+func TestGzipWriterFlushesEachMessage(t *testing.T) {
+	ctor, _, err := GetWriter("gzip/lines")
+	require.NoError(t, err)
+
+	buf := &closeBuffer{}
+	w, err := ctor(buf)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("foo"))))
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("bar"))))
+
+	// Read the compressed data written so far without closing the writer.
+	zr, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	lineBytes := make([]byte, 8)
+	_, err = io.ReadFull(zr, lineBytes)
+	require.NoError(t, err)
+	assert.Equal(t, "foo\nbar\n", string(lineBytes))
+
+	require.NoError(t, w.Close(context.Background()))
+}
+
+func TestAvroOCFWriter(t *testing.T) {
+	schema := `{"type":"record","name":"foo","fields":[{"name":"name","type":"string"},{"name":"age","type":["null","int"]}]}`
+
+	data := writeAll(t, "avro-ocf:schema="+schema,
+		message.NewPart([]byte(`{"name":"foo","age":10}`)),
+		message.NewPart([]byte(`{"name":"bar","age":null}`)),
+	)
+	assert.Equal(t, []string{
+		`{"age":{"int":10},"name":"foo"}`,
+		`{"age":null,"name":"bar"}`,
+	}, readAll(t, "avro-ocf:marshaler=json", data))
+
+	ctor, _, err := GetWriter("avro-ocf:schema=" + schema)
+	require.NoError(t, err)
+
+	buf := &closeBuffer{}
+	w, err := ctor(buf)
+	require.NoError(t, err)
+
+	headerLen := buf.Len()
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte(`{"name":"foo","age":10}`))))
+	assert.Equal(t, headerLen, buf.Len(), "datum should be buffered until flushed")
+	require.NoError(t, FlushWriter(context.Background(), w))
+	assert.Greater(t, buf.Len(), headerLen)
+	require.NoError(t, w.Close(context.Background()))
+
+	_, _, err = GetWriter("avro-ocf")
+	require.Error(t, err)
+
+	_, _, err = GetWriter("avro-ocf:schema=nope")
+	require.Error(t, err)
+}
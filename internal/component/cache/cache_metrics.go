@@ -31,6 +31,15 @@ type metricsCache struct {
 	mDelError   metrics.StatCounter
 	mDelSuccess metrics.StatCounter
 	mDelLatency metrics.StatTimer
+
+	mIncrError   metrics.StatCounter
+	mIncrSuccess metrics.StatCounter
+	mIncrLatency metrics.StatTimer
+
+	mCASMismatch metrics.StatCounter
+	mCASError    metrics.StatCounter
+	mCASSuccess  metrics.StatCounter
+	mCASLatency  metrics.StatTimer
 }
 
 // MetricsForCache wraps a cache with a struct that adds standard metrics over
@@ -60,6 +69,15 @@ func MetricsForCache(c V1, stats metrics.Type) V1 {
 		mDelError:   cacheError.With("delete"),
 		mDelSuccess: cacheSuccess.With("delete"),
 		mDelLatency: cacheLatency.With("delete"),
+
+		mIncrError:   cacheError.With("incr"),
+		mIncrSuccess: cacheSuccess.With("incr"),
+		mIncrLatency: cacheLatency.With("incr"),
+
+		mCASMismatch: stats.GetCounterVec("cache_mismatch", "operation").With("compare_and_swap"),
+		mCASError:    cacheError.With("compare_and_swap"),
+		mCASSuccess:  cacheSuccess.With("compare_and_swap"),
+		mCASLatency:  cacheLatency.With("compare_and_swap"),
 	}
 }
 
@@ -79,6 +97,19 @@ func (a *metricsCache) Get(ctx context.Context, key string) ([]byte, error) {
 	return b, err
 }
 
+func (a *metricsCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	started := time.Now()
+	values, err := a.c.GetMulti(ctx, keys)
+	a.mGetLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mGetError.Incr(int64(len(keys)))
+	} else {
+		a.mGetSuccess.Incr(int64(len(values)))
+		a.mGetNotFound.Incr(int64(len(keys) - len(values)))
+	}
+	return values, err
+}
+
 func (a *metricsCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
 	started := time.Now()
 	err := a.c.Set(ctx, key, value, ttl)
@@ -131,6 +162,40 @@ func (a *metricsCache) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+func (a *metricsCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	started := time.Now()
+	v, err := Incr(ctx, a.c, key, delta, ttl)
+	a.mIncrLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mIncrError.Incr(1)
+	} else {
+		a.mIncrSuccess.Incr(1)
+	}
+	return v, err
+}
+
+func (a *metricsCache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	started := time.Now()
+	err := CompareAndSwap(ctx, a.c, key, oldValue, newValue, ttl)
+	a.mCASLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		if errors.Is(err, component.ErrValueMismatch) ||
+			errors.Is(err, component.ErrKeyNotFound) ||
+			errors.Is(err, component.ErrKeyAlreadyExists) {
+			a.mCASMismatch.Incr(1)
+		} else {
+			a.mCASError.Incr(1)
+		}
+	} else {
+		a.mCASSuccess.Incr(1)
+	}
+	return err
+}
+
+func (a *metricsCache) Keys(ctx context.Context, prefix string) ([]string, error) {
+	return Keys(ctx, a.c, prefix)
+}
+
 func (a *metricsCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
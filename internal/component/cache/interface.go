@@ -3,6 +3,8 @@ package cache
 import (
 	"context"
 	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
 )
 
 // TTLItem contains a value to cache along with an optional TTL.
@@ -17,6 +19,11 @@ type V1 interface {
 	// error if the key does not exist or if the command fails.
 	Get(ctx context.Context, key string) ([]byte, error)
 
+	// GetMulti attempts to locate and return the cached values of multiple
+	// keys in as few requests as possible. Keys that do not exist are omitted
+	// from the result, and an error is returned only if the command fails.
+	GetMulti(ctx context.Context, keys []string) (map[string][]byte, error)
+
 	// Set attempts to set the value of a key, returns an error if the command
 	// fails.
 	Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error
@@ -38,3 +45,66 @@ type V1 interface {
 	// is cancelled.
 	Close(ctx context.Context) error
 }
+
+// Incrementer is an optional interface for caches that are able to atomically
+// increment the integer value of a key.
+type Incrementer interface {
+	// Incr atomically adds a delta to the integer value of a key and returns
+	// the result, a key that does not exist is treated as having a value of
+	// zero. Returns an error if the existing value is not an integer or if
+	// the command fails.
+	Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+// CompareAndSwapper is an optional interface for caches that are able to
+// atomically set the value of a key only if its current value matches an
+// expected value.
+type CompareAndSwapper interface {
+	// CompareAndSwap sets the value of a key to newValue only if its current
+	// value is equal to oldValue, a nil oldValue indicates that the key must
+	// not exist. Returns ErrValueMismatch if the current value does not match,
+	// ErrKeyNotFound if the key does not exist, ErrKeyAlreadyExists if the key
+	// was expected not to exist, or an error if the command fails.
+	CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error
+}
+
+// KeyLister is an optional interface for caches that are able to list the keys
+// they currently hold.
+type KeyLister interface {
+	// Keys returns the keys currently held within the cache that begin with a
+	// prefix, an empty prefix matches all keys. The order of keys is not
+	// guaranteed.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Incr attempts to atomically increment the value of a key of a cache,
+// returning component.ErrNotSupported if the cache does not implement
+// Incrementer.
+func Incr(ctx context.Context, c V1, key string, delta int64, ttl *time.Duration) (int64, error) {
+	i, ok := c.(Incrementer)
+	if !ok {
+		return 0, component.ErrNotSupported
+	}
+	return i.Incr(ctx, key, delta, ttl)
+}
+
+// CompareAndSwap attempts to atomically swap the value of a key of a cache,
+// returning component.ErrNotSupported if the cache does not implement
+// CompareAndSwapper.
+func CompareAndSwap(ctx context.Context, c V1, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	s, ok := c.(CompareAndSwapper)
+	if !ok {
+		return component.ErrNotSupported
+	}
+	return s.CompareAndSwap(ctx, key, oldValue, newValue, ttl)
+}
+
+// Keys attempts to list the keys of a cache that begin with a prefix, returning
+// component.ErrNotSupported if the cache does not implement KeyLister.
+func Keys(ctx context.Context, c V1, prefix string) ([]string, error) {
+	l, ok := c.(KeyLister)
+	if !ok {
+		return nil, component.ErrNotSupported
+	}
+	return l.Keys(ctx, prefix)
+}
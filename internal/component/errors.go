@@ -59,6 +59,8 @@ var (
 	ErrOutputNotFound    = errors.New("output not found")
 	ErrKeyAlreadyExists  = errors.New("key already exists")
 	ErrKeyNotFound       = errors.New("key does not exist")
+	ErrValueMismatch     = errors.New("value does not match")
+	ErrNotSupported      = errors.New("operation not supported")
 	ErrPipeNotFound      = errors.New("pipe was not found")
 )
 
@@ -1,11 +1,30 @@
 package input
 
+// FileFollowConfig contains configuration values for the follow mode of the
+// File input type.
+type FileFollowConfig struct {
+	Enabled      bool   `json:"enabled" yaml:"enabled"`
+	PollInterval string `json:"poll_interval" yaml:"poll_interval"`
+	Cache        string `json:"cache" yaml:"cache"`
+}
+
+// FileWatcherConfig contains configuration values for the watcher mode of the
+// File input type.
+type FileWatcherConfig struct {
+	Enabled      bool   `json:"enabled" yaml:"enabled"`
+	MinimumAge   string `json:"minimum_age" yaml:"minimum_age"`
+	PollInterval string `json:"poll_interval" yaml:"poll_interval"`
+	Cache        string `json:"cache" yaml:"cache"`
+}
+
 // FileConfig contains configuration values for the File input type.
 type FileConfig struct {
-	Paths          []string `json:"paths" yaml:"paths"`
-	Codec          string   `json:"codec" yaml:"codec"`
-	MaxBuffer      int      `json:"max_buffer" yaml:"max_buffer"`
-	DeleteOnFinish bool     `json:"delete_on_finish" yaml:"delete_on_finish"`
+	Paths          []string          `json:"paths" yaml:"paths"`
+	Codec          string            `json:"codec" yaml:"codec"`
+	MaxBuffer      int               `json:"max_buffer" yaml:"max_buffer"`
+	DeleteOnFinish bool              `json:"delete_on_finish" yaml:"delete_on_finish"`
+	Follow         FileFollowConfig  `json:"follow" yaml:"follow"`
+	Watcher        FileWatcherConfig `json:"watcher" yaml:"watcher"`
 }
 
 // NewFileConfig creates a new FileConfig with default values.
@@ -15,5 +34,16 @@ func NewFileConfig() FileConfig {
 		Codec:          "lines",
 		MaxBuffer:      1000000,
 		DeleteOnFinish: false,
+		Follow: FileFollowConfig{
+			Enabled:      false,
+			PollInterval: "1s",
+			Cache:        "",
+		},
+		Watcher: FileWatcherConfig{
+			Enabled:      false,
+			MinimumAge:   "1s",
+			PollInterval: "1s",
+			Cache:        "",
+		},
 	}
 }
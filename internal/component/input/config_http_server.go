@@ -34,6 +34,7 @@ type HTTPServerConfig struct {
 	AllowedVerbs       []string                 `json:"allowed_verbs" yaml:"allowed_verbs"`
 	Timeout            string                   `json:"timeout" yaml:"timeout"`
 	RateLimit          string                   `json:"rate_limit" yaml:"rate_limit"`
+	RateLimitKey       string                   `json:"rate_limit_key" yaml:"rate_limit_key"`
 	CertFile           string                   `json:"cert_file" yaml:"cert_file"`
 	KeyFile            string                   `json:"key_file" yaml:"key_file"`
 	CORS               httpserver.CORSConfig    `json:"cors" yaml:"cors"`
@@ -51,11 +52,12 @@ func NewHTTPServerConfig() HTTPServerConfig {
 		AllowedVerbs: []string{
 			"POST",
 		},
-		Timeout:   "5s",
-		RateLimit: "",
-		CertFile:  "",
-		KeyFile:   "",
-		CORS:      httpserver.NewServerCORSConfig(),
-		Response:  NewHTTPServerResponseConfig(),
+		Timeout:      "5s",
+		RateLimit:    "",
+		RateLimitKey: "",
+		CertFile:     "",
+		KeyFile:      "",
+		CORS:         httpserver.NewServerCORSConfig(),
+		Response:     NewHTTPServerResponseConfig(),
 	}
 }
@@ -60,6 +60,7 @@ type Config struct {
 	SyncResponse       struct{}                `json:"sync_response" yaml:"sync_response"`
 	Socket             SocketConfig            `json:"socket" yaml:"socket"`
 	Processors         []processor.Config      `json:"processors" yaml:"processors"`
+	DeadLetter         *DeadLetterConfig       `json:"dead_letter,omitempty" yaml:"dead_letter,omitempty"`
 }
 
 // NewConfig returns a configuration struct fully populated with default values.
@@ -116,6 +117,7 @@ func NewConfig() Config {
 		SyncResponse:       struct{}{},
 		Socket:             NewSocketConfig(),
 		Processors:         []processor.Config{},
+		DeadLetter:         nil,
 	}
 }
 
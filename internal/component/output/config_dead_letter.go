@@ -0,0 +1,32 @@
+package output
+
+// DeadLetterConfig contains configuration fields for routing messages that an
+// output fails to deliver to a dead letter output.
+type DeadLetterConfig struct {
+	Output      *Config `json:"output" yaml:"output"`
+	Mapping     string  `json:"mapping" yaml:"mapping"`
+	MaxAttempts int     `json:"max_attempts" yaml:"max_attempts"`
+}
+
+// NewDeadLetterConfig creates a new DeadLetterConfig with default values.
+func NewDeadLetterConfig() DeadLetterConfig {
+	return DeadLetterConfig{
+		Output:      nil,
+		Mapping:     "",
+		MaxAttempts: 3,
+	}
+}
+
+// UnmarshalYAML ensures that when parsing configs that are in a map or slice
+// the default values are still applied.
+func (d *DeadLetterConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	type confAlias DeadLetterConfig
+	aliased := confAlias(NewDeadLetterConfig())
+
+	if err := unmarshal(&aliased); err != nil {
+		return err
+	}
+
+	*d = DeadLetterConfig(aliased)
+	return nil
+}
@@ -0,0 +1,224 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+)
+
+// DeadLetter is a type that wraps an output and reattempts the delivery of
+// messages that fail, and once a maximum number of attempts is reached routes
+// those messages to a dead letter output instead.
+type DeadLetter struct {
+	out         Streamed
+	deadLetter  Streamed
+	mapping     *mapping.Executor
+	label       string
+	maxAttempts int
+
+	log log.Modular
+
+	transactionsIn  <-chan message.Transaction
+	transactionsOut chan message.Transaction
+	deadLetterOut   chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+// WrapWithDeadLetter wraps an output such that messages it fails to deliver
+// after maxAttempts attempts are written to a dead letter output. Prior to
+// being routed each message is given the metadata fields dead_letter_error,
+// dead_letter_label and dead_letter_attempts, and then the optional mapping is
+// executed.
+func WrapWithDeadLetter(out, deadLetter Streamed, exec *mapping.Executor, label string, maxAttempts int, log log.Modular) (*DeadLetter, error) {
+	if maxAttempts < 1 {
+		return nil, errors.New("max_attempts must be greater than zero")
+	}
+	d := &DeadLetter{
+		out:             out,
+		deadLetter:      deadLetter,
+		mapping:         exec,
+		label:           label,
+		maxAttempts:     maxAttempts,
+		log:             log,
+		transactionsOut: make(chan message.Transaction),
+		deadLetterOut:   make(chan message.Transaction),
+		shutSig:         shutdown.NewSignaller(),
+	}
+	if err := out.Consume(d.transactionsOut); err != nil {
+		return nil, err
+	}
+	if err := deadLetter.Consume(d.deadLetterOut); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (d *DeadLetter) loop() {
+	var wg sync.WaitGroup
+
+	defer func() {
+		wg.Wait()
+		close(d.transactionsOut)
+		close(d.deadLetterOut)
+		_ = d.out.WaitForClose(context.Background())
+		_ = d.deadLetter.WaitForClose(context.Background())
+		d.shutSig.ShutdownComplete()
+	}()
+
+	cnCtx, cnDone := d.shutSig.CloseNowCtx(context.Background())
+	defer cnDone()
+
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-d.transactionsIn:
+			if !open {
+				return
+			}
+		case <-d.shutSig.CloseNowChan():
+			return
+		}
+
+		resChan := make(chan error)
+		select {
+		case d.transactionsOut <- message.NewTransaction(tran.Payload.ShallowCopy(), resChan):
+		case <-d.shutSig.CloseNowChan():
+			return
+		}
+
+		wg.Add(1)
+		go func(ts message.Transaction, resChan chan error) {
+			defer wg.Done()
+			d.deliver(cnCtx, ts, resChan)
+		}(tran, resChan)
+	}
+}
+
+// deliver awaits the result of each delivery attempt of a transaction, making
+// further attempts until either one succeeds or the maximum attempts is
+// reached, at which point the transaction is routed to the dead letter output.
+func (d *DeadLetter) deliver(ctx context.Context, ts message.Transaction, resChan chan error) {
+	for attempts := 1; ; attempts++ {
+		var res error
+		select {
+		case res = <-resChan:
+		case <-ctx.Done():
+			return
+		}
+
+		if res == nil {
+			_ = ts.Ack(ctx, nil)
+			return
+		}
+
+		if attempts >= d.maxAttempts {
+			d.log.Errorf("Failed to send message after %v attempts, routing to dead letter output: %v\n", attempts, res)
+			if err := d.sendDeadLetter(ctx, ts.Payload, res, attempts); err != nil {
+				d.log.Errorf("Failed to send message to dead letter output: %v\n", err)
+				res = err
+			} else {
+				res = nil
+			}
+			_ = ts.Ack(ctx, res)
+			return
+		}
+
+		d.log.Warnf("Failed to send message (attempt %v of %v): %v\n", attempts, d.maxAttempts, res)
+		select {
+		case d.transactionsOut <- message.NewTransaction(ts.Payload.ShallowCopy(), resChan):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *DeadLetter) sendDeadLetter(ctx context.Context, msg message.Batch, cause error, attempts int) error {
+	deadBatch := make(message.Batch, len(msg))
+	for i, p := range msg {
+		p = p.ShallowCopy()
+		p.MetaSetMut("dead_letter_error", cause.Error())
+		p.MetaSetMut("dead_letter_label", d.label)
+		p.MetaSetMut("dead_letter_attempts", int64(attempts))
+		deadBatch[i] = p
+	}
+
+	if d.mapping != nil {
+		mapped := make(message.Batch, 0, len(deadBatch))
+		for i := range deadBatch {
+			p, err := d.mapping.MapPart(i, deadBatch)
+			if err != nil {
+				return fmt.Errorf("dead letter mapping failed: %w", err)
+			}
+			if p != nil {
+				mapped = append(mapped, p)
+			}
+		}
+		if len(mapped) == 0 {
+			// The mapping deleted every message, which means there's nothing
+			// left to route.
+			return nil
+		}
+		deadBatch = mapped
+	}
+
+	resChan := make(chan error)
+	select {
+	case d.deadLetterOut <- message.NewTransaction(deadBatch, resChan):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-resChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Consume starts the type listening to a message channel from a
+// producer.
+func (d *DeadLetter) Consume(ts <-chan message.Transaction) error {
+	if d.transactionsIn != nil {
+		return component.ErrAlreadyStarted
+	}
+	d.transactionsIn = ts
+	go d.loop()
+	return nil
+}
+
+// Connected returns a boolean indicating whether this output is currently
+// connected to its target.
+func (d *DeadLetter) Connected() bool {
+	return d.out.Connected()
+}
+
+// TriggerCloseNow triggers a closure of this object but does not block.
+func (d *DeadLetter) TriggerCloseNow() {
+	d.shutSig.CloseNow()
+	d.out.TriggerCloseNow()
+	d.deadLetter.TriggerCloseNow()
+}
+
+// WaitForClose is a blocking call to wait until the object has finished closing
+// down and cleaning up resources.
+func (d *DeadLetter) WaitForClose(ctx context.Context) error {
+	select {
+	case <-d.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
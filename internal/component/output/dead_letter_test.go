@@ -0,0 +1,153 @@
+package output_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func readTran(t *testing.T, tChan <-chan message.Transaction) message.Transaction {
+	t.Helper()
+
+	select {
+	case tran, open := <-tChan:
+		require.True(t, open)
+		return tran
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	return message.Transaction{}
+}
+
+func readRes(t *testing.T, resChan <-chan error) error {
+	t.Helper()
+
+	select {
+	case err := <-resChan:
+		return err
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	return nil
+}
+
+func TestDeadLetterRouting(t *testing.T) {
+	exec, err := bloblang.GlobalEnvironment().NewMapping(`
+root.doc = this
+root.error = @dead_letter_error
+root.label = @dead_letter_label
+root.attempts = @dead_letter_attempts
+`)
+	require.NoError(t, err)
+
+	out, dlq := &mock.OutputChanneled{}, &mock.OutputChanneled{}
+	d, err := output.WrapWithDeadLetter(out, dlq, exec, "foo", 2, log.Noop())
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, d.Consume(tChan))
+
+	resChan := make(chan error)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(`{"id":"a"}`)}), resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	for i := 0; i < 2; i++ {
+		tran := readTran(t, out.TChan)
+		assert.Equal(t, `{"id":"a"}`, string(tran.Payload.Get(0).AsBytes()))
+		require.NoError(t, tran.Ack(context.Background(), errors.New("nope")))
+	}
+
+	tran := readTran(t, dlq.TChan)
+	assert.Equal(t, `{"attempts":2,"doc":{"id":"a"},"error":"nope","label":"foo"}`, string(tran.Payload.Get(0).AsBytes()))
+	require.NoError(t, tran.Ack(context.Background(), nil))
+
+	require.NoError(t, readRes(t, resChan))
+
+	close(tChan)
+	require.NoError(t, d.WaitForClose(context.Background()))
+}
+
+func TestDeadLetterEventualSuccess(t *testing.T) {
+	out, dlq := &mock.OutputChanneled{}, &mock.OutputChanneled{}
+	d, err := output.WrapWithDeadLetter(out, dlq, nil, "", 3, log.Noop())
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, d.Consume(tChan))
+
+	resChan := make(chan error)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	tran := readTran(t, out.TChan)
+	require.NoError(t, tran.Ack(context.Background(), errors.New("nope")))
+
+	tran = readTran(t, out.TChan)
+	assert.Equal(t, "hello", string(tran.Payload.Get(0).AsBytes()))
+	require.NoError(t, tran.Ack(context.Background(), nil))
+
+	require.NoError(t, readRes(t, resChan))
+
+	close(tChan)
+	require.NoError(t, d.WaitForClose(context.Background()))
+}
+
+func TestDeadLetterFromConfig(t *testing.T) {
+	var conf output.Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+label: foo
+reject: first failure
+dead_letter:
+  output:
+    reject: dead letter failure
+`), &conf))
+	require.NotNil(t, conf.DeadLetter)
+	assert.Equal(t, 3, conf.DeadLetter.MaxAttempts)
+
+	o, err := mock.NewManager().NewOutput(conf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tChan))
+
+	resChan := make(chan error)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	err = readRes(t, resChan)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dead letter failure")
+
+	close(tChan)
+	require.NoError(t, o.WaitForClose(context.Background()))
+}
+
+func TestDeadLetterBadConfig(t *testing.T) {
+	conf := output.NewConfig()
+	conf.Type = "drop"
+	conf.DeadLetter = &output.DeadLetterConfig{MaxAttempts: 3}
+
+	_, err := mock.NewManager().NewOutput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "an output must be specified")
+}
@@ -6,6 +6,7 @@ type CacheConfig struct {
 	Operator string `json:"operator" yaml:"operator"`
 	Key      string `json:"key" yaml:"key"`
 	Value    string `json:"value" yaml:"value"`
+	OldValue string `json:"old_value" yaml:"old_value"`
 	TTL      string `json:"ttl" yaml:"ttl"`
 }
 
@@ -16,6 +17,7 @@ func NewCacheConfig() CacheConfig {
 		Operator: "",
 		Key:      "",
 		Value:    "",
+		OldValue: "",
 		TTL:      "",
 	}
 }
@@ -2,16 +2,18 @@ package processor
 
 // ProtobufConfig contains configuration fields for the Protobuf processor.
 type ProtobufConfig struct {
-	Operator    string   `json:"operator" yaml:"operator"`
-	Message     string   `json:"message" yaml:"message"`
-	ImportPaths []string `json:"import_paths" yaml:"import_paths"`
+	Operator       string   `json:"operator" yaml:"operator"`
+	Message        string   `json:"message" yaml:"message"`
+	ImportPaths    []string `json:"import_paths" yaml:"import_paths"`
+	DescriptorSets []string `json:"descriptor_sets" yaml:"descriptor_sets"`
 }
 
 // NewProtobufConfig returns a ProtobufConfig with default values.
 func NewProtobufConfig() ProtobufConfig {
 	return ProtobufConfig{
-		Operator:    "",
-		Message:     "",
-		ImportPaths: []string{},
+		Operator:       "",
+		Message:        "",
+		ImportPaths:    []string{},
+		DescriptorSets: []string{},
 	}
 }
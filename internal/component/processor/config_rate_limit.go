@@ -3,11 +3,13 @@ package processor
 // RateLimitConfig contains configuration fields for the RateLimit processor.
 type RateLimitConfig struct {
 	Resource string `json:"resource" yaml:"resource"`
+	Key      string `json:"key" yaml:"key"`
 }
 
 // NewRateLimitConfig returns a RateLimitConfig with default values.
 func NewRateLimitConfig() RateLimitConfig {
 	return RateLimitConfig{
 		Resource: "",
+		Key:      "",
 	}
 }
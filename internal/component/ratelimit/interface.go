@@ -18,3 +18,23 @@ type V1 interface {
 	// is cancelled.
 	Close(ctx context.Context) error
 }
+
+// Keyed is an optional interface implemented by rate limits that are able to
+// track a separate limit for each of any number of keys.
+type Keyed interface {
+	// AccessKey accesses the rate limited resource identified by a key.
+	// Returns a duration or an error if the rate limit check fails. The
+	// returned duration is either zero (meaning the resource may be accessed)
+	// or a reasonable length of time to wait before requesting again.
+	AccessKey(ctx context.Context, key string) (time.Duration, error)
+}
+
+// AccessKey accesses the rate limited resource identified by a key. Rate
+// limits that do not implement Keyed are accessed without the key, and
+// therefore the limit is shared by all keys.
+func AccessKey(ctx context.Context, r V1, key string) (time.Duration, error) {
+	if k, ok := r.(Keyed); ok {
+		return k.AccessKey(ctx, key)
+	}
+	return r.Access(ctx)
+}
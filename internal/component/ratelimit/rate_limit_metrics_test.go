@@ -31,3 +31,26 @@ func TestRateLimitAirGapShutdown(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, rl.closed)
 }
+
+type keyedRateLimit struct {
+	closableRateLimit
+	keys []string
+}
+
+func (k *keyedRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	k.keys = append(k.keys, key)
+	return time.Second, nil
+}
+
+func TestRateLimitAccessKey(t *testing.T) {
+	krl := &keyedRateLimit{}
+	tout, err := AccessKey(context.Background(), MetricsForRateLimit(krl, metrics.Noop()), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, tout)
+	assert.Equal(t, []string{"foo"}, krl.keys)
+
+	// Rate limits that are not keyed are accessed without the key.
+	tout, err = AccessKey(context.Background(), MetricsForRateLimit(&closableRateLimit{}, metrics.Noop()), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), tout)
+}
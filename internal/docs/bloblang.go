@@ -1,6 +1,7 @@
 package docs
 
 import (
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
 	"github.com/benthosdev/benthos/v4/public/bloblang"
 )
 
@@ -14,9 +15,9 @@ func LintBloblangMapping(ctx LintContext, line, col int, v any) []Lint {
 	if str == "" {
 		return nil
 	}
-	_, err := ctx.BloblangEnv.Parse(str)
+	exec, err := ctx.BloblangEnv.Parse(str)
 	if err == nil {
-		return nil
+		return lintBloblangTypes(line, col, exec)
 	}
 	if mErr, ok := err.(*bloblang.ParseError); ok {
 		lint := NewLintError(line+mErr.Line-1, LintBadBloblang, mErr.ErrorMultiline())
@@ -47,3 +48,20 @@ func LintBloblangField(ctx LintContext, line, col int, v any) []Lint {
 	}
 	return []Lint{NewLintError(line, LintBadBloblang, err.Error())}
 }
+
+// lintBloblangTypes reports definite type errors within a parsed mapping.
+func lintBloblangTypes(line, col int, exec *bloblang.Executor) []Lint {
+	uw, ok := exec.XUnwrapper().(interface {
+		Unwrap() *mapping.Executor
+	})
+	if !ok {
+		return nil
+	}
+	var lints []Lint
+	for _, l := range uw.Unwrap().Lint(nil) {
+		lint := NewLintError(line+l.Line-1, LintBadBloblang, l.What)
+		lint.Column = col + l.Column
+		lints = append(lints, lint)
+	}
+	return lints
+}
@@ -74,6 +74,21 @@ func ReservedFieldsByType(t Type) map[string]FieldSpec {
 			return "", false
 		})
 	}
+	if t == TypeOutput {
+		m["dead_letter"] = FieldObject(
+			"dead_letter", "Route messages that fail to be delivered by the output after a maximum number of attempts to a dead letter output.",
+		).WithChildren(
+			FieldOutput("output", "An output to route failed messages to."),
+			FieldBloblang(
+				"mapping", "An optional [Bloblang mapping](/docs/guides/bloblang/about) to execute on failed messages before they are routed, which has access to the metadata fields `dead_letter_error`, `dead_letter_label` and `dead_letter_attempts`.",
+				`root = this
+root.error = @dead_letter_error
+root.label = @dead_letter_label
+root.attempts = @dead_letter_attempts`,
+			).HasDefault(""),
+			FieldInt("max_attempts", "The maximum number of attempts made to deliver a message to the output before it is routed to the dead letter output.").HasDefault(3),
+		).AtVersion("4.14.0")
+	}
 	if t == TypeMetrics {
 		m["mapping"] = MetricsMappingFieldSpec("mapping")
 	}
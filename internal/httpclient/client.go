@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
@@ -35,6 +36,7 @@ type Client struct {
 
 	// Request execution and retry logic
 	rateLimit     string
+	rateLimitKey  *field.Expression
 	numRetries    int
 	retryThrottle *throttle.Type
 	backoffOn     map[int]struct{}
@@ -159,6 +161,11 @@ func NewClientFromOldConfig(conf OldConfig, mgr bundle.NewManagement, opts ...Re
 		if !h.mgr.ProbeRateLimit(h.rateLimit) {
 			return nil, fmt.Errorf("rate limit resource '%v' was not found", h.rateLimit)
 		}
+		if conf.RateLimitKey != "" {
+			if h.rateLimitKey, err = mgr.BloblEnvironment().NewField(conf.RateLimitKey); err != nil {
+				return nil, fmt.Errorf("failed to parse rate limit key expression: %v", err)
+			}
+		}
 	}
 
 	h.numRetries = conf.NumRetries
@@ -195,15 +202,31 @@ func (h *Client) incrCode(code int) {
 	h.codesMut.Unlock()
 }
 
-func (h *Client) waitForAccess(ctx context.Context) bool {
+func (h *Client) waitForAccess(ctx context.Context, sendMsg message.Batch) bool {
 	if h.rateLimit == "" {
 		return true
 	}
+
+	var key string
+	if h.rateLimitKey != nil {
+		if sendMsg.Len() == 0 {
+			sendMsg = message.QuickBatch([][]byte{nil})
+		}
+		var err error
+		if key, err = h.rateLimitKey.String(0, sendMsg); err != nil {
+			h.log.Errorf("Rate limit key interpolation error: %v\n", err)
+		}
+	}
+
 	for {
 		var period time.Duration
 		var err error
 		if rerr := h.mgr.AccessRateLimit(ctx, h.rateLimit, func(rl ratelimit.V1) {
-			period, err = rl.Access(ctx)
+			if h.rateLimitKey != nil {
+				period, err = ratelimit.AccessKey(ctx, rl, key)
+			} else {
+				period, err = rl.Access(ctx)
+			}
 		}); rerr != nil {
 			err = rerr
 		}
@@ -363,7 +386,7 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg message.Batch) (res
 		}
 	}()
 
-	if !h.waitForAccess(ctx) {
+	if !h.waitForAccess(ctx, sendMsg) {
 		return nil, component.ErrTypeClosed
 	}
 
@@ -401,7 +424,7 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg message.Batch) (res
 				return nil, component.ErrTypeClosed
 			}
 		}
-		if !h.waitForAccess(ctx) {
+		if !h.waitForAccess(ctx, sendMsg) {
 			return nil, component.ErrTypeClosed
 		}
 		rateLimited = false
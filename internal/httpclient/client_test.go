@@ -46,6 +46,35 @@ func TestHTTPClientRetries(t *testing.T) {
 	assert.Equal(t, uint32(4), atomic.LoadUint32(&reqCount))
 }
 
+func TestHTTPClientRateLimitKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	var keys []string
+	mgr := mock.NewManager()
+	mgr.RateLimits["foo"] = mock.KeyedRateLimit(func(ctx context.Context, key string) (time.Duration, error) {
+		keys = append(keys, key)
+		return 0, nil
+	})
+
+	conf := NewOldConfig()
+	conf.URL = ts.URL + "/testpost"
+	conf.RateLimit = "foo"
+	conf.RateLimitKey = `${! meta("tenant") }`
+
+	h, err := NewClientFromOldConfig(conf, mgr)
+	require.NoError(t, err)
+	defer h.Close(context.Background())
+
+	for _, tenant := range []string{"a", "b"} {
+		out := message.QuickBatch([][]byte{[]byte("test")})
+		out.Get(0).MetaSetMut("tenant", tenant)
+		_, err = h.Send(context.Background(), out)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
+
 func TestHTTPClientBadRequest(t *testing.T) {
 	conf := NewOldConfig()
 	conf.URL = "htp://notvalid:1111"
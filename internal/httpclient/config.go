@@ -51,6 +51,7 @@ func OldFieldSpec(defaultVerb string, forOutput bool, extraChildren ...docs.Fiel
 	httpSpecs = append(httpSpecs, tls.FieldSpec(),
 		docs.FieldObject("extract_headers", extractHeadersDesc).WithChildren(metadata.IncludeFilterDocs()...).Advanced(),
 		docs.FieldString("rate_limit", "An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by.").Optional(),
+		docs.FieldString("rate_limit_key", "An optional key to access the `rate_limit` with, allowing requests to be throttled separately for each unique key when the rate limit supports it, such as the [`token_bucket` rate limit](/docs/components/rate_limits/token_bucket).", `${! meta("tenant_id") }`).IsInterpolated().Advanced().Optional().AtVersion("4.14.0"),
 		docs.FieldString("timeout", "A static timeout to apply to requests.").HasDefault("5s"),
 		docs.FieldString("retry_period", "The base period to wait between failed requests.").Advanced().HasDefault("1s"),
 		docs.FieldString("max_retry_backoff", "The maximum period to wait between failed requests.").Advanced().HasDefault("300s"),
@@ -112,6 +113,7 @@ type OldConfig struct {
 	Metadata            metadata.IncludeFilterConfig `json:"metadata" yaml:"metadata"`
 	ExtractMetadata     metadata.IncludeFilterConfig `json:"extract_headers" yaml:"extract_headers"`
 	RateLimit           string                       `json:"rate_limit" yaml:"rate_limit"`
+	RateLimitKey        string                       `json:"rate_limit_key" yaml:"rate_limit_key"`
 	Timeout             string                       `json:"timeout" yaml:"timeout"`
 	Retry               string                       `json:"retry_period" yaml:"retry_period"`
 	MaxBackoff          string                       `json:"max_retry_backoff" yaml:"max_retry_backoff"`
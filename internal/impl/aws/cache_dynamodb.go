@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -381,6 +382,127 @@ func (d *dynamodbCache) delete(key string) error {
 	return err
 }
 
+// Incr increments the integer value of a key, since values are stored as
+// binary attributes this is implemented with a compare-and-swap loop.
+func (d *dynamodbCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	boff := d.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		d.boffPool.Put(boff)
+	}()
+
+	for {
+		v, err := d.incr(key, delta, ttl)
+		if err == nil {
+			return v, nil
+		}
+		if errors.Is(err, strconv.ErrSyntax) || errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("value of key '%v' is not an integer: %w", key, err)
+		}
+
+		// Conflicts with other writers are retried immediately.
+		if errors.Is(err, service.ErrKeyAlreadyExists) || errors.Is(err, service.ErrValueMismatch) {
+			continue
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return 0, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return 0, err
+		}
+	}
+}
+
+func (d *dynamodbCache) incr(key string, delta int64, ttl *time.Duration) (int64, error) {
+	current, err := d.get(key)
+	if errors.Is(err, service.ErrKeyNotFound) {
+		if err = d.add(key, []byte(strconv.FormatInt(delta, 10)), ttl); err != nil {
+			return 0, err
+		}
+		return delta, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseInt(string(current), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	v += delta
+
+	if err = d.swap(key, current, []byte(strconv.FormatInt(v, 10)), ttl); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+func (d *dynamodbCache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	boff := d.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		d.boffPool.Put(boff)
+	}()
+
+	for {
+		var err error
+		if oldValue == nil {
+			err = d.add(key, newValue, ttl)
+		} else {
+			err = d.swap(key, oldValue, newValue, ttl)
+		}
+		if err == nil ||
+			errors.Is(err, service.ErrKeyAlreadyExists) ||
+			errors.Is(err, service.ErrKeyNotFound) ||
+			errors.Is(err, service.ErrValueMismatch) {
+			return err
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (d *dynamodbCache) swap(key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	input := d.putItemInput(key, newValue, ttl)
+
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name(d.dataKey).Equal(expression.Value(oldValue))).
+		Build()
+	if err != nil {
+		return err
+	}
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	input.ConditionExpression = expr.Condition()
+
+	if _, err = d.client.PutItem(input); err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			if aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+				// Determine whether the condition failed due to the key not
+				// existing or due to the value not matching.
+				if _, gerr := d.get(key); errors.Is(gerr, service.ErrKeyNotFound) {
+					return service.ErrKeyNotFound
+				}
+				return service.ErrValueMismatch
+			}
+		}
+		return err
+	}
+	return nil
+}
+
 func (d *dynamodbCache) putItemInput(key string, value []byte, ttl *time.Duration) *dynamodb.PutItemInput {
 	input := dynamodb.PutItemInput{
 		Item: map[string]*dynamodb.AttributeValue{
@@ -0,0 +1,210 @@
+package confluent
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/httpclient"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// schemaRegistryClient obtains schemas from a Confluent Schema Registry
+// service, or from a local directory when the URL has the scheme file.
+type schemaRegistryClient struct {
+	client                *http.Client
+	schemaRegistryBaseURL *url.URL
+	requestSigner         httpclient.RequestSigner
+	localDir              string
+	mgr                   *service.Resources
+}
+
+func newSchemaRegistryClient(
+	urlStr string,
+	reqSigner httpclient.RequestSigner,
+	tlsConf *tls.Config,
+	mgr *service.Resources,
+) (*schemaRegistryClient, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	hClient := http.DefaultClient
+	if tlsConf != nil {
+		hClient = &http.Client{}
+		if c, ok := http.DefaultTransport.(*http.Transport); ok {
+			cloned := c.Clone()
+			cloned.TLSClientConfig = tlsConf
+			hClient.Transport = cloned
+		} else {
+			hClient.Transport = &http.Transport{
+				TLSClientConfig: tlsConf,
+			}
+		}
+	}
+
+	c := &schemaRegistryClient{
+		client:                hClient,
+		schemaRegistryBaseURL: u,
+		requestSigner:         reqSigner,
+		mgr:                   mgr,
+	}
+	if u.Scheme == "file" {
+		if c.localDir = strings.TrimPrefix(urlStr, "file://"); c.localDir == "" {
+			return nil, errors.New("a file url must specify a directory")
+		}
+	}
+	return c, nil
+}
+
+const (
+	schemaTypeAvro     = "AVRO"
+	schemaTypeProtobuf = "PROTOBUF"
+	schemaTypeJSON     = "JSON"
+)
+
+// SchemaInfo describes a schema obtained from the registry.
+type SchemaInfo struct {
+	ID         int               `json:"id"`
+	Type       string            `json:"schemaType"`
+	Schema     string            `json:"schema"`
+	References []SchemaReference `json:"references"`
+}
+
+// SchemaReference points to a schema, registered under a subject, that is
+// imported by another schema under a given name.
+type SchemaReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// GetSchemaByID obtains the schema registered with an ID.
+func (c *schemaRegistryClient) GetSchemaByID(ctx context.Context, id int) (resPayload SchemaInfo, err error) {
+	var resBytes []byte
+	if resBytes, err = c.doRequest(ctx, fmt.Sprintf("/schemas/ids/%v", id), fmt.Sprintf("schema '%v'", id)); err != nil {
+		return
+	}
+	if err = json.Unmarshal(resBytes, &resPayload); err != nil {
+		c.mgr.Logger().Errorf("failed to parse response for schema '%v': %v", id, err)
+		return
+	}
+	resPayload.ID = id
+	return
+}
+
+// GetSchemaBySubjectAndVersion obtains a version of the schema registered
+// under a subject, where a nil version obtains the latest version.
+func (c *schemaRegistryClient) GetSchemaBySubjectAndVersion(ctx context.Context, subject string, version *int) (resPayload SchemaInfo, err error) {
+	versionStr := "latest"
+	if version != nil {
+		versionStr = fmt.Sprintf("%v", *version)
+	}
+
+	var resBytes []byte
+	if resBytes, err = c.doRequest(ctx, fmt.Sprintf("/subjects/%s/versions/%v", subject, versionStr), fmt.Sprintf("schema subject '%v'", subject)); err != nil {
+		return
+	}
+	if err = json.Unmarshal(resBytes, &resPayload); err != nil {
+		c.mgr.Logger().Errorf("failed to parse response for schema subject '%v': %v", subject, err)
+		return
+	}
+	return
+}
+
+// WalkReferences calls a closure for each schema referenced by a list of
+// references, including the references of those schemas recursively, where
+// each referenced schema is visited once only.
+func (c *schemaRegistryClient) WalkReferences(ctx context.Context, refs []SchemaReference, fn func(ctx context.Context, name string, info SchemaInfo) error) error {
+	seen := map[string]struct{}{}
+
+	var walk func(refs []SchemaReference) error
+	walk = func(refs []SchemaReference) error {
+		for _, ref := range refs {
+			if _, exists := seen[ref.Name]; exists {
+				continue
+			}
+			seen[ref.Name] = struct{}{}
+
+			version := ref.Version
+			info, err := c.GetSchemaBySubjectAndVersion(ctx, ref.Subject, &version)
+			if err != nil {
+				return fmt.Errorf("failed to obtain reference '%v': %w", ref.Name, err)
+			}
+			if err := walk(info.References); err != nil {
+				return err
+			}
+			if err := fn(ctx, ref.Name, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(refs)
+}
+
+func (c *schemaRegistryClient) doRequest(ctx context.Context, reqPath, target string) (resBytes []byte, err error) {
+	if c.localDir != "" {
+		return c.doLocalRequest(reqPath, target)
+	}
+
+	reqURL := *c.schemaRegistryBaseURL
+	reqURL.Path = path.Join(reqURL.Path, reqPath)
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, "GET", reqURL.String(), http.NoBody); err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/vnd.schemaregistry.v1+json")
+	if err = c.requestSigner(c.mgr.FS(), req); err != nil {
+		return nil, err
+	}
+
+	logger := c.mgr.Logger()
+	for i := 0; i < 3; i++ {
+		var res *http.Response
+		if res, err = c.client.Do(req); err != nil {
+			logger.Errorf("request failed for %v: %v", target, err)
+			continue
+		}
+
+		if res.StatusCode == http.StatusNotFound {
+			res.Body.Close()
+			err = fmt.Errorf("%v not found by registry", target)
+			logger.Errorf(err.Error())
+			break
+		}
+
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			err = fmt.Errorf("request failed for %v", target)
+			logger.Errorf(err.Error())
+			// TODO: Best attempt at parsing out the body
+			continue
+		}
+
+		if res.Body == nil {
+			logger.Errorf("request for %v returned an empty body", target)
+			err = errors.New("schema request returned an empty body")
+			continue
+		}
+
+		resBytes, err = io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			logger.Errorf("failed to read response for %v: %v", target, err)
+			continue
+		}
+
+		break
+	}
+	return
+}
@@ -0,0 +1,127 @@
+package confluent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const localRegistryDescription = `
+### Local Registry
+
+When the ` + "`url`" + ` has the scheme ` + "`file`" + `, for example ` + "`file:///var/schemas`" + `, schemas are read from a local directory instead of a registry service, which allows pipelines to run in environments without access to a registry. The directory must contain a file for each version of each subject with the path ` + "`subjects/<subject>/versions/<version>.json`" + `, where each file contains the response of the registry for that version:
+
+` + "```json" + `
+{
+  "subject": "foo",
+  "version": 1,
+  "id": 3,
+  "schemaType": "PROTOBUF",
+  "schema": "syntax = \"proto3\"; ...",
+  "references": []
+}
+` + "```" + `
+
+The latest version of a subject is the version with the highest number, and schemas are found by ID by searching all version files of all subjects.`
+
+// doLocalRequest serves a request path of the registry API from the files of
+// a local directory.
+func (c *schemaRegistryClient) doLocalRequest(reqPath, target string) ([]byte, error) {
+	segments := strings.Split(strings.Trim(reqPath, "/"), "/")
+
+	var (
+		resBytes []byte
+		err      error
+	)
+	switch {
+	case len(segments) == 4 && segments[0] == "subjects" && segments[2] == "versions":
+		resBytes, err = c.readLocalSubjectVersion(segments[1], segments[3])
+	case len(segments) == 3 && segments[0] == "schemas" && segments[1] == "ids":
+		resBytes, err = c.readLocalSchemaID(segments[2])
+	default:
+		err = fmt.Errorf("request path '%v' is not supported by a local registry", reqPath)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		err = fmt.Errorf("%v not found by registry", target)
+	}
+	if err != nil {
+		c.mgr.Logger().Errorf("request failed for %v: %v", target, err)
+		return nil, err
+	}
+	return resBytes, nil
+}
+
+func (c *schemaRegistryClient) localVersions(subject string) ([]int, error) {
+	entries, err := fs.ReadDir(c.mgr.FS(), path.Join(c.localDir, "subjects", subject, "versions"))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".json" {
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+func (c *schemaRegistryClient) readLocalSubjectVersion(subject, version string) ([]byte, error) {
+	if version == "latest" {
+		versions, err := c.localVersions(subject)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, fs.ErrNotExist
+		}
+		version = strconv.Itoa(versions[len(versions)-1])
+	}
+	return fs.ReadFile(c.mgr.FS(), path.Join(c.localDir, "subjects", subject, "versions", version+".json"))
+}
+
+func (c *schemaRegistryClient) readLocalSchemaID(idStr string) ([]byte, error) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema id '%v': %w", idStr, err)
+	}
+
+	subjects, err := fs.ReadDir(c.mgr.FS(), path.Join(c.localDir, "subjects"))
+	if err != nil {
+		return nil, err
+	}
+	for _, subject := range subjects {
+		if !subject.IsDir() {
+			continue
+		}
+		versions, err := c.localVersions(subject.Name())
+		if err != nil {
+			continue
+		}
+		for _, v := range versions {
+			resBytes, err := c.readLocalSubjectVersion(subject.Name(), strconv.Itoa(v))
+			if err != nil {
+				return nil, err
+			}
+			var info SchemaInfo
+			if err := json.Unmarshal(resBytes, &info); err != nil {
+				return nil, fmt.Errorf("failed to parse subject '%v' version %v: %w", subject.Name(), v, err)
+			}
+			if info.ID == id {
+				return resBytes, nil
+			}
+		}
+	}
+	return nil, fs.ErrNotExist
+}
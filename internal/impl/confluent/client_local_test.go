@@ -0,0 +1,86 @@
+package confluent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func writeLocalSchema(t *testing.T, dir, subject string, version int, info SchemaInfo) {
+	t.Helper()
+
+	versionsDir := filepath.Join(dir, "subjects", subject, "versions")
+	require.NoError(t, os.MkdirAll(versionsDir, 0o755))
+
+	b, err := json.Marshal(info)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(versionsDir, strconv.Itoa(version)+".json"), b, 0o644))
+}
+
+func TestSchemaRegistryLocal(t *testing.T) {
+	dir := t.TempDir()
+
+	writeLocalSchema(t, dir, "foo", 1, SchemaInfo{ID: 1, Schema: testSchema})
+	writeLocalSchema(t, dir, "foo", 2, SchemaInfo{
+		ID:     3,
+		Type:   schemaTypeProtobuf,
+		Schema: testProtoSchema,
+		References: []SchemaReference{
+			{Name: "thing.proto", Subject: "thing", Version: 2},
+		},
+	})
+	writeLocalSchema(t, dir, "thing", 2, SchemaInfo{ID: 4, Type: schemaTypeProtobuf, Schema: testProtoThingSchema})
+
+	subj, err := service.NewInterpolatedString("foo")
+	require.NoError(t, err)
+
+	encoder, err := newSchemaRegistryEncoder("file://"+dir, noopReqSign, nil, subj, false, time.Minute*10, time.Minute, service.MockResources())
+	require.NoError(t, err)
+
+	decoder, err := newSchemaRegistryDecoder("file://"+dir, noopReqSign, nil, false, service.MockResources())
+	require.NoError(t, err)
+
+	batch, err := encoder.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"foo","thing":{"id":5}}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	require.Len(t, batch[0], 1)
+	require.NoError(t, batch[0][0].GetError())
+
+	b, err := batch[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "\x00\x00\x00\x00\x03\x00\x0a\x03foo\x12\x02\x08\x05", string(b))
+
+	outMsgs, err := decoder.Process(context.Background(), batch[0][0])
+	require.NoError(t, err)
+	require.Len(t, outMsgs, 1)
+
+	b, err = outMsgs[0].AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"foo","thing":{"id":5}}`, string(b))
+
+	outMsgs, err = decoder.Process(context.Background(), service.NewMessage([]byte("\x00\x00\x00\x00\x01\x06foo\x00\x00")))
+	require.NoError(t, err)
+	require.Len(t, outMsgs, 1)
+
+	b, err = outMsgs[0].AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Name":"foo","MaybeHobby":null,"Address": null}`, string(b))
+
+	_, err = decoder.Process(context.Background(), service.NewMessage([]byte("\x00\x00\x00\x00\x06\x06foo")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema '6' not found by registry")
+
+	require.NoError(t, encoder.Close(context.Background()))
+	require.NoError(t, decoder.Close(context.Background()))
+}
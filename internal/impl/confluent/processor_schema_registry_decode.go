@@ -4,13 +4,8 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/binary"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"path"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -30,7 +25,15 @@ func schemaRegistryDecoderConfig() *service.ConfigSpec {
 		Description(`
 Decodes messages automatically from a schema stored within a [Confluent Schema Registry service](https://docs.confluent.io/platform/current/schema-registry/index.html) by extracting a schema ID from the message and obtaining the associated schema from the registry. If a message fails to match against the schema then it will remain unchanged and the error can be caught using error handling methods outlined [here](/docs/configuration/error_handling).
 
-Currently only Avro schemas are supported.
+Avro, Protobuf and JSON Schema schemas are supported, where schemas of other types result in an error.
+
+### Protobuf Format
+
+Protobuf messages are decoded into JSON documents as per the [JSON mapping of protobuf messages](https://developers.google.com/protocol-buffers/docs/proto3#json). The message type of each message is identified by the message indexes that follow the schema ID, and therefore schemas containing multiple (or nested) message types are supported. Schemas that import other schemas are resolved from the references of the schema, whereas imports of well-known types (e.g. ` + "`google/protobuf/timestamp.proto`" + `) are resolved automatically.
+
+### JSON Schema Format
+
+Messages encoded with JSON Schema schemas are plain JSON documents, and are therefore left unchanged other than the removal of the schema ID, but are validated against the schema. Schemas that reference other schemas with ` + "`$ref`" + ` are resolved from the references of the schema.
 
 ### Avro JSON Format
 
@@ -45,11 +48,12 @@ For example, the union schema ` + "`[\"null\",\"string\",\"Foo\"]`, where `Foo`"
 - the string ` + "`\"a\"` as `{\"string\": \"a\"}`" + `; and
 - a ` + "`Foo` instance as `{\"Foo\": {...}}`, where `{...}` indicates the JSON encoding of a `Foo`" + ` instance.
 
-However, it is possible to instead create documents in [standard/raw JSON format](https://pkg.go.dev/github.com/linkedin/goavro/v2#NewCodecForStandardJSONFull) by setting the field ` + "[`avro_raw_json`](#avro_raw_json) to `true`" + `.`).
+However, it is possible to instead create documents in [standard/raw JSON format](https://pkg.go.dev/github.com/linkedin/goavro/v2#NewCodecForStandardJSONFull) by setting the field ` + "[`avro_raw_json`](#avro_raw_json) to `true`" + `.
+` + localRegistryDescription).
 		Field(service.NewBoolField("avro_raw_json").
 			Description("Whether Avro messages should be decoded into normal JSON (\"json that meets the expectations of regular internet json\") rather than [Avro JSON](https://avro.apache.org/docs/current/specification/_print/#json-encoding). If `true` the schema returned from the subject should be decoded as [standard json](https://pkg.go.dev/github.com/linkedin/goavro/v2#NewCodecForStandardJSONFull) instead of as [avro json](https://pkg.go.dev/github.com/linkedin/goavro/v2#NewCodec). There is a [comment in goavro](https://github.com/linkedin/goavro/blob/5ec5a5ee7ec82e16e6e2b438d610e1cab2588393/union.go#L224-L249), the [underlining library used for avro serialization](https://github.com/linkedin/goavro), that explains in more detail the difference between the standard json and avro json.").
 			Advanced().Default(false)).
-		Field(service.NewURLField("url").Description("The base URL of the schema registry service."))
+		Field(service.NewURLField("url").Description("The base URL of the schema registry service, or a `file` URL of a directory to read schemas from as described in [Local Registry](#local-registry)."))
 
 	for _, f := range httpclient.AuthFieldSpecs() {
 		spec = spec.Field(f.Version("4.7.0"))
@@ -72,12 +76,9 @@ func init() {
 //------------------------------------------------------------------------------
 
 type schemaRegistryDecoder struct {
-	client      *http.Client
+	client      *schemaRegistryClient
 	avroRawJSON bool
 
-	schemaRegistryBaseURL *url.URL
-	requestSigner         httpclient.RequestSigner
-
 	schemas    map[int]*cachedSchemaDecoder
 	cacheMut   sync.RWMutex
 	requestMut sync.Mutex
@@ -114,33 +115,18 @@ func newSchemaRegistryDecoder(
 	avroRawJSON bool,
 	mgr *service.Resources,
 ) (*schemaRegistryDecoder, error) {
-	u, err := url.Parse(urlStr)
+	client, err := newSchemaRegistryClient(urlStr, reqSigner, tlsConf, mgr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse url: %w", err)
+		return nil, err
 	}
 
 	s := &schemaRegistryDecoder{
-		avroRawJSON:           avroRawJSON,
-		schemaRegistryBaseURL: u,
-		requestSigner:         reqSigner,
-		schemas:               map[int]*cachedSchemaDecoder{},
-		shutSig:               shutdown.NewSignaller(),
-		logger:                mgr.Logger(),
-		mgr:                   mgr,
-	}
-
-	s.client = http.DefaultClient
-	if tlsConf != nil {
-		s.client = &http.Client{}
-		if c, ok := http.DefaultTransport.(*http.Transport); ok {
-			cloned := c.Clone()
-			cloned.TLSClientConfig = tlsConf
-			s.client.Transport = cloned
-		} else {
-			s.client.Transport = &http.Transport{
-				TLSClientConfig: tlsConf,
-			}
-		}
+		client:      client,
+		avroRawJSON: avroRawJSON,
+		schemas:     map[int]*cachedSchemaDecoder{},
+		shutSig:     shutdown.NewSignaller(),
+		logger:      mgr.Logger(),
+		mgr:         mgr,
 	}
 
 	go func() {
@@ -270,80 +256,50 @@ func (s *schemaRegistryDecoder) getDecoder(id int) (schemaDecoder, error) {
 	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
 	defer done()
 
-	reqURL := *s.schemaRegistryBaseURL
-	reqURL.Path = path.Join(reqURL.Path, fmt.Sprintf("/schemas/ids/%v", id))
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL.String(), http.NoBody)
+	resPayload, err := s.client.GetSchemaByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Accept", "application/vnd.schemaregistry.v1+json")
-	if err := s.requestSigner(s.mgr.FS(), req); err != nil {
-		return nil, err
-	}
 
-	var resBytes []byte
-	for i := 0; i < 3; i++ {
-		var res *http.Response
-		if res, err = s.client.Do(req); err != nil {
-			s.logger.Errorf("request failed for schema '%v': %v", id, err)
-			continue
-		}
-
-		if res.StatusCode == http.StatusNotFound {
-			err = fmt.Errorf("schema '%v' not found by registry", id)
-			s.logger.Errorf(err.Error())
-			break
-		}
-
-		if res.StatusCode != http.StatusOK {
-			err = fmt.Errorf("request failed for schema '%v'", id)
-			s.logger.Errorf(err.Error())
-			// TODO: Best attempt at parsing out the body
-			continue
-		}
-
-		if res.Body == nil {
-			s.logger.Errorf("request for schema '%v' returned an empty body", id)
-			err = errors.New("schema request returned an empty body")
-			continue
-		}
-
-		resBytes, err = io.ReadAll(res.Body)
-		res.Body.Close()
-		if err != nil {
-			s.logger.Errorf("failed to read response for schema '%v': %v", id, err)
-			continue
-		}
-
-		break
+	var decoder schemaDecoder
+	switch resPayload.Type {
+	case "", schemaTypeAvro:
+		decoder, err = s.getAvroDecoder(resPayload)
+	case schemaTypeProtobuf:
+		decoder, err = s.getProtobufDecoder(ctx, resPayload)
+	case schemaTypeJSON:
+		decoder, err = s.getJSONDecoder(ctx, resPayload)
+	default:
+		err = fmt.Errorf("schema type '%v' is not supported", resPayload.Type)
 	}
 	if err != nil {
+		s.logger.Errorf("failed to parse response for schema '%v': %v", id, err)
 		return nil, err
 	}
 
-	resPayload := struct {
-		Schema string `json:"schema"`
-	}{}
-	if err = json.Unmarshal(resBytes, &resPayload); err != nil {
-		s.logger.Errorf("failed to parse response for schema '%v': %v", id, err)
-		return nil, err
+	s.cacheMut.Lock()
+	s.schemas[id] = &cachedSchemaDecoder{
+		lastUsedUnixSeconds: time.Now().Unix(),
+		decoder:             decoder,
 	}
+	s.cacheMut.Unlock()
 
+	return decoder, nil
+}
+
+func (s *schemaRegistryDecoder) getAvroDecoder(info SchemaInfo) (schemaDecoder, error) {
 	var codec *goavro.Codec
+	var err error
 	if s.avroRawJSON {
-		if codec, err = goavro.NewCodecForStandardJSONFull(resPayload.Schema); err != nil {
-			s.logger.Errorf("failed to parse response for schema subject '%v': %v", id, err)
-			return nil, err
-		}
+		codec, err = goavro.NewCodecForStandardJSONFull(info.Schema)
 	} else {
-		if codec, err = goavro.NewCodec(resPayload.Schema); err != nil {
-			s.logger.Errorf("failed to parse response for schema subject '%v': %v", id, err)
-			return nil, err
-		}
+		codec, err = goavro.NewCodec(info.Schema)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	decoder := func(m *service.Message) error {
+	return func(m *service.Message) error {
 		b, err := m.AsBytes()
 		if err != nil {
 			return err
@@ -361,14 +317,5 @@ func (s *schemaRegistryDecoder) getDecoder(id int) (schemaDecoder, error) {
 		m.SetBytes(jb)
 
 		return nil
-	}
-
-	s.cacheMut.Lock()
-	s.schemas[id] = &cachedSchemaDecoder{
-		lastUsedUnixSeconds: time.Now().Unix(),
-		decoder:             decoder,
-	}
-	s.cacheMut.Unlock()
-
-	return decoder, nil
+	}, nil
 }
@@ -4,13 +4,8 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/binary"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"path"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -33,7 +28,15 @@ Encodes messages automatically from schemas obtains from a [Confluent Schema Reg
 
 If a message fails to encode under the schema then it will remain unchanged and the error can be caught using error handling methods outlined [here](/docs/configuration/error_handling).
 
-Currently only Avro schemas are supported.
+Avro, Protobuf and JSON Schema schemas are supported, where schemas of other types result in an error.
+
+### Protobuf Format
+
+Documents are expected to follow the [JSON mapping of protobuf messages](https://developers.google.com/protocol-buffers/docs/proto3#json), and are encoded as the first message type defined within the schema. Schemas that import other schemas are resolved from the references of the schema, whereas imports of well-known types (e.g. ` + "`google/protobuf/timestamp.proto`" + `) are resolved automatically.
+
+### JSON Schema Format
+
+Documents are validated against JSON Schema schemas before being prefixed with the schema ID, and documents that fail validation remain unchanged and are flagged with an error describing each violation. Schemas that reference other schemas with ` + "`$ref`" + ` are resolved from the references of the schema.
 
 ### Avro JSON Format
 
@@ -53,8 +56,8 @@ However, it is possible to instead consume documents in [standard/raw JSON forma
 ### Known Issues
 
 Important! There is an outstanding issue in the [avro serializing library](https://github.com/linkedin/goavro) that benthos uses which means it [doesn't encode logical types correctly](https://github.com/linkedin/goavro/issues/252). It's still possible to encode logical types that are in-line with the spec if ` + "`avro_raw_json` is set to true" + `, though now of course non-logical types will not be in-line with the spec.
-`).
-		Field(service.NewURLField("url").Description("The base URL of the schema registry service.")).
+` + localRegistryDescription).
+		Field(service.NewURLField("url").Description("The base URL of the schema registry service, or a `file` URL of a directory to read schemas from as described in [Local Registry](#local-registry).")).
 		Field(service.NewInterpolatedStringField("subject").Description("The schema subject to derive schemas from.").
 			Example("foo").
 			Example(`${! meta("kafka_topic") }`)).
@@ -88,14 +91,11 @@ func init() {
 //------------------------------------------------------------------------------
 
 type schemaRegistryEncoder struct {
-	client             *http.Client
+	client             *schemaRegistryClient
 	subject            *service.InterpolatedString
 	avroRawJSON        bool
 	schemaRefreshAfter time.Duration
 
-	schemaRegistryBaseURL *url.URL
-	requestSigner         httpclient.RequestSigner
-
 	schemas    map[string]*cachedSchemaEncoder
 	cacheMut   sync.RWMutex
 	requestMut sync.Mutex
@@ -151,36 +151,21 @@ func newSchemaRegistryEncoder(
 	schemaRefreshAfter, schemaRefreshTicker time.Duration,
 	mgr *service.Resources,
 ) (*schemaRegistryEncoder, error) {
-	u, err := url.Parse(urlStr)
+	client, err := newSchemaRegistryClient(urlStr, reqSigner, tlsConf, mgr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse url: %w", err)
+		return nil, err
 	}
 
 	s := &schemaRegistryEncoder{
-		schemaRegistryBaseURL: u,
-		requestSigner:         reqSigner,
-		subject:               subject,
-		avroRawJSON:           avroRawJSON,
-		schemaRefreshAfter:    schemaRefreshAfter,
-		schemas:               map[string]*cachedSchemaEncoder{},
-		shutSig:               shutdown.NewSignaller(),
-		logger:                mgr.Logger(),
-		mgr:                   mgr,
-		nowFn:                 time.Now,
-	}
-
-	s.client = http.DefaultClient
-	if tlsConf != nil {
-		s.client = &http.Client{}
-		if c, ok := http.DefaultTransport.(*http.Transport); ok {
-			cloned := c.Clone()
-			cloned.TLSClientConfig = tlsConf
-			s.client.Transport = cloned
-		} else {
-			s.client.Transport = &http.Transport{
-				TLSClientConfig: tlsConf,
-			}
-		}
+		client:             client,
+		subject:            subject,
+		avroRawJSON:        avroRawJSON,
+		schemaRefreshAfter: schemaRefreshAfter,
+		schemas:            map[string]*cachedSchemaEncoder{},
+		shutSig:            shutdown.NewSignaller(),
+		logger:             mgr.Logger(),
+		mgr:                mgr,
+		nowFn:              time.Now,
 	}
 
 	go func() {
@@ -315,80 +300,41 @@ func (s *schemaRegistryEncoder) getLatestEncoder(subject string) (schemaEncoder,
 	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
 	defer done()
 
-	reqURL := *s.schemaRegistryBaseURL
-	reqURL.Path = path.Join(reqURL.Path, fmt.Sprintf("/subjects/%s/versions/latest", subject))
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL.String(), http.NoBody)
+	resPayload, err := s.client.GetSchemaBySubjectAndVersion(ctx, subject, nil)
 	if err != nil {
 		return nil, 0, err
 	}
-	req.Header.Add("Accept", "application/vnd.schemaregistry.v1+json")
-	if err := s.requestSigner(s.mgr.FS(), req); err != nil {
-		return nil, 0, err
-	}
-
-	var resBytes []byte
-	for i := 0; i < 3; i++ {
-		var res *http.Response
-		if res, err = s.client.Do(req); err != nil {
-			s.logger.Errorf("request failed for schema subject '%v': %v", subject, err)
-			continue
-		}
-
-		if res.StatusCode == http.StatusNotFound {
-			err = fmt.Errorf("schema subject '%v' not found by registry", subject)
-			s.logger.Errorf(err.Error())
-			break
-		}
-
-		if res.StatusCode != http.StatusOK {
-			err = fmt.Errorf("request failed for schema subject '%v'", subject)
-			s.logger.Errorf(err.Error())
-			// TODO: Best attempt at parsing out the body
-			continue
-		}
-
-		if res.Body == nil {
-			s.logger.Errorf("request for schema subject latest '%v' returned an empty body", subject)
-			err = errors.New("schema request returned an empty body")
-			continue
-		}
 
-		resBytes, err = io.ReadAll(res.Body)
-		res.Body.Close()
-		if err != nil {
-			s.logger.Errorf("failed to read response for schema subject '%v': %v", subject, err)
-			continue
-		}
-
-		break
+	s.logger.Tracef("Loaded new codec for subject %v: %s", subject, resPayload.Schema)
+
+	var encoder schemaEncoder
+	switch resPayload.Type {
+	case "", schemaTypeAvro:
+		encoder, err = s.getAvroEncoder(resPayload)
+	case schemaTypeProtobuf:
+		encoder, err = s.getProtobufEncoder(ctx, resPayload)
+	case schemaTypeJSON:
+		encoder, err = s.getJSONEncoder(ctx, resPayload)
+	default:
+		err = fmt.Errorf("schema type '%v' is not supported", resPayload.Type)
 	}
 	if err != nil {
-		return nil, 0, err
-	}
-
-	resPayload := struct {
-		Schema string `json:"schema"`
-		ID     int    `json:"id"`
-	}{}
-	if err = json.Unmarshal(resBytes, &resPayload); err != nil {
 		s.logger.Errorf("failed to parse response for schema subject '%v': %v", subject, err)
 		return nil, 0, err
 	}
+	return encoder, resPayload.ID, nil
+}
 
-	s.logger.Tracef("Loaded new codec for subject %v: %s", subject, resBytes)
-
+func (s *schemaRegistryEncoder) getAvroEncoder(info SchemaInfo) (schemaEncoder, error) {
 	var codec *goavro.Codec
+	var err error
 	if s.avroRawJSON {
-		if codec, err = goavro.NewCodecForStandardJSONFull(resPayload.Schema); err != nil {
-			s.logger.Errorf("failed to parse response for schema subject '%v': %v", subject, err)
-			return nil, 0, err
-		}
+		codec, err = goavro.NewCodecForStandardJSONFull(info.Schema)
 	} else {
-		if codec, err = goavro.NewCodec(resPayload.Schema); err != nil {
-			s.logger.Errorf("failed to parse response for schema subject '%v': %v", subject, err)
-			return nil, 0, err
-		}
+		codec, err = goavro.NewCodec(info.Schema)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	return func(m *service.Message) error {
@@ -409,7 +355,7 @@ func (s *schemaRegistryEncoder) getLatestEncoder(subject string) (schemaEncoder,
 
 		m.SetBytes(binary)
 		return nil
-	}, resPayload.ID, nil
+	}, nil
 }
 
 func (s *schemaRegistryEncoder) getEncoder(subject string) (schemaEncoder, int, error) {
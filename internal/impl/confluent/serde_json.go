@@ -0,0 +1,100 @@
+package confluent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	jsonschema "github.com/xeipuuv/gojsonschema"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// jsonSchemaBaseURL is the base that relative reference names are resolved
+// against, as schemas must be registered under absolute URLs.
+const jsonSchemaBaseURL = "https://schema-registry.benthos.local/"
+
+func jsonSchemaURL(name string) string {
+	if u, err := url.Parse(name); err == nil && u.IsAbs() {
+		return name
+	}
+	return jsonSchemaBaseURL + strings.TrimPrefix(name, "/")
+}
+
+// compileJSONSchema compiles the JSON Schema document of a schema, where the
+// documents of all schemas it references are registered under the names of the
+// references so that they can be targeted with `$ref`.
+func (c *schemaRegistryClient) compileJSONSchema(ctx context.Context, info SchemaInfo) (*jsonschema.Schema, error) {
+	loader := jsonschema.NewSchemaLoader()
+	if err := c.WalkReferences(ctx, info.References, func(ctx context.Context, name string, ref SchemaInfo) error {
+		if err := loader.AddSchema(jsonSchemaURL(name), jsonschema.NewStringLoader(ref.Schema)); err != nil {
+			return fmt.Errorf("failed to add reference '%v': %w", name, err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	rootURL := jsonSchemaURL(fmt.Sprintf("schema_registry_%v.json", info.ID))
+	if err := loader.AddSchema(rootURL, jsonschema.NewStringLoader(info.Schema)); err != nil {
+		return nil, fmt.Errorf("failed to compile JSON schema: %w", err)
+	}
+
+	schema, err := loader.Compile(jsonschema.NewReferenceLoader(rootURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile JSON schema: %w", err)
+	}
+	return schema, nil
+}
+
+// validateJSONSchema validates the raw contents of a message against a JSON
+// Schema, returning an error describing each violation.
+func validateJSONSchema(schema *jsonschema.Schema, m *service.Message) error {
+	b, err := m.AsBytes()
+	if err != nil {
+		return err
+	}
+
+	result, err := schema.Validate(jsonschema.NewBytesLoader(b))
+	if err != nil {
+		return fmt.Errorf("failed to validate JSON document: %w", err)
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	var errStr strings.Builder
+	for i, desc := range result.Errors() {
+		if i > 0 {
+			errStr.WriteString("\n")
+		}
+		errStr.WriteString(desc.String())
+	}
+	return errors.New(errStr.String())
+}
+
+func (s *schemaRegistryDecoder) getJSONDecoder(ctx context.Context, info SchemaInfo) (schemaDecoder, error) {
+	schema, err := s.client.compileJSONSchema(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON Schema payloads are plain JSON documents, and therefore decoding
+	// consists only of validation.
+	return func(m *service.Message) error {
+		return validateJSONSchema(schema, m)
+	}, nil
+}
+
+func (s *schemaRegistryEncoder) getJSONEncoder(ctx context.Context, info SchemaInfo) (schemaEncoder, error) {
+	schema, err := s.client.compileJSONSchema(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(m *service.Message) error {
+		return validateJSONSchema(schema, m)
+	}, nil
+}
@@ -0,0 +1,115 @@
+package confluent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const testJSONSchema = `{
+	"type": "object",
+	"properties": {
+		"name": { "type": "string" },
+		"address": { "$ref": "address.json" }
+	},
+	"required": [ "name" ]
+}`
+
+const testJSONAddressSchema = `{
+	"type": "object",
+	"properties": {
+		"city": { "type": "string" }
+	},
+	"required": [ "city" ]
+}`
+
+func runJSONSchemaRegistryServer(t *testing.T) string {
+	t.Helper()
+
+	rootPayload, err := json.Marshal(SchemaInfo{
+		ID:     3,
+		Type:   schemaTypeJSON,
+		Schema: testJSONSchema,
+		References: []SchemaReference{
+			{Name: "address.json", Subject: "address", Version: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	addressPayload, err := json.Marshal(SchemaInfo{
+		ID:     4,
+		Type:   schemaTypeJSON,
+		Schema: testJSONAddressSchema,
+	})
+	require.NoError(t, err)
+
+	return runSchemaRegistryServer(t, func(path string) ([]byte, error) {
+		switch path {
+		case "/schemas/ids/3", "/subjects/foo/versions/latest":
+			return rootPayload, nil
+		case "/subjects/address/versions/1":
+			return addressPayload, nil
+		}
+		return nil, nil
+	})
+}
+
+func TestSchemaRegistryDecodeJSON(t *testing.T) {
+	urlStr := runJSONSchemaRegistryServer(t)
+
+	decoder, err := newSchemaRegistryDecoder(urlStr, noopReqSign, nil, false, service.MockResources())
+	require.NoError(t, err)
+
+	outMsgs, err := decoder.Process(context.Background(), service.NewMessage([]byte("\x00\x00\x00\x00\x03"+`{"name":"foo","address":{"city":"bar"}}`)))
+	require.NoError(t, err)
+	require.Len(t, outMsgs, 1)
+
+	b, err := outMsgs[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"foo","address":{"city":"bar"}}`, string(b))
+
+	_, err = decoder.Process(context.Background(), service.NewMessage([]byte("\x00\x00\x00\x00\x03"+`{"name":"foo","address":{}}`)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "city is required")
+
+	require.NoError(t, decoder.Close(context.Background()))
+}
+
+func TestSchemaRegistryEncodeJSON(t *testing.T) {
+	urlStr := runJSONSchemaRegistryServer(t)
+
+	subj, err := service.NewInterpolatedString("foo")
+	require.NoError(t, err)
+
+	encoder, err := newSchemaRegistryEncoder(urlStr, noopReqSign, nil, subj, false, time.Minute*10, time.Minute, service.MockResources())
+	require.NoError(t, err)
+
+	batch, err := encoder.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"foo","address":{"city":"bar"}}`)),
+		service.NewMessage([]byte(`{"address":{"city":5}}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	require.Len(t, batch[0], 2)
+
+	b, err := batch[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "\x00\x00\x00\x00\x03"+`{"name":"foo","address":{"city":"bar"}}`, string(b))
+	require.NoError(t, batch[0][0].GetError())
+
+	b, err = batch[0][1].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{"address":{"city":5}}`, string(b))
+
+	require.Error(t, batch[0][1].GetError())
+	assert.Contains(t, batch[0][1].GetError().Error(), "name is required")
+	assert.Contains(t, batch[0][1].GetError().Error(), "Invalid type. Expected: string, given: integer")
+
+	require.NoError(t, encoder.Close(context.Background()))
+}
@@ -0,0 +1,184 @@
+package confluent
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	// nolint:staticcheck // Ignore SA1019 deprecation warning until we can switch to "google.golang.org/protobuf/types/dynamicpb"
+	"github.com/golang/protobuf/jsonpb"
+	// nolint:staticcheck // Ignore SA1019 deprecation warning until we can switch to "google.golang.org/protobuf/types/dynamicpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/dynamic"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// parseProtobufSchema parses the .proto document of a schema along with the
+// documents of all schemas it references, which are imported by the names of
+// the references. Imports of well-known types are resolved without requiring
+// references.
+func (c *schemaRegistryClient) parseProtobufSchema(ctx context.Context, info SchemaInfo) (*desc.FileDescriptor, error) {
+	rootName := fmt.Sprintf("schema_registry_%v.proto", info.ID)
+	files := map[string]string{
+		rootName: info.Schema,
+	}
+	if err := c.WalkReferences(ctx, info.References, func(ctx context.Context, name string, ref SchemaInfo) error {
+		files[name] = ref.Schema
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(files),
+	}
+	fds, err := parser.ParseFiles(rootName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse protobuf schema: %w", err)
+	}
+	return fds[0], nil
+}
+
+// readMessageIndexes consumes the message indexes that prefix a protobuf
+// payload, which identify the message type within the schema as a path of
+// indexes through its (nested) message definitions. A single zero is
+// shorthand for the first message of the schema.
+func readMessageIndexes(b []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(b)
+	if n <= 0 {
+		return nil, nil, errors.New("failed to read message indexes length")
+	}
+	b = b[n:]
+	if count == 0 {
+		return []int{0}, b, nil
+	}
+	if count < 0 || count > int64(len(b)) {
+		return nil, nil, fmt.Errorf("invalid message indexes length: %v", count)
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		index, n := binary.Varint(b)
+		if n <= 0 {
+			return nil, nil, errors.New("failed to read message index")
+		}
+		indexes[i] = int(index)
+		b = b[n:]
+	}
+	return indexes, b, nil
+}
+
+// appendMessageIndexes writes the message indexes that identify a message type
+// as a prefix to a protobuf payload.
+func appendMessageIndexes(b []byte, indexes []int) []byte {
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(b, 0)
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	b = append(b, buf[:binary.PutVarint(buf, int64(len(indexes)))]...)
+	for _, index := range indexes {
+		b = append(b, buf[:binary.PutVarint(buf, int64(index))]...)
+	}
+	return b
+}
+
+func messageFromIndexes(fd *desc.FileDescriptor, indexes []int) (*desc.MessageDescriptor, error) {
+	msgTypes := fd.GetMessageTypes()
+	var md *desc.MessageDescriptor
+	for _, index := range indexes {
+		if index < 0 || index >= len(msgTypes) {
+			return nil, fmt.Errorf("message index %v is out of bounds for schema", indexes)
+		}
+		md = msgTypes[index]
+		msgTypes = md.GetNestedMessageTypes()
+	}
+	if md == nil {
+		return nil, errors.New("message indexes are empty")
+	}
+	return md, nil
+}
+
+func (s *schemaRegistryDecoder) getProtobufDecoder(ctx context.Context, info SchemaInfo) (schemaDecoder, error) {
+	fd, err := s.client.parseProtobufSchema(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	marshaller := &jsonpb.Marshaler{
+		AnyResolver: dynamic.AnyResolver(dynamic.NewMessageFactoryWithDefaults(), fd),
+	}
+
+	return func(m *service.Message) error {
+		b, err := m.AsBytes()
+		if err != nil {
+			return err
+		}
+
+		indexes, remaining, err := readMessageIndexes(b)
+		if err != nil {
+			return err
+		}
+
+		md, err := messageFromIndexes(fd, indexes)
+		if err != nil {
+			return err
+		}
+
+		msg := dynamic.NewMessage(md)
+		if err := proto.Unmarshal(remaining, msg); err != nil {
+			return fmt.Errorf("failed to unmarshal protobuf message: %w", err)
+		}
+
+		data, err := msg.MarshalJSONPB(marshaller)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON message: %w", err)
+		}
+
+		m.SetBytes(data)
+		return nil
+	}, nil
+}
+
+func (s *schemaRegistryEncoder) getProtobufEncoder(ctx context.Context, info SchemaInfo) (schemaEncoder, error) {
+	fd, err := s.client.parseProtobufSchema(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents are encoded as the first message type of the schema, which is
+	// the convention followed by Confluent serializers.
+	indexes := []int{0}
+	md, err := messageFromIndexes(fd, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	unmarshaler := &jsonpb.Unmarshaler{
+		AnyResolver: dynamic.AnyResolver(dynamic.NewMessageFactoryWithDefaults(), fd),
+	}
+
+	return func(m *service.Message) error {
+		b, err := m.AsBytes()
+		if err != nil {
+			return err
+		}
+
+		msg := dynamic.NewMessage(md)
+		if err := msg.UnmarshalJSONPB(unmarshaler, b); err != nil {
+			return fmt.Errorf("failed to unmarshal JSON message: %w", err)
+		}
+
+		data, err := msg.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to marshal protobuf message: %w", err)
+		}
+
+		m.SetBytes(append(appendMessageIndexes(nil, indexes), data...))
+		return nil
+	}, nil
+}
@@ -0,0 +1,185 @@
+package confluent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const testProtoSchema = `
+syntax = "proto3";
+package testing;
+
+import "thing.proto";
+import "google/protobuf/timestamp.proto";
+
+message Person {
+  string name = 1;
+  testing.Thing thing = 2;
+  google.protobuf.Timestamp created = 3;
+}
+
+message Group {
+  message Member {
+    string name = 1;
+  }
+  repeated Member members = 1;
+}
+`
+
+const testProtoThingSchema = `
+syntax = "proto3";
+package testing;
+
+message Thing {
+  int32 id = 1;
+}
+`
+
+func runProtobufSchemaRegistryServer(t *testing.T) string {
+	t.Helper()
+
+	rootPayload, err := json.Marshal(SchemaInfo{
+		ID:     3,
+		Type:   schemaTypeProtobuf,
+		Schema: testProtoSchema,
+		References: []SchemaReference{
+			{Name: "thing.proto", Subject: "thing", Version: 2},
+		},
+	})
+	require.NoError(t, err)
+
+	thingPayload, err := json.Marshal(SchemaInfo{
+		ID:     4,
+		Type:   schemaTypeProtobuf,
+		Schema: testProtoThingSchema,
+	})
+	require.NoError(t, err)
+
+	return runSchemaRegistryServer(t, func(path string) ([]byte, error) {
+		switch path {
+		case "/schemas/ids/3", "/subjects/foo/versions/latest":
+			return rootPayload, nil
+		case "/subjects/thing/versions/2":
+			return thingPayload, nil
+		}
+		return nil, nil
+	})
+}
+
+func TestSchemaRegistryDecodeProtobuf(t *testing.T) {
+	urlStr := runProtobufSchemaRegistryServer(t)
+
+	decoder, err := newSchemaRegistryDecoder(urlStr, noopReqSign, nil, false, service.MockResources())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		input       string
+		output      string
+		errContains string
+	}{
+		{
+			name:   "first message",
+			input:  "\x00\x00\x00\x00\x03\x00\x0a\x03foo\x12\x02\x08\x05\x1a\x02\x08\x01",
+			output: `{"name":"foo","thing":{"id":5},"created":"1970-01-01T00:00:01Z"}`,
+		},
+		{
+			name:   "first message explicit index",
+			input:  "\x00\x00\x00\x00\x03\x02\x00\x0a\x03foo",
+			output: `{"name":"foo"}`,
+		},
+		{
+			name:   "second message",
+			input:  "\x00\x00\x00\x00\x03\x02\x02\x0a\x05\x0a\x03bar",
+			output: `{"members":[{"name":"bar"}]}`,
+		},
+		{
+			name:   "nested message",
+			input:  "\x00\x00\x00\x00\x03\x04\x02\x00\x0a\x03baz",
+			output: `{"name":"baz"}`,
+		},
+		{
+			name:        "message index out of bounds",
+			input:       "\x00\x00\x00\x00\x03\x02\x06\x0a\x03foo",
+			errContains: "message index [3] is out of bounds",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			outMsgs, err := decoder.Process(context.Background(), service.NewMessage([]byte(test.input)))
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, outMsgs, 1)
+
+			b, err := outMsgs[0].AsBytes()
+			require.NoError(t, err)
+			assert.JSONEq(t, test.output, string(b))
+		})
+	}
+
+	require.NoError(t, decoder.Close(context.Background()))
+}
+
+func TestSchemaRegistryEncodeProtobuf(t *testing.T) {
+	urlStr := runProtobufSchemaRegistryServer(t)
+
+	subj, err := service.NewInterpolatedString("foo")
+	require.NoError(t, err)
+
+	encoder, err := newSchemaRegistryEncoder(urlStr, noopReqSign, nil, subj, false, time.Minute*10, time.Minute, service.MockResources())
+	require.NoError(t, err)
+
+	decoder, err := newSchemaRegistryDecoder(urlStr, noopReqSign, nil, false, service.MockResources())
+	require.NoError(t, err)
+
+	batch, err := encoder.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"foo","thing":{"id":5},"created":"1970-01-01T00:00:01Z"}`)),
+		service.NewMessage([]byte(`{"nope":"foo"}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	require.Len(t, batch[0], 2)
+
+	b, err := batch[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "\x00\x00\x00\x00\x03\x00\x0a\x03foo\x12\x02\x08\x05\x1a\x02\x08\x01", string(b))
+
+	require.Error(t, batch[0][1].GetError())
+	assert.Contains(t, batch[0][1].GetError().Error(), "has no known field named nope")
+
+	outMsgs, err := decoder.Process(context.Background(), batch[0][0])
+	require.NoError(t, err)
+	require.Len(t, outMsgs, 1)
+
+	b, err = outMsgs[0].AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"foo","thing":{"id":5},"created":"1970-01-01T00:00:01Z"}`, string(b))
+
+	require.NoError(t, encoder.Close(context.Background()))
+	require.NoError(t, decoder.Close(context.Background()))
+}
+
+func TestProtobufMessageIndexes(t *testing.T) {
+	for _, indexes := range [][]int{{0}, {1}, {1, 0}, {70, 3}} {
+		b := appendMessageIndexes(nil, indexes)
+		b = append(b, "foo"...)
+
+		readIndexes, remaining, err := readMessageIndexes(b)
+		require.NoError(t, err)
+		assert.Equal(t, indexes, readIndexes)
+		assert.Equal(t, "foo", string(remaining))
+	}
+}
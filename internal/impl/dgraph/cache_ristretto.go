@@ -3,6 +3,7 @@ package dgraph
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -63,26 +64,30 @@ func newRistrettoCacheFromConfig(conf *service.ParsedConfig) (*ristrettoCache, e
 
 //------------------------------------------------------------------------------
 
+// ristrettoItem is the value stored within ristretto, which only retains hashes
+// of keys, and therefore the key is kept alongside the value so that it can be
+// removed from the tracked keys once the item leaves the cache.
+type ristrettoItem struct {
+	key   string
+	value []byte
+}
+
 type ristrettoCache struct {
 	defaultTTL time.Duration
 	cache      *ristretto.Cache
 
+	keys    map[string]*ristrettoItem
+	keysMut sync.Mutex
+	closed  bool
+
 	retriesEnabled bool
 	boffPool       sync.Pool
 }
 
 func newRistrettoCache(defaultTTL time.Duration, retriesEnabled bool, backOff *backoff.ExponentialBackOff) (*ristrettoCache, error) {
-	cache, err := ristretto.NewCache(&ristretto.Config{
-		NumCounters: 1e7,     // number of keys to track frequency of (10M).
-		MaxCost:     1 << 30, // maximum cost of cache (1GB).
-		BufferItems: 64,      // number of keys per Get buffer.
-	})
-	if err != nil {
-		return nil, err
-	}
 	r := &ristrettoCache{
 		defaultTTL:     defaultTTL,
-		cache:          cache,
+		keys:           map[string]*ristrettoItem{},
 		retriesEnabled: retriesEnabled,
 		boffPool: sync.Pool{
 			New: func() any {
@@ -93,16 +98,44 @@ func newRistrettoCache(defaultTTL time.Duration, retriesEnabled bool, backOff *b
 		},
 	}
 
+	var err error
+	if r.cache, err = ristretto.NewCache(&ristretto.Config{
+		NumCounters: 1e7,     // number of keys to track frequency of (10M).
+		MaxCost:     1 << 30, // maximum cost of cache (1GB).
+		BufferItems: 64,      // number of keys per Get buffer.
+		OnExit: func(val any) {
+			if item, ok := val.(*ristrettoItem); ok {
+				r.untrackKey(item)
+			}
+		},
+	}); err != nil {
+		return nil, err
+	}
 	return r, nil
 }
 
+// untrackKey removes an item from the tracked keys, unless the key has since
+// been updated with a new item or the key is still held by the cache, which is
+// the case when a write to an existing key is rejected.
+func (r *ristrettoCache) untrackKey(item *ristrettoItem) {
+	r.keysMut.Lock()
+	// Items are released whilst the cache store is locked during close, and
+	// therefore the cache must not be queried.
+	if !r.closed && r.keys[item.key] == item {
+		if _, exists := r.cache.GetTTL(item.key); !exists {
+			delete(r.keys, item.key)
+		}
+	}
+	r.keysMut.Unlock()
+}
+
 func (r *ristrettoCache) Get(ctx context.Context, key string) ([]byte, error) {
 	var boff backoff.BackOff
 
 	for {
 		res, ok := r.cache.Get(key)
 		if ok {
-			return res.([]byte), nil
+			return res.(*ristrettoItem).value, nil
 		}
 
 		if r.retriesEnabled {
@@ -136,7 +169,13 @@ func (r *ristrettoCache) Set(ctx context.Context, key string, value []byte, ttl
 	} else {
 		t = r.defaultTTL
 	}
-	if !r.cache.SetWithTTL(key, value, 1, t) {
+	item := &ristrettoItem{key: key, value: value}
+	r.keysMut.Lock()
+	r.keys[key] = item
+	r.keysMut.Unlock()
+
+	if !r.cache.SetWithTTL(key, item, 1, t) {
+		r.untrackKey(item)
 		return errors.New("set operation was dropped")
 	}
 	return nil
@@ -148,10 +187,38 @@ func (r *ristrettoCache) Add(ctx context.Context, key string, value []byte, ttl
 
 func (r *ristrettoCache) Delete(ctx context.Context, key string) error {
 	r.cache.Del(key)
+	r.keysMut.Lock()
+	delete(r.keys, key)
+	r.keysMut.Unlock()
 	return nil
 }
 
+// Keys returns the tracked keys beginning with a prefix that are still present
+// within the cache. Since ristretto applies writes asynchronously keys that
+// were very recently set might not yet be included.
+func (r *ristrettoCache) Keys(ctx context.Context, prefix string) ([]string, error) {
+	r.keysMut.Lock()
+	candidates := make([]string, 0, len(r.keys))
+	for k := range r.keys {
+		if strings.HasPrefix(k, prefix) {
+			candidates = append(candidates, k)
+		}
+	}
+	r.keysMut.Unlock()
+
+	keys := candidates[:0]
+	for _, k := range candidates {
+		if _, exists := r.cache.GetTTL(k); exists {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
 func (r *ristrettoCache) Close(ctx context.Context) error {
+	r.keysMut.Lock()
+	r.closed = true
+	r.keysMut.Unlock()
 	r.cache.Close()
 	return nil
 }
@@ -63,3 +63,30 @@ func TestRistrettoCacheWithTTL(t *testing.T) {
 		return err == service.ErrKeyNotFound
 	}, time.Second, time.Millisecond*5)
 }
+
+func TestRistrettoCacheKeys(t *testing.T) {
+	c, err := newRistrettoCache(0, false, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = c.Close(context.Background())
+	})
+
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "foo/a", []byte("1"), nil))
+	require.NoError(t, c.Set(ctx, "foo/b", []byte("2"), nil))
+	require.NoError(t, c.Set(ctx, "bar/a", []byte("3"), nil))
+	require.NoError(t, c.Set(ctx, "foo/a", []byte("4"), nil))
+
+	assert.Eventually(t, func() bool {
+		keys, err := c.Keys(ctx, "foo/")
+		require.NoError(t, err)
+		return assert.ObjectsAreEqual(2, len(keys))
+	}, time.Second, time.Millisecond*5)
+
+	require.NoError(t, c.Delete(ctx, "foo/b"))
+
+	keys, err := c.Keys(ctx, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo/a", "bar/a"}, keys)
+}
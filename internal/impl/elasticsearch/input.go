@@ -0,0 +1,593 @@
+package elasticsearch
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/benthosdev/benthos/v4/internal/checkpoint"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	paginationPointInTime = "point_in_time"
+	paginationScroll      = "scroll"
+)
+
+func elasticsearchInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("4.14.0").
+		Categories("Services").
+		Summary("Executes a query against Elasticsearch and creates a batch of messages for each page of documents returned, paging through the results with either a point in time and `search_after`, or a scroll.").
+		Description(`
+The `+"`_source`"+` of each document becomes the contents of a message, and once all documents matching the query have been consumed this input shuts down, allowing the pipeline to gracefully terminate (or the next input in a [sequence](/docs/components/inputs/sequence) to execute).
+
+### Pagination
+
+When `+"`pagination`"+` is set to `+"`point_in_time`"+` (the default) a point in time is opened against the target indexes and pages of results are read with `+"`search_after`"+`, which requires Elasticsearch 7.10 or later. When no `+"`sort`"+` is specified documents are sorted by `+"`_shard_doc`"+`, which requires Elasticsearch 7.12 or later.
+
+When `+"`pagination`"+` is set to `+"`scroll`"+` a scroll context is used instead, which is also supported by OpenSearch. When no `+"`sort`"+` is specified documents are sorted by `+"`_doc`"+`.
+
+### Checkpointing
+
+When a `+"`checkpoint.cache`"+` is specified the sort values of the last document of each page are stored within the cache once the page, and all pages preceding it, have been acknowledged. When the input is restarted it resumes from the stored sort values, and therefore queries can be run periodically in order to consume documents added since the last run.
+
+Checkpointing requires `+"`point_in_time`"+` pagination, and as a new point in time is opened each time the input is restarted the `+"`sort`"+` field must be set to sort clauses that order documents in a stable and unique way, such as a timestamp followed by a unique identifier.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- elasticsearch_id
+- elasticsearch_index
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(
+			service.NewStringListField("urls").
+				Description("A list of URLs to connect to. If an item of the list contains commas it will be expanded into multiple URLs.").
+				Example([]string{"http://localhost:9200"}),
+			service.NewStringField("index").
+				Description("The index to query, multiple indexes can be queried with a comma separated list.").
+				Example("my-index").
+				Example("logs-*,metrics-*"),
+			service.NewStringField("query").
+				Description("A [query DSL](https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl.html) document, in JSON format, used to select the documents to consume.").
+				Example(`{"range":{"created_at":{"gte":"now-1d/d"}}}`).
+				Default(`{"match_all":{}}`),
+			service.NewStringField("sort").
+				Description("An optional array of sort clauses, in JSON format, determining the order in which documents are consumed.").
+				Example(`[{"created_at":"asc"},{"id":"asc"}]`).
+				Optional(),
+			service.NewStringEnumField("pagination", paginationPointInTime, paginationScroll).
+				Description("The method used to page through results.").
+				Default(paginationPointInTime),
+			service.NewDurationField("keep_alive").
+				Description("The period of time to keep the point in time or scroll context alive between pages.").
+				Default("1m").
+				Advanced(),
+			service.NewIntField("batch_size").
+				Description("The maximum number of documents to read per page, each page is emitted as a batch of messages.").
+				Default(100),
+			service.NewObjectField("checkpoint",
+				service.NewStringField("cache").
+					Description("An optional [cache resource](/docs/components/caches/about) to store the position of the input within.").
+					Default(""),
+				service.NewStringField("key").
+					Description("The key under which the position of the input is stored within the cache.").
+					Default("elasticsearch_input"),
+			).Description("Stores the position of the input within a cache, allowing it to resume from that position when restarted."),
+			service.NewBoolField("sniff").
+				Description("Prompts Benthos to sniff for brokers to connect to when establishing a connection.").
+				Default(true).
+				Advanced(),
+			service.NewBoolField("healthcheck").
+				Description("Whether to enable healthchecks.").
+				Default(true).
+				Advanced(),
+			service.NewDurationField("timeout").
+				Description("The maximum time to wait before abandoning a request (and trying again).").
+				Default("5s").
+				Advanced(),
+			service.NewTLSToggledField("tls"),
+			service.NewObjectField("basic_auth",
+				service.NewBoolField("enabled").
+					Description("Whether to use basic authentication in requests.").
+					Default(false),
+				service.NewStringField("username").
+					Description("A username to authenticate as.").
+					Default(""),
+				service.NewStringField("password").
+					Description("A password to authenticate with.").
+					Default("").
+					Secret(),
+			).Description("Allows you to specify basic authentication.").Advanced(),
+		).
+		Example("Reindex", `
+Here we copy the documents of an index into another cluster, preserving their IDs. The `+"`elasticsearch`"+` output writes each page as a bulk request:`,
+			`
+input:
+  elasticsearch:
+    urls: [ http://source:9200 ]
+    index: my-index
+    batch_size: 500
+
+output:
+  elasticsearch:
+    urls: [ http://target:9200 ]
+    index: ${! meta("elasticsearch_index") }
+    id: ${! meta("elasticsearch_id") }
+`,
+		).
+		Example("Incremental Backfill", `
+Here we consume documents created within the last week ordered by their creation date and ID, checkpointing our position within a file cache so that subsequent runs only consume documents added since the previous run:`,
+			`
+input:
+  elasticsearch:
+    urls: [ http://localhost:9200 ]
+    index: orders
+    query: '{"range":{"created_at":{"gte":"now-7d/d"}}}'
+    sort: '[{"created_at":"asc"},{"order_id":"asc"}]'
+    checkpoint:
+      cache: checkpoints
+      key: orders_backfill
+
+cache_resources:
+  - label: checkpoints
+    file:
+      directory: /var/lib/benthos/checkpoints
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchInput("elasticsearch", elasticsearchInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			i, err := newElasticsearchInputFromParsed(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacksBatched(i), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type esSearchResult struct {
+	ScrollID string `json:"_scroll_id"`
+	PitID    string `json:"pit_id"`
+	Hits     struct {
+		Hits []esSearchHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// esSearchHit is decoded separately from the hits of the client library as the
+// sort values must be preserved exactly, large integers included.
+type esSearchHit struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+	Sort   json.RawMessage `json:"sort"`
+}
+
+type elasticsearchInput struct {
+	urls        []string
+	index       string
+	query       json.RawMessage
+	sort        json.RawMessage
+	scroll      bool
+	keepAlive   string
+	batchSize   int
+	sniff       bool
+	healthcheck bool
+	timeout     time.Duration
+	tlsConf     *tls.Config
+	authEnabled bool
+	username    string
+	password    string
+
+	checkpointCache string
+	checkpointKey   string
+
+	log *service.Logger
+	mgr *service.Resources
+
+	client      *elastic.Client
+	pitID       string
+	scrollID    string
+	searchAfter json.RawMessage
+	done        bool
+
+	cMut         sync.Mutex
+	checkpointer *checkpoint.Uncapped[json.RawMessage]
+}
+
+func newElasticsearchInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (e *elasticsearchInput, err error) {
+	e = &elasticsearchInput{
+		log:          mgr.Logger(),
+		mgr:          mgr,
+		checkpointer: checkpoint.NewUncapped[json.RawMessage](),
+	}
+
+	var urls []string
+	if urls, err = conf.FieldStringList("urls"); err != nil {
+		return
+	}
+	for _, u := range urls {
+		for _, splitURL := range strings.Split(u, ",") {
+			if len(splitURL) > 0 {
+				e.urls = append(e.urls, splitURL)
+			}
+		}
+	}
+
+	if e.index, err = conf.FieldString("index"); err != nil {
+		return
+	}
+
+	var queryStr string
+	if queryStr, err = conf.FieldString("query"); err != nil {
+		return
+	}
+	if !json.Valid([]byte(queryStr)) {
+		return nil, errors.New("query must be a valid JSON document")
+	}
+	e.query = json.RawMessage(queryStr)
+
+	var sortStr string
+	if conf.Contains("sort") {
+		if sortStr, err = conf.FieldString("sort"); err != nil {
+			return
+		}
+	}
+	if sortStr != "" {
+		var sortClauses []json.RawMessage
+		if err = json.Unmarshal([]byte(sortStr), &sortClauses); err != nil {
+			return nil, fmt.Errorf("sort must be a JSON array of sort clauses: %w", err)
+		}
+		e.sort = json.RawMessage(sortStr)
+	}
+
+	var pagination string
+	if pagination, err = conf.FieldString("pagination"); err != nil {
+		return
+	}
+	e.scroll = pagination == paginationScroll
+
+	var keepAlive time.Duration
+	if keepAlive, err = conf.FieldDuration("keep_alive"); err != nil {
+		return
+	}
+	e.keepAlive = fmt.Sprintf("%dms", keepAlive.Milliseconds())
+
+	if e.batchSize, err = conf.FieldInt("batch_size"); err != nil {
+		return
+	}
+	if e.batchSize < 1 {
+		return nil, errors.New("batch_size must be greater than zero")
+	}
+
+	if e.checkpointCache, err = conf.FieldString("checkpoint", "cache"); err != nil {
+		return
+	}
+	if e.checkpointKey, err = conf.FieldString("checkpoint", "key"); err != nil {
+		return
+	}
+	if e.checkpointCache != "" {
+		if e.scroll {
+			return nil, errors.New("checkpointing requires point_in_time pagination")
+		}
+		if e.sort == nil {
+			return nil, errors.New("a sort must be specified when checkpointing")
+		}
+		if !mgr.HasCache(e.checkpointCache) {
+			return nil, fmt.Errorf("cache resource '%v' was not found", e.checkpointCache)
+		}
+	}
+
+	if e.sniff, err = conf.FieldBool("sniff"); err != nil {
+		return
+	}
+	if e.healthcheck, err = conf.FieldBool("healthcheck"); err != nil {
+		return
+	}
+	if e.timeout, err = conf.FieldDuration("timeout"); err != nil {
+		return
+	}
+
+	var tlsEnabled bool
+	if e.tlsConf, tlsEnabled, err = conf.FieldTLSToggled("tls"); err != nil {
+		return
+	}
+	if !tlsEnabled {
+		e.tlsConf = nil
+	}
+
+	if e.authEnabled, err = conf.FieldBool("basic_auth", "enabled"); err != nil {
+		return
+	}
+	if e.username, err = conf.FieldString("basic_auth", "username"); err != nil {
+		return
+	}
+	if e.password, err = conf.FieldString("basic_auth", "password"); err != nil {
+		return
+	}
+	return e, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (e *elasticsearchInput) Connect(ctx context.Context) error {
+	if e.client == nil {
+		opts := []elastic.ClientOptionFunc{
+			elastic.SetURL(e.urls...),
+			elastic.SetSniff(e.sniff),
+			elastic.SetHealthcheck(e.healthcheck),
+		}
+		if e.authEnabled {
+			opts = append(opts, elastic.SetBasicAuth(e.username, e.password))
+		}
+
+		httpClient := &http.Client{Timeout: e.timeout}
+		if e.tlsConf != nil {
+			httpClient.Transport = &http.Transport{
+				TLSClientConfig: e.tlsConf,
+			}
+		}
+		opts = append(opts, elastic.SetHttpClient(httpClient))
+
+		client, err := elastic.NewClient(opts...)
+		if err != nil {
+			return err
+		}
+
+		if e.checkpointCache != "" {
+			if err := e.readCheckpoint(ctx); err != nil {
+				return err
+			}
+		}
+		e.client = client
+	}
+
+	if !e.scroll && e.pitID == "" && !e.done {
+		res, err := e.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/" + url.PathEscape(e.index) + "/_pit",
+			Params: url.Values{"keep_alive": []string{e.keepAlive}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to open point in time: %w", err)
+		}
+
+		var pit struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(res.Body, &pit); err != nil {
+			return fmt.Errorf("failed to parse point in time response: %w", err)
+		}
+		if pit.ID == "" {
+			return errors.New("point in time response did not contain an id")
+		}
+		e.pitID = pit.ID
+	}
+
+	e.log.Infof("Consuming documents from Elasticsearch index '%v' at urls: %s\n", e.index, e.urls)
+	return nil
+}
+
+func (e *elasticsearchInput) readCheckpoint(ctx context.Context) error {
+	var cached []byte
+	var getErr error
+	if err := e.mgr.AccessCache(ctx, e.checkpointCache, func(c service.Cache) {
+		cached, getErr = c.Get(ctx, e.checkpointKey)
+	}); err != nil {
+		return fmt.Errorf("failed to access checkpoint cache: %w", err)
+	}
+	if getErr != nil {
+		if errors.Is(getErr, service.ErrKeyNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to read checkpoint: %w", getErr)
+	}
+	if !json.Valid(cached) {
+		return fmt.Errorf("checkpoint stored under key '%v' is not valid JSON", e.checkpointKey)
+	}
+	e.searchAfter = json.RawMessage(cached)
+	return nil
+}
+
+func (e *elasticsearchInput) writeCheckpoint(ctx context.Context, sortValues json.RawMessage) error {
+	var setErr error
+	if err := e.mgr.AccessCache(ctx, e.checkpointCache, func(c service.Cache) {
+		setErr = c.Set(ctx, e.checkpointKey, sortValues, nil)
+	}); err != nil {
+		return fmt.Errorf("failed to access checkpoint cache: %w", err)
+	}
+	if setErr != nil {
+		return fmt.Errorf("failed to store checkpoint: %w", setErr)
+	}
+	return nil
+}
+
+func (e *elasticsearchInput) search(ctx context.Context) (*esSearchResult, error) {
+	var opts elastic.PerformRequestOptions
+	switch {
+	case !e.scroll:
+		body := map[string]any{
+			"query": e.query,
+			"size":  e.batchSize,
+			"pit": map[string]any{
+				"id":         e.pitID,
+				"keep_alive": e.keepAlive,
+			},
+			"sort": json.RawMessage(`[{"_shard_doc":"asc"}]`),
+		}
+		if e.sort != nil {
+			body["sort"] = e.sort
+		}
+		if e.searchAfter != nil {
+			body["search_after"] = e.searchAfter
+		}
+		opts = elastic.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_search",
+			Body:   body,
+		}
+	case e.scrollID == "":
+		body := map[string]any{
+			"query": e.query,
+			"size":  e.batchSize,
+			"sort":  json.RawMessage(`["_doc"]`),
+		}
+		if e.sort != nil {
+			body["sort"] = e.sort
+		}
+		opts = elastic.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/" + url.PathEscape(e.index) + "/_search",
+			Params: url.Values{"scroll": []string{e.keepAlive}},
+			Body:   body,
+		}
+	default:
+		opts = elastic.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_search/scroll",
+			Body: map[string]any{
+				"scroll":    e.keepAlive,
+				"scroll_id": e.scrollID,
+			},
+		}
+	}
+
+	res, err := e.client.PerformRequest(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result esSearchResult
+	if err := json.Unmarshal(res.Body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+	return &result, nil
+}
+
+func (e *elasticsearchInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	if e.client == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+	if e.done {
+		return nil, nil, service.ErrEndOfInput
+	}
+
+	result, err := e.search(ctx)
+	if err != nil {
+		if !e.scroll && elastic.IsNotFound(err) {
+			// The point in time has most likely expired, in which case we open
+			// a new one and continue from the last page.
+			e.log.Warnf("Point in time was not found, opening a new one: %v\n", err)
+			e.pitID = ""
+			return nil, nil, service.ErrNotConnected
+		}
+		return nil, nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	if result.PitID != "" {
+		e.pitID = result.PitID
+	}
+	if result.ScrollID != "" {
+		e.scrollID = result.ScrollID
+	}
+
+	hits := result.Hits.Hits
+	if len(hits) == 0 {
+		e.done = true
+		e.release(ctx)
+		return nil, nil, service.ErrEndOfInput
+	}
+
+	batch := make(service.MessageBatch, len(hits))
+	for i, hit := range hits {
+		msg := service.NewMessage(hit.Source)
+		msg.MetaSetMut("elasticsearch_id", hit.ID)
+		msg.MetaSetMut("elasticsearch_index", hit.Index)
+		batch[i] = msg
+	}
+
+	lastSort := hits[len(hits)-1].Sort
+	e.searchAfter = lastSort
+
+	if e.checkpointCache == "" {
+		return batch, func(context.Context, error) error {
+			return nil
+		}, nil
+	}
+
+	e.cMut.Lock()
+	resolveFn := e.checkpointer.Track(lastSort, int64(len(hits)))
+	e.cMut.Unlock()
+
+	return batch, func(ctx context.Context, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		e.cMut.Lock()
+		defer e.cMut.Unlock()
+
+		highest := resolveFn()
+		if highest == nil {
+			return nil
+		}
+		return e.writeCheckpoint(ctx, *highest)
+	}, nil
+}
+
+// release frees the point in time or scroll context held by the input.
+func (e *elasticsearchInput) release(ctx context.Context) {
+	if e.client == nil {
+		return
+	}
+
+	var err error
+	if e.pitID != "" {
+		_, err = e.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: "DELETE",
+			Path:   "/_pit",
+			Body:   map[string]any{"id": e.pitID},
+		})
+		e.pitID = ""
+	} else if e.scrollID != "" {
+		_, err = e.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: "DELETE",
+			Path:   "/_search/scroll",
+			Body:   map[string]any{"scroll_id": e.scrollID},
+		})
+		e.scrollID = ""
+	}
+	if err != nil {
+		e.log.Debugf("Failed to release search context: %v\n", err)
+	}
+}
+
+func (e *elasticsearchInput) Close(ctx context.Context) error {
+	e.release(ctx)
+	if e.client != nil {
+		e.client.Stop()
+		e.client = nil
+	}
+	return nil
+}
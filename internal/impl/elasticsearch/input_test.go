@@ -0,0 +1,305 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// stubSeqBase ensures sort values exceed the precision of a float64.
+const stubSeqBase = int64(1690000000000000000)
+
+type stubSearchServer struct {
+	t    *testing.T
+	docs int
+
+	mut      sync.Mutex
+	searches []map[string]any
+	released []string
+}
+
+func (s *stubSearchServer) page(after int64, size int) []map[string]any {
+	hits := []map[string]any{}
+	for i := 0; i < s.docs && len(hits) < size; i++ {
+		seq := stubSeqBase + int64(i)
+		if seq <= after {
+			continue
+		}
+		hits = append(hits, map[string]any{
+			"_index":  "foo",
+			"_id":     fmt.Sprintf("doc-%v", i),
+			"_source": map[string]any{"n": i},
+			"sort":    []json.Number{json.Number(strconv.FormatInt(seq, 10))},
+		})
+	}
+	return hits
+}
+
+func (s *stubSearchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	reply := func(v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+
+	size := 0
+	if n, ok := body["size"].(json.Number); ok {
+		i, _ := n.Int64()
+		size = int(i)
+	}
+
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/foo/_pit":
+		assert.Equal(s.t, "60000ms", r.URL.Query().Get("keep_alive"))
+		reply(map[string]any{"id": "pit-a"})
+	case r.Method == "POST" && r.URL.Path == "/_search":
+		s.searches = append(s.searches, body)
+		after := int64(-1)
+		if sa, ok := body["search_after"].([]any); ok {
+			after, _ = sa[0].(json.Number).Int64()
+		}
+		reply(map[string]any{
+			"pit_id": "pit-b",
+			"hits":   map[string]any{"hits": s.page(after, size)},
+		})
+	case r.Method == "POST" && r.URL.Path == "/foo/_search":
+		s.searches = append(s.searches, body)
+		hits := s.page(-1, size)
+		reply(map[string]any{
+			"_scroll_id": fmt.Sprintf("scroll-%v", len(hits)),
+			"hits":       map[string]any{"hits": hits},
+		})
+	case r.Method == "POST" && r.URL.Path == "/_search/scroll":
+		s.searches = append(s.searches, body)
+		offset, _ := strconv.Atoi(strings.TrimPrefix(body["scroll_id"].(string), "scroll-"))
+		hits := s.page(stubSeqBase+int64(offset)-1, 2)
+		reply(map[string]any{
+			"_scroll_id": fmt.Sprintf("scroll-%v", offset+len(hits)),
+			"hits":       map[string]any{"hits": hits},
+		})
+	case r.Method == "DELETE" && r.URL.Path == "/_pit":
+		s.released = append(s.released, body["id"].(string))
+		reply(map[string]any{"succeeded": true})
+	case r.Method == "DELETE" && r.URL.Path == "/_search/scroll":
+		s.released = append(s.released, body["scroll_id"].(string))
+		reply(map[string]any{"succeeded": true})
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func testESInput(t *testing.T, confStr string, res *service.Resources) *elasticsearchInput {
+	t.Helper()
+
+	pConf, err := elasticsearchInputSpec().ParseYAML(confStr, nil)
+	require.NoError(t, err)
+
+	i, err := newElasticsearchInputFromParsed(pConf, res)
+	require.NoError(t, err)
+	return i
+}
+
+func readAllES(t *testing.T, i *elasticsearchInput) (pages [][]string) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, i.Connect(ctx))
+	for {
+		batch, ackFn, err := i.ReadBatch(ctx)
+		if errors.Is(err, service.ErrEndOfInput) {
+			break
+		}
+		require.NoError(t, err)
+
+		var page []string
+		for _, m := range batch {
+			b, err := m.AsBytes()
+			require.NoError(t, err)
+			id, _ := m.MetaGet("elasticsearch_id")
+			index, _ := m.MetaGet("elasticsearch_index")
+			page = append(page, fmt.Sprintf("%v/%v:%s", index, id, b))
+		}
+		pages = append(pages, page)
+		require.NoError(t, ackFn(ctx, nil))
+	}
+	require.NoError(t, i.Close(ctx))
+	return
+}
+
+func TestElasticsearchInputPointInTime(t *testing.T) {
+	stub := &stubSearchServer{t: t, docs: 5}
+	ts := httptest.NewServer(stub)
+	t.Cleanup(ts.Close)
+
+	i := testESInput(t, fmt.Sprintf(`
+urls: [ %v ]
+index: foo
+query: '{"term":{"bar":"baz"}}'
+batch_size: 2
+sniff: false
+healthcheck: false
+`, ts.URL), service.MockResources())
+
+	assert.Equal(t, [][]string{
+		{`foo/doc-0:{"n":0}`, `foo/doc-1:{"n":1}`},
+		{`foo/doc-2:{"n":2}`, `foo/doc-3:{"n":3}`},
+		{`foo/doc-4:{"n":4}`},
+	}, readAllES(t, i))
+
+	stub.mut.Lock()
+	defer stub.mut.Unlock()
+
+	require.Len(t, stub.searches, 4)
+	assert.Equal(t, map[string]any{"term": map[string]any{"bar": "baz"}}, stub.searches[0]["query"])
+	assert.Equal(t, map[string]any{"id": "pit-a", "keep_alive": "60000ms"}, stub.searches[0]["pit"])
+	assert.Equal(t, []any{map[string]any{"_shard_doc": "asc"}}, stub.searches[0]["sort"])
+	assert.NotContains(t, stub.searches[0], "search_after")
+
+	// Subsequent pages use the most recent point in time ID and the exact sort
+	// values of the previous page.
+	assert.Equal(t, "pit-b", stub.searches[1]["pit"].(map[string]any)["id"])
+	assert.Equal(t, []any{json.Number("1690000000000000001")}, stub.searches[1]["search_after"])
+	assert.Equal(t, []string{"pit-b"}, stub.released)
+}
+
+func TestElasticsearchInputScroll(t *testing.T) {
+	stub := &stubSearchServer{t: t, docs: 3}
+	ts := httptest.NewServer(stub)
+	t.Cleanup(ts.Close)
+
+	i := testESInput(t, fmt.Sprintf(`
+urls: [ %v ]
+index: foo
+pagination: scroll
+batch_size: 2
+sniff: false
+healthcheck: false
+`, ts.URL), service.MockResources())
+
+	assert.Equal(t, [][]string{
+		{`foo/doc-0:{"n":0}`, `foo/doc-1:{"n":1}`},
+		{`foo/doc-2:{"n":2}`},
+	}, readAllES(t, i))
+
+	stub.mut.Lock()
+	defer stub.mut.Unlock()
+
+	require.Len(t, stub.searches, 3)
+	assert.Equal(t, []any{"_doc"}, stub.searches[0]["sort"])
+	assert.Equal(t, map[string]any{"scroll": "60000ms", "scroll_id": "scroll-2"}, stub.searches[1])
+	assert.Equal(t, []string{"scroll-3"}, stub.released)
+}
+
+func TestElasticsearchInputCheckpoint(t *testing.T) {
+	stub := &stubSearchServer{t: t, docs: 3}
+	ts := httptest.NewServer(stub)
+	t.Cleanup(ts.Close)
+
+	res := service.MockResources(service.MockResourcesOptAddCache("foocache"))
+	confStr := fmt.Sprintf(`
+urls: [ %v ]
+index: foo
+sort: '[{"seq":"asc"}]'
+batch_size: 2
+checkpoint:
+  cache: foocache
+  key: fookey
+sniff: false
+healthcheck: false
+`, ts.URL)
+
+	assert.Equal(t, [][]string{
+		{`foo/doc-0:{"n":0}`, `foo/doc-1:{"n":1}`},
+		{`foo/doc-2:{"n":2}`},
+	}, readAllES(t, testESInput(t, confStr, res)))
+
+	var cached []byte
+	require.NoError(t, res.AccessCache(context.Background(), "foocache", func(c service.Cache) {
+		var err error
+		cached, err = c.Get(context.Background(), "fookey")
+		require.NoError(t, err)
+	}))
+	assert.Equal(t, `[1690000000000000002]`, string(cached))
+
+	// New documents are consumed from the checkpoint on a subsequent run.
+	stub.mut.Lock()
+	stub.docs = 4
+	stub.searches = nil
+	stub.mut.Unlock()
+
+	assert.Equal(t, [][]string{
+		{`foo/doc-3:{"n":3}`},
+	}, readAllES(t, testESInput(t, confStr, res)))
+
+	stub.mut.Lock()
+	defer stub.mut.Unlock()
+	require.NotEmpty(t, stub.searches)
+	assert.Equal(t, []any{map[string]any{"seq": "asc"}}, stub.searches[0]["sort"])
+	assert.Equal(t, []any{json.Number("1690000000000000002")}, stub.searches[0]["search_after"])
+}
+
+func TestElasticsearchInputBadConfig(t *testing.T) {
+	res := service.MockResources(service.MockResourcesOptAddCache("foocache"))
+	for _, test := range []struct {
+		name   string
+		conf   string
+		errStr string
+	}{
+		{
+			name:   "invalid query",
+			conf:   `query: '{"match_all":'`,
+			errStr: "query must be a valid JSON document",
+		},
+		{
+			name:   "checkpoint with scroll",
+			conf:   "pagination: scroll\ncheckpoint:\n  cache: foocache",
+			errStr: "checkpointing requires point_in_time pagination",
+		},
+		{
+			name:   "checkpoint without sort",
+			conf:   "checkpoint:\n  cache: foocache",
+			errStr: "a sort must be specified when checkpointing",
+		},
+		{
+			name:   "missing cache",
+			conf:   "sort: '[\"seq\"]'\ncheckpoint:\n  cache: nope",
+			errStr: "cache resource 'nope' was not found",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			pConf, err := elasticsearchInputSpec().ParseYAML("urls: [ http://localhost:9200 ]\nindex: foo\n"+test.conf, nil)
+			require.NoError(t, err)
+
+			_, err = newElasticsearchInputFromParsed(pConf, res)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errStr)
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	// nolint:staticcheck // Ignore SA1019 deprecation warning until we can switch to "google.golang.org/protobuf/types/dynamicpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const clientMethodDescription = `
+The method is invoked using descriptors parsed from the .proto files found within ` + "`import_paths`" + `, and therefore no generated code is required. Messages are converted from JSON documents into the input type of the method as per the [JSON mapping of protobuf messages](https://developers.google.com/protocol-buffers/docs/proto3#json).
+
+Unary and server streaming methods are invoked once for each message, whereas client and bidirectional streaming methods are invoked once for each batch, where each message of the batch is sent as a request of the stream.`
+
+func grpcClientFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewStringField("address").
+			Description("The address of the gRPC server to connect to.").
+			Example("localhost:50051"),
+		importPathsField(),
+		service.NewStringField("method").
+			Description("The full name of the method to invoke, of the form `package.Service/Method`.").
+			Example("helloworld.Greeter/SayHello"),
+		service.NewInterpolatedStringMapField("metadata").
+			Description("A map of metadata to add to each call.").
+			Example(map[string]any{"authorization": "Bearer ${! env(\"TOKEN\") }"}).
+			Default(map[string]any{}),
+		service.NewDurationField("timeout").
+			Description("The maximum period to wait for a call to complete before abandoning it.").
+			Default("5s"),
+		service.NewTLSToggledField("tls"),
+	}
+}
+
+type grpcClient struct {
+	address  string
+	method   *desc.MethodDescriptor
+	metadata map[string]*service.InterpolatedString
+	timeout  time.Duration
+	tlsConf  *tls.Config
+
+	codec *protoCodec
+
+	connMut sync.RWMutex
+	conn    *grpc.ClientConn
+	stub    grpcdynamic.Stub
+}
+
+func newGRPCClientFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*grpcClient, error) {
+	c := &grpcClient{}
+
+	var err error
+	if c.address, err = conf.FieldString("address"); err != nil {
+		return nil, err
+	}
+	if c.metadata, err = conf.FieldInterpolatedStringMap("metadata"); err != nil {
+		return nil, err
+	}
+	if c.timeout, err = conf.FieldDuration("timeout"); err != nil {
+		return nil, err
+	}
+
+	tlsConf, tlsEnabled, err := conf.FieldTLSToggled("tls")
+	if err != nil {
+		return nil, err
+	}
+	if tlsEnabled {
+		c.tlsConf = tlsConf
+	}
+
+	fds, codec, err := loadServiceDescriptors(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+	c.codec = codec
+
+	methodName, err := conf.FieldString("method")
+	if err != nil {
+		return nil, err
+	}
+	if c.method, err = findMethod(fds, methodName); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *grpcClient) connect(ctx context.Context) error {
+	c.connMut.Lock()
+	defer c.connMut.Unlock()
+
+	if c.conn != nil {
+		return nil
+	}
+
+	creds := insecure.NewCredentials()
+	if c.tlsConf != nil {
+		creds = credentials.NewTLS(c.tlsConf)
+	}
+
+	conn, err := grpc.DialContext(ctx, c.address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.stub = grpcdynamic.NewStub(conn)
+	return nil
+}
+
+// invoke calls the method once for each message of a batch, or once for the
+// entire batch if the method is client streaming, and returns the responses
+// received for each call along with the error of each call. When the method is
+// client streaming the responses are returned at the index of the first
+// message of the batch, and an error is returned for the entire batch.
+func (c *grpcClient) invoke(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, []error, error) {
+	c.connMut.RLock()
+	stub, connected := c.stub, c.conn != nil
+	c.connMut.RUnlock()
+	if !connected {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	responses := make([]service.MessageBatch, len(batch))
+	errs := make([]error, len(batch))
+
+	if c.method.IsClientStreaming() {
+		reqs := make([]proto.Message, len(batch))
+		for i := range batch {
+			req, err := c.request(batch, i)
+			if err != nil {
+				return nil, nil, err
+			}
+			reqs[i] = req
+		}
+
+		md, err := c.outgoingMetadata(batch, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		res, err := c.call(ctx, stub, md, reqs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if responses[0], err = c.responseBatch(batch[0], res); err != nil {
+			return nil, nil, err
+		}
+		return responses, errs, nil
+	}
+
+	for i, msg := range batch {
+		res, err := c.callFor(ctx, stub, batch, i)
+		if err == nil {
+			responses[i], err = c.responseBatch(msg, res)
+		}
+		errs[i] = err
+	}
+	return responses, errs, nil
+}
+
+// callFor invokes the method for an individual message of a batch.
+func (c *grpcClient) callFor(ctx context.Context, stub grpcdynamic.Stub, batch service.MessageBatch, index int) ([]proto.Message, error) {
+	req, err := c.request(batch, index)
+	if err != nil {
+		return nil, err
+	}
+	md, err := c.outgoingMetadata(batch, index)
+	if err != nil {
+		return nil, err
+	}
+	return c.call(ctx, stub, md, []proto.Message{req})
+}
+
+func (c *grpcClient) request(batch service.MessageBatch, index int) (*dynamic.Message, error) {
+	b, err := batch[index].AsBytes()
+	if err != nil {
+		return nil, err
+	}
+	return c.codec.fromJSON(c.method.GetInputType(), b)
+}
+
+func (c *grpcClient) outgoingMetadata(batch service.MessageBatch, index int) (metadata.MD, error) {
+	md := metadata.MD{}
+	for k, v := range c.metadata {
+		value, err := batch.TryInterpolatedString(index, v)
+		if err != nil {
+			return nil, fmt.Errorf("metadata %v interpolation error: %w", k, err)
+		}
+		md.Append(k, value)
+	}
+	return md, nil
+}
+
+// responseBatch creates a message for each response of a call, which is a copy
+// of the message that the call was made for.
+func (c *grpcClient) responseBatch(origin *service.Message, responses []proto.Message) (service.MessageBatch, error) {
+	resBatch := make(service.MessageBatch, 0, len(responses))
+	for _, res := range responses {
+		dynRes, err := dynamic.AsDynamicMessage(res)
+		if err != nil {
+			return nil, err
+		}
+		b, err := c.codec.toJSON(dynRes)
+		if err != nil {
+			return nil, err
+		}
+		msg := origin.Copy()
+		msg.SetBytes(b)
+		resBatch = append(resBatch, msg)
+	}
+	return resBatch, nil
+}
+
+// call invokes the method with a slice of requests, which must contain a single
+// request unless the method is client streaming, and returns all responses.
+func (c *grpcClient) call(ctx context.Context, stub grpcdynamic.Stub, md metadata.MD, reqs []proto.Message) ([]proto.Message, error) {
+	ctx, done := context.WithTimeout(metadata.NewOutgoingContext(ctx, md), c.timeout)
+	defer done()
+
+	switch {
+	case c.method.IsClientStreaming() && c.method.IsServerStreaming():
+		stream, err := stub.InvokeRpcBidiStream(ctx, c.method)
+		if err != nil {
+			return nil, err
+		}
+
+		sendErrChan := make(chan error, 1)
+		go func() {
+			for _, req := range reqs {
+				if err := stream.SendMsg(req); err != nil {
+					sendErrChan <- err
+					return
+				}
+			}
+			sendErrChan <- stream.CloseSend()
+		}()
+
+		var responses []proto.Message
+		for {
+			res, err := stream.RecvMsg()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			responses = append(responses, res)
+		}
+		if err := <-sendErrChan; err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return responses, nil
+
+	case c.method.IsClientStreaming():
+		stream, err := stub.InvokeRpcClientStream(ctx, c.method)
+		if err != nil {
+			return nil, err
+		}
+		for _, req := range reqs {
+			if err := stream.SendMsg(req); err != nil {
+				if errors.Is(err, io.EOF) {
+					// The server has ended the call, the cause of which is
+					// returned when receiving.
+					break
+				}
+				return nil, err
+			}
+		}
+		res, err := stream.CloseAndReceive()
+		if err != nil {
+			return nil, err
+		}
+		return []proto.Message{res}, nil
+
+	case c.method.IsServerStreaming():
+		stream, err := stub.InvokeRpcServerStream(ctx, c.method, reqs[0])
+		if err != nil {
+			return nil, err
+		}
+		var responses []proto.Message
+		for {
+			res, err := stream.RecvMsg()
+			if errors.Is(err, io.EOF) {
+				return responses, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			responses = append(responses, res)
+		}
+	}
+
+	res, err := stub.InvokeRpc(ctx, c.method, reqs[0])
+	if err != nil {
+		return nil, err
+	}
+	return []proto.Message{res}, nil
+}
+
+func (c *grpcClient) close() error {
+	c.connMut.Lock()
+	defer c.connMut.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
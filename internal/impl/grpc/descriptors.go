@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+
+	// nolint:staticcheck // Ignore SA1019 deprecation warning until we can switch to "google.golang.org/protobuf/types/dynamicpb"
+	"github.com/golang/protobuf/jsonpb"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+
+	"github.com/benthosdev/benthos/v4/internal/impl/pure"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func importPathsField() *service.ConfigField {
+	return service.NewStringListField("import_paths").
+		Description("A list of directories containing .proto files, including all definitions required for parsing the target service. If left empty the current directory is used. Each directory listed will be walked with all found .proto files imported.").
+		Default([]any{})
+}
+
+// protoCodec converts dynamic protobuf messages to and from JSON documents.
+type protoCodec struct {
+	marshaler   *jsonpb.Marshaler
+	unmarshaler *jsonpb.Unmarshaler
+}
+
+func newProtoCodec(fds []*desc.FileDescriptor) *protoCodec {
+	resolver := dynamic.AnyResolver(dynamic.NewMessageFactoryWithDefaults(), fds...)
+	return &protoCodec{
+		marshaler:   &jsonpb.Marshaler{AnyResolver: resolver},
+		unmarshaler: &jsonpb.Unmarshaler{AnyResolver: resolver},
+	}
+}
+
+func (c *protoCodec) fromJSON(md *desc.MessageDescriptor, b []byte) (*dynamic.Message, error) {
+	msg := dynamic.NewMessage(md)
+	if err := msg.UnmarshalJSONPB(c.unmarshaler, b); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON message: %w", err)
+	}
+	return msg, nil
+}
+
+func (c *protoCodec) toJSON(msg *dynamic.Message) ([]byte, error) {
+	b, err := msg.MarshalJSONPB(c.marshaler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal protobuf message: %w", err)
+	}
+	return b, nil
+}
+
+// loadServiceDescriptors parses the .proto files within a list of import paths
+// and returns them along with a codec able to resolve any of their types.
+func loadServiceDescriptors(conf *service.ParsedConfig, mgr *service.Resources) ([]*desc.FileDescriptor, *protoCodec, error) {
+	importPaths, err := conf.FieldStringList("import_paths")
+	if err != nil {
+		return nil, nil, err
+	}
+	fds, err := pure.LoadProtobufDescriptors(mgr.FS(), importPaths)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fds, newProtoCodec(fds), nil
+}
+
+func findService(fds []*desc.FileDescriptor, name string) (*desc.ServiceDescriptor, error) {
+	for _, fd := range fds {
+		if sd := fd.FindService(name); sd != nil {
+			return sd, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to find service '%v' definition", name)
+}
+
+// findMethod returns the descriptor of a method from its full name, which is
+// of the form `package.Service/Method`.
+func findMethod(fds []*desc.FileDescriptor, name string) (*desc.MethodDescriptor, error) {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(name, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("method '%v' must be of the form package.Service/Method", name)
+	}
+	sd, err := findService(fds, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	md := sd.FindMethodByName(methodName)
+	if md == nil {
+		return nil, fmt.Errorf("unable to find method '%v' within service '%v'", methodName, serviceName)
+	}
+	return md, nil
+}
+
+// fullMethodName returns the path of a method as it is transmitted by gRPC.
+func fullMethodName(md *desc.MethodDescriptor) string {
+	return "/" + md.GetService().GetFullyQualifiedName() + "/" + md.GetName()
+}
@@ -0,0 +1,317 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+
+	_ "github.com/benthosdev/benthos/v4/internal/impl/pure"
+)
+
+const testProto = `
+syntax = "proto3";
+package testing;
+
+service Greeter {
+  rpc SayHello (HelloRequest) returns (HelloReply);
+  rpc SayHelloToMany (stream HelloRequest) returns (HelloReply);
+  rpc SayHelloRepeatedly (HelloRequest) returns (stream HelloReply);
+}
+
+message HelloRequest {
+  string name = 1;
+}
+
+message HelloReply {
+  string message = 1;
+}
+`
+
+func testProtoDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "greeter.proto"), []byte(testProto), 0o644))
+	return dir
+}
+
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return addr
+}
+
+// waitForServer blocks until a server is accepting connections, as clients
+// back off for increasingly long periods after failing to connect.
+func waitForServer(t *testing.T, address string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, time.Second*10, time.Millisecond*10)
+}
+
+func runServerStream(t *testing.T, address, yamlStr string) {
+	t.Helper()
+
+	builder := service.NewStreamBuilder()
+	require.NoError(t, builder.SetLoggerYAML(`level: none`))
+	require.NoError(t, builder.SetYAML(yamlStr))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	go func() {
+		_ = strm.Run(context.Background())
+	}()
+	t.Cleanup(func() {
+		_ = strm.StopWithin(time.Second * 5)
+	})
+	waitForServer(t, address)
+}
+
+func testClientProcessor(t *testing.T, protoDir, address, method string) *grpcClientProcessor {
+	t.Helper()
+
+	conf, err := grpcClientProcessorConfig().ParseYAML(fmt.Sprintf(`
+address: %v
+import_paths: [ %v ]
+method: %v
+metadata:
+  greeting: '${! meta("greeting") }'
+`, address, protoDir, method), nil)
+	require.NoError(t, err)
+
+	proc, err := newGRPCClientProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+	return proc
+}
+
+func batchContents(t *testing.T, batch service.MessageBatch) []string {
+	t.Helper()
+
+	var contents []string
+	for _, msg := range batch {
+		require.NoError(t, msg.GetError())
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		contents = append(contents, string(b))
+	}
+	return contents
+}
+
+func TestGRPCServerSyncResponses(t *testing.T) {
+	protoDir := testProtoDir(t)
+	address := freeAddress(t)
+
+	runServerStream(t, address, fmt.Sprintf(`
+input:
+  grpc_server:
+    address: %v
+    import_paths: [ %v ]
+    service: testing.Greeter
+pipeline:
+  processors:
+    - mapping: |
+        root.message = "%%s to %%s from %%s".format(@greeting, this.name, @grpc_server_method)
+output:
+  sync_response: {}
+`, address, protoDir))
+
+	proc := testClientProcessor(t, protoDir, address, "testing.Greeter/SayHello")
+
+	msg := service.NewMessage([]byte(`{"name":"foo"}`))
+	msg.MetaSetMut("greeting", "hello")
+
+	res, err := proc.ProcessBatch(context.Background(), service.MessageBatch{msg})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, []string{
+		`{"message":"hello to foo from /testing.Greeter/SayHello"}`,
+	}, batchContents(t, res[0]))
+
+	v, _ := res[0][0].MetaGet("greeting")
+	assert.Equal(t, "hello", v)
+
+	proc = testClientProcessor(t, protoDir, address, "testing.Greeter/SayHelloToMany")
+
+	batch := service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"foo"}`)),
+		service.NewMessage([]byte(`{"name":"bar"}`)),
+	}
+	batch[0].MetaSetMut("greeting", "hey")
+
+	res, err = proc.ProcessBatch(context.Background(), batch)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, []string{
+		`{"message":"hey to foo from /testing.Greeter/SayHelloToMany"}`,
+	}, batchContents(t, res[0]))
+
+	proc = testClientProcessor(t, protoDir, address, "testing.Greeter/SayHelloRepeatedly")
+
+	res, err = proc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"foo"}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, res[0], 1)
+	require.Error(t, res[0][0].GetError())
+	assert.Equal(t, codes.Unimplemented, status.Code(res[0][0].GetError()))
+}
+
+func TestGRPCServerRejection(t *testing.T) {
+	protoDir := testProtoDir(t)
+	address := freeAddress(t)
+
+	runServerStream(t, address, fmt.Sprintf(`
+input:
+  grpc_server:
+    address: %v
+    import_paths: [ %v ]
+    service: testing.Greeter
+output:
+  reject: 'nope: ${! this.name }'
+`, address, protoDir))
+
+	proc := testClientProcessor(t, protoDir, address, "testing.Greeter/SayHello")
+
+	res, err := proc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"foo"}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, res[0], 1)
+	assert.Equal(t, codes.Internal, status.Code(res[0][0].GetError()))
+	assert.Contains(t, res[0][0].GetError().Error(), "nope: foo")
+
+	b, err := res[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"foo"}`, string(b))
+}
+
+func TestGRPCClientOutput(t *testing.T) {
+	protoDir := testProtoDir(t)
+	address := freeAddress(t)
+
+	builder := service.NewStreamBuilder()
+	require.NoError(t, builder.SetLoggerYAML(`level: none`))
+	require.NoError(t, builder.AddInputYAML(fmt.Sprintf(`
+grpc_server:
+  address: %v
+  import_paths: [ %v ]
+  service: testing.Greeter
+`, address, protoDir)))
+
+	var mut sync.Mutex
+	var received []string
+	require.NoError(t, builder.AddBatchConsumerFunc(func(ctx context.Context, batch service.MessageBatch) error {
+		mut.Lock()
+		defer mut.Unlock()
+		for _, msg := range batch {
+			b, err := msg.AsBytes()
+			if err != nil {
+				return err
+			}
+			received = append(received, string(b))
+		}
+		return nil
+	}))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+	go func() {
+		_ = strm.Run(context.Background())
+	}()
+	t.Cleanup(func() {
+		_ = strm.StopWithin(time.Second * 5)
+	})
+	waitForServer(t, address)
+
+	conf, err := grpcClientOutputConfig().ParseYAML(fmt.Sprintf(`
+address: %v
+import_paths: [ %v ]
+method: testing.Greeter/SayHelloToMany
+`, address, protoDir), nil)
+	require.NoError(t, err)
+
+	out, err := newGRPCClientOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	require.Equal(t, service.ErrNotConnected, out.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"foo"}`)),
+	}))
+	require.NoError(t, out.Connect(context.Background()))
+
+	require.NoError(t, out.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"foo"}`)),
+		service.NewMessage([]byte(`{"name":"bar"}`)),
+	}))
+	require.NoError(t, out.Close(context.Background()))
+
+	mut.Lock()
+	assert.Equal(t, []string{`{"name":"foo"}`, `{"name":"bar"}`}, received)
+	mut.Unlock()
+}
+
+func TestGRPCConfigErrors(t *testing.T) {
+	protoDir := testProtoDir(t)
+
+	tests := map[string]struct {
+		method string
+		err    string
+	}{
+		"bad format": {
+			method: "testing.Greeter.SayHello",
+			err:    "method 'testing.Greeter.SayHello' must be of the form package.Service/Method",
+		},
+		"missing service": {
+			method: "testing.Nope/SayHello",
+			err:    "unable to find service 'testing.Nope' definition",
+		},
+		"missing method": {
+			method: "testing.Greeter/SayNope",
+			err:    "unable to find method 'SayNope' within service 'testing.Greeter'",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			conf, err := grpcClientProcessorConfig().ParseYAML(fmt.Sprintf(`
+address: localhost:50051
+import_paths: [ %v ]
+method: %v
+`, protoDir, test.method), nil)
+			require.NoError(t, err)
+
+			_, err = newGRPCClientProcessorFromConfig(conf, service.MockResources())
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
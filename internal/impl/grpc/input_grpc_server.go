@@ -0,0 +1,347 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func grpcServerInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.14.0").
+		Summary("Serves the methods of a gRPC service described by .proto files, where each request is consumed as a JSON message.").
+		Description(`
+The methods of the service are served using descriptors parsed from the .proto files found within `+"`import_paths`"+`, and therefore no generated code is required. Requests are converted into JSON documents as per the [JSON mapping of protobuf messages](https://developers.google.com/protocol-buffers/docs/proto3#json).
+
+Unary and client streaming methods are supported. Each request of a unary method is consumed as a single message, whereas the requests of a client streaming call are consumed as a batch once the client has finished sending them. Server and bidirectional streaming methods are not served.
+
+### Responses
+
+It's possible to return a response for each call using [synchronous responses](/docs/guides/sync_responses), where the first response message is converted from JSON into the output type of the method. When no response is set the output type is returned empty once the messages of the call have been delivered.
+
+If the messages of a call are rejected, or are not delivered within the `+"`timeout`"+`, then the call fails with an error status.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"``` text"+`
+- grpc_server_method
+`+"```"+`
+
+Where `+"`grpc_server_method`"+` is the full path of the method called, e.g. `+"`/helloworld.Greeter/SayHello`"+`. The metadata of the call is also added to each message, where keys with multiple values have them joined with a comma.
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Field(service.NewStringField("address").
+			Description("The address to listen on.").
+			Example("0.0.0.0:50051")).
+		Field(importPathsField()).
+		Field(service.NewStringField("service").
+			Description("The fully qualified name of the service to serve.").
+			Example("helloworld.Greeter")).
+		Field(service.NewDurationField("timeout").
+			Description("The maximum time to wait for the messages of a call to be delivered before failing the call.").
+			Default("5s")).
+		Field(service.NewStringField("cert_file").
+			Description("An optional certificate file for enabling TLS.").
+			Advanced().
+			Default("")).
+		Field(service.NewStringField("key_file").
+			Description("An optional key file for enabling TLS.").
+			Advanced().
+			Default("")).
+		Example(
+			"Greeter Service",
+			"Serves the method `SayHello` of the service `helloworld.Greeter`, responding with a greeting for each request.",
+			`
+input:
+  grpc_server:
+    address: 0.0.0.0:50051
+    import_paths: [ ./protos ]
+    service: helloworld.Greeter
+
+pipeline:
+  processors:
+    - mapping: 'root.message = "Hello " + this.name'
+
+output:
+  sync_response: {}
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"grpc_server", grpcServerInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			return newGRPCServerInputFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type grpcServerTransaction struct {
+	batch   service.MessageBatch
+	resChan chan error
+}
+
+type grpcServerInput struct {
+	address  string
+	certFile string
+	keyFile  string
+	timeout  time.Duration
+
+	methods map[string]*desc.MethodDescriptor
+	codec   *protoCodec
+	log     *service.Logger
+
+	serverMut sync.Mutex
+	server    *grpc.Server
+	listener  net.Listener
+
+	transactions chan grpcServerTransaction
+	shutSig      *shutdown.Signaller
+}
+
+func newGRPCServerInputFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*grpcServerInput, error) {
+	g := &grpcServerInput{
+		methods:      map[string]*desc.MethodDescriptor{},
+		log:          mgr.Logger(),
+		transactions: make(chan grpcServerTransaction),
+		shutSig:      shutdown.NewSignaller(),
+	}
+
+	var err error
+	if g.address, err = conf.FieldString("address"); err != nil {
+		return nil, err
+	}
+	if g.timeout, err = conf.FieldDuration("timeout"); err != nil {
+		return nil, err
+	}
+	if g.certFile, err = conf.FieldString("cert_file"); err != nil {
+		return nil, err
+	}
+	if g.keyFile, err = conf.FieldString("key_file"); err != nil {
+		return nil, err
+	}
+	if (g.certFile == "") != (g.keyFile == "") {
+		return nil, errors.New("both cert_file and key_file must be specified in order to enable TLS")
+	}
+
+	fds, codec, err := loadServiceDescriptors(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+	g.codec = codec
+
+	serviceName, err := conf.FieldString("service")
+	if err != nil {
+		return nil, err
+	}
+	sd, err := findService(fds, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	for _, md := range sd.GetMethods() {
+		if md.IsServerStreaming() {
+			g.log.Debugf("Skipping server streaming method %v", md.GetName())
+			continue
+		}
+		g.methods[fullMethodName(md)] = md
+	}
+	if len(g.methods) == 0 {
+		return nil, fmt.Errorf("service '%v' does not have any unary or client streaming methods", serviceName)
+	}
+	return g, nil
+}
+
+func (g *grpcServerInput) Connect(ctx context.Context) error {
+	g.serverMut.Lock()
+	defer g.serverMut.Unlock()
+
+	if g.server != nil {
+		return nil
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.UnknownServiceHandler(g.handleStream),
+	}
+	if g.certFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(g.certFile, g.keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", g.address)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer(opts...)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			g.log.Errorf("Server error: %v", err)
+		}
+	}()
+
+	g.server, g.listener = server, listener
+	g.log.Infof("Serving gRPC service at: %v", listener.Addr())
+	return nil
+}
+
+func (g *grpcServerInput) readRequests(stream grpc.ServerStream, md *desc.MethodDescriptor) (service.MessageBatch, error) {
+	var batch service.MessageBatch
+	for {
+		req := dynamic.NewMessage(md.GetInputType())
+		err := stream.RecvMsg(req)
+		if errors.Is(err, io.EOF) && md.IsClientStreaming() {
+			return batch, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		jBytes, err := g.codec.toJSON(req)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		batch = append(batch, service.NewMessage(jBytes))
+
+		if !md.IsClientStreaming() {
+			return batch, nil
+		}
+	}
+}
+
+func (g *grpcServerInput) handleStream(srv any, stream grpc.ServerStream) error {
+	fullMethod, _ := grpc.MethodFromServerStream(stream)
+	md, exists := g.methods[fullMethod]
+	if !exists {
+		return status.Errorf(codes.Unimplemented, "method %v is not implemented", fullMethod)
+	}
+
+	batch, err := g.readRequests(stream, md)
+	if err != nil {
+		return err
+	}
+	if len(batch) == 0 {
+		return status.Error(codes.InvalidArgument, "no requests were received")
+	}
+
+	inMeta, _ := metadata.FromIncomingContext(stream.Context())
+	for _, msg := range batch {
+		msg.MetaSetMut("grpc_server_method", fullMethod)
+		for k, v := range inMeta {
+			msg.MetaSetMut(k, strings.Join(v, ","))
+		}
+	}
+
+	store := service.NewSyncResponseStore()
+	batch = batch.WithSyncResponseStore(store)
+
+	ctx, done := context.WithTimeout(stream.Context(), g.timeout)
+	defer done()
+
+	resChan := make(chan error, 1)
+	select {
+	case g.transactions <- grpcServerTransaction{batch: batch, resChan: resChan}:
+	case <-ctx.Done():
+		return status.Error(codes.DeadlineExceeded, "request timed out")
+	case <-g.shutSig.CloseAtLeisureChan():
+		return status.Error(codes.Unavailable, "server closing")
+	}
+
+	select {
+	case res := <-resChan:
+		if res != nil {
+			return status.Error(codes.Internal, res.Error())
+		}
+	case <-ctx.Done():
+		return status.Error(codes.DeadlineExceeded, "request timed out")
+	case <-g.shutSig.CloseNowChan():
+		return status.Error(codes.Unavailable, "server closing")
+	}
+
+	resp, err := g.responseFrom(md, store)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.SendMsg(resp)
+}
+
+func (g *grpcServerInput) responseFrom(md *desc.MethodDescriptor, store *service.SyncResponseStore) (any, error) {
+	for _, resBatch := range store.Read() {
+		if len(resBatch) == 0 {
+			continue
+		}
+		resBytes, err := resBatch[0].AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		return g.codec.fromJSON(md.GetOutputType(), resBytes)
+	}
+	return g.codec.fromJSON(md.GetOutputType(), []byte("{}"))
+}
+
+func (g *grpcServerInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	select {
+	case t := <-g.transactions:
+		return t.batch, func(ctx context.Context, err error) error {
+			t.resChan <- err
+			return nil
+		}, nil
+	case <-g.shutSig.CloseAtLeisureChan():
+		return nil, nil, service.ErrNotConnected
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (g *grpcServerInput) Close(ctx context.Context) error {
+	g.shutSig.CloseNow()
+
+	g.serverMut.Lock()
+	server := g.server
+	g.server = nil
+	g.serverMut.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func grpcClientOutputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.14.0").
+		Summary("Invokes a method of a gRPC service for each message or batch, where messages are converted from JSON into the input type of the method.").
+		Description(clientMethodDescription + `
+
+Responses are discarded, in order to use the responses of a method use the ` + "[`grpc_client` processor](/docs/components/processors/grpc_client)" + ` instead.
+
+### Performance
+
+This output benefits from sending multiple messages in flight in parallel for improved performance. You can tune the max number of in flight messages (or message batches) with the field ` + "`max_in_flight`" + `.
+
+This output benefits from sending messages as a batch for improved performance. Batches can be formed at both the input and output level. You can find out more [in this doc](/docs/configuration/batching).`).
+		Fields(grpcClientFields()...).
+		Field(service.NewIntField("max_in_flight").
+			Description("The maximum number of messages to have in flight at a given time. Increase this to improve throughput.").
+			Default(64)).
+		Field(service.NewBatchPolicyField("batching"))
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"grpc_client", grpcClientOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (output service.BatchOutput, batchPol service.BatchPolicy, maxInFlight int, err error) {
+			if batchPol, err = conf.FieldBatchPolicy("batching"); err != nil {
+				return
+			}
+			if maxInFlight, err = conf.FieldInt("max_in_flight"); err != nil {
+				return
+			}
+			output, err = newGRPCClientOutputFromConfig(conf, mgr)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type grpcClientOutput struct {
+	client *grpcClient
+}
+
+func newGRPCClientOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*grpcClientOutput, error) {
+	client, err := newGRPCClientFromConfig(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcClientOutput{client: client}, nil
+}
+
+func (g *grpcClientOutput) Connect(ctx context.Context) error {
+	return g.client.connect(ctx)
+}
+
+func (g *grpcClientOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	_, errs, err := g.client.invoke(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	var batchErr *service.BatchError
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if batchErr == nil {
+			batchErr = service.NewBatchError(batch, err)
+		}
+		batchErr.Failed(i, err)
+	}
+	if batchErr != nil {
+		return batchErr
+	}
+	return nil
+}
+
+func (g *grpcClientOutput) Close(ctx context.Context) error {
+	return g.client.close()
+}
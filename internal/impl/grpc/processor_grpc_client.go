@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func grpcClientProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Integration").
+		Version("4.14.0").
+		Summary("Invokes a method of a gRPC service for each message or batch, and replaces the messages with the responses of the method as JSON documents.").
+		Description(clientMethodDescription+`
+
+The responses of each call replace the message (or batch) that the call was made for, where each response is a copy of the original message with its contents set to the response converted into a JSON document. Server streaming methods therefore result in a message for each response received, and client streaming methods result in a single message in place of the batch.
+
+If a call fails then the messages it was made for are unchanged and [flagged as failed](/docs/configuration/error_handling), which allows you to handle them with other processors.`).
+		Fields(grpcClientFields()...).
+		Example(
+			"Greeter Service",
+			"Calls the method `SayHello` of the service `helloworld.Greeter` for each message, replacing it with the greeting returned.",
+			`
+pipeline:
+  processors:
+    - grpc_client:
+        address: localhost:50051
+        import_paths: [ ./protos ]
+        method: helloworld.Greeter/SayHello
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"grpc_client", grpcClientProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			return newGRPCClientProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type grpcClientProcessor struct {
+	client *grpcClient
+}
+
+func newGRPCClientProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*grpcClientProcessor, error) {
+	client, err := newGRPCClientFromConfig(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+	// Dialing is non-blocking and therefore doesn't fail when the server is
+	// unavailable.
+	if err := client.connect(context.Background()); err != nil {
+		return nil, err
+	}
+	return &grpcClientProcessor{client: client}, nil
+}
+
+func (g *grpcClientProcessor) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	responses, errs, err := g.client.invoke(ctx, batch)
+	if err != nil {
+		for _, msg := range batch {
+			msg.SetError(err)
+		}
+		return []service.MessageBatch{batch}, nil
+	}
+
+	var resBatch service.MessageBatch
+	for i, msg := range batch {
+		if errs[i] != nil {
+			msg.SetError(errs[i])
+			resBatch = append(resBatch, msg)
+			continue
+		}
+		resBatch = append(resBatch, responses[i]...)
+	}
+	return []service.MessageBatch{resBatch}, nil
+}
+
+func (g *grpcClientProcessor) Close(ctx context.Context) error {
+	return g.client.close()
+}
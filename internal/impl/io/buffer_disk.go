@@ -0,0 +1,840 @@
+package io
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	dbFieldDirectory     = "directory"
+	dbFieldSegmentSize   = "segment_size"
+	dbFieldLimit         = "limit"
+	dbFieldMaxAge        = "max_age"
+	dbFieldSyncPolicy    = "sync_policy"
+	dbFieldSyncInterval  = "sync_interval"
+	dbSyncPolicyAlways   = "always"
+	dbSyncPolicyInterval = "interval"
+	dbSyncPolicyNever    = "never"
+)
+
+func diskBufferConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Summary("Stores messages in a segmented write-ahead log on disk and acknowledges them at the input level.").
+		Description(`
+Messages are appended to segment files within a directory and are consumed in the order they were written. Acknowledgements of consumed messages are also recorded on disk, and a segment is deleted once all of its messages have been acknowledged and it is no longer being written to. When the service is restarted any messages that were written to the log but not yet acknowledged are consumed again.
+
+## Delivery Guarantees
+
+Messages are not acknowledged at the input level until they have been written to the log, and are only marked as delivered once they are successfully sent at the output level. How resilient the log is to a crash of the host (rather than the process) depends on the `+"`sync_policy`"+`, where `+"`always`"+` flushes each write to disk before acknowledging the input. If the tail of a segment is found to be incomplete or corrupt when the buffer starts, for example due to a power loss, the log is truncated at the last complete message.
+
+Messages that are older than `+"`max_age`"+` when they are consumed are dropped rather than delivered, which prevents a long outage from resulting in a flood of stale data.
+
+## Batching
+
+Messages that are logically batched at the point where they are added to the buffer will continue to be associated with that batch when they are consumed.
+
+## Metrics
+
+This buffer emits the gauges `+"`buffer_disk_backlog_bytes`"+` and `+"`buffer_disk_backlog_messages`"+`, which track the size of the messages in the log that have not yet been acknowledged, and the counter `+"`buffer_disk_expired`"+`, which counts messages dropped due to `+"`max_age`"+`.`).
+		Field(service.NewStringField(dbFieldDirectory).
+			Description("The directory in which segment files are stored, which will be created if it does not already exist. Only one buffer should use a given directory at any time.")).
+		Field(service.NewIntField(dbFieldSegmentSize).
+			Description("The maximum size in bytes of a segment file, once a write would exceed this size a new segment is started. Batches larger than this size are rejected.").
+			Default(64*1024*1024)).
+		Field(service.NewIntField(dbFieldLimit).
+			Description("The maximum total size in bytes of messages stored within the log that have not yet been acknowledged. Once this limit is reached back pressure is applied upstream.").
+			Default(1024*1024*1024)).
+		Field(service.NewDurationField(dbFieldMaxAge).
+			Description("An optional maximum age of messages, messages that have been stored for longer than this duration are dropped when they are consumed.").
+			Example("24h").
+			Optional()).
+		Field(service.NewStringAnnotatedEnumField(dbFieldSyncPolicy, map[string]string{
+			dbSyncPolicyAlways:   "Sync the log to disk after each write and acknowledgement, this is the safest and slowest option.",
+			dbSyncPolicyInterval: "Sync the log to disk periodically according to `sync_interval`.",
+			dbSyncPolicyNever:    "Never explicitly sync the log to disk, leaving it to the operating system. Messages survive a crash of the process but may be lost if the host fails.",
+		}).
+			Description("Determines when writes to the log are flushed to disk.").
+			Default(dbSyncPolicyInterval)).
+		Field(service.NewDurationField(dbFieldSyncInterval).
+			Description("The period between syncs of the log to disk when the `sync_policy` is `interval`.").
+			Default("1s")).
+		Example("Surviving upstream outages", "Buffer up to 10GB of data whilst an output is unavailable, and drop anything older than a day.", `
+buffer:
+  disk:
+    directory: /var/lib/benthos/buffer
+    limit: 10737418240
+    max_age: 24h
+`)
+}
+
+func init() {
+	err := service.RegisterBatchBuffer(
+		"disk", diskBufferConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchBuffer, error) {
+			return newDiskBufferFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type diskBufferConfigVals struct {
+	directory    string
+	segmentSize  int64
+	limit        int64
+	maxAge       time.Duration
+	syncPolicy   string
+	syncInterval time.Duration
+}
+
+func newDiskBufferFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*diskBuffer, error) {
+	var c diskBufferConfigVals
+	var err error
+	if c.directory, err = conf.FieldString(dbFieldDirectory); err != nil {
+		return nil, err
+	}
+	var tmpInt int
+	if tmpInt, err = conf.FieldInt(dbFieldSegmentSize); err != nil {
+		return nil, err
+	}
+	c.segmentSize = int64(tmpInt)
+	if tmpInt, err = conf.FieldInt(dbFieldLimit); err != nil {
+		return nil, err
+	}
+	c.limit = int64(tmpInt)
+	if conf.Contains(dbFieldMaxAge) {
+		if c.maxAge, err = conf.FieldDuration(dbFieldMaxAge); err != nil {
+			return nil, err
+		}
+	}
+	if c.syncPolicy, err = conf.FieldString(dbFieldSyncPolicy); err != nil {
+		return nil, err
+	}
+	if c.syncInterval, err = conf.FieldDuration(dbFieldSyncInterval); err != nil {
+		return nil, err
+	}
+	return newDiskBuffer(c, mgr)
+}
+
+//------------------------------------------------------------------------------
+
+// Each record of a segment consists of a header followed by the payload. The
+// header contains the length of the payload and a CRC32 checksum of it, which
+// allows us to detect torn writes.
+const walHeaderLen = 8
+
+var errCorruptRecord = errors.New("corrupt record")
+
+type walRecord struct {
+	id        uint64
+	timestamp time.Time
+	batch     service.MessageBatch
+	size      int64
+	msgs      int64
+}
+
+func encodeWALRecord(id uint64, ts time.Time, batch service.MessageBatch) ([]byte, error) {
+	payload := binary.BigEndian.AppendUint64(nil, id)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(ts.UnixNano()))
+	payload = binary.BigEndian.AppendUint32(payload, uint32(len(batch)))
+	for _, msg := range batch {
+		metaObj := map[string]any{}
+		_ = msg.MetaWalkMut(func(key string, value any) error {
+			metaObj[key] = value
+			return nil
+		})
+		metaBytes, err := msgpack.Marshal(metaObj)
+		if err != nil {
+			return nil, err
+		}
+		msgBytes, err := msg.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(metaBytes)))
+		payload = append(payload, metaBytes...)
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(msgBytes)))
+		payload = append(payload, msgBytes...)
+	}
+
+	record := make([]byte, walHeaderLen, walHeaderLen+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	return append(record, payload...), nil
+}
+
+// readWALRecord reads the next record from a segment, returning io.EOF if the
+// end of the segment is reached cleanly. Records with a payload larger than
+// maxPayload are treated as corrupt. When decodeMsgs is false only the fixed
+// fields of the record are parsed.
+func readWALRecord(r io.Reader, maxPayload int64, decodeMsgs bool) (*walRecord, error) {
+	var header [walHeaderLen]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = errCorruptRecord
+		}
+		return nil, err
+	}
+
+	payloadLen := int64(binary.BigEndian.Uint32(header[0:4]))
+	if payloadLen > maxPayload {
+		return nil, errCorruptRecord
+	}
+
+	payload := make([]byte, payloadLen)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = errCorruptRecord
+		}
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) || len(payload) < 20 {
+		return nil, errCorruptRecord
+	}
+
+	rec := &walRecord{
+		id:        binary.BigEndian.Uint64(payload[0:8]),
+		timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(payload[8:16]))),
+		msgs:      int64(binary.BigEndian.Uint32(payload[16:20])),
+		size:      int64(walHeaderLen + len(payload)),
+	}
+	if !decodeMsgs {
+		return rec, nil
+	}
+
+	b := payload[20:]
+	if rec.msgs > int64(len(b)/8) {
+		// Each message consists of at least two length prefixes.
+		return nil, errCorruptRecord
+	}
+	readChunk := func() ([]byte, error) {
+		if len(b) < 4 {
+			return nil, errCorruptRecord
+		}
+		l := binary.BigEndian.Uint32(b)
+		if uint32(len(b)-4) < l {
+			return nil, errCorruptRecord
+		}
+		chunk := b[4 : 4+l]
+		b = b[4+l:]
+		return chunk, nil
+	}
+
+	rec.batch = make(service.MessageBatch, rec.msgs)
+	for i := range rec.batch {
+		metaBytes, err := readChunk()
+		if err != nil {
+			return nil, err
+		}
+		msgBytes, err := readChunk()
+		if err != nil {
+			return nil, err
+		}
+
+		msg := service.NewMessage(msgBytes)
+		metaObj := map[string]any{}
+		if err := msgpack.Unmarshal(metaBytes, &metaObj); err != nil {
+			return nil, err
+		}
+		for k, v := range metaObj {
+			msg.MetaSetMut(k, v)
+		}
+		rec.batch[i] = msg
+	}
+	return rec, nil
+}
+
+//------------------------------------------------------------------------------
+
+type walSegment struct {
+	seq     uint64
+	walPath string
+	ackPath string
+
+	ackFile *os.File
+
+	// Records are counted as written until they're acknowledged, at which
+	// point they're removed from the live counts.
+	written   int
+	acked     int
+	liveBytes int64
+	liveMsgs  int64
+
+	// IDs of records acknowledged prior to the buffer starting.
+	recoveredAcks map[uint64]struct{}
+}
+
+func newWALSegment(dir string, seq uint64) *walSegment {
+	name := fmt.Sprintf("%020d", seq)
+	return &walSegment{
+		seq:     seq,
+		walPath: filepath.Join(dir, name+".wal"),
+		ackPath: filepath.Join(dir, name+".ack"),
+	}
+}
+
+func (s *walSegment) appendAck(id uint64, sync bool) error {
+	if s.ackFile == nil {
+		var err error
+		if s.ackFile, err = os.OpenFile(s.ackPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+			return err
+		}
+	}
+	if _, err := s.ackFile.Write(binary.BigEndian.AppendUint64(nil, id)); err != nil {
+		return err
+	}
+	if sync {
+		return s.ackFile.Sync()
+	}
+	return nil
+}
+
+func (s *walSegment) remove() error {
+	if s.ackFile != nil {
+		_ = s.ackFile.Close()
+		s.ackFile = nil
+	}
+	if err := os.Remove(s.walPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(s.ackPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+type inFlightRecord struct {
+	rec *walRecord
+	seg *walSegment
+}
+
+type diskBuffer struct {
+	conf diskBufferConfigVals
+	log  *service.Logger
+
+	mBacklogBytes *service.MetricGauge
+	mBacklogMsgs  *service.MetricGauge
+	mExpired      *service.MetricCounter
+
+	cond *sync.Cond
+
+	// Segments that still contain unacknowledged records, the last of which is
+	// being written to.
+	segments    []*walSegment
+	writeFile   *os.File
+	writeOffset int64
+	nextID      uint64
+
+	// The reader consumes segments in order, readIndex is the position of the
+	// segment it's currently consuming within segments.
+	readIndex  int
+	readFile   *os.File
+	readBuf    *bufio.Reader
+	readOffset int64
+	readSize   int64
+
+	requeued []inFlightRecord
+	inFlight int
+
+	dirty      bool
+	endOfInput bool
+	closed     bool
+	closeChan  chan struct{}
+}
+
+func newDiskBuffer(conf diskBufferConfigVals, mgr *service.Resources) (*diskBuffer, error) {
+	if conf.segmentSize <= 0 {
+		return nil, fmt.Errorf("%v must be greater than zero", dbFieldSegmentSize)
+	}
+	if err := os.MkdirAll(conf.directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	d := &diskBuffer{
+		conf:          conf,
+		log:           mgr.Logger(),
+		mBacklogBytes: mgr.Metrics().NewGauge("buffer_disk_backlog_bytes"),
+		mBacklogMsgs:  mgr.Metrics().NewGauge("buffer_disk_backlog_messages"),
+		mExpired:      mgr.Metrics().NewCounter("buffer_disk_expired"),
+		cond:          sync.NewCond(&sync.Mutex{}),
+		closeChan:     make(chan struct{}),
+	}
+	if err := d.recover(); err != nil {
+		d.closeFiles()
+		return nil, err
+	}
+	d.updateMetrics()
+
+	if conf.syncPolicy == dbSyncPolicyInterval {
+		go d.syncLoop()
+	}
+	return d, nil
+}
+
+// recover loads existing segments from the directory, truncating the tail of
+// any segment that was only partially written, and opens a new segment for
+// writing.
+func (d *diskBuffer) recover() error {
+	entries, err := os.ReadDir(d.conf.directory)
+	if err != nil {
+		return err
+	}
+
+	var seqs []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".wal") {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, ".wal"), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	var nextSeq uint64
+	for _, seq := range seqs {
+		seg := newWALSegment(d.conf.directory, seq)
+		if err := d.recoverSegment(seg); err != nil {
+			return fmt.Errorf("failed to recover segment %v: %w", seg.walPath, err)
+		}
+		nextSeq = seq + 1
+		if seg.written == seg.acked {
+			if err := seg.remove(); err != nil {
+				return err
+			}
+			continue
+		}
+		d.segments = append(d.segments, seg)
+	}
+
+	// Writes always go to a fresh segment so that recovered segments remain
+	// immutable.
+	return d.openWriteSegment(nextSeq)
+}
+
+func (d *diskBuffer) recoverSegment(seg *walSegment) error {
+	seg.recoveredAcks = map[uint64]struct{}{}
+	if ackBytes, err := os.ReadFile(seg.ackPath); err == nil {
+		for i := 0; i+8 <= len(ackBytes); i += 8 {
+			seg.recoveredAcks[binary.BigEndian.Uint64(ackBytes[i:])] = struct{}{}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	f, err := os.OpenFile(seg.walPath, os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	r := bufio.NewReader(f)
+	for {
+		rec, err := readWALRecord(r, d.maxRecordPayload(offset, info.Size()), false)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if errors.Is(err, errCorruptRecord) {
+				d.log.Warnf("Truncating segment %v at offset %v due to an incomplete or corrupt record", seg.walPath, offset)
+				if err := f.Truncate(offset); err != nil {
+					return err
+				}
+				break
+			}
+			return err
+		}
+		offset += rec.size
+		if rec.id >= d.nextID {
+			d.nextID = rec.id + 1
+		}
+
+		seg.written++
+		if _, acked := seg.recoveredAcks[rec.id]; acked {
+			seg.acked++
+			continue
+		}
+		seg.liveBytes += rec.size
+		seg.liveMsgs += rec.msgs
+	}
+	return nil
+}
+
+func (d *diskBuffer) openWriteSegment(seq uint64) error {
+	seg := newWALSegment(d.conf.directory, seq)
+	f, err := os.OpenFile(seg.walPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if d.writeFile != nil {
+		if err := d.writeFile.Sync(); err != nil {
+			d.log.Errorf("Failed to sync segment: %v", err)
+		}
+		_ = d.writeFile.Close()
+	}
+	d.writeFile = f
+	d.writeOffset = 0
+	d.segments = append(d.segments, seg)
+	return nil
+}
+
+func (d *diskBuffer) writeSegment() *walSegment {
+	return d.segments[len(d.segments)-1]
+}
+
+func (d *diskBuffer) backlog() (bytes, msgs int64) {
+	for _, s := range d.segments {
+		bytes += s.liveBytes
+		msgs += s.liveMsgs
+	}
+	return
+}
+
+func (d *diskBuffer) updateMetrics() {
+	bytes, msgs := d.backlog()
+	d.mBacklogBytes.Set(bytes)
+	d.mBacklogMsgs.Set(msgs)
+}
+
+func (d *diskBuffer) syncLoop() {
+	ticker := time.NewTicker(d.conf.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.cond.L.Lock()
+			if err := d.syncFiles(); err != nil {
+				d.log.Errorf("Failed to sync disk buffer: %v", err)
+			}
+			d.cond.L.Unlock()
+		case <-d.closeChan:
+			return
+		}
+	}
+}
+
+func (d *diskBuffer) syncFiles() error {
+	if !d.dirty || d.closed {
+		return nil
+	}
+	if err := d.writeFile.Sync(); err != nil {
+		return err
+	}
+	for _, s := range d.segments {
+		if s.ackFile != nil {
+			if err := s.ackFile.Sync(); err != nil {
+				return err
+			}
+		}
+	}
+	d.dirty = false
+	return nil
+}
+
+func (d *diskBuffer) closeFiles() {
+	if d.writeFile != nil {
+		_ = d.writeFile.Sync()
+		_ = d.writeFile.Close()
+		d.writeFile = nil
+	}
+	if d.readFile != nil {
+		_ = d.readFile.Close()
+		d.readFile = nil
+	}
+	for _, s := range d.segments {
+		if s.ackFile != nil {
+			_ = s.ackFile.Sync()
+			_ = s.ackFile.Close()
+			s.ackFile = nil
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// removeFinishedSegments deletes segments that are fully acknowledged, have
+// been fully read and are no longer being written to.
+func (d *diskBuffer) removeFinishedSegments() {
+	for len(d.segments) > 1 && d.readIndex > 0 {
+		seg := d.segments[0]
+		if seg.acked < seg.written {
+			return
+		}
+		if err := seg.remove(); err != nil {
+			d.log.Errorf("Failed to remove segment %v: %v", seg.walPath, err)
+			return
+		}
+		d.segments[0] = nil
+		d.segments = d.segments[1:]
+		d.readIndex--
+	}
+}
+
+// maxRecordPayload returns the largest payload that a record starting at an
+// offset of a segment can have, as records never exceed the segment size nor
+// extend beyond the end of the segment.
+func (d *diskBuffer) maxRecordPayload(offset, segEnd int64) int64 {
+	maxLen := segEnd - offset
+	if maxLen > d.conf.segmentSize {
+		maxLen = d.conf.segmentSize
+	}
+	return maxLen - walHeaderLen
+}
+
+// nextRecord attempts to read the next unacknowledged record from disk,
+// returning nil if no records are available.
+func (d *diskBuffer) nextRecord() (*walRecord, *walSegment, error) {
+	for {
+		seg := d.segments[d.readIndex]
+		if d.readFile == nil {
+			f, err := os.Open(seg.walPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			info, err := f.Stat()
+			if err != nil {
+				_ = f.Close()
+				return nil, nil, err
+			}
+			d.readFile = f
+			d.readBuf = bufio.NewReader(f)
+			d.readOffset = 0
+			d.readSize = info.Size()
+		}
+
+		// Records of the write segment are only read up until the current
+		// write offset in order to avoid observing partial writes.
+		isWriteSeg := d.readIndex == len(d.segments)-1
+		if isWriteSeg && d.readOffset >= d.writeOffset {
+			return nil, nil, nil
+		}
+
+		segEnd := d.readSize
+		if isWriteSeg {
+			segEnd = d.writeOffset
+		}
+		rec, err := readWALRecord(d.readBuf, d.maxRecordPayload(d.readOffset, segEnd), true)
+		if err == nil {
+			d.readOffset += rec.size
+			if _, acked := seg.recoveredAcks[rec.id]; acked {
+				continue
+			}
+			return rec, seg, nil
+		}
+		if !errors.Is(err, io.EOF) {
+			if !errors.Is(err, errCorruptRecord) || isWriteSeg {
+				return nil, nil, err
+			}
+			d.log.Errorf("Skipping the remainder of segment %v due to a corrupt record", seg.walPath)
+		}
+		if isWriteSeg {
+			return nil, nil, nil
+		}
+
+		// Move onto the next segment.
+		_ = d.readFile.Close()
+		d.readFile, d.readBuf = nil, nil
+		seg.recoveredAcks = nil
+		d.readIndex++
+		d.removeFinishedSegments()
+	}
+}
+
+func (d *diskBuffer) ackRecord(rec *walRecord, seg *walSegment) error {
+	if err := seg.appendAck(rec.id, d.conf.syncPolicy == dbSyncPolicyAlways); err != nil {
+		return err
+	}
+	d.dirty = true
+	seg.acked++
+	seg.liveBytes -= rec.size
+	seg.liveMsgs -= rec.msgs
+	d.removeFinishedSegments()
+	d.updateMetrics()
+	return nil
+}
+
+func (d *diskBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+
+	go func() {
+		<-ctx.Done()
+		d.cond.Broadcast()
+	}()
+
+	d.cond.L.Lock()
+	defer d.cond.L.Unlock()
+
+	var next inFlightRecord
+	for {
+		if d.closed {
+			return nil, nil, service.ErrEndOfBuffer
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+
+		if len(d.requeued) > 0 {
+			next = d.requeued[0]
+			d.requeued[0] = inFlightRecord{}
+			d.requeued = d.requeued[1:]
+			break
+		}
+
+		rec, seg, err := d.nextRecord()
+		if err != nil {
+			return nil, nil, err
+		}
+		if rec != nil {
+			if d.conf.maxAge > 0 && time.Since(rec.timestamp) > d.conf.maxAge {
+				d.mExpired.Incr(rec.msgs)
+				if err := d.ackRecord(rec, seg); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			next = inFlightRecord{rec: rec, seg: seg}
+			break
+		}
+
+		if d.endOfInput && d.inFlight == 0 {
+			return nil, nil, service.ErrEndOfBuffer
+		}
+
+		// None of our exit conditions triggered, so exit
+		d.cond.Wait()
+	}
+
+	d.inFlight++
+	return next.rec.batch.Copy(), func(ctx context.Context, err error) error {
+		d.cond.L.Lock()
+		defer d.cond.L.Unlock()
+
+		d.inFlight--
+		defer d.cond.Broadcast()
+
+		if err != nil {
+			d.requeued = append(d.requeued, next)
+			return nil
+		}
+		if d.closed {
+			return component.ErrTypeClosed
+		}
+		return d.ackRecord(next.rec, next.seg)
+	}, nil
+}
+
+func (d *diskBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageBatch, aFn service.AckFunc) error {
+	recBytes, err := encodeWALRecord(0, time.Time{}, msgBatch)
+	if err != nil {
+		return err
+	}
+	if int64(len(recBytes)) > d.conf.limit || int64(len(recBytes)) > d.conf.segmentSize {
+		return component.ErrMessageTooLarge
+	}
+
+	d.cond.L.Lock()
+	defer d.cond.L.Unlock()
+
+	for {
+		if d.closed {
+			return component.ErrTypeClosed
+		}
+		if backlogBytes, _ := d.backlog(); backlogBytes+int64(len(recBytes)) <= d.conf.limit {
+			break
+		}
+		d.cond.Wait()
+	}
+
+	if d.writeOffset > 0 && d.writeOffset+int64(len(recBytes)) > d.conf.segmentSize {
+		if err := d.openWriteSegment(d.writeSegment().seq + 1); err != nil {
+			return err
+		}
+	}
+
+	id := d.nextID
+	if recBytes, err = encodeWALRecord(id, time.Now(), msgBatch); err != nil {
+		return err
+	}
+	if _, err := d.writeFile.Write(recBytes); err != nil {
+		// Attempt to remove a partial write so that the segment remains
+		// readable.
+		_ = d.writeFile.Truncate(d.writeOffset)
+		_, _ = d.writeFile.Seek(d.writeOffset, io.SeekStart)
+		return err
+	}
+	if d.conf.syncPolicy == dbSyncPolicyAlways {
+		if err := d.writeFile.Sync(); err != nil {
+			return err
+		}
+	} else {
+		d.dirty = true
+	}
+	d.nextID++
+
+	seg := d.writeSegment()
+	seg.written++
+	seg.liveBytes += int64(len(recBytes))
+	seg.liveMsgs += int64(len(msgBatch))
+	d.writeOffset += int64(len(recBytes))
+	d.updateMetrics()
+
+	if err := aFn(ctx, nil); err != nil {
+		return err
+	}
+
+	d.cond.Broadcast()
+	return nil
+}
+
+func (d *diskBuffer) EndOfInput() {
+	go func() {
+		d.cond.L.Lock()
+		defer d.cond.L.Unlock()
+
+		d.endOfInput = true
+		d.cond.Broadcast()
+	}()
+}
+
+func (d *diskBuffer) Close(ctx context.Context) error {
+	d.cond.L.Lock()
+	defer d.cond.L.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	close(d.closeChan)
+	d.closeFiles()
+	d.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,253 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func diskBufFromConf(t testing.TB, conf string) *diskBuffer {
+	t.Helper()
+
+	parsedConf, err := diskBufferConfig().ParseYAML(conf, nil)
+	require.NoError(t, err)
+
+	buf, err := newDiskBufferFromConfig(parsedConf, service.MockResources())
+	require.NoError(t, err)
+
+	return buf
+}
+
+func diskBufWrite(t testing.TB, buf *diskBuffer, contents ...string) {
+	t.Helper()
+
+	var batch service.MessageBatch
+	for _, c := range contents {
+		msg := service.NewMessage([]byte(c))
+		msg.MetaSetMut("content", c)
+		batch = append(batch, msg)
+	}
+	require.NoError(t, buf.WriteBatch(context.Background(), batch, func(ctx context.Context, err error) error {
+		return err
+	}))
+}
+
+func diskBufRead(t testing.TB, buf *diskBuffer, expected ...string) service.AckFunc {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	batch, ackFn, err := buf.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, batch, len(expected))
+
+	for i, exp := range expected {
+		mBytes, err := batch[i].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, exp, string(mBytes))
+
+		v, _ := batch[i].MetaGetMut("content")
+		assert.Equal(t, exp, v)
+	}
+	return ackFn
+}
+
+func TestDiskBufferBasic(t *testing.T) {
+	dir := t.TempDir()
+	buf := diskBufFromConf(t, `directory: `+dir)
+
+	diskBufWrite(t, buf, "foo", "bar")
+	diskBufWrite(t, buf, "baz")
+
+	ackA := diskBufRead(t, buf, "foo", "bar")
+	ackB := diskBufRead(t, buf, "baz")
+
+	// Rejected batches are consumed again before new data.
+	require.NoError(t, ackA(context.Background(), errors.New("nope")))
+	diskBufWrite(t, buf, "buz")
+	ackA = diskBufRead(t, buf, "foo", "bar")
+
+	require.NoError(t, ackA(context.Background(), nil))
+	require.NoError(t, ackB(context.Background(), nil))
+	require.NoError(t, diskBufRead(t, buf, "buz")(context.Background(), nil))
+
+	buf.EndOfInput()
+
+	_, _, err := buf.ReadBatch(context.Background())
+	assert.Equal(t, service.ErrEndOfBuffer, err)
+
+	require.NoError(t, buf.Close(context.Background()))
+}
+
+func TestDiskBufferRecovery(t *testing.T) {
+	dir := t.TempDir()
+	buf := diskBufFromConf(t, `
+directory: `+dir+`
+sync_policy: always
+`)
+
+	diskBufWrite(t, buf, "foo")
+	diskBufWrite(t, buf, "bar")
+	diskBufWrite(t, buf, "baz")
+
+	require.NoError(t, diskBufRead(t, buf, "foo")(context.Background(), nil))
+	_ = diskBufRead(t, buf, "bar")
+	require.NoError(t, buf.Close(context.Background()))
+
+	// Simulate a torn write at the end of the segment.
+	segs, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	require.NoError(t, err)
+	require.Len(t, segs, 1)
+
+	f, err := os.OpenFile(segs[0], os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 50, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	buf = diskBufFromConf(t, `directory: `+dir)
+
+	diskBufWrite(t, buf, "buz")
+
+	require.NoError(t, diskBufRead(t, buf, "bar")(context.Background(), nil))
+	require.NoError(t, diskBufRead(t, buf, "baz")(context.Background(), nil))
+	require.NoError(t, diskBufRead(t, buf, "buz")(context.Background(), nil))
+	require.NoError(t, buf.Close(context.Background()))
+
+	// Everything has been acknowledged and so only the empty write segment
+	// should remain after a restart.
+	buf = diskBufFromConf(t, `directory: `+dir)
+	require.NoError(t, buf.Close(context.Background()))
+
+	segs, err = filepath.Glob(filepath.Join(dir, "*.wal"))
+	require.NoError(t, err)
+	assert.Len(t, segs, 1)
+}
+
+func TestDiskBufferSegments(t *testing.T) {
+	dir := t.TempDir()
+	buf := diskBufFromConf(t, `
+directory: `+dir+`
+segment_size: 60
+`)
+
+	// Batches that cannot fit within a segment are rejected.
+	err := buf.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage(make([]byte, 60)),
+	}, func(ctx context.Context, err error) error { return nil })
+	assert.Equal(t, component.ErrMessageTooLarge, err)
+
+	diskBufWrite(t, buf, "foo")
+	diskBufWrite(t, buf, "bar")
+	diskBufWrite(t, buf, "baz")
+
+	segs, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	require.NoError(t, err)
+	assert.Len(t, segs, 3)
+
+	for _, seg := range segs {
+		info, err := os.Stat(seg)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(60))
+	}
+
+	require.NoError(t, diskBufRead(t, buf, "foo")(context.Background(), nil))
+	require.NoError(t, diskBufRead(t, buf, "bar")(context.Background(), nil))
+	ackFn := diskBufRead(t, buf, "baz")
+
+	segs, err = filepath.Glob(filepath.Join(dir, "*.wal"))
+	require.NoError(t, err)
+	assert.Len(t, segs, 1)
+
+	require.NoError(t, ackFn(context.Background(), nil))
+	require.NoError(t, buf.Close(context.Background()))
+}
+
+func TestDiskBufferRecordLengthBounds(t *testing.T) {
+	rec, err := encodeWALRecord(1, time.Now(), service.MessageBatch{service.NewMessage([]byte("foo"))})
+	require.NoError(t, err)
+
+	_, err = readWALRecord(bytes.NewReader(rec), int64(len(rec)-walHeaderLen), true)
+	require.NoError(t, err)
+
+	_, err = readWALRecord(bytes.NewReader(rec), int64(len(rec)-walHeaderLen-1), true)
+	require.ErrorIs(t, err, errCorruptRecord)
+
+	// A corrupt length is rejected before the payload is allocated.
+	binary.BigEndian.PutUint32(rec[0:4], math.MaxUint32)
+	_, err = readWALRecord(bytes.NewReader(rec), 1024, true)
+	require.ErrorIs(t, err, errCorruptRecord)
+}
+
+func TestDiskBufferLimit(t *testing.T) {
+	dir := t.TempDir()
+	buf := diskBufFromConf(t, `
+directory: `+dir+`
+limit: 150
+`)
+
+	err := buf.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage(make([]byte, 200)),
+	}, func(ctx context.Context, err error) error { return nil })
+	assert.Equal(t, component.ErrMessageTooLarge, err)
+
+	diskBufWrite(t, buf, "foo")
+	diskBufWrite(t, buf, "bar")
+
+	writeErr := make(chan error)
+	go func() {
+		writeErr <- buf.WriteBatch(context.Background(), service.MessageBatch{
+			service.NewMessage(make([]byte, 40)),
+		}, func(ctx context.Context, err error) error { return nil })
+	}()
+
+	select {
+	case err := <-writeErr:
+		t.Fatalf("write should be blocked, got: %v", err)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	require.NoError(t, diskBufRead(t, buf, "foo")(context.Background(), nil))
+
+	select {
+	case err := <-writeErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	require.NoError(t, buf.Close(context.Background()))
+}
+
+func TestDiskBufferMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	buf := diskBufFromConf(t, `
+directory: `+dir+`
+max_age: 50ms
+`)
+
+	diskBufWrite(t, buf, "foo")
+	<-time.After(time.Millisecond * 100)
+	diskBufWrite(t, buf, "bar")
+
+	require.NoError(t, diskBufRead(t, buf, "bar")(context.Background(), nil))
+
+	bytes, msgs := buf.backlog()
+	assert.Equal(t, int64(0), bytes)
+	assert.Equal(t, int64(0), msgs)
+
+	require.NoError(t, buf.Close(context.Background()))
+}
@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
@@ -88,6 +89,33 @@ func (f *fileCache) Delete(_ context.Context, key string) error {
 	return f.mgr.FS().Remove(filepath.Join(f.dir, key))
 }
 
+func (f *fileCache) Keys(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := fs.WalkDir(f.mgr.FS(), f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		key, err := filepath.Rel(f.dir, path)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
 func (f *fileCache) Close(context.Context) error {
 	return nil
 }
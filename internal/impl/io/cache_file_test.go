@@ -3,6 +3,7 @@ package io
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,3 +49,27 @@ func TestFileCache(t *testing.T) {
 	_, err = c.Get(tCtx, "foo")
 	assert.Equal(t, service.ErrKeyNotFound, err)
 }
+
+func TestFileCacheKeys(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo"), 0o755))
+
+	tCtx := context.Background()
+	c := newFileCache(dir, service.MockResources())
+
+	require.NoError(t, c.Set(tCtx, "foo/a", []byte("1"), nil))
+	require.NoError(t, c.Set(tCtx, "foo/b", []byte("2"), nil))
+	require.NoError(t, c.Set(tCtx, "bar", []byte("3"), nil))
+
+	keys, err := c.Keys(tCtx, "foo/")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo/a", "foo/b"}, keys)
+
+	keys, err = c.Keys(tCtx, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo/a", "foo/b", "bar"}, keys)
+
+	keys, err = newFileCache(filepath.Join(dir, "nope"), service.MockResources()).Keys(tCtx, "")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
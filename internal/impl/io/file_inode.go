@@ -0,0 +1,18 @@
+//go:build !windows
+
+package io
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileInode returns the inode of a file, which identifies the file regardless
+// of renames.
+func fileInode(info fs.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Ino), true
+}
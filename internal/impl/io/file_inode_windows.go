@@ -0,0 +1,13 @@
+//go:build windows
+
+package io
+
+import (
+	"io/fs"
+)
+
+// fileInode returns the inode of a file, which identifies the file regardless
+// of renames. Inodes are not available on Windows.
+func fileInode(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
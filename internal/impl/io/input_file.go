@@ -20,7 +20,13 @@ import (
 
 func init() {
 	err := bundle.AllInputs.Add(processors.WrapConstructor(func(conf input.Config, nm bundle.NewManagement) (input.Streamed, error) {
-		rdr, err := newFileConsumer(conf.File, nm)
+		var rdr input.Async
+		var err error
+		if conf.File.Follow.Enabled {
+			rdr, err = newFileFollower(conf.File, nm)
+		} else {
+			rdr, err = newFileConsumer(conf.File, nm)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -34,6 +40,17 @@ Consumes data from files on disk, emitting messages according to a chosen codec.
 			codec.ReaderDocs,
 			docs.FieldInt("max_buffer", "The largest token size expected when consuming files with a tokenised codec such as `lines`.").Advanced(),
 			docs.FieldBool("delete_on_finish", "Whether to delete input files from the disk once they are fully consumed.").Advanced(),
+			docs.FieldObject("follow", "Follow the target paths for new files and lines appended to files, rather than consuming each file once. Read more in [Following Files](#following-files).").WithChildren(
+				docs.FieldBool("enabled", "Whether follow mode is enabled."),
+				docs.FieldString("poll_interval", "The interval between each check of the target paths for new, appended, rotated and truncated files.", "100ms", "1s"),
+				docs.FieldString("cache", "An optional [cache resource](/docs/components/caches/about) for storing the position of each file up to which lines have been acknowledged, allowing a restarted input to resume where it left off."),
+			).AtVersion("4.14.0").Advanced(),
+			docs.FieldObject("watcher", "Watch the target paths for new files and consume each of them once they stop changing, rather than finishing once the files present at start up are consumed. Read more in [Watching Directories](#watching-directories).").WithChildren(
+				docs.FieldBool("enabled", "Whether watcher mode is enabled."),
+				docs.FieldString("minimum_age", "The minimum period of time since a file was last updated before attempting to consume it. Increasing this period decreases the likelihood that a file will be consumed whilst it is still being written to.", "10s", "1m", "10m"),
+				docs.FieldString("poll_interval", "The interval between each scan of the target paths for new files when no file system notifications are received.", "100ms", "1s"),
+				docs.FieldString("cache", "A [cache resource](/docs/components/caches/about) for storing the paths of files already consumed."),
+			).AtVersion("4.14.0").Advanced(),
 		).ChildDefaultAndTypesFromStruct(input.NewFileConfig()),
 		Description: `
 ### Metadata
@@ -47,7 +64,40 @@ This input adds the following metadata fields to each message:
 ` + "```" + `
 
 You can access these metadata fields using
-[function interpolation](/docs/configuration/interpolation#bloblang-queries).`,
+[function interpolation](/docs/configuration/interpolation#bloblang-queries).
+
+### Following Files
+
+When ` + "`follow.enabled`" + ` is ` + "`true`" + ` the input does not finish once the target
+files are consumed, instead it continues to check the target paths (including
+glob patterns) for new files and lines appended to existing files, which makes
+it suitable for shipping logs. In this mode files are always consumed line by
+line, and therefore the ` + "`codec`" + ` must be ` + "`lines`" + ` and ` + "`delete_on_finish`" + `
+cannot be enabled.
+
+Rotated files are handled whether they're renamed and replaced by a new file,
+in which case the renamed file is consumed until its end before the new file is
+followed, or truncated in place (copytruncate), in which case the file is
+consumed again from its start.
+
+When a ` + "`follow.cache`" + ` is specified the inode and offset of each file, up to
+which all lines have been acknowledged, are stored in the cache under the path
+of the file. A restarted input then resumes each file from its stored offset,
+provided the path still refers to the same file.
+
+### Watching Directories
+
+When ` + "`watcher.enabled`" + ` is ` + "`true`" + ` the input does not finish once the target
+files are consumed, instead it waits for new files matching the target paths
+(including glob patterns) and consumes each of them with the configured codec.
+New files are detected with file system notifications where supported, with the
+target paths also scanned every ` + "`watcher.poll_interval`" + ` as a fallback.
+
+A file is only consumed once it has not been modified for at least
+` + "`watcher.minimum_age`" + `, and once all messages of a file have been acknowledged
+its path is stored in the ` + "`watcher.cache`" + `, which prevents it from being consumed
+again, including after a restart. Watcher mode cannot be combined with follow
+mode.`,
 		Categories: []string{
 			"Local",
 		},
@@ -60,6 +110,43 @@ input:
   file:
     paths: [ ./data/*.csv ]
     codec: csv
+`,
+			},
+			{
+				Title:   "Ship Logs",
+				Summary: "In order to continuously consume the lines written to a directory of log files, and resume where we left off after a restart, we can enable follow mode with a checkpoint cache:",
+				Config: `
+input:
+  file:
+    paths: [ /var/log/app/*.log ]
+    codec: lines
+    follow:
+      enabled: true
+      cache: checkpoints
+
+cache_resources:
+  - label: checkpoints
+    file:
+      directory: /var/lib/benthos/checkpoints
+`,
+			},
+			{
+				Title:   "Watch a Drop Directory",
+				Summary: "In order to consume gzipped CSV files as they're dropped into a directory, only once they've finished being written to, we can enable watcher mode:",
+				Config: `
+input:
+  file:
+    paths: [ /var/drop/*.csv.gz ]
+    codec: gzip/csv
+    watcher:
+      enabled: true
+      minimum_age: 10s
+      cache: consumed
+
+cache_resources:
+  - label: consumed
+    file:
+      directory: /var/lib/benthos/consumed
 `,
 			},
 		},
@@ -71,6 +158,8 @@ input:
 
 //------------------------------------------------------------------------------
 
+var errNoWatchedFiles = errors.New("no new files found")
+
 type scannerInfo struct {
 	scanner     codec.Reader
 	currentPath string
@@ -87,12 +176,20 @@ type fileConsumer struct {
 	scannerMut  sync.Mutex
 	scannerInfo *scannerInfo
 
-	delete bool
+	delete  bool
+	watcher *fileWatcher
 }
 
 func newFileConsumer(conf input.FileConfig, nm bundle.NewManagement) (*fileConsumer, error) {
-	expandedPaths, err := filepath.Globs(nm.FS(), conf.Paths)
-	if err != nil {
+	var expandedPaths []string
+	var watcher *fileWatcher
+	var err error
+	if conf.Watcher.Enabled {
+		// Paths are expanded by the watcher when the first batch is read.
+		if watcher, err = newFileWatcher(conf, nm); err != nil {
+			return nil, err
+		}
+	} else if expandedPaths, err = filepath.Globs(nm.FS(), conf.Paths); err != nil {
 		return nil, err
 	}
 
@@ -100,6 +197,9 @@ func newFileConsumer(conf input.FileConfig, nm bundle.NewManagement) (*fileConsu
 	codecConf.MaxScanTokenSize = conf.MaxBuffer
 	ctor, err := codec.GetReader(conf.Codec, codecConf)
 	if err != nil {
+		if watcher != nil {
+			_ = watcher.Close()
+		}
 		return nil, err
 	}
 
@@ -109,6 +209,7 @@ func newFileConsumer(conf input.FileConfig, nm bundle.NewManagement) (*fileConsu
 		scannerCtor: ctor,
 		paths:       expandedPaths,
 		delete:      conf.DeleteOnFinish,
+		watcher:     watcher,
 	}, nil
 }
 
@@ -124,6 +225,17 @@ func (f *fileConsumer) getReader(ctx context.Context) (scannerInfo, error) {
 		return *f.scannerInfo, nil
 	}
 
+	if len(f.paths) == 0 && f.watcher != nil {
+		paths, err := f.watcher.scan(ctx)
+		if err != nil {
+			return scannerInfo{}, err
+		}
+		if len(paths) == 0 {
+			return scannerInfo{}, errNoWatchedFiles
+		}
+		f.paths = paths
+	}
+
 	if len(f.paths) == 0 {
 		return scannerInfo{}, component.ErrTypeClosed
 	}
@@ -132,10 +244,21 @@ func (f *fileConsumer) getReader(ctx context.Context) (scannerInfo, error) {
 
 	file, err := f.nm.FS().Open(nextPath)
 	if err != nil {
+		if f.watcher != nil {
+			// The file might have been removed since the scan, in which case it
+			// is skipped.
+			f.paths = f.paths[1:]
+			_ = f.watcher.done(ctx, nextPath, err)
+		}
 		return scannerInfo{}, err
 	}
 
 	scanner, err := f.scannerCtor(nextPath, file, func(ctx context.Context, err error) error {
+		if f.watcher != nil {
+			if werr := f.watcher.done(ctx, nextPath, err); werr != nil {
+				return werr
+			}
+		}
 		if err == nil && f.delete {
 			return f.nm.FS().Remove(nextPath)
 		}
@@ -169,6 +292,10 @@ func (f *fileConsumer) ReadBatch(ctx context.Context) (message.Batch, input.Asyn
 	for {
 		scannerInfo, err := f.getReader(ctx)
 		if err != nil {
+			if errors.Is(err, errNoWatchedFiles) {
+				f.watcher.wait(ctx)
+				err = component.ErrTimeout
+			}
 			return nil, nil, err
 		}
 
@@ -220,5 +347,10 @@ func (f *fileConsumer) Close(ctx context.Context) (err error) {
 		f.scannerInfo = nil
 		f.paths = nil
 	}
+	if f.watcher != nil {
+		if werr := f.watcher.Close(); err == nil {
+			err = werr
+		}
+	}
 	return
 }
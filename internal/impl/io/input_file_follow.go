@@ -0,0 +1,447 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/filepath"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// followCheckpoint is the position within a file up to which all lines have
+// been acknowledged, as stored within the checkpoint cache.
+type followCheckpoint struct {
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+type followPending struct {
+	end  int64
+	done bool
+}
+
+// followSource is an open file being followed, where a new source is created
+// each time a path is rotated or truncated.
+type followSource struct {
+	path       string
+	file       fs.File
+	inode      uint64
+	hasInode   bool
+	modTimeUTC time.Time
+
+	buf       []byte
+	bufOffset int64
+	chunk     []byte
+
+	// Set when the path no longer points to the file of this source, at which
+	// point the source is read until EOF and then replaced.
+	rotated bool
+
+	// Protected by the ackMut of the follower.
+	pending   []*followPending
+	committed int64
+	replaced  bool
+}
+
+// readOffset returns the offset of the file up to which data has been read.
+func (s *followSource) readOffset() int64 {
+	return s.bufOffset + int64(len(s.buf))
+}
+
+// nextLine returns the next complete line of the file along with the offset
+// following it, or io.EOF if a complete line is not yet available. When flush
+// is true any remaining data without a trailing newline is returned as a line.
+func (s *followSource) nextLine(maxBuffer int, flush bool) ([]byte, int64, error) {
+	for {
+		if i := bytes.IndexByte(s.buf, '\n'); i >= 0 {
+			line := append([]byte(nil), s.buf[:i]...)
+			s.buf = s.buf[i+1:]
+			s.bufOffset += int64(i + 1)
+			return line, s.bufOffset, nil
+		}
+		if len(s.buf) > 0 && len(s.buf) >= maxBuffer {
+			line := append([]byte(nil), s.buf...)
+			s.bufOffset += int64(len(s.buf))
+			s.buf = nil
+			return line, s.bufOffset, nil
+		}
+
+		n, err := s.file.Read(s.chunk)
+		s.buf = append(s.buf, s.chunk[:n]...)
+		if n > 0 {
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, 0, err
+		}
+		if flush && len(s.buf) > 0 {
+			line := append([]byte(nil), s.buf...)
+			s.bufOffset += int64(len(s.buf))
+			s.buf = nil
+			return line, s.bufOffset, nil
+		}
+		return nil, 0, io.EOF
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type fileFollower struct {
+	log log.Modular
+	nm  bundle.NewManagement
+
+	patterns     []string
+	maxBuffer    int
+	pollInterval time.Duration
+	cache        string
+
+	sourcesMut sync.Mutex
+	sources    map[string]*followSource
+	order      []string
+	next       int
+	lastScan   time.Time
+
+	ackMut sync.Mutex
+}
+
+func newFileFollower(conf input.FileConfig, nm bundle.NewManagement) (*fileFollower, error) {
+	if conf.Codec != "lines" {
+		return nil, fmt.Errorf("follow mode requires the lines codec, got: %v", conf.Codec)
+	}
+	if conf.DeleteOnFinish {
+		return nil, errors.New("follow mode cannot be combined with delete_on_finish")
+	}
+
+	pollInterval, err := time.ParseDuration(conf.Follow.PollInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse follow poll interval: %w", err)
+	}
+	if conf.Follow.Cache != "" && !nm.ProbeCache(conf.Follow.Cache) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", conf.Follow.Cache)
+	}
+
+	maxBuffer := conf.MaxBuffer
+	if maxBuffer <= 0 {
+		maxBuffer = input.NewFileConfig().MaxBuffer
+	}
+
+	return &fileFollower{
+		log:          nm.Logger(),
+		nm:           nm,
+		patterns:     conf.Paths,
+		maxBuffer:    maxBuffer,
+		pollInterval: pollInterval,
+		cache:        conf.Follow.Cache,
+		sources:      map[string]*followSource{},
+	}, nil
+}
+
+func (f *fileFollower) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (f *fileFollower) readCheckpoint(ctx context.Context, path string) (cp followCheckpoint, exists bool) {
+	if f.cache == "" {
+		return
+	}
+
+	var cpBytes []byte
+	var getErr error
+	if err := f.nm.AccessCache(ctx, f.cache, func(c cache.V1) {
+		cpBytes, getErr = c.Get(ctx, path)
+	}); err != nil {
+		f.log.Errorf("Failed to access checkpoint cache: %v", err)
+		return
+	}
+	if getErr != nil {
+		if !errors.Is(getErr, component.ErrKeyNotFound) {
+			f.log.Errorf("Failed to read checkpoint of file '%v': %v", path, getErr)
+		}
+		return
+	}
+	if err := json.Unmarshal(cpBytes, &cp); err != nil {
+		f.log.Errorf("Failed to parse checkpoint of file '%v': %v", path, err)
+		return
+	}
+	return cp, true
+}
+
+func (f *fileFollower) writeCheckpoint(ctx context.Context, path string, cp followCheckpoint) error {
+	cpBytes, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	var setErr error
+	if err := f.nm.AccessCache(ctx, f.cache, func(c cache.V1) {
+		setErr = c.Set(ctx, path, cpBytes, nil)
+	}); err != nil {
+		return err
+	}
+	return setErr
+}
+
+// openSource opens a path for following, resuming from the checkpoint of the
+// path when it refers to the same file.
+func (f *fileFollower) openSource(ctx context.Context, path string) (*followSource, error) {
+	file, err := f.nm.FS().Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil
+	}
+
+	src := &followSource{
+		path:       path,
+		file:       file,
+		modTimeUTC: info.ModTime().UTC(),
+		chunk:      make([]byte, 32*1024),
+	}
+	src.inode, src.hasInode = fileInode(info)
+
+	if cp, exists := f.readCheckpoint(ctx, path); exists && cp.Inode == src.inode && cp.Offset <= info.Size() {
+		seeker, ok := file.(io.Seeker)
+		if !ok {
+			file.Close()
+			return nil, fmt.Errorf("file '%v' does not support seeking", path)
+		}
+		if _, err := seeker.Seek(cp.Offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+		src.bufOffset, src.committed = cp.Offset, cp.Offset
+		f.log.Infof("Resuming file '%v' from offset %v\n", path, cp.Offset)
+	} else {
+		f.log.Infof("Following file '%v'\n", path)
+	}
+	return src, nil
+}
+
+// truncate handles a file that has been truncated in place by continuing to
+// follow it from the start with a new source.
+func (f *fileFollower) truncate(src *followSource) error {
+	seeker, ok := src.file.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("file '%v' does not support seeking", src.path)
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	f.ackMut.Lock()
+	src.replaced = true
+	f.ackMut.Unlock()
+
+	f.sources[src.path] = &followSource{
+		path:       src.path,
+		file:       src.file,
+		inode:      src.inode,
+		hasInode:   src.hasInode,
+		modTimeUTC: src.modTimeUTC,
+		chunk:      src.chunk,
+	}
+	f.log.Infof("File '%v' was truncated, following from the start\n", src.path)
+	return nil
+}
+
+// remove stops following the file of a source.
+func (f *fileFollower) remove(src *followSource) {
+	f.ackMut.Lock()
+	src.replaced = true
+	f.ackMut.Unlock()
+
+	src.file.Close()
+	delete(f.sources, src.path)
+	f.resetOrder()
+}
+
+func (f *fileFollower) resetOrder() {
+	f.order = f.order[:0]
+	for path := range f.sources {
+		f.order = append(f.order, path)
+	}
+	sort.Strings(f.order)
+}
+
+// scan expands the target paths in order to find new files, and checks the
+// paths of existing sources for rotation and truncation.
+func (f *fileFollower) scan(ctx context.Context) {
+	paths, err := filepath.Globs(f.nm.FS(), f.patterns)
+	if err != nil {
+		f.log.Errorf("Failed to expand paths: %v", err)
+		return
+	}
+
+	added := false
+	for _, path := range paths {
+		if _, exists := f.sources[path]; exists {
+			continue
+		}
+		src, err := f.openSource(ctx, path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				f.log.Errorf("Failed to open file '%v': %v", path, err)
+			}
+			continue
+		}
+		if src != nil {
+			f.sources[path] = src
+			added = true
+		}
+	}
+	if added {
+		f.resetOrder()
+	}
+
+	for path, src := range f.sources {
+		if src.rotated {
+			continue
+		}
+		info, err := f.nm.FS().Stat(path)
+		if err != nil {
+			// The file might have been renamed with a new file yet to be
+			// created, in which case we continue reading the renamed file.
+			continue
+		}
+		if inode, ok := fileInode(info); ok && src.hasInode && inode != src.inode {
+			src.rotated = true
+			continue
+		}
+		if info.Size() < src.readOffset() {
+			if err := f.truncate(src); err != nil {
+				f.log.Errorf("Failed to follow truncated file '%v': %v", path, err)
+				f.remove(src)
+			}
+		}
+	}
+}
+
+// readLine attempts to read the next line of any followed file, returning a
+// nil source if no complete lines are currently available.
+func (f *fileFollower) readLine() (*followSource, []byte, *followPending) {
+	order := append([]string(nil), f.order...)
+	for i := 0; i < len(order); i++ {
+		idx := (f.next + i) % len(order)
+		src, exists := f.sources[order[idx]]
+		if !exists {
+			continue
+		}
+
+		for {
+			line, end, err := src.nextLine(f.maxBuffer, src.rotated)
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					f.log.Errorf("Failed to read file '%v': %v", src.path, err)
+					f.remove(src)
+				} else if src.rotated {
+					// The rotated file has been fully read, and so the path is
+					// opened afresh during the next scan.
+					f.log.Infof("File '%v' was rotated\n", src.path)
+					f.remove(src)
+				}
+				break
+			}
+
+			// Empty lines are skipped but must still be tracked in order for
+			// the offsets of subsequent lines to be committed.
+			p := &followPending{end: end, done: len(line) == 0}
+			f.ackMut.Lock()
+			src.pending = append(src.pending, p)
+			f.ackMut.Unlock()
+
+			if len(line) > 0 {
+				f.next = idx + 1
+				return src, line, p
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+func (f *fileFollower) ack(ctx context.Context, src *followSource, p *followPending) error {
+	f.ackMut.Lock()
+	defer f.ackMut.Unlock()
+
+	p.done = true
+
+	advanced := false
+	for len(src.pending) > 0 && src.pending[0].done {
+		src.committed = src.pending[0].end
+		src.pending = src.pending[1:]
+		advanced = true
+	}
+	if !advanced || src.replaced || f.cache == "" {
+		return nil
+	}
+	return f.writeCheckpoint(ctx, src.path, followCheckpoint{
+		Inode:  src.inode,
+		Offset: src.committed,
+	})
+}
+
+func (f *fileFollower) ReadBatch(ctx context.Context) (message.Batch, input.AsyncAckFn, error) {
+	f.sourcesMut.Lock()
+	if f.sources == nil {
+		f.sourcesMut.Unlock()
+		return nil, nil, component.ErrTypeClosed
+	}
+	if time.Since(f.lastScan) >= f.pollInterval {
+		f.scan(ctx)
+		f.lastScan = time.Now()
+	}
+	src, line, p := f.readLine()
+	f.sourcesMut.Unlock()
+
+	if src == nil {
+		select {
+		case <-time.After(f.pollInterval):
+		case <-ctx.Done():
+		}
+		return nil, nil, component.ErrTimeout
+	}
+
+	part := message.NewPart(line)
+	part.MetaSetMut("path", src.path)
+	part.MetaSetMut("mod_time_unix", src.modTimeUTC.Unix())
+	part.MetaSetMut("mod_time", src.modTimeUTC.Format(time.RFC3339))
+
+	return message.Batch{part}, func(rctx context.Context, res error) error {
+		if res != nil {
+			return nil
+		}
+		return f.ack(rctx, src, p)
+	}, nil
+}
+
+func (f *fileFollower) Close(ctx context.Context) error {
+	f.sourcesMut.Lock()
+	defer f.sourcesMut.Unlock()
+
+	for _, src := range f.sources {
+		src.file.Close()
+	}
+	f.sources = nil
+	f.order = nil
+	return nil
+}
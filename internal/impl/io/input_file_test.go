@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
 	"github.com/benthosdev/benthos/v4/internal/message"
@@ -111,3 +113,184 @@ func assertValidMetaData(t *testing.T, res *message.Part, tmpFile *os.File) {
 func mockTime() time.Time {
 	return time.Date(2015, 8, 25, 23, 23, 0, 0, time.UTC)
 }
+
+func readFollowLine(t *testing.T, i input.Streamed) string {
+	t.Helper()
+
+	var tran message.Transaction
+	var open bool
+	select {
+	case tran, open = <-i.TransactionChan():
+		require.True(t, open)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(context.Background(), nil))
+	return string(tran.Payload.Get(0).AsBytes())
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestFileFollow(t *testing.T) {
+	tmpDir := t.TempDir()
+	logPath := filepath.Join(tmpDir, "app.log")
+
+	appendFile(t, logPath, "foo\nbar\n")
+
+	conf := input.NewConfig()
+	conf.Type = "file"
+	conf.File.Paths = []string{filepath.Join(tmpDir, "*.log")}
+	conf.File.Follow.Enabled = true
+	conf.File.Follow.PollInterval = "10ms"
+	conf.File.Follow.Cache = "checkpoints"
+
+	mgr := mock.NewManager()
+	mgr.Caches["checkpoints"] = map[string]mock.CacheItem{}
+
+	i, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, "foo", readFollowLine(t, i))
+	assert.Equal(t, "bar", readFollowLine(t, i))
+
+	// Appended lines are picked up, including a partial line once completed.
+	appendFile(t, logPath, "baz\nbu")
+	assert.Equal(t, "baz", readFollowLine(t, i))
+	appendFile(t, logPath, "z\n")
+	assert.Equal(t, "buz", readFollowLine(t, i))
+
+	// New files matching the pattern are followed.
+	appendFile(t, filepath.Join(tmpDir, "other.log"), "other\n")
+	assert.Equal(t, "other", readFollowLine(t, i))
+
+	// Rotation by rename drains the old file before following the new one.
+	appendFile(t, logPath, "before rotate\n")
+	require.NoError(t, os.Rename(logPath, logPath+".1"))
+	appendFile(t, logPath+".1", "after rename\n")
+	appendFile(t, logPath, "rotated\n")
+	assert.Equal(t, "before rotate", readFollowLine(t, i))
+	assert.Equal(t, "after rename", readFollowLine(t, i))
+	assert.Equal(t, "rotated", readFollowLine(t, i))
+
+	// Truncation in place restarts from the beginning of the file.
+	require.NoError(t, os.Truncate(logPath, 0))
+	time.Sleep(time.Millisecond * 100)
+	appendFile(t, logPath, "new\n")
+	assert.Equal(t, "new", readFollowLine(t, i))
+
+	i.TriggerStopConsuming()
+	require.NoError(t, i.WaitForClose(context.Background()))
+
+	// A new input resumes from the checkpoints.
+	appendFile(t, logPath, "resumed\n")
+
+	i, err = mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, "resumed", readFollowLine(t, i))
+
+	i.TriggerStopConsuming()
+	require.NoError(t, i.WaitForClose(context.Background()))
+}
+
+func TestFileFollowBadConfig(t *testing.T) {
+	conf := input.NewConfig()
+	conf.Type = "file"
+	conf.File.Paths = []string{"./foo.log"}
+	conf.File.Codec = "all-bytes"
+	conf.File.Follow.Enabled = true
+
+	_, err := mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "follow mode requires the lines codec")
+
+	conf.File.Codec = "lines"
+	conf.File.Follow.Cache = "nope"
+
+	_, err = mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache resource 'nope' was not found")
+}
+
+func TestFileWatcher(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oldPath := filepath.Join(tmpDir, "old.csv")
+	require.NoError(t, os.WriteFile(oldPath, []byte("id,name\n1,foo\n"), 0o644))
+	require.NoError(t, os.Chtimes(oldPath, mockTime(), mockTime()))
+
+	conf := input.NewConfig()
+	conf.Type = "file"
+	conf.File.Paths = []string{filepath.Join(tmpDir, "*.csv")}
+	conf.File.Codec = "csv"
+	conf.File.Watcher.Enabled = true
+	conf.File.Watcher.MinimumAge = "200ms"
+	conf.File.Watcher.PollInterval = "50ms"
+	conf.File.Watcher.Cache = "consumed"
+
+	mgr := mock.NewManager()
+	mgr.Caches["consumed"] = map[string]mock.CacheItem{}
+
+	i, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"id":"1","name":"foo"}`, readFollowLine(t, i))
+
+	// A new file is only consumed once it reaches the minimum age.
+	newPath := filepath.Join(tmpDir, "new.csv")
+	require.NoError(t, os.WriteFile(newPath, []byte("id,name\n2,bar\n"), 0o644))
+	written := time.Now()
+
+	assert.Equal(t, `{"id":"2","name":"bar"}`, readFollowLine(t, i))
+	assert.GreaterOrEqual(t, time.Since(written), time.Millisecond*150)
+
+	assert.Eventually(t, func() bool {
+		var oldErr, newErr error
+		require.NoError(t, mgr.AccessCache(context.Background(), "consumed", func(c cache.V1) {
+			_, oldErr = c.Get(context.Background(), oldPath)
+			_, newErr = c.Get(context.Background(), newPath)
+		}))
+		return oldErr == nil && newErr == nil
+	}, time.Second, time.Millisecond*10)
+
+	i.TriggerStopConsuming()
+	require.NoError(t, i.WaitForClose(context.Background()))
+
+	// Files recorded in the cache are not consumed again after a restart.
+	i, err = mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	lastPath := filepath.Join(tmpDir, "last.csv")
+	require.NoError(t, os.WriteFile(lastPath, []byte("id,name\n3,baz\n"), 0o644))
+	require.NoError(t, os.Chtimes(lastPath, mockTime(), mockTime()))
+
+	assert.Equal(t, `{"id":"3","name":"baz"}`, readFollowLine(t, i))
+
+	i.TriggerStopConsuming()
+	require.NoError(t, i.WaitForClose(context.Background()))
+}
+
+func TestFileWatcherBadConfig(t *testing.T) {
+	conf := input.NewConfig()
+	conf.Type = "file"
+	conf.File.Paths = []string{"./foo.csv"}
+	conf.File.Watcher.Enabled = true
+
+	_, err := mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a cache must be specified when watcher mode is enabled")
+
+	conf.File.Watcher.Cache = "nope"
+
+	_, err = mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache resource 'nope' was not found")
+}
@@ -0,0 +1,256 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	ifilepath "github.com/benthosdev/benthos/v4/internal/filepath"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+// fileWatcher finds new files matching the target paths of a file input, where
+// files are consumed only once they reach a minimum age and are recorded within
+// a cache once fully consumed.
+type fileWatcher struct {
+	log log.Modular
+	nm  bundle.NewManagement
+
+	patterns     []string
+	pollInterval time.Duration
+	minAge       time.Duration
+	cache        string
+
+	// When nil, due to the filesystem not being the OS or notifications not
+	// being supported, new files are found by polling alone.
+	notify  *fsnotify.Watcher
+	watched map[string]struct{}
+
+	mut          sync.Mutex
+	consuming    map[string]struct{}
+	nextEligible time.Time
+}
+
+func newFileWatcher(conf input.FileConfig, nm bundle.NewManagement) (*fileWatcher, error) {
+	if conf.Follow.Enabled {
+		return nil, errors.New("watcher mode cannot be combined with follow mode")
+	}
+
+	pollInterval, err := time.ParseDuration(conf.Watcher.PollInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse watcher poll interval: %w", err)
+	}
+
+	minAge, err := time.ParseDuration(conf.Watcher.MinimumAge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse watcher minimum age: %w", err)
+	}
+
+	if conf.Watcher.Cache == "" {
+		return nil, errors.New("a cache must be specified when watcher mode is enabled")
+	}
+
+	if !nm.ProbeCache(conf.Watcher.Cache) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", conf.Watcher.Cache)
+	}
+
+	w := &fileWatcher{
+		log:          nm.Logger(),
+		nm:           nm,
+		patterns:     conf.Paths,
+		pollInterval: pollInterval,
+		minAge:       minAge,
+		cache:        conf.Watcher.Cache,
+		watched:      map[string]struct{}{},
+		consuming:    map[string]struct{}{},
+	}
+
+	if ifs.IsOS(nm.FS()) {
+		if w.notify, err = fsnotify.NewWatcher(); err != nil {
+			w.log.Warnf("Failed to create file system notifier, falling back to polling: %v\n", err)
+			w.notify = nil
+		}
+	}
+	if w.notify != nil {
+		for _, p := range conf.Paths {
+			w.watchDir(patternDir(p))
+		}
+	}
+	return w, nil
+}
+
+// patternDir returns the deepest directory of a path that precedes any glob
+// patterns.
+func patternDir(pattern string) string {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	for i, s := range segments {
+		if strings.ContainsAny(s, `*?[`) {
+			if i == 0 {
+				return "."
+			}
+			return filepath.FromSlash(strings.Join(segments[:i], "/"))
+		}
+	}
+	return filepath.Dir(pattern)
+}
+
+func (w *fileWatcher) watchDir(dir string) {
+	if dir == "" {
+		dir = "."
+	}
+	if _, exists := w.watched[dir]; exists {
+		return
+	}
+	if err := w.notify.Add(dir); err != nil {
+		w.log.Debugf("Unable to watch directory '%v', relying on polling: %v\n", dir, err)
+		return
+	}
+	w.watched[dir] = struct{}{}
+}
+
+// scan returns the paths of files that match the target paths, have reached
+// the minimum age, and are neither recorded in the cache nor currently being
+// consumed.
+func (w *fileWatcher) scan(ctx context.Context) ([]string, error) {
+	paths, err := ifilepath.Globs(w.nm.FS(), w.patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	var nextEligible time.Time
+	var newPaths []string
+	if cerr := w.nm.AccessCache(ctx, w.cache, func(c cache.V1) {
+		for _, path := range paths {
+			if _, exists := w.consuming[path]; exists {
+				continue
+			}
+
+			info, err := w.nm.FS().Stat(path)
+			if err != nil {
+				w.log.Warnf("Failed to stat path %v: %v\n", path, err)
+				continue
+			}
+			if info.IsDir() {
+				continue
+			}
+			if w.notify != nil {
+				// Super globs can match files within directories created after
+				// the input started.
+				w.watchDir(filepath.Dir(path))
+			}
+
+			if _, err := c.Get(ctx, path); err == nil {
+				if err = c.Set(ctx, path, []byte("@"), nil); err != nil { // Reset the TTL for the path
+					w.log.Warnf("Failed to set key in cache for path %v: %v\n", path, err)
+				}
+				continue
+			}
+
+			if eligible := info.ModTime().Add(w.minAge); time.Now().Before(eligible) {
+				if nextEligible.IsZero() || eligible.Before(nextEligible) {
+					nextEligible = eligible
+				}
+				continue
+			}
+
+			w.consuming[path] = struct{}{}
+			newPaths = append(newPaths, path)
+		}
+	}); cerr != nil {
+		return nil, fmt.Errorf("failed to access cache for file watcher mode: %w", cerr)
+	}
+
+	w.nextEligible = nextEligible
+	return newPaths, nil
+}
+
+// wait blocks until either a file system notification is received, a file
+// skipped during the last scan reaches the minimum age, or the poll interval
+// elapses.
+func (w *fileWatcher) wait(ctx context.Context) {
+	w.mut.Lock()
+	waitFor := w.pollInterval
+	if !w.nextEligible.IsZero() {
+		if untilEligible := time.Until(w.nextEligible); untilEligible < waitFor {
+			waitFor = untilEligible
+		}
+	}
+	w.mut.Unlock()
+
+	timer := time.NewTimer(waitFor)
+	defer timer.Stop()
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if w.notify != nil {
+		events, errs = w.notify.Events, w.notify.Errors
+	}
+
+	for {
+		select {
+		case event, open := <-events:
+			if !open {
+				events = nil
+				continue
+			}
+			// Writes are ignored as files are only consumed once they stop
+			// changing, which is tracked by the eligibility of the last scan.
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				return
+			}
+		case err, open := <-errs:
+			if !open {
+				errs = nil
+				continue
+			}
+			w.log.Warnf("File system notifier error: %v\n", err)
+		case <-timer.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// done is called once all messages of a file have been acknowledged, and
+// records the path of a successfully consumed file in the cache.
+func (w *fileWatcher) done(ctx context.Context, path string, res error) error {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	delete(w.consuming, path)
+	if res != nil {
+		return nil
+	}
+
+	var setErr error
+	if cerr := w.nm.AccessCache(ctx, w.cache, func(c cache.V1) {
+		setErr = c.Set(ctx, path, []byte("@"), nil)
+	}); cerr != nil {
+		return fmt.Errorf("failed to access cache for file watcher mode: %w", cerr)
+	}
+	if setErr != nil {
+		return fmt.Errorf("failed to update path in cache %s: %w", path, setErr)
+	}
+	return nil
+}
+
+func (w *fileWatcher) Close() error {
+	if w.notify != nil {
+		return w.notify.Close()
+	}
+	return nil
+}
@@ -51,6 +51,8 @@ If the ` + "`address`" + ` config field is left blank the [service-wide HTTP ser
 
 The field ` + "`rate_limit`" + ` allows you to specify an optional ` + "[`rate_limit` resource](/docs/components/rate_limits/about)" + `, which will be applied to each HTTP request made and each websocket payload received.
 
+The field ` + "`rate_limit_key`" + ` can be used in order to access the rate limit with a key derived from each request, such as a header identifying the customer, which allows rate limits that support keyed access, such as the ` + "[`token_bucket` rate limit](/docs/components/rate_limits/token_bucket)" + `, to throttle each key separately. For HTTP requests the key is resolved against the request metadata only, whereas for websocket payloads the payload contents are also available.
+
 When the rate limit is breached HTTP requests will have a 429 response returned with a Retry-After header. Websocket payloads will be dropped and an optional response payload will be sent as per ` + "`ws_rate_limit_message`" + `.
 
 ### Responses
@@ -105,6 +107,7 @@ You can access these metadata fields using [function interpolation](/docs/config
 			docs.FieldString("allowed_verbs", "An array of verbs that are allowed for the `path` endpoint.").AtVersion("3.33.0").Array(),
 			docs.FieldString("timeout", "Timeout for requests. If a consumed messages takes longer than this to be delivered the connection is closed, but the message may still be delivered."),
 			docs.FieldString("rate_limit", "An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by."),
+			docs.FieldString("rate_limit_key", "An optional key to access the `rate_limit` with, allowing requests to be throttled separately for each unique key when the rate limit supports it. Can be resolved from request metadata such as headers and query parameters.", `${! meta("X-Customer-Id") }`, `${! meta("api_key") }`).IsInterpolated().AtVersion("4.14.0").Advanced(),
 			docs.FieldString("cert_file", "Enable TLS by specifying a certificate and key file. Only valid with a custom `address`.").Advanced(),
 			docs.FieldString("key_file", "Enable TLS by specifying a certificate and key file. Only valid with a custom `address`.").Advanced(),
 			corsSpec,
@@ -142,6 +145,7 @@ type httpServerInput struct {
 	server  *http.Server
 	timeout time.Duration
 
+	rateLimitKey    *field.Expression
 	responseStatus  *field.Expression
 	responseHeaders map[string]*field.Expression
 	metaFilter      *imetadata.IncludeFilter
@@ -205,6 +209,11 @@ func newHTTPServerInput(conf input.Config, mgr bundle.NewManagement) (input.Stre
 		mPostRcvd: mRcvd,
 	}
 
+	if h.conf.RateLimitKey != "" {
+		if h.rateLimitKey, err = mgr.BloblEnvironment().NewField(h.conf.RateLimitKey); err != nil {
+			return nil, fmt.Errorf("failed to parse rate limit key expression: %v", err)
+		}
+	}
 	if h.responseStatus, err = mgr.BloblEnvironment().NewField(h.conf.Response.Status); err != nil {
 		return nil, fmt.Errorf("failed to parse response status expression: %v", err)
 	}
@@ -252,6 +261,51 @@ func newHTTPServerInput(conf input.Config, mgr bundle.NewManagement) (input.Stre
 
 //------------------------------------------------------------------------------
 
+// setRequestMetadata adds metadata extracted from an HTTP request to a message.
+func setRequestMetadata(p *message.Part, r *http.Request) {
+	p.MetaSetMut("http_server_user_agent", r.UserAgent())
+	p.MetaSetMut("http_server_request_path", r.URL.Path)
+	p.MetaSetMut("http_server_verb", r.Method)
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		p.MetaSetMut("http_server_remote_ip", host)
+	}
+
+	if r.TLS != nil {
+		var tlsVersion string
+		switch r.TLS.Version {
+		case tls.VersionTLS10:
+			tlsVersion = "TLSv1.0"
+		case tls.VersionTLS11:
+			tlsVersion = "TLSv1.1"
+		case tls.VersionTLS12:
+			tlsVersion = "TLSv1.2"
+		case tls.VersionTLS13:
+			tlsVersion = "TLSv1.3"
+		}
+		p.MetaSetMut("http_server_tls_version", tlsVersion)
+		if len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			p.MetaSetMut("http_server_tls_subject", r.TLS.VerifiedChains[0][0].Subject.String())
+		}
+		p.MetaSetMut("http_server_tls_cipher_suite", tls.CipherSuiteName(r.TLS.CipherSuite))
+	}
+	for k, v := range r.Header {
+		if len(v) > 0 {
+			p.MetaSetMut(k, v[0])
+		}
+	}
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			p.MetaSetMut(k, v[0])
+		}
+	}
+	for k, v := range mux.Vars(r) {
+		p.MetaSetMut(k, v)
+	}
+	for _, c := range r.Cookies() {
+		p.MetaSetMut(c.Name, c.Value)
+	}
+}
+
 func (h *httpServerInput) extractMessageFromRequest(r *http.Request) (message.Batch, error) {
 	msg := message.QuickBatch(nil)
 
@@ -290,47 +344,7 @@ func (h *httpServerInput) extractMessageFromRequest(r *http.Request) (message.Ba
 	}
 
 	_ = msg.Iter(func(i int, p *message.Part) error {
-		p.MetaSetMut("http_server_user_agent", r.UserAgent())
-		p.MetaSetMut("http_server_request_path", r.URL.Path)
-		p.MetaSetMut("http_server_verb", r.Method)
-		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
-			p.MetaSetMut("http_server_remote_ip", host)
-		}
-
-		if r.TLS != nil {
-			var tlsVersion string
-			switch r.TLS.Version {
-			case tls.VersionTLS10:
-				tlsVersion = "TLSv1.0"
-			case tls.VersionTLS11:
-				tlsVersion = "TLSv1.1"
-			case tls.VersionTLS12:
-				tlsVersion = "TLSv1.2"
-			case tls.VersionTLS13:
-				tlsVersion = "TLSv1.3"
-			}
-			p.MetaSetMut("http_server_tls_version", tlsVersion)
-			if len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
-				p.MetaSetMut("http_server_tls_subject", r.TLS.VerifiedChains[0][0].Subject.String())
-			}
-			p.MetaSetMut("http_server_tls_cipher_suite", tls.CipherSuiteName(r.TLS.CipherSuite))
-		}
-		for k, v := range r.Header {
-			if len(v) > 0 {
-				p.MetaSetMut(k, v[0])
-			}
-		}
-		for k, v := range r.URL.Query() {
-			if len(v) > 0 {
-				p.MetaSetMut(k, v[0])
-			}
-		}
-		for k, v := range mux.Vars(r) {
-			p.MetaSetMut(k, v)
-		}
-		for _, c := range r.Cookies() {
-			p.MetaSetMut(c.Name, c.Value)
-		}
+		setRequestMetadata(p, r)
 		return nil
 	})
 
@@ -345,6 +359,27 @@ func (h *httpServerInput) extractMessageFromRequest(r *http.Request) (message.Ba
 	return msg, nil
 }
 
+// accessRateLimit accesses the configured rate limit, using a key resolved
+// against the provided message when a rate limit key is configured.
+func (h *httpServerInput) accessRateLimit(ctx context.Context, msg message.Batch) (tUntil time.Duration, err error) {
+	var key string
+	if h.rateLimitKey != nil {
+		if key, err = h.rateLimitKey.String(0, msg); err != nil {
+			return 0, fmt.Errorf("rate limit key interpolation error: %w", err)
+		}
+	}
+	if rerr := h.mgr.AccessRateLimit(ctx, h.conf.RateLimit, func(rl ratelimit.V1) {
+		if h.rateLimitKey != nil {
+			tUntil, err = ratelimit.AccessKey(ctx, rl, key)
+		} else {
+			tUntil, err = rl.Access(ctx)
+		}
+	}); rerr != nil {
+		return 0, rerr
+	}
+	return
+}
+
 func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 	h.handlerWG.Add(1)
 	defer h.handlerWG.Done()
@@ -356,15 +391,12 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if h.conf.RateLimit != "" {
-		var tUntil time.Duration
-		var err error
-		if rerr := h.mgr.AccessRateLimit(r.Context(), h.conf.RateLimit, func(rl ratelimit.V1) {
-			tUntil, err = rl.Access(r.Context())
-		}); rerr != nil {
-			http.Error(w, "Server error", http.StatusBadGateway)
-			h.log.Warnf("Failed to access rate limit: %v\n", rerr)
-			return
-		}
+		// The body has not yet been read and so the key is resolved against
+		// the request metadata only.
+		keyPart := message.NewPart(nil)
+		setRequestMetadata(keyPart, r)
+
+		tUntil, err := h.accessRateLimit(r.Context(), message.Batch{keyPart})
 		if err != nil {
 			http.Error(w, "Server error", http.StatusBadGateway)
 			h.log.Warnf("Failed to access rate limit: %v\n", err)
@@ -559,13 +591,11 @@ func (h *httpServerInput) wsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if h.conf.RateLimit != "" {
+			keyPart := message.NewPart(msgBytes)
+			setRequestMetadata(keyPart, r)
+
 			var tUntil time.Duration
-			if rerr := h.mgr.AccessRateLimit(r.Context(), h.conf.RateLimit, func(rl ratelimit.V1) {
-				tUntil, err = rl.Access(r.Context())
-			}); rerr != nil {
-				h.log.Warnf("Failed to access rate limit: %v\n", rerr)
-				err = rerr
-			}
+			tUntil, err = h.accessRateLimit(r.Context(), message.Batch{keyPart})
 			if err != nil || tUntil > 0 {
 				if err != nil {
 					h.log.Warnf("Failed to access rate limit: %v\n", err)
@@ -762,6 +762,73 @@ rate_limit_resources:
 	}
 }
 
+func TestHTTPRateLimitKeyed(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+
+	mgrConf := manager.NewResourceConfig()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+rate_limit_resources:
+  - label: foorl
+    token_bucket:
+      burst: 1
+      refill_rate: 0.001
+`), &mgrConf))
+
+	mgr, err := manager.New(mgrConf, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := input.NewConfig()
+	conf.Type = "http_server"
+	conf.HTTPServer.Path = "/testpost"
+	conf.HTTPServer.RateLimit = "foorl"
+	conf.HTTPServer.RateLimitKey = `${! meta("X-Customer") }`
+
+	h, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			var ts message.Transaction
+			select {
+			case ts = <-h.TransactionChan():
+			case <-time.After(time.Second):
+				t.Error("Timed out waiting for message")
+				return
+			}
+			require.NoError(t, ts.Ack(tCtx, nil))
+		}
+	}()
+
+	for _, test := range []struct {
+		customer string
+		status   int
+	}{
+		{customer: "foo", status: http.StatusOK},
+		{customer: "foo", status: http.StatusTooManyRequests},
+		{customer: "bar", status: http.StatusOK},
+	} {
+		req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewBuffer([]byte("hello world")))
+		require.NoError(t, err)
+		req.Header.Set("X-Customer", test.customer)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, test.status, res.StatusCode, test.customer)
+	}
+
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
 func TestHTTPServerWebsockets(t *testing.T) {
 	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
 	defer done()
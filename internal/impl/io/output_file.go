@@ -157,6 +157,12 @@ func (w *fileWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
 	if err != nil {
 		return err
 	}
+
+	w.handleMut.Lock()
+	defer w.handleMut.Unlock()
+	if w.handle != nil {
+		return codec.FlushWriter(ctx, w.handle)
+	}
 	return nil
 }
 
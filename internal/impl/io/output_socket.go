@@ -110,7 +110,7 @@ func (s *socketWriter) WriteBatch(ctx context.Context, msg message.Batch) error
 		return component.ErrNotConnected
 	}
 
-	return msg.Iter(func(i int, part *message.Part) error {
+	err := msg.Iter(func(i int, part *message.Part) error {
 		serr := w.Write(ctx, part)
 		if serr != nil || s.codecConf.CloseAfter {
 			s.writerMut.Lock()
@@ -120,6 +120,16 @@ func (s *socketWriter) WriteBatch(ctx context.Context, msg message.Batch) error
 		}
 		return serr
 	})
+	if err != nil || s.codecConf.CloseAfter {
+		return err
+	}
+	if err = codec.FlushWriter(ctx, w); err != nil {
+		s.writerMut.Lock()
+		s.writer.Close(ctx)
+		s.writer = nil
+		s.writerMut.Unlock()
+	}
+	return err
 }
 
 func (s *socketWriter) Close(ctx context.Context) error {
@@ -64,9 +64,12 @@ func (w *stdoutWriter) Connect(ctx context.Context) error {
 }
 
 func (w *stdoutWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
-	return output.IterateBatchedSend(msg, func(i int, p *message.Part) error {
+	if err := output.IterateBatchedSend(msg, func(i int, p *message.Part) error {
 		return w.handle.Write(ctx, p)
-	})
+	}); err != nil {
+		return err
+	}
+	return codec.FlushWriter(ctx, w.handle)
 }
 
 func (w *stdoutWriter) Close(ctx context.Context) error {
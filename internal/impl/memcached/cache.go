@@ -1,8 +1,11 @@
 package memcached
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -152,6 +155,40 @@ func (m *memcachedCache) Get(ctx context.Context, key string) ([]byte, error) {
 	}
 }
 
+func (m *memcachedCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	boff := m.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		m.boffPool.Put(boff)
+	}()
+
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = m.prefix + key
+	}
+
+	for {
+		items, err := m.mc.GetMulti(prefixedKeys)
+		if err == nil {
+			values := make(map[string][]byte, len(items))
+			for k, item := range items {
+				values[strings.TrimPrefix(k, m.prefix)] = item.Value
+			}
+			return values, nil
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return nil, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
 func (m *memcachedCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
 	boff := m.boffPool.Get().(backoff.BackOff)
 	defer func() {
@@ -233,6 +270,132 @@ func (m *memcachedCache) Delete(ctx context.Context, key string) error {
 	}
 }
 
+// Incr increments the integer value of a key. Since the native increment
+// command of memcached does not support negative values this is implemented
+// with a compare-and-swap loop.
+func (m *memcachedCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	boff := m.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		m.boffPool.Put(boff)
+	}()
+
+	for {
+		v, err := m.incr(key, delta, ttl)
+		if err == nil {
+			return v, nil
+		}
+		if errors.Is(err, strconv.ErrSyntax) || errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("value of key '%v' is not an integer: %w", key, err)
+		}
+
+		// Conflicts with other writers are retried immediately.
+		if errors.Is(err, memcache.ErrCASConflict) || errors.Is(err, memcache.ErrNotStored) {
+			continue
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return 0, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return 0, err
+		}
+	}
+}
+
+func (m *memcachedCache) incr(key string, delta int64, ttl *time.Duration) (int64, error) {
+	item, err := m.mc.Get(m.prefix + key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		if err = m.mc.Add(m.getItemFor(key, []byte(strconv.FormatInt(delta, 10)), ttl)); err != nil {
+			return 0, err
+		}
+		return delta, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	current, err := strconv.ParseInt(string(item.Value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	current += delta
+
+	item.Value = []byte(strconv.FormatInt(current, 10))
+	if ttl != nil {
+		item.Expiration = m.getItemFor(key, nil, ttl).Expiration
+	}
+	if err = m.mc.CompareAndSwap(item); err != nil {
+		return 0, err
+	}
+	return current, nil
+}
+
+func (m *memcachedCache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	boff := m.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		m.boffPool.Put(boff)
+	}()
+
+	for {
+		err := m.compareAndSwap(key, oldValue, newValue, ttl)
+		if err == nil ||
+			errors.Is(err, service.ErrKeyAlreadyExists) ||
+			errors.Is(err, service.ErrKeyNotFound) ||
+			errors.Is(err, service.ErrValueMismatch) {
+			return err
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (m *memcachedCache) compareAndSwap(key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	if oldValue == nil {
+		err := m.mc.Add(m.getItemFor(key, newValue, ttl))
+		if errors.Is(err, memcache.ErrNotStored) {
+			return service.ErrKeyAlreadyExists
+		}
+		return err
+	}
+
+	item, err := m.mc.Get(m.prefix + key)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return service.ErrKeyNotFound
+		}
+		return err
+	}
+	if !bytes.Equal(item.Value, oldValue) {
+		return service.ErrValueMismatch
+	}
+
+	item.Value = newValue
+	item.Expiration = m.getItemFor(key, nil, ttl).Expiration
+	if err = m.mc.CompareAndSwap(item); err != nil {
+		// The value was modified or removed between our get and swap.
+		if errors.Is(err, memcache.ErrCASConflict) {
+			return service.ErrValueMismatch
+		}
+		if errors.Is(err, memcache.ErrNotStored) {
+			return service.ErrKeyNotFound
+		}
+	}
+	return err
+}
+
 func (m *memcachedCache) Close(ctx context.Context) error {
 	return nil
 }
@@ -58,6 +58,9 @@ cache_resources:
 		integration.CacheTestDoubleAdd(),
 		integration.CacheTestDelete(),
 		integration.CacheTestGetAndSet(50),
+		integration.CacheTestIncr(),
+		integration.CacheTestCompareAndSwap(),
+		integration.CacheTestGetMulti(),
 	)
 	suite.Run(
 		t, template,
@@ -0,0 +1,160 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/eclipse/paho.golang/packets"
+	"github.com/eclipse/paho.golang/paho"
+
+	mqttconf "github.com/benthosdev/benthos/v4/internal/impl/mqtt/shared"
+)
+
+const (
+	protocolV311 = "3.1.1"
+	protocolV5   = "5"
+)
+
+func validateProtocolVersion(v string) error {
+	switch v {
+	case protocolV311, protocolV5:
+		return nil
+	}
+	return fmt.Errorf("unsupported protocol_version: %v", v)
+}
+
+// parseExpirySeconds parses a duration string as a number of seconds suitable
+// for an MQTT 5 expiry interval property.
+func parseExpirySeconds(s string) (uint32, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, errors.New("expiry interval must not be negative")
+	}
+	secs := d.Seconds()
+	if secs > math.MaxUint32 {
+		return math.MaxUint32, nil
+	}
+	return uint32(math.Ceil(secs)), nil
+}
+
+// dialV5 attempts to open a network connection to each broker URL in turn,
+// returning the first that succeeds.
+func dialV5(ctx context.Context, urls []string, tlsConf *tls.Config, timeout time.Duration) (net.Conn, error) {
+	var errs []error
+	for _, u := range urls {
+		conn, err := dialURLV5(ctx, u, tlsConf, timeout)
+		if err == nil {
+			return packets.NewThreadSafeConn(conn), nil
+		}
+		errs = append(errs, fmt.Errorf("%v: %w", u, err))
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no broker urls were specified")
+	}
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, fmt.Errorf("failed to connect to any broker: %v", errs)
+}
+
+func dialURLV5(ctx context.Context, urlStr string, tlsConf *tls.Config, timeout time.Duration) (net.Conn, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done := context.WithTimeout(ctx, timeout)
+	defer done()
+
+	dialer := &net.Dialer{}
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		if tlsConf == nil {
+			return dialer.DialContext(ctx, "tcp", u.Host)
+		}
+	case "ssl", "tls", "tcps", "mqtts":
+		if tlsConf == nil {
+			tlsConf = &tls.Config{}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported url scheme: %v", u.Scheme)
+	}
+	tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConf}
+	return tlsDialer.DialContext(ctx, "tcp", u.Host)
+}
+
+type connectV5Options struct {
+	clientID      string
+	cleanStart    bool
+	keepAlive     int64
+	user          string
+	password      string
+	will          mqttconf.Will
+	sessionExpiry uint32
+}
+
+func (o connectV5Options) packet() *paho.Connect {
+	cp := &paho.Connect{
+		ClientID:   o.clientID,
+		CleanStart: o.cleanStart,
+		KeepAlive:  uint16(o.keepAlive),
+		Properties: &paho.ConnectProperties{},
+	}
+	if o.sessionExpiry > 0 {
+		sessionExpiry := o.sessionExpiry
+		cp.Properties.SessionExpiryInterval = &sessionExpiry
+	}
+	if o.user != "" {
+		cp.UsernameFlag = true
+		cp.Username = o.user
+	}
+	if o.password != "" {
+		cp.PasswordFlag = true
+		cp.Password = []byte(o.password)
+	}
+	if o.will.Enabled {
+		cp.WillMessage = &paho.WillMessage{
+			Retain:  o.will.Retained,
+			QoS:     o.will.QoS,
+			Topic:   o.will.Topic,
+			Payload: []byte(o.will.Payload),
+		}
+	}
+	return cp
+}
+
+// noopPinger disables keep alive pings, which the default pinger of the client
+// does not support.
+type noopPinger struct{}
+
+func (noopPinger) Start(net.Conn, time.Duration) {}
+func (noopPinger) Stop()                         {}
+func (noopPinger) PingResp()                     {}
+func (noopPinger) SetDebug(paho.Logger)          {}
+
+// connectV5 establishes an MQTT 5 session over a network connection, where
+// errors that occur after the session is established are passed to onErr.
+func connectV5(ctx context.Context, conn net.Conn, opts connectV5Options, timeout time.Duration, conf paho.ClientConfig) (*paho.Client, error) {
+	conf.Conn = conn
+	if opts.keepAlive <= 0 {
+		conf.PingHandler = noopPinger{}
+	}
+	client := paho.NewClient(conf)
+
+	ctx, done := context.WithTimeout(ctx, timeout)
+	defer done()
+
+	if _, err := client.Connect(ctx, opts.packet()); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
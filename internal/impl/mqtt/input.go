@@ -2,6 +2,7 @@ package mqtt
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -23,7 +24,16 @@ import (
 
 func init() {
 	err := bundle.AllInputs.Add(processors.WrapConstructor(func(conf input.Config, nm bundle.NewManagement) (input.Streamed, error) {
-		m, err := newMQTTReader(conf.MQTT, nm)
+		if err := validateProtocolVersion(conf.MQTT.ProtocolVersion); err != nil {
+			return nil, err
+		}
+		var m input.Async
+		var err error
+		if conf.MQTT.ProtocolVersion == protocolV5 {
+			m, err = newMQTTV5Reader(conf.MQTT, nm)
+		} else {
+			m, err = newMQTTReader(conf.MQTT, nm)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -46,16 +56,54 @@ This input adds the following metadata fields to each message:
 ` + "```" + `
 
 You can access these metadata fields using
-[function interpolation](/docs/configuration/interpolation#bloblang-queries).`,
+[function interpolation](/docs/configuration/interpolation#bloblang-queries).
+
+### MQTT 5
+
+When ` + "`protocol_version`" + ` is ` + "`5`" + ` the ` + "`mqtt_duplicate`" + ` field is not added, and the
+following metadata fields are added when the respective properties are set on a
+message:
+
+` + "``` text" + `
+- mqtt_content_type
+- mqtt_correlation_data
+- mqtt_message_expiry
+- mqtt_response_topic
+` + "```" + `
+
+The user properties of each message are also added as metadata fields, where
+the key and value of each property become the key and value of a field.
+
+Messages with a response topic can be replied to using a
+[` + "`sync_response`" + ` output](/docs/components/outputs/sync_response), where the
+responses are published to the response topic along with the correlation data
+of the message once the message is acknowledged. This allows Benthos to serve
+MQTT 5 request/response interactions.
+
+The ` + "`session_expiry_interval`" + ` field allows a session, including its
+subscriptions and undelivered messages, to outlive a connection when
+` + "`clean_session`" + ` is ` + "`false`" + `.
+
+### Shared Subscriptions
+
+When a ` + "`shared_subscription_group`" + ` is set each topic is subscribed to as a
+shared subscription of that group, and the broker distributes the messages of
+each topic across all clients subscribed within the group. This allows multiple
+instances of Benthos to consume topics in parallel. Shared subscriptions are a
+feature of MQTT 5, although some brokers also support them for MQTT 3.1.1
+clients.`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldURL("urls", "A list of URLs to connect to. If an item of the list contains commas it will be expanded into multiple URLs.").Array(),
+			mqttconf.ProtocolVersionFieldSpec(),
 			docs.FieldString("topics", "A list of topics to consume from.").Array(),
+			docs.FieldString("shared_subscription_group", "An optional group name with which to subscribe to each topic as a shared subscription. Read more in [Shared Subscriptions](#shared-subscriptions).", "benthos").Advanced().AtVersion("4.14.0"),
 			docs.FieldString("client_id", "An identifier for the client connection."),
 			docs.FieldString("dynamic_client_id_suffix", "Append a dynamically generated suffix to the specified `client_id` on each run of the pipeline. This can be useful when clustering Benthos producers.").Optional().Advanced().HasAnnotatedOptions(
 				"nanoid", "append a nanoid of length 21 characters",
 			).LinterFunc(nil),
 			docs.FieldInt("qos", "The level of delivery guarantee to enforce.").HasOptions("0", "1", "2").Advanced().LinterFunc(nil),
 			docs.FieldBool("clean_session", "Set whether the connection is non-persistent.").Advanced(),
+			mqttconf.SessionExpiryFieldSpec(),
 			mqttconf.WillFieldSpec(),
 			docs.FieldString("connect_timeout", "The maximum amount of time to wait in order to establish a connection before the attempt is abandoned.", "1s", "500ms").HasDefault("30s").AtVersion("3.58.0"),
 			docs.FieldString("user", "A username to assume for the connection.").Advanced(),
@@ -117,6 +165,12 @@ func newMQTTReader(conf input.MQTTConfig, mgr bundle.NewManagement) (*mqttReader
 		return nil, err
 	}
 
+	if sessionExpiry, err := parseExpirySeconds(conf.SessionExpiry); err != nil {
+		return nil, fmt.Errorf("unable to parse session expiry interval: %w", err)
+	} else if sessionExpiry > 0 {
+		return nil, errors.New("session_expiry_interval requires protocol_version 5")
+	}
+
 	for _, u := range conf.URLs {
 		for _, splitURL := range strings.Split(u, ",") {
 			if len(splitURL) > 0 {
@@ -164,7 +218,7 @@ func (m *mqttReader) Connect(ctx context.Context) error {
 		SetOnConnectHandler(func(c mqtt.Client) {
 			topics := make(map[string]byte)
 			for _, topic := range m.conf.Topics {
-				topics[topic] = m.conf.QoS
+				topics[sharedTopic(m.conf.SharedGroup, topic)] = m.conf.QoS
 			}
 
 			tok := c.SubscribeMultiple(topics, func(c mqtt.Client, msg mqtt.Message) {
@@ -0,0 +1,280 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/transaction"
+)
+
+// sharedTopic returns the topic filter of a shared subscription to a topic.
+func sharedTopic(group, topic string) string {
+	if group == "" {
+		return topic
+	}
+	return "$share/" + group + "/" + topic
+}
+
+type mqttV5Reader struct {
+	client  *paho.Client
+	msgChan chan *paho.Publish
+	cMut    sync.Mutex
+
+	connectTimeout time.Duration
+	sessionExpiry  uint32
+	conf           input.MQTTConfig
+
+	interruptChan chan struct{}
+	interruptOnce sync.Once
+
+	urls []string
+
+	log log.Modular
+	mgr bundle.NewManagement
+}
+
+func newMQTTV5Reader(conf input.MQTTConfig, mgr bundle.NewManagement) (*mqttV5Reader, error) {
+	m := &mqttV5Reader{
+		conf:          conf,
+		interruptChan: make(chan struct{}),
+		log:           mgr.Logger(),
+		mgr:           mgr,
+	}
+
+	var err error
+	if m.connectTimeout, err = time.ParseDuration(conf.ConnectTimeout); err != nil {
+		return nil, fmt.Errorf("unable to parse connect timeout duration string: %w", err)
+	}
+	if m.sessionExpiry, err = parseExpirySeconds(conf.SessionExpiry); err != nil {
+		return nil, fmt.Errorf("unable to parse session expiry interval: %w", err)
+	}
+
+	switch m.conf.DynamicClientIDSuffix {
+	case "nanoid":
+		nid, err := gonanoid.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate nanoid: %w", err)
+		}
+		m.conf.ClientID += nid
+	case "":
+	default:
+		return nil, fmt.Errorf("unknown dynamic_client_id_suffix: %v", m.conf.DynamicClientIDSuffix)
+	}
+
+	if err := m.conf.Will.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, u := range conf.URLs {
+		for _, splitURL := range strings.Split(u, ",") {
+			if len(splitURL) > 0 {
+				m.urls = append(m.urls, splitURL)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m *mqttV5Reader) Connect(ctx context.Context) error {
+	m.cMut.Lock()
+	defer m.cMut.Unlock()
+
+	if m.client != nil {
+		return nil
+	}
+
+	var msgMut sync.Mutex
+	msgChan := make(chan *paho.Publish)
+
+	closeMsgChan := func() bool {
+		msgMut.Lock()
+		chanOpen := msgChan != nil
+		if chanOpen {
+			close(msgChan)
+			msgChan = nil
+		}
+		msgMut.Unlock()
+		return chanOpen
+	}
+
+	var tlsConf *tls.Config
+	if m.conf.TLS.Enabled {
+		var err error
+		if tlsConf, err = m.conf.TLS.Get(m.mgr.FS()); err != nil {
+			return err
+		}
+	}
+
+	conn, err := dialV5(ctx, m.urls, tlsConf, m.connectTimeout)
+	if err != nil {
+		return err
+	}
+
+	client, err := connectV5(ctx, conn, connectV5Options{
+		clientID:      m.conf.ClientID,
+		cleanStart:    m.conf.CleanSession,
+		keepAlive:     m.conf.KeepAlive,
+		user:          m.conf.User,
+		password:      m.conf.Password,
+		will:          m.conf.Will,
+		sessionExpiry: m.sessionExpiry,
+	}, m.connectTimeout, paho.ClientConfig{
+		ClientID: m.conf.ClientID,
+		Router: paho.NewSingleHandlerRouter(func(p *paho.Publish) {
+			msgMut.Lock()
+			if msgChan != nil {
+				select {
+				case msgChan <- p:
+				case <-m.interruptChan:
+				}
+			}
+			msgMut.Unlock()
+		}),
+		// Messages are acknowledged only once they've been successfully
+		// delivered, the client then sends acknowledgements in the order in
+		// which the messages were received as required by the spec.
+		EnableManualAcknowledgment: true,
+		OnClientError: func(err error) {
+			if closeMsgChan() {
+				m.log.Errorf("Connection lost due to: %v\n", err)
+			}
+		},
+		OnServerDisconnect: func(d *paho.Disconnect) {
+			if closeMsgChan() {
+				m.log.Errorf("Disconnected by broker with reason code: %v\n", d.ReasonCode)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	subs := map[string]paho.SubscribeOptions{}
+	for _, topic := range m.conf.Topics {
+		subs[sharedTopic(m.conf.SharedGroup, topic)] = paho.SubscribeOptions{QoS: m.conf.QoS}
+	}
+	if _, err := client.Subscribe(ctx, &paho.Subscribe{Subscriptions: subs}); err != nil {
+		_ = client.Disconnect(&paho.Disconnect{})
+		return fmt.Errorf("failed to subscribe to topics '%v': %w", m.conf.Topics, err)
+	}
+
+	m.log.Infof("Receiving MQTT 5 messages from topics: %v\n", m.conf.Topics)
+
+	m.client = client
+	m.msgChan = msgChan
+	return nil
+}
+
+// respond publishes the synchronous responses to a message to the response
+// topic of the message, along with its correlation data.
+func (m *mqttV5Reader) respond(ctx context.Context, client *paho.Client, req *paho.Publish, store transaction.ResultStore) error {
+	for _, resBatch := range store.Get() {
+		if err := resBatch.Iter(func(i int, p *message.Part) error {
+			_, err := client.Publish(ctx, &paho.Publish{
+				QoS:     m.conf.QoS,
+				Topic:   req.Properties.ResponseTopic,
+				Payload: p.AsBytes(),
+				Properties: &paho.PublishProperties{
+					CorrelationData: req.Properties.CorrelationData,
+				},
+			})
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mqttV5Reader) ReadBatch(ctx context.Context) (message.Batch, input.AsyncAckFn, error) {
+	m.cMut.Lock()
+	msgChan, client := m.msgChan, m.client
+	m.cMut.Unlock()
+
+	if msgChan == nil {
+		return nil, nil, component.ErrNotConnected
+	}
+
+	select {
+	case pub, open := <-msgChan:
+		if !open {
+			m.cMut.Lock()
+			m.msgChan = nil
+			m.client = nil
+			m.cMut.Unlock()
+			return nil, nil, component.ErrNotConnected
+		}
+
+		msg := message.QuickBatch([][]byte{pub.Payload})
+
+		p := msg.Get(0)
+		p.MetaSetMut("mqtt_qos", int(pub.QoS))
+		p.MetaSetMut("mqtt_retained", pub.Retain)
+		p.MetaSetMut("mqtt_topic", pub.Topic)
+		p.MetaSetMut("mqtt_message_id", int(pub.PacketID))
+
+		var store transaction.ResultStore
+		if props := pub.Properties; props != nil {
+			for _, up := range props.User {
+				p.MetaSetMut(up.Key, up.Value)
+			}
+			if props.ContentType != "" {
+				p.MetaSetMut("mqtt_content_type", props.ContentType)
+			}
+			if props.MessageExpiry != nil {
+				p.MetaSetMut("mqtt_message_expiry", int64(*props.MessageExpiry))
+			}
+			if props.CorrelationData != nil {
+				p.MetaSetMut("mqtt_correlation_data", string(props.CorrelationData))
+			}
+			if props.ResponseTopic != "" {
+				p.MetaSetMut("mqtt_response_topic", props.ResponseTopic)
+				store = transaction.NewResultStore()
+				transaction.AddResultStore(msg, store)
+			}
+		}
+
+		return msg, func(ctx context.Context, res error) error {
+			if res != nil {
+				return nil
+			}
+			if store != nil {
+				if err := m.respond(ctx, client, pub, store); err != nil {
+					m.log.Errorf("Failed to publish response to topic '%v': %v\n", pub.Properties.ResponseTopic, err)
+				}
+			}
+			return client.Ack(pub)
+		}, nil
+	case <-ctx.Done():
+	case <-m.interruptChan:
+		return nil, nil, component.ErrTypeClosed
+	}
+	return nil, nil, component.ErrTimeout
+}
+
+func (m *mqttV5Reader) Close(ctx context.Context) (err error) {
+	m.cMut.Lock()
+	defer m.cMut.Unlock()
+
+	m.interruptOnce.Do(func() {
+		close(m.interruptChan)
+	})
+	if m.client != nil {
+		_ = m.client.Disconnect(&paho.Disconnect{})
+		m.client = nil
+	}
+	return
+}
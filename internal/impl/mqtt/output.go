@@ -2,6 +2,7 @@ package mqtt
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -20,12 +21,22 @@ import (
 	mqttconf "github.com/benthosdev/benthos/v4/internal/impl/mqtt/shared"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/metadata"
 	"github.com/benthosdev/benthos/v4/internal/tls"
 )
 
 func init() {
 	err := bundle.AllOutputs.Add(processors.WrapConstructor(func(conf output.Config, nm bundle.NewManagement) (output.Streamed, error) {
-		w, err := newMQTTWriter(conf.MQTT, nm)
+		if err := validateProtocolVersion(conf.MQTT.ProtocolVersion); err != nil {
+			return nil, err
+		}
+		var w output.AsyncSink
+		var err error
+		if conf.MQTT.ProtocolVersion == protocolV5 {
+			w, err = newMQTTV5Writer(conf.MQTT, nm)
+		} else {
+			w, err = newMQTTWriter(conf.MQTT, nm)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -41,10 +52,22 @@ Pushes messages to an MQTT broker.`,
 		Description: output.Description(true, false, `
 The `+"`topic`"+` field can be dynamically set using function interpolations
 described [here](/docs/configuration/interpolation#bloblang-queries). When sending batched
-messages these interpolations are performed per message part.`),
+messages these interpolations are performed per message part.
+
+### MQTT 5
+
+When `+"`protocol_version`"+` is `+"`5`"+` the metadata fields of each message are sent
+as user properties, which can be restricted with the `+"`metadata`"+` field. The
+fields `+"`message_expiry`"+`, `+"`response_topic`"+`, `+"`correlation_data`"+` and
+`+"`session_expiry_interval`"+` are only supported by MQTT 5.`),
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldURL("urls", "A list of URLs to connect to. If an item of the list contains commas it will be expanded into multiple URLs.", []string{"tcp://localhost:1883"}).Array(),
+			mqttconf.ProtocolVersionFieldSpec(),
 			docs.FieldString("topic", "The topic to publish messages to."),
+			docs.FieldString("message_expiry", "An optional period of time after which the broker discards messages that have not yet been delivered to subscribers. Only supported by MQTT 5.", "60s", "1h").Advanced().AtVersion("4.14.0"),
+			docs.FieldString("response_topic", "An optional topic to which subscribers should publish responses to each message. Only supported by MQTT 5.", `${! meta("reply_topic") }`).IsInterpolated().Advanced().AtVersion("4.14.0"),
+			docs.FieldString("correlation_data", "Optional correlation data to set on each message, allowing responses to be matched to requests. Only supported by MQTT 5.", `${! uuid_v4() }`).IsInterpolated().Advanced().AtVersion("4.14.0"),
+			docs.FieldObject("metadata", "Specify criteria for which metadata values are sent as user properties. Only supported by MQTT 5.").WithChildren(metadata.ExcludeFilterFields()...).Advanced().AtVersion("4.14.0"),
 			docs.FieldString("client_id", "An identifier for the client connection."),
 			docs.FieldString("dynamic_client_id_suffix", "Append a dynamically generated suffix to the specified `client_id` on each run of the pipeline. This can be useful when clustering Benthos producers.").Optional().Advanced().HasAnnotatedOptions(
 				"nanoid", "append a nanoid of length 21 characters",
@@ -55,6 +78,7 @@ messages these interpolations are performed per message part.`),
 			docs.FieldBool("retained", "Set message as retained on the topic."),
 			docs.FieldString("retained_interpolated", "Override the value of `retained` with an interpolable value, this allows it to be dynamically set based on message contents. The value must resolve to either `true` or `false`.").IsInterpolated().Advanced().AtVersion("3.59.0"),
 			mqttconf.WillFieldSpec(),
+			mqttconf.SessionExpiryFieldSpec(),
 			docs.FieldString("user", "A username to connect with.").Advanced(),
 			docs.FieldString("password", "A password to connect with.").Advanced().Secret(),
 			docs.FieldInt("keepalive", "Max seconds of inactivity before a keepalive message is sent.").Advanced(),
@@ -127,6 +151,15 @@ func newMQTTWriter(conf output.MQTTConfig, mgr bundle.NewManagement) (*mqttWrite
 		return nil, err
 	}
 
+	if sessionExpiry, err := parseExpirySeconds(conf.SessionExpiry); err != nil {
+		return nil, fmt.Errorf("unable to parse session expiry interval: %w", err)
+	} else if sessionExpiry > 0 {
+		return nil, errors.New("session_expiry_interval requires protocol_version 5")
+	}
+	if conf.MessageExpiry != "" || conf.ResponseTopic != "" || conf.CorrelationData != "" {
+		return nil, errors.New("message_expiry, response_topic and correlation_data require protocol_version 5")
+	}
+
 	for _, u := range conf.URLs {
 		for _, splitURL := range strings.Split(u, ",") {
 			if len(splitURL) > 0 {
@@ -0,0 +1,255 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/metadata"
+)
+
+type mqttV5Writer struct {
+	log log.Modular
+	mgr bundle.NewManagement
+
+	connectTimeout time.Duration
+	writeTimeout   time.Duration
+	sessionExpiry  uint32
+	messageExpiry  *uint32
+
+	urls            []string
+	conf            output.MQTTConfig
+	topic           *field.Expression
+	retained        *field.Expression
+	responseTopic   *field.Expression
+	correlationData *field.Expression
+	metaFilter      *metadata.ExcludeFilter
+
+	client  *paho.Client
+	connMut sync.RWMutex
+}
+
+func newMQTTV5Writer(conf output.MQTTConfig, mgr bundle.NewManagement) (*mqttV5Writer, error) {
+	m := &mqttV5Writer{
+		log:  mgr.Logger(),
+		mgr:  mgr,
+		conf: conf,
+	}
+
+	var err error
+	if m.connectTimeout, err = time.ParseDuration(conf.ConnectTimeout); err != nil {
+		return nil, fmt.Errorf("unable to parse connect timeout duration string: %w", err)
+	}
+	if m.writeTimeout, err = time.ParseDuration(conf.WriteTimeout); err != nil {
+		return nil, fmt.Errorf("unable to parse write timeout duration string: %w", err)
+	}
+	if m.sessionExpiry, err = parseExpirySeconds(conf.SessionExpiry); err != nil {
+		return nil, fmt.Errorf("unable to parse session expiry interval: %w", err)
+	}
+	if conf.MessageExpiry != "" {
+		messageExpiry, err := parseExpirySeconds(conf.MessageExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse message expiry: %w", err)
+		}
+		m.messageExpiry = &messageExpiry
+	}
+
+	if m.topic, err = mgr.BloblEnvironment().NewField(conf.Topic); err != nil {
+		return nil, fmt.Errorf("failed to parse topic expression: %v", err)
+	}
+
+	if conf.RetainedInterpolated != "" {
+		if m.retained, err = mgr.BloblEnvironment().NewField(conf.RetainedInterpolated); err != nil {
+			return nil, fmt.Errorf("failed to parse retained expression: %v", err)
+		}
+	}
+
+	if conf.ResponseTopic != "" {
+		if m.responseTopic, err = mgr.BloblEnvironment().NewField(conf.ResponseTopic); err != nil {
+			return nil, fmt.Errorf("failed to parse response topic expression: %v", err)
+		}
+	}
+
+	if conf.CorrelationData != "" {
+		if m.correlationData, err = mgr.BloblEnvironment().NewField(conf.CorrelationData); err != nil {
+			return nil, fmt.Errorf("failed to parse correlation data expression: %v", err)
+		}
+	}
+
+	if m.metaFilter, err = conf.Metadata.Filter(); err != nil {
+		return nil, fmt.Errorf("failed to construct metadata filter: %w", err)
+	}
+
+	switch m.conf.DynamicClientIDSuffix {
+	case "nanoid":
+		nid, err := gonanoid.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate nanoid: %w", err)
+		}
+		m.conf.ClientID += nid
+	case "":
+	default:
+		return nil, fmt.Errorf("unknown dynamic_client_id_suffix: %v", m.conf.DynamicClientIDSuffix)
+	}
+
+	if err := m.conf.Will.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, u := range conf.URLs {
+		for _, splitURL := range strings.Split(u, ",") {
+			if len(splitURL) > 0 {
+				m.urls = append(m.urls, splitURL)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m *mqttV5Writer) Connect(ctx context.Context) error {
+	m.connMut.Lock()
+	defer m.connMut.Unlock()
+
+	if m.client != nil {
+		return nil
+	}
+
+	var tlsConf *tls.Config
+	if m.conf.TLS.Enabled {
+		var err error
+		if tlsConf, err = m.conf.TLS.Get(m.mgr.FS()); err != nil {
+			return err
+		}
+	}
+
+	conn, err := dialV5(ctx, m.urls, tlsConf, m.connectTimeout)
+	if err != nil {
+		return err
+	}
+
+	var client *paho.Client
+	dropClient := func() {
+		m.connMut.Lock()
+		if m.client == client {
+			m.client = nil
+		}
+		m.connMut.Unlock()
+	}
+
+	if client, err = connectV5(ctx, conn, connectV5Options{
+		clientID:      m.conf.ClientID,
+		cleanStart:    true,
+		keepAlive:     m.conf.KeepAlive,
+		user:          m.conf.User,
+		password:      m.conf.Password,
+		will:          m.conf.Will,
+		sessionExpiry: m.sessionExpiry,
+	}, m.connectTimeout, paho.ClientConfig{
+		ClientID: m.conf.ClientID,
+		OnClientError: func(err error) {
+			dropClient()
+			m.log.Errorf("Connection lost due to: %v\n", err)
+		},
+		OnServerDisconnect: func(d *paho.Disconnect) {
+			dropClient()
+			m.log.Errorf("Disconnected by broker with reason code: %v\n", d.ReasonCode)
+		},
+	}); err != nil {
+		return err
+	}
+
+	m.client = client
+	return nil
+}
+
+func (m *mqttV5Writer) WriteBatch(ctx context.Context, msg message.Batch) error {
+	m.connMut.RLock()
+	client := m.client
+	m.connMut.RUnlock()
+
+	if client == nil {
+		return component.ErrNotConnected
+	}
+
+	return output.IterateBatchedSend(msg, func(i int, p *message.Part) error {
+		retained := m.conf.Retained
+		if m.retained != nil {
+			retainedStr, parseErr := m.retained.String(i, msg)
+			if parseErr != nil {
+				m.log.Errorf("Retained interpolation error: %v", parseErr)
+			} else if retained, parseErr = strconv.ParseBool(retainedStr); parseErr != nil {
+				m.log.Errorf("Error parsing boolean value from retained flag: %v \n", parseErr)
+			}
+		}
+
+		topicStr, err := m.topic.String(i, msg)
+		if err != nil {
+			return fmt.Errorf("topic interpolation error: %w", err)
+		}
+
+		props := &paho.PublishProperties{
+			MessageExpiry: m.messageExpiry,
+		}
+		if m.responseTopic != nil {
+			if props.ResponseTopic, err = m.responseTopic.String(i, msg); err != nil {
+				return fmt.Errorf("response topic interpolation error: %w", err)
+			}
+		}
+		if m.correlationData != nil {
+			correlationData, err := m.correlationData.Bytes(i, msg)
+			if err != nil {
+				return fmt.Errorf("correlation data interpolation error: %w", err)
+			}
+			props.CorrelationData = correlationData
+		}
+		_ = m.metaFilter.IterStr(p, func(k, v string) error {
+			props.User.Add(k, v)
+			return nil
+		})
+
+		writeCtx, done := context.WithTimeout(ctx, m.writeTimeout)
+		defer done()
+
+		_, sendErr := client.Publish(writeCtx, &paho.Publish{
+			QoS:        m.conf.QoS,
+			Retain:     retained,
+			Topic:      topicStr,
+			Payload:    p.AsBytes(),
+			Properties: props,
+		})
+		if sendErr != nil {
+			m.connMut.RLock()
+			connected := m.client == client
+			m.connMut.RUnlock()
+			if !connected {
+				sendErr = component.ErrNotConnected
+			}
+		}
+		return sendErr
+	})
+}
+
+func (m *mqttV5Writer) Close(context.Context) error {
+	m.connMut.Lock()
+	defer m.connMut.Unlock()
+
+	if m.client != nil {
+		_ = m.client.Disconnect(&paho.Disconnect{})
+		m.client = nil
+	}
+	return nil
+}
@@ -47,3 +47,18 @@ func WillFieldSpec() docs.FieldSpec {
 		docs.FieldString("payload", "Set payload for last will message."),
 	).Advanced()
 }
+
+// ProtocolVersionFieldSpec defines the MQTT protocol version to connect with.
+func ProtocolVersionFieldSpec() docs.FieldSpec {
+	return docs.FieldString(
+		"protocol_version", "The version of the MQTT protocol to connect with. Some fields are only supported by MQTT 5.",
+	).HasOptions("3.1.1", "5").AtVersion("4.14.0")
+}
+
+// SessionExpiryFieldSpec defines the session expiry interval of an MQTT 5
+// connection.
+func SessionExpiryFieldSpec() docs.FieldSpec {
+	return docs.FieldString(
+		"session_expiry_interval", "The period of time for which the broker retains the session after the connection is closed, which allows subscriptions and undelivered messages to survive reconnects. Only supported by MQTT 5, where a value of zero ends the session along with the connection.", "0s", "1h",
+	).Advanced().AtVersion("4.14.0")
+}
@@ -0,0 +1,368 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.golang/packets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/transaction"
+)
+
+// testBroker is a minimal MQTT 5 broker that supports QoS 0 and 1, exact topic
+// matches and shared subscriptions.
+type testBroker struct {
+	ln net.Listener
+
+	mut      sync.Mutex
+	connects []*packets.Connect
+	subs     []*testSub
+	shared   map[string]int
+}
+
+type testSub struct {
+	conn   *testBrokerConn
+	topic  string
+	group  string
+	filter string
+}
+
+type testBrokerConn struct {
+	conn   net.Conn
+	mut    sync.Mutex
+	nextID uint16
+}
+
+func runTestBroker(t *testing.T) (*testBroker, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	b := &testBroker{ln: ln, shared: map[string]int{}}
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(&testBrokerConn{conn: packets.NewThreadSafeConn(conn)})
+		}
+	}()
+	return b, "tcp://" + ln.Addr().String()
+}
+
+func (b *testBroker) serve(c *testBrokerConn) {
+	defer func() {
+		_ = c.conn.Close()
+		b.mut.Lock()
+		subs := b.subs[:0]
+		for _, s := range b.subs {
+			if s.conn != c {
+				subs = append(subs, s)
+			}
+		}
+		b.subs = subs
+		b.mut.Unlock()
+	}()
+
+	for {
+		cp, err := packets.ReadPacket(c.conn)
+		if err != nil {
+			return
+		}
+
+		switch p := cp.Content.(type) {
+		case *packets.Connect:
+			b.mut.Lock()
+			b.connects = append(b.connects, p)
+			b.mut.Unlock()
+			if _, err := (&packets.Connack{Properties: &packets.Properties{}}).WriteTo(c.conn); err != nil {
+				return
+			}
+		case *packets.Subscribe:
+			sa := &packets.Suback{PacketID: p.PacketID, Properties: &packets.Properties{}}
+			b.mut.Lock()
+			for filter, opts := range p.Subscriptions {
+				sub := &testSub{conn: c, topic: filter, filter: filter}
+				if strings.HasPrefix(filter, "$share/") {
+					parts := strings.SplitN(filter, "/", 3)
+					sub.group, sub.topic = parts[1], parts[2]
+				}
+				b.subs = append(b.subs, sub)
+				sa.Reasons = append(sa.Reasons, opts.QoS)
+			}
+			b.mut.Unlock()
+			if _, err := sa.WriteTo(c.conn); err != nil {
+				return
+			}
+		case *packets.Publish:
+			if p.QoS > 0 {
+				if _, err := (&packets.Puback{PacketID: p.PacketID, Properties: &packets.Properties{}}).WriteTo(c.conn); err != nil {
+					return
+				}
+			}
+			b.route(p)
+		case *packets.Pingreq:
+			if _, err := (&packets.Pingresp{}).WriteTo(c.conn); err != nil {
+				return
+			}
+		case *packets.Disconnect:
+			return
+		}
+	}
+}
+
+func (b *testBroker) route(p *packets.Publish) {
+	b.mut.Lock()
+	var targets []*testBrokerConn
+	groups := map[string][]*testSub{}
+	for _, s := range b.subs {
+		if s.topic != p.Topic {
+			continue
+		}
+		if s.group == "" {
+			targets = append(targets, s.conn)
+			continue
+		}
+		groups[s.group] = append(groups[s.group], s)
+	}
+	for group, subs := range groups {
+		// Shared subscriptions are delivered to a single member of the group
+		// in turn.
+		targets = append(targets, subs[b.shared[group]%len(subs)].conn)
+		b.shared[group]++
+	}
+	b.mut.Unlock()
+
+	for _, c := range targets {
+		c.mut.Lock()
+		c.nextID++
+		fwd := &packets.Publish{
+			QoS:        p.QoS,
+			Topic:      p.Topic,
+			Payload:    p.Payload,
+			Properties: p.Properties,
+			PacketID:   c.nextID,
+		}
+		_, _ = fwd.WriteTo(c.conn)
+		c.mut.Unlock()
+	}
+}
+
+func (b *testBroker) connectPackets() []*packets.Connect {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return append([]*packets.Connect(nil), b.connects...)
+}
+
+func (b *testBroker) subscriptionFilters() []string {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	var filters []string
+	for _, s := range b.subs {
+		filters = append(filters, s.filter)
+	}
+	return filters
+}
+
+//------------------------------------------------------------------------------
+
+func testV5Reader(t *testing.T, urlStr string, fn func(conf *input.MQTTConfig)) *mqttV5Reader {
+	t.Helper()
+
+	conf := input.NewMQTTConfig()
+	conf.ProtocolVersion = protocolV5
+	conf.URLs = []string{urlStr}
+	conf.Topics = []string{"foo"}
+	conf.ClientID = "test-reader"
+	conf.ConnectTimeout = "1s"
+	if fn != nil {
+		fn(&conf)
+	}
+
+	r, err := newMQTTV5Reader(conf, mock.NewManager())
+	require.NoError(t, err)
+	require.NoError(t, r.Connect(context.Background()))
+	t.Cleanup(func() {
+		_ = r.Close(context.Background())
+	})
+	return r
+}
+
+func testV5Writer(t *testing.T, urlStr string, fn func(conf *output.MQTTConfig)) *mqttV5Writer {
+	t.Helper()
+
+	conf := output.NewMQTTConfig()
+	conf.ProtocolVersion = protocolV5
+	conf.URLs = []string{urlStr}
+	conf.Topic = "foo"
+	conf.ClientID = "test-writer"
+	conf.ConnectTimeout = "1s"
+	if fn != nil {
+		fn(&conf)
+	}
+
+	w, err := newMQTTV5Writer(conf, mock.NewManager())
+	require.NoError(t, err)
+	require.NoError(t, w.Connect(context.Background()))
+	t.Cleanup(func() {
+		_ = w.Close(context.Background())
+	})
+	return w
+}
+
+func readV5(t *testing.T, r *mqttV5Reader) (message.Batch, input.AsyncAckFn) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	for {
+		msg, ackFn, err := r.ReadBatch(ctx)
+		if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+			t.Fatal("timed out")
+		}
+		if err != nil {
+			continue
+		}
+		return msg, ackFn
+	}
+}
+
+func TestMQTTV5Properties(t *testing.T) {
+	broker, urlStr := runTestBroker(t)
+
+	r := testV5Reader(t, urlStr, func(conf *input.MQTTConfig) {
+		conf.CleanSession = false
+		conf.SessionExpiry = "1h"
+	})
+	w := testV5Writer(t, urlStr, func(conf *output.MQTTConfig) {
+		conf.MessageExpiry = "90s"
+		conf.Metadata.ExcludePrefixes = []string{"skip_"}
+	})
+
+	part := message.NewPart([]byte("hello world"))
+	part.MetaSetMut("foo", "bar")
+	part.MetaSetMut("skip_me", "nope")
+	require.NoError(t, w.WriteBatch(context.Background(), message.Batch{part}))
+
+	msg, ackFn := readV5(t, r)
+	require.Equal(t, 1, msg.Len())
+
+	p := msg.Get(0)
+	assert.Equal(t, "hello world", string(p.AsBytes()))
+	assert.Equal(t, "bar", p.MetaGetStr("foo"))
+	assert.Equal(t, "", p.MetaGetStr("skip_me"))
+	assert.Equal(t, "foo", p.MetaGetStr("mqtt_topic"))
+	assert.Equal(t, "90", p.MetaGetStr("mqtt_message_expiry"))
+	assert.Equal(t, "", p.MetaGetStr("mqtt_response_topic"))
+	require.NoError(t, ackFn(context.Background(), nil))
+
+	var readerConnect *packets.Connect
+	for _, c := range broker.connectPackets() {
+		if c.ClientID == "test-reader" {
+			readerConnect = c
+		}
+	}
+	require.NotNil(t, readerConnect)
+	assert.False(t, readerConnect.CleanStart)
+	require.NotNil(t, readerConnect.Properties.SessionExpiryInterval)
+	assert.Equal(t, uint32(3600), *readerConnect.Properties.SessionExpiryInterval)
+}
+
+func TestMQTTV5SharedSubscription(t *testing.T) {
+	broker, urlStr := runTestBroker(t)
+
+	readers := []*mqttV5Reader{
+		testV5Reader(t, urlStr, func(conf *input.MQTTConfig) {
+			conf.ClientID = "shared-a"
+			conf.SharedGroup = "benthos"
+		}),
+		testV5Reader(t, urlStr, func(conf *input.MQTTConfig) {
+			conf.ClientID = "shared-b"
+			conf.SharedGroup = "benthos"
+		}),
+	}
+	assert.Equal(t, []string{"$share/benthos/foo", "$share/benthos/foo"}, broker.subscriptionFilters())
+
+	w := testV5Writer(t, urlStr, nil)
+	for _, content := range []string{"first", "second", "third", "fourth"} {
+		require.NoError(t, w.WriteBatch(context.Background(), message.QuickBatch([][]byte{[]byte(content)})))
+	}
+
+	var received []string
+	for i := 0; i < 4; i++ {
+		msg, ackFn := readV5(t, readers[i%2])
+		received = append(received, string(msg.Get(0).AsBytes()))
+		require.NoError(t, ackFn(context.Background(), nil))
+	}
+	assert.ElementsMatch(t, []string{"first", "second", "third", "fourth"}, received)
+}
+
+func TestMQTTV5SyncResponse(t *testing.T) {
+	_, urlStr := runTestBroker(t)
+
+	server := testV5Reader(t, urlStr, func(conf *input.MQTTConfig) {
+		conf.ClientID = "server"
+		conf.Topics = []string{"requests"}
+	})
+	client := testV5Reader(t, urlStr, func(conf *input.MQTTConfig) {
+		conf.ClientID = "client"
+		conf.Topics = []string{"replies"}
+	})
+	w := testV5Writer(t, urlStr, func(conf *output.MQTTConfig) {
+		conf.Topic = "requests"
+		conf.ResponseTopic = "replies"
+		conf.CorrelationData = `${! meta("id") }`
+	})
+
+	req := message.NewPart([]byte("ping"))
+	req.MetaSetMut("id", "abc123")
+	require.NoError(t, w.WriteBatch(context.Background(), message.Batch{req}))
+
+	msg, ackFn := readV5(t, server)
+	assert.Equal(t, "ping", string(msg.Get(0).AsBytes()))
+	assert.Equal(t, "replies", msg.Get(0).MetaGetStr("mqtt_response_topic"))
+	assert.Equal(t, "abc123", msg.Get(0).MetaGetStr("mqtt_correlation_data"))
+
+	msg.Get(0).SetBytes([]byte("pong"))
+	require.NoError(t, transaction.SetAsResponse(msg))
+	require.NoError(t, ackFn(context.Background(), nil))
+
+	res, resAckFn := readV5(t, client)
+	assert.Equal(t, "pong", string(res.Get(0).AsBytes()))
+	assert.Equal(t, "abc123", res.Get(0).MetaGetStr("mqtt_correlation_data"))
+	require.NoError(t, resAckFn(context.Background(), nil))
+}
+
+func TestMQTTV5OnlyFields(t *testing.T) {
+	inConf := input.NewMQTTConfig()
+	inConf.SessionExpiry = "1h"
+
+	_, err := newMQTTReader(inConf, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session_expiry_interval requires protocol_version 5")
+
+	outConf := output.NewMQTTConfig()
+	outConf.MessageExpiry = "1m"
+
+	_, err = newMQTTWriter(outConf, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "require protocol_version 5")
+}
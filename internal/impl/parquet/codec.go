@@ -0,0 +1,268 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+
+	"github.com/segmentio/parquet-go"
+
+	"github.com/benthosdev/benthos/v4/internal/codec"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func init() {
+	codec.RegisterReaderCodec("parquet", func(params string, conf codec.ReaderConfig) (codec.ReaderConstructor, error) {
+		if params != "" {
+			return nil, fmt.Errorf("parquet codec does not support parameters, got: %v", params)
+		}
+		return func(path string, r io.ReadCloser, fn codec.ReaderAckFn) (codec.Reader, error) {
+			return newParquetCodecReader(r, fn)
+		}, nil
+	})
+	codec.RegisterWriterCodec("parquet", func(params string) (codec.WriterConstructor, codec.WriterConfig, error) {
+		if !strings.HasPrefix(params, "schema=") {
+			return nil, codec.WriterConfig{}, errors.New("parquet codec requires a schema parameter")
+		}
+		schema, err := parquetSchemaFromString(strings.TrimPrefix(params, "schema="))
+		if err != nil {
+			return nil, codec.WriterConfig{}, err
+		}
+		return func(w io.WriteCloser) (codec.Writer, error) {
+			return newParquetCodecWriter(w, schema), nil
+		}, codec.WriterConfig{Truncate: true}, nil
+	})
+}
+
+// parquetSchemaFromString parses a list of columns in the same format as the
+// schema field of the parquet_encode processor.
+func parquetSchemaFromString(schemaStr string) (*parquet.Schema, error) {
+	pConf, err := service.NewConfigSpec().
+		Field(parquetSchemaConfig()).
+		ParseYAML("schema: "+schemaStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse parquet schema: %w", err)
+	}
+
+	schemaConfs, err := pConf.FieldObjectList("schema")
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := parquetGroupFromConfig(schemaConfs, defaultEncodingFn)
+	if err != nil {
+		return nil, err
+	}
+	return parquet.NewSchema("", node), nil
+}
+
+//------------------------------------------------------------------------------
+
+type parquetCodecReader struct {
+	r         io.ReadCloser
+	rdr       *parquet.GenericReader[any]
+	schema    *parquet.Schema
+	eConf     extractConfig
+	sourceAck codec.ReaderAckFn
+
+	rowBuf   []parquet.Row
+	rowIndex int
+	rowCount int
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+// readerAtFrom attempts to use the source directly for random access, which is
+// possible for files and other seekable sources. Otherwise the source is
+// loaded into memory.
+func readerAtFrom(r io.ReadCloser) (io.ReaderAt, int64, error) {
+	if rAt, ok := r.(io.ReaderAt); ok {
+		if f, ok := r.(fs.File); ok {
+			stat, err := f.Stat()
+			if err == nil {
+				return rAt, stat.Size(), nil
+			}
+		}
+		if s, ok := r.(io.Seeker); ok {
+			size, err := s.Seek(0, io.SeekEnd)
+			if err == nil {
+				return rAt, size, nil
+			}
+		}
+	}
+
+	allBytes, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(allBytes), int64(len(allBytes)), nil
+}
+
+func newParquetCodecReader(r io.ReadCloser, ackFn codec.ReaderAckFn) (codec.Reader, error) {
+	rAt, size, err := readerAtFrom(r)
+	if err != nil {
+		return nil, err
+	}
+
+	inFile, err := parquet.OpenFile(rAt, size)
+	if err != nil {
+		return nil, err
+	}
+
+	rdr := parquet.NewGenericReader[any](inFile)
+
+	var once sync.Once
+	return &parquetCodecReader{
+		r:      r,
+		rdr:    rdr,
+		schema: rdr.Schema(),
+		sourceAck: func(ctx context.Context, err error) (ackErr error) {
+			once.Do(func() {
+				ackErr = ackFn(ctx, err)
+			})
+			return
+		},
+		rowBuf: make([]parquet.Row, 64),
+	}, nil
+}
+
+func (p *parquetCodecReader) ack(ctx context.Context, err error) error {
+	p.mut.Lock()
+	p.pending--
+	doAck := p.pending == 0 && p.finished
+	p.mut.Unlock()
+
+	if err != nil {
+		return p.sourceAck(ctx, err)
+	}
+	if doAck {
+		return p.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+// nextRow returns the next row of the file, reading rows from the file in
+// small chunks in order to avoid a read call for each row.
+func (p *parquetCodecReader) nextRow() (parquet.Row, error) {
+	for p.rowIndex >= p.rowCount {
+		n, err := p.rdr.ReadRows(p.rowBuf)
+		p.rowIndex, p.rowCount = 0, n
+		if n > 0 {
+			break
+		}
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	row := p.rowBuf[p.rowIndex]
+	p.rowIndex++
+	return row, nil
+}
+
+func (p *parquetCodecReader) Next(ctx context.Context) ([]*message.Part, codec.ReaderAckFn, error) {
+	row, err := p.nextRow()
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			p.finished = true
+		} else {
+			_ = p.sourceAck(ctx, err)
+		}
+		return nil, nil, err
+	}
+
+	mappedData := map[string]any{}
+	_, _ = p.eConf.extractPQValueGroup(p.schema.Fields(), row, mappedData, 0, 0)
+
+	part := message.NewPart(nil)
+	part.SetStructuredMut(mappedData)
+
+	p.pending++
+	return []*message.Part{part}, p.ack, nil
+}
+
+func (p *parquetCodecReader) Close(ctx context.Context) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if !p.finished {
+		_ = p.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if p.pending == 0 {
+		_ = p.sourceAck(ctx, nil)
+	}
+	_ = p.rdr.Close()
+	return p.r.Close()
+}
+
+//------------------------------------------------------------------------------
+
+// parquetCodecWriter buffers the rows of each batch, which are written as a
+// row group when the writer is flushed.
+type parquetCodecWriter struct {
+	w      io.WriteCloser
+	pWtr   *parquet.GenericWriter[any]
+	schema *parquet.Schema
+	rows   []parquet.Row
+}
+
+func newParquetCodecWriter(w io.WriteCloser, schema *parquet.Schema) *parquetCodecWriter {
+	return &parquetCodecWriter{
+		w:      w,
+		pWtr:   parquet.NewGenericWriter[any](w, schema),
+		schema: schema,
+	}
+}
+
+func (p *parquetCodecWriter) Write(ctx context.Context, part *message.Part) error {
+	v, err := part.AsStructured()
+	if err != nil {
+		return err
+	}
+
+	obj, isObj := v.(map[string]any)
+	if !isObj {
+		return fmt.Errorf("unable to encode message type %T as parquet row", v)
+	}
+
+	row, err := (&inserterConfig{}).toPQValuesGroup(p.schema.Fields(), obj, 0, 0)
+	if err != nil {
+		return err
+	}
+	p.rows = append(p.rows, row)
+	return nil
+}
+
+func (p *parquetCodecWriter) Flush(ctx context.Context) error {
+	if len(p.rows) == 0 {
+		return nil
+	}
+	if _, err := p.pWtr.WriteRows(p.rows); err != nil {
+		return err
+	}
+	p.rows = nil
+	return p.pWtr.Flush()
+}
+
+func (p *parquetCodecWriter) Close(ctx context.Context) error {
+	err := p.Flush(ctx)
+	if err == nil {
+		err = p.pWtr.Close()
+	}
+	if cErr := p.w.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
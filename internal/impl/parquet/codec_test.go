@@ -0,0 +1,100 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/codec"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type closeBuffer struct {
+	bytes.Buffer
+}
+
+func (c *closeBuffer) Close() error {
+	return nil
+}
+
+func TestParquetCodecRoundTrip(t *testing.T) {
+	wCtor, wConf, err := codec.GetWriter(`parquet:schema=[{"name":"id","type":"INT64"},{"name":"name","type":"UTF8","optional":true}]`)
+	require.NoError(t, err)
+	assert.True(t, wConf.Truncate)
+
+	buf := &closeBuffer{}
+	w, err := wCtor(buf)
+	require.NoError(t, err)
+
+	for _, batch := range [][]string{
+		{`{"id":1,"name":"foo"}`, `{"id":2}`},
+		{`{"id":3,"name":"bar"}`},
+	} {
+		for _, m := range batch {
+			require.NoError(t, w.Write(context.Background(), message.NewPart([]byte(m))))
+		}
+		require.NoError(t, codec.FlushWriter(context.Background(), w))
+	}
+	require.NoError(t, w.Close(context.Background()))
+
+	rCtor, err := codec.GetReader("parquet", codec.NewReaderConfig())
+	require.NoError(t, err)
+
+	var ackErr error
+	acked := false
+	r, err := rCtor("", io.NopCloser(bytes.NewReader(buf.Bytes())), func(ctx context.Context, err error) error {
+		acked, ackErr = true, err
+		return nil
+	})
+	require.NoError(t, err)
+
+	var results []string
+	var ackFns []codec.ReaderAckFn
+	for {
+		parts, ackFn, err := r.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.Len(t, parts, 1)
+		results = append(results, string(parts[0].AsBytes()))
+		ackFns = append(ackFns, ackFn)
+	}
+	assert.Equal(t, []string{
+		`{"id":1,"name":"foo"}`,
+		`{"id":2,"name":null}`,
+		`{"id":3,"name":"bar"}`,
+	}, results)
+
+	require.NoError(t, r.Close(context.Background()))
+	assert.False(t, acked)
+
+	for _, fn := range ackFns {
+		require.NoError(t, fn(context.Background(), nil))
+	}
+	assert.True(t, acked)
+	assert.NoError(t, ackErr)
+}
+
+func TestParquetCodecErrors(t *testing.T) {
+	_, _, err := codec.GetWriter("parquet")
+	require.EqualError(t, err, "parquet codec requires a schema parameter")
+
+	_, _, err = codec.GetWriter(`parquet:schema=[{"name":"id","type":"NOPE"}]`)
+	require.Error(t, err)
+
+	_, err = codec.GetReader("parquet:foo", codec.NewReaderConfig())
+	require.EqualError(t, err, "parquet codec does not support parameters, got: foo")
+
+	wCtor, _, err := codec.GetWriter(`parquet:schema=[{"name":"id","type":"INT64"}]`)
+	require.NoError(t, err)
+
+	w, err := wCtor(&closeBuffer{})
+	require.NoError(t, err)
+	require.Error(t, w.Write(context.Background(), message.NewPart([]byte(`["foo"]`))))
+}
@@ -0,0 +1,698 @@
+package pure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/batch"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func eventWindowBufferConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("4.14.0").
+		Categories("Windowing").
+		Summary("Chops a stream of messages into tumbling or sliding windows of fixed temporal size, following a watermark derived from the timestamps of the messages themselves.").
+		Description(`
+A window is a grouping of messages that fit within a discrete measure of time, where each message is assigned a timestamp by the `+"[`timestamp_mapping`](#timestamp_mapping)"+`, which usually extracts a time from the message itself (the event time). Windows are aligned against the zeroth minute of the zeroth hour of the day by default.
+
+Unlike the `+"[`system_window` buffer](/docs/components/buffers/system_window)"+` windows are not closed according to the system clock. Instead the buffer tracks a watermark, which is the latest timestamp observed so far minus the `+"[`allowed_lateness`](#allowed_lateness)"+`, and a window is flushed once the watermark passes its end. This means that windows are flushed correctly when replaying historical data, but also that the final window is not flushed until newer messages arrive.
+
+Messages that arrive with a timestamp older than the watermark, and therefore only belong to windows that have already been flushed, are dropped.
+
+When a message is added to a window it has a metadata field `+"`window_end_timestamp`"+` added to it containing the timestamp of the end of the window as an RFC3339 string.
+
+## Sliding Windows
+
+Sliding windows begin from an offset of the prior windows' beginning rather than its end, and therefore messages may belong to multiple windows. In order to produce sliding windows specify a `+"[`slide` duration](#slide)"+`.
+
+## Checkpointing
+
+By default messages are held in memory until the windows they belong to are flushed and delivered, and are only then acknowledged. During graceful termination any messages belonging to windows that have not been flushed are nacked such that they are re-consumed the next time the service starts.
+
+When a `+"[`checkpoint_cache`](#checkpoint_cache)"+` is specified the open windows, along with the watermark, are instead written to the cache, and messages are acknowledged at the input level once they are written. Each window is stored under its own key, and only windows that have changed are written, along with an index key containing the watermark and the windows that are yet to be delivered. Each time a window is delivered its key is removed. When the buffer starts it restores its state from the checkpoint, and therefore windows survive restarts.
+
+Changes are written to the checkpoint at most once per `+"[`checkpoint_period`](#checkpoint_period)"+`, and therefore messages are acknowledged at the input level with a delay of up to this period.
+
+## Delivery Guarantees
+
+If a flushed window is rejected by the output it is delivered again. When this buffer is configured with a slide duration it is possible for messages to belong to multiple windows, and therefore be delivered multiple times.
+`).
+		Field(service.NewBloblangField("timestamp_mapping").
+			Description(`
+A [Bloblang mapping](/docs/guides/bloblang/about) applied to each message during ingestion that provides the event timestamp to use for allocating it a window.
+
+The timestamp value assigned to `+"`root`"+` must either be a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in ISO 8601 format. If the mapping fails or provides an invalid result the message will be rejected.
+`).
+			Example("root = this.created_at").Example(`root = meta("kafka_timestamp_unix").number()`)).
+		Field(service.NewStringField("size").
+			Description("A duration string describing the size of each window. By default windows are aligned to the zeroth minute and zeroth hour on the UTC clock, meaning windows of 1 hour duration will match the turn of each hour in the day, this can be adjusted with the `offset` field.").
+			Example("30s").Example("10m")).
+		Field(service.NewStringField("slide").
+			Description("An optional duration string describing by how much time the beginning of each window should be offset from the beginning of the previous, and therefore creates sliding windows instead of tumbling. When specified this duration must be smaller than the `size` of the window.").
+			Default("").
+			Example("30s").Example("10m")).
+		Field(service.NewStringField("offset").
+			Description("An optional duration string to offset the beginning of each window by, otherwise they are aligned to the zeroth minute and zeroth hour on the UTC clock. The offset cannot be a larger or equal measure to the window size or the slide.").
+			Default("").
+			Example("-6h").Example("30m")).
+		Field(service.NewStringField("allowed_lateness").
+			Description("An optional duration string describing how far the watermark lags behind the latest observed event timestamp, allowing messages that arrive out of order to be included in their windows.").
+			Default("").
+			Example("10s").Example("1m")).
+		Field(service.NewStringField("checkpoint_cache").
+			Description("An optional [cache resource](/docs/components/caches/about) to checkpoint open windows to, allowing them to survive restarts.").
+			Default("")).
+		Field(service.NewStringField("checkpoint_key").
+			Description("The key under which the checkpoint is stored within the `checkpoint_cache`, the key of each window is prefixed with this value. This must be unique for each buffer sharing a cache.").
+			Default("event_window").
+			Advanced()).
+		Field(service.NewStringField("checkpoint_period").
+			Description("The maximum period of time to wait before writing changes to the checkpoint, allowing changes from multiple batches to be written together. Set to an empty string in order to write changes for each batch.").
+			Default("1s").
+			Advanced()).
+		Example("Replaying Traffic Data", `Given a stream of messages relating to cars passing through various traffic lights, which may be consumed long after they were produced, we can count the cars passing each light for each hour according to when they actually passed, whilst allowing for messages arriving up to five minutes out of order, with the following config:`,
+			`
+input:
+  kafka:
+    addresses: [ TODO ]
+    topics: [ traffic ]
+    consumer_group: benthos_traffic
+    batching:
+      count: 100
+      period: 1s
+
+buffer:
+  event_window:
+    timestamp_mapping: root = this.created_at
+    size: 1h
+    allowed_lateness: 5m
+    checkpoint_cache: window_checkpoints
+
+pipeline:
+  processors:
+    - group_by_value:
+        value: '${! json("traffic_light") }'
+    - mapping: |
+        root = if batch_index() == 0 {
+          {
+            "traffic_light": this.traffic_light,
+            "created_at": meta("window_end_timestamp"),
+            "total_cars": batch_size(),
+          }
+        } else { deleted() }
+
+cache_resources:
+  - label: window_checkpoints
+    redis:
+      url: TODO
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchBuffer(
+		"event_window", eventWindowBufferConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchBuffer, error) {
+			size, err := getDuration(conf, true, "size")
+			if err != nil {
+				return nil, err
+			}
+			slide, err := getDuration(conf, false, "slide")
+			if err != nil {
+				return nil, err
+			}
+			if slide >= size {
+				return nil, fmt.Errorf("invalid window slide '%v' must be lower than the size '%v'", slide, size)
+			}
+			offset, err := getDuration(conf, false, "offset")
+			if err != nil {
+				return nil, err
+			}
+			if offset >= size {
+				return nil, fmt.Errorf("invalid offset '%v' must be lower than the size '%v'", offset, size)
+			}
+			if slide > 0 && offset >= slide {
+				return nil, fmt.Errorf("invalid offset '%v' must be lower than the slide '%v'", offset, slide)
+			}
+			allowedLateness, err := getDuration(conf, false, "allowed_lateness")
+			if err != nil {
+				return nil, err
+			}
+			tsMapping, err := conf.FieldBloblang("timestamp_mapping")
+			if err != nil {
+				return nil, err
+			}
+			w := newEventWindowBuffer(tsMapping, size, slide, offset, allowedLateness, mgr.Logger())
+
+			if w.checkpointCache, err = conf.FieldString("checkpoint_cache"); err != nil {
+				return nil, err
+			}
+			if w.checkpointCache != "" {
+				if !mgr.HasCache(w.checkpointCache) {
+					return nil, fmt.Errorf("cache resource '%v' was not found", w.checkpointCache)
+				}
+				if w.checkpointKey, err = conf.FieldString("checkpoint_key"); err != nil {
+					return nil, err
+				}
+				if w.checkpointPeriod, err = getDuration(conf, false, "checkpoint_period"); err != nil {
+					return nil, err
+				}
+				w.res = mgr
+			}
+			return w, nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type eventWindow struct {
+	start, end time.Time
+	pending    []*tsMessage
+}
+
+type eventWindowBuffer struct {
+	logger *service.Logger
+
+	tsMapping                            *bloblang.Executor
+	size, slide, offset, allowedLateness time.Duration
+
+	res              *service.Resources
+	checkpointCache  string
+	checkpointKey    string
+	checkpointPeriod time.Duration
+
+	// The start times of windows that have changed since the last checkpoint,
+	// the acknowledgements of batches waiting for the next checkpoint, and the
+	// timer that triggers it.
+	checkpointDirty map[int64]struct{}
+	checkpointAcks  []service.AckFunc
+	checkpointTimer *time.Timer
+
+	// The windows that are yet to be flushed, keyed by their start time in unix
+	// nanoseconds.
+	windows map[int64]*eventWindow
+
+	// Windows that have been flushed but not yet acknowledged, and windows
+	// that were rejected and must be delivered again.
+	inFlight map[*eventWindow]struct{}
+	retries  []*eventWindow
+
+	maxTS      time.Time
+	restored   bool
+	pendingMut sync.Mutex
+
+	writeSignal chan struct{}
+
+	endOfInputChan      chan struct{}
+	closeEndOfInputOnce sync.Once
+}
+
+func newEventWindowBuffer(
+	tsMapping *bloblang.Executor,
+	size, slide, offset, allowedLateness time.Duration,
+	logger *service.Logger,
+) *eventWindowBuffer {
+	return &eventWindowBuffer{
+		logger:          logger,
+		tsMapping:       tsMapping,
+		size:            size,
+		slide:           slide,
+		offset:          offset,
+		allowedLateness: allowedLateness,
+		windows:         map[int64]*eventWindow{},
+		inFlight:        map[*eventWindow]struct{}{},
+		checkpointDirty: map[int64]struct{}{},
+		writeSignal:     make(chan struct{}, 1),
+		endOfInputChan:  make(chan struct{}),
+	}
+}
+
+func (w *eventWindowBuffer) watermark() time.Time {
+	return w.maxTS.Add(-w.allowedLateness)
+}
+
+// windowStarts returns the start times of all windows that a timestamp
+// belongs to, in unix nanoseconds.
+func (w *eventWindowBuffer) windowStarts(ts time.Time) []int64 {
+	epoch := int64(w.size)
+	if w.slide > 0 {
+		epoch = int64(w.slide)
+	}
+
+	n := ts.UnixNano()
+	rem := (n - int64(w.offset)) % epoch
+	if rem < 0 {
+		rem += epoch
+	}
+
+	var starts []int64
+	for start := n - rem; start+int64(w.size) > n; start -= epoch {
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+func (w *eventWindowBuffer) getTimestamp(i int, msgBatch service.MessageBatch) (time.Time, error) {
+	return getMappedTimestamp(w.logger, w.tsMapping, i, msgBatch)
+}
+
+// addMessage adds a message to each window it belongs to that has not yet
+// passed the watermark, returning false if there were none.
+func (w *eventWindowBuffer) addMessage(watermark, ts time.Time, msg *service.Message, ackFn service.AckFunc) bool {
+	added := false
+	for _, start := range w.windowStarts(ts) {
+		startTS := time.Unix(0, start).UTC()
+		endTS := startTS.Add(w.size)
+		if !endTS.After(watermark) {
+			// This window has already been flushed.
+			continue
+		}
+		win, exists := w.windows[start]
+		if !exists {
+			win = &eventWindow{start: startTS, end: endTS}
+			w.windows[start] = win
+		}
+		win.pending = append(win.pending, &tsMessage{ts: ts, m: msg, ackFn: ackFn})
+		w.checkpointDirty[start] = struct{}{}
+		added = true
+	}
+	return added
+}
+
+func (w *eventWindowBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageBatch, aFn service.AckFunc) error {
+	timestamps := make([]time.Time, len(msgBatch))
+	for i := range msgBatch {
+		var err error
+		if timestamps[i], err = w.getTimestamp(i, msgBatch); err != nil {
+			return err
+		}
+	}
+
+	w.pendingMut.Lock()
+	defer w.pendingMut.Unlock()
+
+	if err := w.restoreCheckpoint(ctx); err != nil {
+		return err
+	}
+
+	// Messages of a batch are all compared against the watermark prior to the
+	// batch, and therefore their order within it doesn't matter.
+	watermark := w.watermark()
+	updateMaxTS := func() {
+		for _, ts := range timestamps {
+			if ts.After(w.maxTS) {
+				w.maxTS = ts
+			}
+		}
+	}
+
+	if w.checkpointCache != "" {
+		// Messages are acknowledged once they're written to the checkpoint
+		// and so no longer need to be tracked individually.
+		for i, msg := range msgBatch {
+			if !w.addMessage(watermark, timestamps[i], msg, nil) {
+				w.logger.Debugf("Dropping message with timestamp %v older than the watermark", timestamps[i])
+			}
+		}
+		updateMaxTS()
+		w.signalWrite()
+		if w.checkpointPeriod <= 0 {
+			if err := w.writeCheckpoint(ctx); err != nil {
+				return err
+			}
+			return aFn(ctx, nil)
+		}
+		w.checkpointAcks = append(w.checkpointAcks, aFn)
+		w.scheduleCheckpoint()
+		return nil
+	}
+
+	aggregatedAck := batch.NewCombinedAcker(batch.AckFunc(aFn))
+	for i, msg := range msgBatch {
+		ackFn := service.AckFunc(aggregatedAck.Derive())
+		if !w.addMessage(watermark, timestamps[i], msg, ackFn) {
+			// Reject messages too old to fit into a window by acknowledging
+			// them.
+			w.logger.Debugf("Dropping message with timestamp %v older than the watermark", timestamps[i])
+			_ = ackFn(ctx, nil)
+		}
+	}
+	updateMaxTS()
+	w.signalWrite()
+	return nil
+}
+
+func (w *eventWindowBuffer) signalWrite() {
+	select {
+	case w.writeSignal <- struct{}{}:
+	default:
+	}
+}
+
+// nextWindow returns the oldest window that is ready to be flushed, or nil if
+// no windows are ready.
+func (w *eventWindowBuffer) nextWindow() *eventWindow {
+	if len(w.retries) > 0 {
+		win := w.retries[0]
+		w.retries[0] = nil
+		w.retries = w.retries[1:]
+		return win
+	}
+
+	watermark := w.watermark()
+
+	var oldestStart int64
+	var oldest *eventWindow
+	for start, win := range w.windows {
+		if win.end.After(watermark) {
+			continue
+		}
+		if oldest == nil || win.start.Before(oldest.start) {
+			oldest, oldestStart = win, start
+		}
+	}
+	if oldest != nil {
+		delete(w.windows, oldestStart)
+	}
+	return oldest
+}
+
+func (w *eventWindowBuffer) flushWindow(win *eventWindow) (service.MessageBatch, service.AckFunc) {
+	w.inFlight[win] = struct{}{}
+
+	flushBatch := make(service.MessageBatch, 0, len(win.pending))
+	for _, pending := range win.pending {
+		tmpMsg := pending.m.Copy()
+		tmpMsg.MetaSet("window_end_timestamp", win.end.Format(time.RFC3339Nano))
+		flushBatch = append(flushBatch, tmpMsg)
+	}
+
+	return flushBatch, func(ctx context.Context, err error) error {
+		w.pendingMut.Lock()
+		defer w.pendingMut.Unlock()
+
+		if err != nil {
+			w.retries = append(w.retries, win)
+			w.signalWrite()
+			return nil
+		}
+
+		delete(w.inFlight, win)
+		for _, pending := range win.pending {
+			if pending.ackFn != nil {
+				_ = pending.ackFn(ctx, nil)
+			}
+		}
+		if w.checkpointCache != "" {
+			w.checkpointDirty[win.start.UnixNano()] = struct{}{}
+			if w.checkpointPeriod > 0 {
+				w.scheduleCheckpoint()
+			} else if cErr := w.writeCheckpoint(ctx); cErr != nil {
+				w.logger.Errorf("Failed to write checkpoint: %v", cErr)
+			}
+		}
+		return nil
+	}
+}
+
+func (w *eventWindowBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	for {
+		w.pendingMut.Lock()
+		if err := w.restoreCheckpoint(ctx); err != nil {
+			w.pendingMut.Unlock()
+			return nil, nil, err
+		}
+		if win := w.nextWindow(); win != nil {
+			msgBatch, aFn := w.flushWindow(win)
+			w.pendingMut.Unlock()
+			return msgBatch, aFn, nil
+		}
+		w.pendingMut.Unlock()
+
+		select {
+		case <-w.writeSignal:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-w.endOfInputChan:
+			w.pendingMut.Lock()
+			if len(w.retries) > 0 {
+				w.pendingMut.Unlock()
+				continue
+			}
+			// Nack all pending messages so that we re-consume them on the next
+			// start up, when checkpointing these messages are already
+			// acknowledged and will be restored instead.
+			for _, win := range w.windows {
+				for _, pending := range win.pending {
+					if pending.ackFn != nil {
+						_ = pending.ackFn(ctx, errWindowClosed)
+					}
+				}
+			}
+			w.windows = map[int64]*eventWindow{}
+			w.pendingMut.Unlock()
+			return nil, nil, service.ErrEndOfBuffer
+		}
+	}
+}
+
+func (w *eventWindowBuffer) EndOfInput() {
+	w.closeEndOfInputOnce.Do(func() {
+		close(w.endOfInputChan)
+	})
+}
+
+func (w *eventWindowBuffer) Close(ctx context.Context) error {
+	w.pendingMut.Lock()
+	defer w.pendingMut.Unlock()
+
+	if w.checkpointTimer != nil {
+		w.checkpointTimer.Stop()
+		w.checkpointTimer = nil
+	}
+	if w.checkpointCache == "" || (len(w.checkpointDirty) == 0 && len(w.checkpointAcks) == 0) {
+		return nil
+	}
+	return w.writeCheckpoint(ctx)
+}
+
+//------------------------------------------------------------------------------
+
+type eventWindowCheckpoint struct {
+	MaxTS   int64   `json:"max_ts"`
+	Windows []int64 `json:"windows"`
+}
+
+type eventWindowCheckpointMessage struct {
+	TS       int64          `json:"ts"`
+	Content  []byte         `json:"content"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+func (w *eventWindowBuffer) windowKey(start int64) string {
+	return w.checkpointKey + "_" + strconv.FormatInt(start, 10)
+}
+
+// scheduleCheckpoint starts a timer for writing the checkpoint if one isn't
+// already running. Must be called whilst holding the pending mutex.
+func (w *eventWindowBuffer) scheduleCheckpoint() {
+	if w.checkpointTimer != nil {
+		return
+	}
+	w.checkpointTimer = time.AfterFunc(w.checkpointPeriod, func() {
+		w.pendingMut.Lock()
+		defer w.pendingMut.Unlock()
+
+		w.checkpointTimer = nil
+		if err := w.writeCheckpoint(context.Background()); err != nil {
+			w.logger.Errorf("Failed to write checkpoint: %v", err)
+			w.scheduleCheckpoint()
+		}
+	})
+}
+
+// writeCheckpoint writes the windows that have changed since the previous
+// checkpoint to the checkpoint cache along with the index, removes the windows
+// that have been delivered, and then acknowledges all batches awaiting the
+// checkpoint. Must be called whilst holding the pending mutex.
+func (w *eventWindowBuffer) writeCheckpoint(ctx context.Context) error {
+	if w.checkpointCache == "" {
+		return nil
+	}
+
+	live := make(map[int64]*eventWindow, len(w.windows)+len(w.inFlight))
+	for start, win := range w.windows {
+		live[start] = win
+	}
+	for win := range w.inFlight {
+		live[win.start.UnixNano()] = win
+	}
+
+	cp := eventWindowCheckpoint{
+		MaxTS:   w.maxTS.UnixNano(),
+		Windows: make([]int64, 0, len(live)),
+	}
+	for start := range live {
+		cp.Windows = append(cp.Windows, start)
+	}
+	sort.Slice(cp.Windows, func(i, j int) bool {
+		return cp.Windows[i] < cp.Windows[j]
+	})
+
+	var removed []int64
+	sets := map[string][]byte{}
+	for start := range w.checkpointDirty {
+		win, exists := live[start]
+		if !exists {
+			removed = append(removed, start)
+			continue
+		}
+		winBytes, err := marshalEventWindow(win)
+		if err != nil {
+			return err
+		}
+		sets[w.windowKey(start)] = winBytes
+	}
+
+	cpBytes, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	// Windows are written prior to the index so that the index never refers
+	// to a window that is missing, and are removed only once the index no
+	// longer refers to them.
+	var cacheErr error
+	if err := w.res.AccessCache(ctx, w.checkpointCache, func(c service.Cache) {
+		for k, v := range sets {
+			if cacheErr = c.Set(ctx, k, v, nil); cacheErr != nil {
+				return
+			}
+		}
+		if cacheErr = c.Set(ctx, w.checkpointKey, cpBytes, nil); cacheErr != nil {
+			return
+		}
+		for _, start := range removed {
+			if err := c.Delete(ctx, w.windowKey(start)); err != nil && !errors.Is(err, service.ErrKeyNotFound) {
+				w.logger.Warnf("Failed to remove delivered window from checkpoint: %v", err)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if cacheErr != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", cacheErr)
+	}
+
+	w.checkpointDirty = map[int64]struct{}{}
+	acks := w.checkpointAcks
+	w.checkpointAcks = nil
+	for _, aFn := range acks {
+		_ = aFn(ctx, nil)
+	}
+	return nil
+}
+
+func marshalEventWindow(win *eventWindow) ([]byte, error) {
+	msgs := make([]eventWindowCheckpointMessage, 0, len(win.pending))
+	for _, pending := range win.pending {
+		content, err := pending.m.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		cpMsg := eventWindowCheckpointMessage{
+			TS:      pending.ts.UnixNano(),
+			Content: content,
+		}
+		_ = pending.m.MetaWalkMut(func(key string, value any) error {
+			if cpMsg.Metadata == nil {
+				cpMsg.Metadata = map[string]any{}
+			}
+			cpMsg.Metadata[key] = value
+			return nil
+		})
+		msgs = append(msgs, cpMsg)
+	}
+	return json.Marshal(msgs)
+}
+
+// restoreCheckpoint loads the windows stored within the checkpoint cache the
+// first time it is called. Must be called whilst holding the pending mutex.
+func (w *eventWindowBuffer) restoreCheckpoint(ctx context.Context) error {
+	if w.restored || w.checkpointCache == "" {
+		return nil
+	}
+
+	var cpBytes []byte
+	var getErr error
+	if err := w.res.AccessCache(ctx, w.checkpointCache, func(c service.Cache) {
+		cpBytes, getErr = c.Get(ctx, w.checkpointKey)
+	}); err != nil {
+		return err
+	}
+	if getErr != nil {
+		if errors.Is(getErr, service.ErrKeyNotFound) {
+			w.restored = true
+			return nil
+		}
+		return fmt.Errorf("failed to read checkpoint: %w", getErr)
+	}
+
+	var cp eventWindowCheckpoint
+	if err := json.Unmarshal(cpBytes, &cp); err != nil {
+		return fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+
+	windows := map[int64]*eventWindow{}
+	for _, start := range cp.Windows {
+		var winBytes []byte
+		if err := w.res.AccessCache(ctx, w.checkpointCache, func(c service.Cache) {
+			winBytes, getErr = c.Get(ctx, w.windowKey(start))
+		}); err != nil {
+			return err
+		}
+		if getErr != nil {
+			if errors.Is(getErr, service.ErrKeyNotFound) {
+				w.logger.Warnf("Window %v missing from checkpoint, skipping", start)
+				continue
+			}
+			return fmt.Errorf("failed to read checkpoint window: %w", getErr)
+		}
+
+		var cpMsgs []eventWindowCheckpointMessage
+		if err := json.Unmarshal(winBytes, &cpMsgs); err != nil {
+			return fmt.Errorf("failed to parse checkpoint window: %w", err)
+		}
+
+		startTS := time.Unix(0, start).UTC()
+		win := &eventWindow{start: startTS, end: startTS.Add(w.size)}
+		for _, cpMsg := range cpMsgs {
+			msg := service.NewMessage(cpMsg.Content)
+			for k, v := range cpMsg.Metadata {
+				msg.MetaSetMut(k, v)
+			}
+			win.pending = append(win.pending, &tsMessage{
+				ts: time.Unix(0, cpMsg.TS).UTC(),
+				m:  msg,
+			})
+		}
+		windows[start] = win
+	}
+
+	w.maxTS = time.Unix(0, cp.MaxTS).UTC()
+	w.windows = windows
+	w.restored = true
+	return nil
+}
@@ -0,0 +1,269 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func eventWindowRead(t testing.TB, w *eventWindowBuffer) (service.MessageBatch, service.AckFunc) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	msgBatch, aFn, err := w.ReadBatch(ctx)
+	require.NoError(t, err)
+	return msgBatch, aFn
+}
+
+func eventWindowReadEmpty(t testing.TB, w *eventWindowBuffer) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer done()
+
+	_, _, err := w.ReadBatch(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestEventWindowStarts(t *testing.T) {
+	tests := []struct {
+		size, slide, offset time.Duration
+		ts                  int64
+		starts              []int64
+	}{
+		{size: 10, ts: 25, starts: []int64{20}},
+		{size: 10, ts: 20, starts: []int64{20}},
+		{size: 10, ts: -5, starts: []int64{-10}},
+		{size: 10, offset: 3, ts: 25, starts: []int64{23}},
+		{size: 10, slide: 5, ts: 27, starts: []int64{25, 20}},
+		{size: 10, slide: 3, ts: 27, starts: []int64{27, 24, 21, 18}},
+	}
+
+	for _, test := range tests {
+		w := newEventWindowBuffer(nil, test.size, test.slide, test.offset, 0, nil)
+		assert.Equal(t, test.starts, w.windowStarts(time.Unix(0, test.ts)), "%+v", test)
+	}
+}
+
+func TestEventWindowWatermark(t *testing.T) {
+	mapping, err := bloblang.Parse(`root = this.ts`)
+	require.NoError(t, err)
+
+	w := newEventWindowBuffer(mapping, time.Second, 0, 0, time.Millisecond*500, nil)
+
+	var ackErrs []error
+	ackFn := func(ctx context.Context, err error) error {
+		ackErrs = append(ackErrs, err)
+		return nil
+	}
+
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"1","ts":10.1}`)),
+		service.NewMessage([]byte(`{"id":"2","ts":10.9}`)),
+		service.NewMessage([]byte(`{"id":"3","ts":11.2}`)),
+	}, ackFn))
+
+	// The watermark is at 10.7 and so nothing can be flushed yet.
+	eventWindowReadEmpty(t, w)
+
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"4","ts":11.6}`)),
+		service.NewMessage([]byte(`{"id":"5","ts":10.5}`)),
+	}, ackFn))
+
+	msgBatch, aFn := eventWindowRead(t, w)
+	assert.Equal(t, []string{
+		`{"id":"1","ts":10.1}`,
+		`{"id":"2","ts":10.9}`,
+		`{"id":"5","ts":10.5}`,
+	}, keyedWindowBatchContents(t, msgBatch))
+
+	endTS, _ := msgBatch[0].MetaGet("window_end_timestamp")
+	assert.Equal(t, "1970-01-01T00:00:11Z", endTS)
+
+	// Rejected windows are delivered again.
+	require.NoError(t, aFn(context.Background(), errors.New("nope")))
+	msgBatch, aFn = eventWindowRead(t, w)
+	assert.Len(t, msgBatch, 3)
+	require.NoError(t, aFn(context.Background(), nil))
+	assert.Empty(t, ackErrs)
+
+	// Messages older than the watermark are dropped.
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"6","ts":10.8}`)),
+	}, ackFn))
+	assert.Equal(t, []error{nil}, ackErrs)
+
+	w.EndOfInput()
+	_, _, err = w.ReadBatch(context.Background())
+	require.ErrorIs(t, err, service.ErrEndOfBuffer)
+
+	// The batches containing messages of the incomplete window are nacked.
+	require.Len(t, ackErrs, 3)
+	assert.True(t, errors.Is(ackErrs[1], errWindowClosed))
+	assert.True(t, errors.Is(ackErrs[2], errWindowClosed))
+}
+
+func TestEventWindowSliding(t *testing.T) {
+	mapping, err := bloblang.Parse(`root = this.ts`)
+	require.NoError(t, err)
+
+	w := newEventWindowBuffer(mapping, time.Second, time.Millisecond*500, 0, 0, nil)
+
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"1","ts":10.2}`)),
+		service.NewMessage([]byte(`{"id":"2","ts":10.7}`)),
+		service.NewMessage([]byte(`{"id":"3","ts":11.6}`)),
+	}, noopAck))
+
+	msgBatch, _ := eventWindowRead(t, w)
+	assert.Equal(t, []string{`{"id":"1","ts":10.2}`}, keyedWindowBatchContents(t, msgBatch))
+
+	msgBatch, _ = eventWindowRead(t, w)
+	assert.Equal(t, []string{`{"id":"1","ts":10.2}`, `{"id":"2","ts":10.7}`}, keyedWindowBatchContents(t, msgBatch))
+
+	msgBatch, _ = eventWindowRead(t, w)
+	assert.Equal(t, []string{`{"id":"2","ts":10.7}`}, keyedWindowBatchContents(t, msgBatch))
+
+	eventWindowReadEmpty(t, w)
+}
+
+func TestEventWindowCheckpoint(t *testing.T) {
+	mapping, err := bloblang.Parse(`root = this.ts`)
+	require.NoError(t, err)
+
+	res := service.MockResources(service.MockResourcesOptAddCache("foo"))
+	newBuffer := func() *eventWindowBuffer {
+		w := newEventWindowBuffer(mapping, time.Second, 0, 0, 0, nil)
+		w.res, w.checkpointCache, w.checkpointKey = res, "foo", "bar"
+		return w
+	}
+
+	w := newBuffer()
+
+	var ackErrs []error
+	ackFn := func(ctx context.Context, err error) error {
+		ackErrs = append(ackErrs, err)
+		return nil
+	}
+
+	msg := service.NewMessage([]byte(`{"id":"1","ts":10.1}`))
+	msg.MetaSetMut("foo", "bar")
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		msg,
+		service.NewMessage([]byte(`{"id":"2","ts":11.1}`)),
+		service.NewMessage([]byte(`{"id":"3","ts":12.1}`)),
+	}, ackFn))
+
+	// Messages are acknowledged once they're checkpointed.
+	assert.Equal(t, []error{nil}, ackErrs)
+
+	msgBatch, aFn := eventWindowRead(t, w)
+	assert.Equal(t, []string{`{"id":"1","ts":10.1}`}, keyedWindowBatchContents(t, msgBatch))
+	require.NoError(t, aFn(context.Background(), nil))
+
+	// Delivered but unacknowledged windows are also restored.
+	msgBatch, _ = eventWindowRead(t, w)
+	assert.Equal(t, []string{`{"id":"2","ts":11.1}`}, keyedWindowBatchContents(t, msgBatch))
+
+	w.EndOfInput()
+	_, _, err = w.ReadBatch(context.Background())
+	require.ErrorIs(t, err, service.ErrEndOfBuffer)
+
+	w = newBuffer()
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"4","ts":10.9}`)),
+		service.NewMessage([]byte(`{"id":"5","ts":13.1}`)),
+	}, ackFn))
+
+	msgBatch, aFn = eventWindowRead(t, w)
+	assert.Equal(t, []string{`{"id":"2","ts":11.1}`}, keyedWindowBatchContents(t, msgBatch))
+	require.NoError(t, aFn(context.Background(), nil))
+
+	msgBatch, aFn = eventWindowRead(t, w)
+	assert.Equal(t, []string{`{"id":"3","ts":12.1}`}, keyedWindowBatchContents(t, msgBatch))
+	require.NoError(t, aFn(context.Background(), nil))
+
+	eventWindowReadEmpty(t, w)
+}
+
+func TestEventWindowCheckpointPeriod(t *testing.T) {
+	mapping, err := bloblang.Parse(`root = this.ts`)
+	require.NoError(t, err)
+
+	res := service.MockResources(service.MockResourcesOptAddCache("foo"))
+	w := newEventWindowBuffer(mapping, time.Second, 0, 0, 0, nil)
+	w.res, w.checkpointCache, w.checkpointKey = res, "foo", "bar"
+	w.checkpointPeriod = time.Millisecond * 50
+
+	cacheGet := func(key string) (string, error) {
+		var v []byte
+		var getErr error
+		require.NoError(t, res.AccessCache(context.Background(), "foo", func(c service.Cache) {
+			v, getErr = c.Get(context.Background(), key)
+		}))
+		return string(v), getErr
+	}
+
+	var ackMut sync.Mutex
+	var ackErrs []error
+	ackFn := func(ctx context.Context, err error) error {
+		ackMut.Lock()
+		ackErrs = append(ackErrs, err)
+		ackMut.Unlock()
+		return nil
+	}
+	ackCount := func() int {
+		ackMut.Lock()
+		defer ackMut.Unlock()
+		return len(ackErrs)
+	}
+
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"1","ts":10}`)),
+	}, ackFn))
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"2","ts":11}`)),
+	}, ackFn))
+
+	// Batches are acknowledged once the checkpoint covering them is written.
+	assert.Equal(t, 0, ackCount())
+	assert.Eventually(t, func() bool {
+		return ackCount() == 2
+	}, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, []error{nil, nil}, ackErrs)
+
+	index, err := cacheGet("bar")
+	require.NoError(t, err)
+	assert.Equal(t, `{"max_ts":11000000000,"windows":[10000000000,11000000000]}`, index)
+
+	window, err := cacheGet("bar_10000000000")
+	require.NoError(t, err)
+	assert.Contains(t, window, `"ts":10000000000`)
+
+	// Delivered windows are removed from the checkpoint.
+	msgBatch, aFn := eventWindowRead(t, w)
+	assert.Equal(t, []string{`{"id":"1","ts":10}`}, keyedWindowBatchContents(t, msgBatch))
+	require.NoError(t, aFn(context.Background(), nil))
+
+	assert.Eventually(t, func() bool {
+		_, err := cacheGet("bar_10000000000")
+		return errors.Is(err, service.ErrKeyNotFound)
+	}, time.Second*5, time.Millisecond*10)
+
+	index, err = cacheGet("bar")
+	require.NoError(t, err)
+	assert.Equal(t, `{"max_ts":11000000000,"windows":[11000000000]}`, index)
+
+	require.NoError(t, w.Close(context.Background()))
+}
@@ -18,7 +18,7 @@ func tumblingWindowBufferConfig() *service.ConfigSpec {
 		Beta().
 		Version("3.53.0").
 		Categories("Windowing").
-		Summary("Chops a stream of messages into tumbling or sliding windows of fixed temporal size, following the system clock.").
+		Summary("Chops a stream of messages into tumbling, sliding, session or count based windows, following the system clock.").
 		Description(`
 A window is a grouping of messages that fit within a discrete measure of time following the system clock. Messages are allocated to a window either by the processing time (the time at which they're ingested) or by the event time, and this is controlled via the `+"[`timestamp_mapping` field](#timestamp_mapping)"+`.
 
@@ -45,7 +45,25 @@ This buffer honours the transaction model within Benthos in order to ensure that
 When this buffer is configured with a slide duration it is possible for messages to belong to multiple windows, and therefore be delivered multiple times. In this case the first time the message is delivered it will be acked (or nacked) and subsequent deliveries of the same message will be a "best attempt".
 
 During graceful termination if the current window is partially populated with messages they will be nacked such that they are re-consumed the next time the service starts.
+
+## Session Windows
+
+When the `+"[`mode`](#mode)"+` is set to `+"`session`"+` messages are grouped by the result of the `+"[`key_mapping`](#key_mapping)"+`, and a window for a given key is flushed once no new messages of that key have been received for the duration of the `+"[`gap`](#gap)"+`, following the system clock. The fields `+"`timestamp_mapping`, `size`, `slide`, `offset` and `allowed_lateness`"+` are ignored in this mode.
+
+## Count Windows
+
+When the `+"[`mode`](#mode)"+` is set to `+"`count`"+` messages are grouped by the result of the `+"[`key_mapping`](#key_mapping)"+`, and a window for a given key is flushed once it contains `+"[`count`](#count)"+` messages. If a `+"`gap`"+` is also specified then partially populated windows are flushed once no new messages of that key have been received for that duration.
+
+Session and count windows are held in memory until they are flushed, and therefore memory usage grows with the number of active keys. When a session or count window is flushed its messages have the metadata fields `+"`window_key`"+`, containing the key of the window, and `+"`window_end_timestamp`"+`, containing the time at which the window was closed as an RFC3339 string.
 `).
+		Field(service.NewStringAnnotatedEnumField("mode", map[string]string{
+			"time":    "Tumbling or sliding windows of fixed temporal size, configured with the `size`, `slide`, `offset` and `allowed_lateness` fields.",
+			"session": "Windows per key that are flushed after a period of inactivity configured with the `gap` field.",
+			"count":   "Windows per key that are flushed once they contain `count` messages.",
+		}).
+			Description("The windowing mode to use.").
+			Default("time").
+			Version("4.14.0")).
 		Field(service.NewBloblangField("timestamp_mapping").
 			Description(`
 A [Bloblang mapping](/docs/guides/bloblang/about) applied to each message during ingestion that provides the timestamp to use for allocating it a window. By default the function `+"`now()`"+` is used in order to generate a fresh timestamp at the time of ingestion (the processing time), whereas this mapping can instead extract a timestamp from the message itself (the event time).
@@ -55,7 +73,8 @@ The timestamp value assigned to `+"`root`"+` must either be a numerical unix tim
 			Default("root = now()").
 			Example("root = this.created_at").Example(`root = meta("kafka_timestamp_unix").number()`)).
 		Field(service.NewStringField("size").
-			Description("A duration string describing the size of each window. By default windows are aligned to the zeroth minute and zeroth hour on the UTC clock, meaning windows of 1 hour duration will match the turn of each hour in the day, this can be adjusted with the `offset` field.").
+			Description("A duration string describing the size of each window. By default windows are aligned to the zeroth minute and zeroth hour on the UTC clock, meaning windows of 1 hour duration will match the turn of each hour in the day, this can be adjusted with the `offset` field. This field is required when the `mode` is `time`.").
+			Default("").
 			Example("30s").Example("10m")).
 		Field(service.NewStringField("slide").
 			Description("An optional duration string describing by how much time the beginning of each window should be offset from the beginning of the previous, and therefore creates sliding windows instead of tumbling. When specified this duration must be smaller than the `size` of the window.").
@@ -69,6 +88,26 @@ The timestamp value assigned to `+"`root`"+` must either be a numerical unix tim
 			Description("An optional duration string describing the length of time to wait after a window has ended before flushing it, allowing late arrivals to be included. Since this windowing buffer uses the system clock an allowed lateness can improve the matching of messages when using event time.").
 			Default("").
 			Example("10s").Example("1m")).
+		Field(service.NewBloblangField("key_mapping").
+			Description("A [Bloblang mapping](/docs/guides/bloblang/about) applied to each message during ingestion that provides the key of the session or count window it belongs to. This field is only used when the `mode` is `session` or `count`. If the mapping fails the message is rejected.").
+			Default(`root = ""`).
+			Example(`root = this.user_id`).
+			Example(`root = meta("kafka_key")`).
+			Version("4.14.0")).
+		Field(service.NewStringField("gap").
+			Description("A duration string describing the length of inactivity after which the window of a key is flushed. This field is required when the `mode` is `session`, and optional when the `mode` is `count`.").
+			Default("").
+			Example("30s").Example("10m").
+			Version("4.14.0")).
+		Field(service.NewIntField("count").
+			Description("The number of messages of a key after which its window is flushed. This field is required when the `mode` is `count`.").
+			Default(0).
+			Version("4.14.0")).
+		LintRule(`root = match {
+  this.mode.or("time") == "time" && this.size.or("") == "" => [ "field size is required" ],
+  this.mode.or("time") == "session" && this.gap.or("") == "" => [ "field gap is required when mode is session" ],
+  this.mode.or("time") == "count" && this.count.or(0) <= 0 => [ "field count must be greater than zero when mode is count" ],
+}`).
 		Example("Counting Passengers at Traffic", `Given a stream of messages relating to cars passing through various traffic lights of the form:
 
 `+"```json"+`
@@ -115,6 +154,26 @@ pipeline:
             "passengers": json("passengers").from_all().sum(),
           }
         } else { deleted() }
+`,
+		).
+		Example("User Sessions", `Group the events of each user into sessions, where a session ends once the user has been inactive for ten minutes, and emit a single summary message per session:`,
+			`
+buffer:
+  system_window:
+    mode: session
+    key_mapping: root = this.user_id
+    gap: 10m
+
+pipeline:
+  processors:
+    - mapping: |
+        root = if batch_index() == 0 {
+          {
+            "user_id": meta("window_key"),
+            "ended_at": meta("window_end_timestamp"),
+            "events": json("event").from_all(),
+          }
+        } else { deleted() }
 `,
 		)
 }
@@ -138,6 +197,14 @@ func init() {
 	err := service.RegisterBatchBuffer(
 		"system_window", tumblingWindowBufferConfig(),
 		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchBuffer, error) {
+			mode, err := conf.FieldString("mode")
+			if err != nil {
+				return nil, err
+			}
+			if mode != "time" {
+				return keyedWindowBufferFromConfig(mode, conf, mgr)
+			}
+
 			size, err := getDuration(conf, true, "size")
 			if err != nil {
 				return nil, err
@@ -266,9 +333,15 @@ func (w *systemWindowBuffer) nextSystemWindow() (prevStart, prevEnd, start, end
 }
 
 func (w *systemWindowBuffer) getTimestamp(i int, batch service.MessageBatch) (ts time.Time, err error) {
+	return getMappedTimestamp(w.logger, w.tsMapping, i, batch)
+}
+
+// getMappedTimestamp executes a timestamp mapping against a message of a batch
+// and attempts to parse the result as a timestamp.
+func getMappedTimestamp(logger *service.Logger, tsMapping *bloblang.Executor, i int, batch service.MessageBatch) (ts time.Time, err error) {
 	var tsValueMsg *service.Message
-	if tsValueMsg, err = batch.BloblangQuery(i, w.tsMapping); err != nil {
-		w.logger.Errorf("Timestamp mapping failed for message: %v", err)
+	if tsValueMsg, err = batch.BloblangQuery(i, tsMapping); err != nil {
+		logger.Errorf("Timestamp mapping failed for message: %v", err)
 		err = fmt.Errorf("timestamp mapping failed: %w", err)
 		return
 	}
@@ -281,13 +354,13 @@ func (w *systemWindowBuffer) getTimestamp(i int, batch service.MessageBatch) (ts
 		}
 	}
 	if err != nil {
-		w.logger.Errorf("Timestamp mapping failed for message: unable to parse result as structured value: %v", err)
+		logger.Errorf("Timestamp mapping failed for message: unable to parse result as structured value: %v", err)
 		err = fmt.Errorf("unable to parse result of timestamp mapping as structured value: %w", err)
 		return
 	}
 
 	if ts, err = query.IGetTimestamp(tsValue); err != nil {
-		w.logger.Errorf("Timestamp mapping failed for message: %v", err)
+		logger.Errorf("Timestamp mapping failed for message: %v", err)
 		err = fmt.Errorf("unable to parse result of timestamp mapping as timestamp: %w", err)
 	}
 	return
@@ -0,0 +1,306 @@
+package pure
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/batch"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func keyedWindowBufferFromConfig(mode string, conf *service.ParsedConfig, mgr *service.Resources) (*keyedWindowBuffer, error) {
+	keyMapping, err := conf.FieldBloblang("key_mapping")
+	if err != nil {
+		return nil, err
+	}
+	gap, err := getDuration(conf, mode == "session", "gap")
+	if err != nil {
+		return nil, err
+	}
+	count, err := conf.FieldInt("count")
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case "session":
+		if gap <= 0 {
+			return nil, fmt.Errorf("invalid gap '%v' must be greater than zero", gap)
+		}
+		count = 0
+	case "count":
+		if count <= 0 {
+			return nil, fmt.Errorf("invalid count '%v' must be greater than zero", count)
+		}
+	default:
+		return nil, fmt.Errorf("unrecognised window mode: %v", mode)
+	}
+
+	return newKeyedWindowBuffer(keyMapping, func() time.Time {
+		return time.Now().UTC()
+	}, gap, count, mgr.Logger()), nil
+}
+
+//------------------------------------------------------------------------------
+
+type keyedWindow struct {
+	key      string
+	lastSeen time.Time
+	closedAt time.Time
+	pending  []*tsMessage
+
+	// The index of the window within the deadline heap, or -1 if it isn't
+	// within the heap.
+	index int
+}
+
+// keyedWindowHeap is a min-heap of windows ordered by the time they were last
+// seen, and therefore by their deadline.
+type keyedWindowHeap []*keyedWindow
+
+func (h keyedWindowHeap) Len() int { return len(h) }
+
+func (h keyedWindowHeap) Less(i, j int) bool {
+	return h[i].lastSeen.Before(h[j].lastSeen)
+}
+
+func (h keyedWindowHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *keyedWindowHeap) Push(x any) {
+	win := x.(*keyedWindow)
+	win.index = len(*h)
+	*h = append(*h, win)
+}
+
+func (h *keyedWindowHeap) Pop() any {
+	old := *h
+	n := len(old)
+	win := old[n-1]
+	old[n-1] = nil
+	win.index = -1
+	*h = old[:n-1]
+	return win
+}
+
+// keyedWindowBuffer groups messages into windows by a key, where a window is
+// flushed either after a period of inactivity (session windows) or once it
+// reaches a number of messages (count windows).
+type keyedWindowBuffer struct {
+	logger *service.Logger
+
+	keyMapping *bloblang.Executor
+	clock      utcNowProvider
+	gap        time.Duration
+	count      int
+
+	windows    map[string]*keyedWindow
+	deadlines  keyedWindowHeap
+	ready      []*keyedWindow
+	pendingMut sync.Mutex
+
+	writeSignal chan struct{}
+
+	endOfInputChan      chan struct{}
+	closeEndOfInputOnce sync.Once
+}
+
+func newKeyedWindowBuffer(
+	keyMapping *bloblang.Executor,
+	clock utcNowProvider,
+	gap time.Duration,
+	count int,
+	logger *service.Logger,
+) *keyedWindowBuffer {
+	return &keyedWindowBuffer{
+		logger:         logger,
+		keyMapping:     keyMapping,
+		clock:          clock,
+		gap:            gap,
+		count:          count,
+		windows:        map[string]*keyedWindow{},
+		writeSignal:    make(chan struct{}, 1),
+		endOfInputChan: make(chan struct{}),
+	}
+}
+
+func (w *keyedWindowBuffer) getKey(i int, msgBatch service.MessageBatch) (string, error) {
+	keyMsg, err := msgBatch.BloblangQuery(i, w.keyMapping)
+	if err != nil {
+		w.logger.Errorf("Key mapping failed for message: %v", err)
+		return "", fmt.Errorf("key mapping failed: %w", err)
+	}
+	if keyMsg == nil {
+		return "", nil
+	}
+	keyBytes, err := keyMsg.AsBytes()
+	if err != nil {
+		return "", err
+	}
+	return string(keyBytes), nil
+}
+
+func (w *keyedWindowBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageBatch, aFn service.AckFunc) error {
+	keys := make([]string, len(msgBatch))
+	for i := range msgBatch {
+		var err error
+		if keys[i], err = w.getKey(i, msgBatch); err != nil {
+			return err
+		}
+	}
+
+	w.pendingMut.Lock()
+	defer w.pendingMut.Unlock()
+
+	now := w.clock()
+	aggregatedAck := batch.NewCombinedAcker(batch.AckFunc(aFn))
+	for i, msg := range msgBatch {
+		win, exists := w.windows[keys[i]]
+		if !exists {
+			win = &keyedWindow{key: keys[i], index: -1}
+			w.windows[keys[i]] = win
+		}
+		win.lastSeen = now
+		if w.gap > 0 {
+			if win.index < 0 {
+				heap.Push(&w.deadlines, win)
+			} else {
+				heap.Fix(&w.deadlines, win.index)
+			}
+		}
+		win.pending = append(win.pending, &tsMessage{
+			ts: now, m: msg, ackFn: service.AckFunc(aggregatedAck.Derive()),
+		})
+		if w.count > 0 && len(win.pending) >= w.count {
+			win.closedAt = now
+			w.ready = append(w.ready, win)
+			delete(w.windows, keys[i])
+			if win.index >= 0 {
+				heap.Remove(&w.deadlines, win.index)
+			}
+		}
+	}
+
+	select {
+	case w.writeSignal <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// nextWindow returns the next window ready to be flushed, or, when none are
+// ready, the duration to wait until the next window expires, which is zero if
+// there are no pending windows with a deadline.
+func (w *keyedWindowBuffer) nextWindow() (*keyedWindow, time.Duration) {
+	w.pendingMut.Lock()
+	defer w.pendingMut.Unlock()
+
+	if len(w.ready) > 0 {
+		win := w.ready[0]
+		w.ready[0] = nil
+		w.ready = w.ready[1:]
+		return win, 0
+	}
+	if w.gap <= 0 {
+		return nil, 0
+	}
+
+	if len(w.deadlines) == 0 {
+		return nil, 0
+	}
+
+	oldest := w.deadlines[0]
+	deadline := oldest.lastSeen.Add(w.gap)
+	if waitFor := deadline.Sub(w.clock()); waitFor > 0 {
+		return nil, waitFor
+	}
+	heap.Pop(&w.deadlines)
+	oldest.closedAt = deadline
+	delete(w.windows, oldest.key)
+	return oldest, 0
+}
+
+func (w *keyedWindowBuffer) flushWindow(win *keyedWindow) (service.MessageBatch, service.AckFunc) {
+	flushBatch := make(service.MessageBatch, 0, len(win.pending))
+	flushAcks := make([]service.AckFunc, 0, len(win.pending))
+	for _, pending := range win.pending {
+		tmpMsg := pending.m.Copy()
+		tmpMsg.MetaSet("window_key", win.key)
+		tmpMsg.MetaSet("window_end_timestamp", win.closedAt.Format(time.RFC3339Nano))
+		flushBatch = append(flushBatch, tmpMsg)
+		flushAcks = append(flushAcks, pending.ackFn)
+	}
+	return flushBatch, func(ctx context.Context, err error) error {
+		for _, aFn := range flushAcks {
+			_ = aFn(ctx, err)
+		}
+		return nil
+	}
+}
+
+func (w *keyedWindowBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	for {
+		win, waitFor := w.nextWindow()
+		if win != nil {
+			msgBatch, aFn := w.flushWindow(win)
+			return msgBatch, aFn, nil
+		}
+
+		var timer *time.Timer
+		var timerChan <-chan time.Time
+		if waitFor > 0 {
+			timer = time.NewTimer(waitFor)
+			timerChan = timer.C
+		}
+		stopTimer := func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+
+		select {
+		case <-timerChan:
+		case <-w.writeSignal:
+			stopTimer()
+		case <-ctx.Done():
+			stopTimer()
+			return nil, nil, ctx.Err()
+		case <-w.endOfInputChan:
+			stopTimer()
+			// Windows that are complete are still flushed, but partially
+			// populated windows are nacked so that we re-consume them on the
+			// next start up.
+			w.pendingMut.Lock()
+			if len(w.ready) > 0 {
+				w.pendingMut.Unlock()
+				continue
+			}
+			for _, win := range w.windows {
+				for _, pending := range win.pending {
+					_ = pending.ackFn(ctx, errWindowClosed)
+				}
+			}
+			w.windows = map[string]*keyedWindow{}
+			w.deadlines = nil
+			w.pendingMut.Unlock()
+			return nil, nil, service.ErrEndOfBuffer
+		}
+	}
+}
+
+func (w *keyedWindowBuffer) EndOfInput() {
+	w.closeEndOfInputOnce.Do(func() {
+		close(w.endOfInputChan)
+	})
+}
+
+func (w *keyedWindowBuffer) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,174 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func keyedWindowBatchContents(t testing.TB, b service.MessageBatch) []string {
+	t.Helper()
+
+	var contents []string
+	for _, m := range b {
+		mBytes, err := m.AsBytes()
+		require.NoError(t, err)
+		contents = append(contents, string(mBytes))
+	}
+	return contents
+}
+
+func TestSessionWindowBuffer(t *testing.T) {
+	mapping, err := bloblang.Parse(`root = this.user`)
+	require.NoError(t, err)
+
+	currentTS := time.Unix(10, 0).UTC()
+	w := newKeyedWindowBuffer(mapping, func() time.Time {
+		return currentTS
+	}, time.Second, 0, nil)
+
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"user":"a","id":1}`)),
+		service.NewMessage([]byte(`{"user":"b","id":2}`)),
+	}, noopAck))
+
+	currentTS = time.Unix(10, 500000000).UTC()
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"user":"a","id":3}`)),
+	}, noopAck))
+
+	// The session of b expires first as a has been active more recently.
+	currentTS = time.Unix(11, 200000000).UTC()
+
+	resBatch, _, err := w.ReadBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"user":"b","id":2}`}, keyedWindowBatchContents(t, resBatch))
+
+	key, _ := resBatch[0].MetaGet("window_key")
+	assert.Equal(t, "b", key)
+	endTS, _ := resBatch[0].MetaGet("window_end_timestamp")
+	assert.Equal(t, "1970-01-01T00:00:11Z", endTS)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*50)
+	_, _, err = w.ReadBatch(ctx)
+	done()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	currentTS = time.Unix(11, 600000000).UTC()
+
+	resBatch, _, err = w.ReadBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"user":"a","id":1}`, `{"user":"a","id":3}`}, keyedWindowBatchContents(t, resBatch))
+}
+
+func TestSessionWindowBufferExpiryOrder(t *testing.T) {
+	mapping, err := bloblang.Parse(`root = this.user`)
+	require.NoError(t, err)
+
+	currentTS := time.Unix(10, 0).UTC()
+	w := newKeyedWindowBuffer(mapping, func() time.Time {
+		return currentTS
+	}, time.Second, 0, nil)
+
+	write := func(users ...string) {
+		t.Helper()
+		var b service.MessageBatch
+		for _, u := range users {
+			b = append(b, service.NewMessage([]byte(`{"user":"`+u+`"}`)))
+		}
+		require.NoError(t, w.WriteBatch(context.Background(), b, noopAck))
+		currentTS = currentTS.Add(time.Millisecond * 10)
+	}
+
+	write("a", "b", "c")
+	write("d", "e")
+	write("a", "c")
+	write("f")
+	write("d")
+
+	currentTS = currentTS.Add(time.Second)
+
+	var keys []string
+	for i := 0; i < 6; i++ {
+		resBatch, _, err := w.ReadBatch(context.Background())
+		require.NoError(t, err)
+		key, _ := resBatch[0].MetaGet("window_key")
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"b", "e"}, keys[:2])
+	assert.ElementsMatch(t, []string{"a", "c"}, keys[2:4])
+	assert.Equal(t, []string{"f", "d"}, keys[4:])
+	assert.Empty(t, w.deadlines)
+	assert.Empty(t, w.windows)
+}
+
+func TestCountWindowBuffer(t *testing.T) {
+	mapping, err := bloblang.Parse(`root = this.user`)
+	require.NoError(t, err)
+
+	currentTS := time.Unix(10, 0).UTC()
+	w := newKeyedWindowBuffer(mapping, func() time.Time {
+		return currentTS
+	}, 0, 2, nil)
+
+	var ackErrs []error
+	ackFn := func(ctx context.Context, err error) error {
+		ackErrs = append(ackErrs, err)
+		return nil
+	}
+
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"user":"a","id":1}`)),
+		service.NewMessage([]byte(`{"user":"b","id":2}`)),
+	}, ackFn))
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"user":"b","id":3}`)),
+		service.NewMessage([]byte(`{"user":"b","id":4}`)),
+	}, ackFn))
+
+	resBatch, aFn, err := w.ReadBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"user":"b","id":2}`, `{"user":"b","id":3}`}, keyedWindowBatchContents(t, resBatch))
+	require.NoError(t, aFn(context.Background(), nil))
+	assert.Empty(t, ackErrs)
+
+	w.EndOfInput()
+
+	_, _, err = w.ReadBatch(context.Background())
+	require.ErrorIs(t, err, service.ErrEndOfBuffer)
+
+	// Both input batches contain messages of incomplete windows and are
+	// therefore rejected.
+	require.Len(t, ackErrs, 2)
+	for _, err := range ackErrs {
+		assert.True(t, errors.Is(err, errWindowClosed))
+	}
+}
+
+func TestCountWindowBufferGap(t *testing.T) {
+	mapping, err := bloblang.Parse(`root = this.user`)
+	require.NoError(t, err)
+
+	currentTS := time.Unix(10, 0).UTC()
+	w := newKeyedWindowBuffer(mapping, func() time.Time {
+		return currentTS
+	}, time.Second, 3, nil)
+
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"user":"a","id":1}`)),
+		service.NewMessage([]byte(`{"user":"a","id":2}`)),
+	}, noopAck))
+
+	currentTS = time.Unix(11, 0).UTC()
+
+	resBatch, _, err := w.ReadBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"user":"a","id":1}`, `{"user":"a","id":2}`}, keyedWindowBatchContents(t, resBatch))
+}
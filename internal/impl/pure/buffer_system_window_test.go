@@ -90,6 +90,36 @@ system_window:
 `,
 			buildErrContains: "invalid allowed_lateness",
 		},
+		{
+			config: `
+system_window:
+  mode: session
+  key_mapping: root = this.id
+  gap: 10s
+`,
+		},
+		{
+			config: `
+system_window:
+  mode: session
+`,
+			lintErrContains: "field gap is required when mode is session",
+		},
+		{
+			config: `
+system_window:
+  mode: count
+  count: 10
+  gap: 1m
+`,
+		},
+		{
+			config: `
+system_window:
+  mode: count
+`,
+			lintErrContains: "field count must be greater than zero when mode is count",
+		},
 	}
 
 	for i, test := range tests {
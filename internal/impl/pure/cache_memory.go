@@ -1,7 +1,11 @@
 package pure
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -196,6 +200,30 @@ func (m *memoryCache) Get(_ context.Context, key string) ([]byte, error) {
 	return k.value, nil
 }
 
+func (m *memoryCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if v, err := m.Get(ctx, key); err == nil {
+			values[key] = v
+		}
+	}
+	return values, nil
+}
+
+func (m *memoryCache) Keys(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for _, shard := range m.shards {
+		shard.RLock()
+		for k, v := range shard.items {
+			if strings.HasPrefix(k, prefix) && !shard.isExpired(v) {
+				keys = append(keys, k)
+			}
+		}
+		shard.RUnlock()
+	}
+	return keys, nil
+}
+
 func (m *memoryCache) Set(_ context.Context, key string, value []byte, ttl *time.Duration) error {
 	var expires time.Time
 	if ttl != nil {
@@ -239,6 +267,66 @@ func (m *memoryCache) Delete(_ context.Context, key string) error {
 	return nil
 }
 
+func (m *memoryCache) Incr(_ context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	shard := m.getShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	var current int64
+	k, exists := shard.items[key]
+	if exists && !shard.isExpired(k) {
+		var err error
+		if current, err = strconv.ParseInt(string(k.value), 10, 64); err != nil {
+			return 0, fmt.Errorf("value of key '%v' is not an integer: %w", key, err)
+		}
+	} else {
+		exists = false
+	}
+	current += delta
+
+	// The expiry of existing keys is only changed when a TTL is specified.
+	expires := k.expires
+	if ttl != nil {
+		expires = time.Now().Add(*ttl)
+	} else if !exists {
+		expires = time.Now().Add(m.defaultTTL)
+	}
+
+	shard.compaction()
+	shard.items[key] = item{value: []byte(strconv.FormatInt(current, 10)), expires: expires}
+	return current, nil
+}
+
+func (m *memoryCache) CompareAndSwap(_ context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	var expires time.Time
+	if ttl != nil {
+		expires = time.Now().Add(*ttl)
+	} else {
+		expires = time.Now().Add(m.defaultTTL)
+	}
+	shard := m.getShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	k, exists := shard.items[key]
+	if exists && shard.isExpired(k) {
+		exists = false
+	}
+	if oldValue == nil {
+		if exists {
+			return service.ErrKeyAlreadyExists
+		}
+	} else if !exists {
+		return service.ErrKeyNotFound
+	} else if !bytes.Equal(k.value, oldValue) {
+		return service.ErrValueMismatch
+	}
+
+	shard.compaction()
+	shard.items[key] = item{value: newValue, expires: expires}
+	return nil
+}
+
 func (m *memoryCache) Close(context.Context) error {
 	return nil
 }
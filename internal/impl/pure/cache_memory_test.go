@@ -3,6 +3,7 @@ package pure
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -253,3 +254,99 @@ shards: 10
 		assert.Equal(b, value, res)
 	}
 }
+
+func TestMemoryCacheIncr(t *testing.T) {
+	c := newMemCache(time.Minute, 0, 1, map[string]string{
+		"foo": "10",
+		"bar": "nope",
+	})
+	ctx := context.Background()
+
+	v, err := c.Incr(ctx, "foo", 5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), v)
+
+	v, err = c.Incr(ctx, "baz", -3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-3), v)
+
+	b, err := c.Get(ctx, "baz")
+	require.NoError(t, err)
+	assert.Equal(t, "-3", string(b))
+
+	_, err = c.Incr(ctx, "bar", 1, nil)
+	require.Error(t, err)
+}
+
+func TestMemoryCacheIncrParallel(t *testing.T) {
+	c := newMemCache(time.Minute, 0, 4, nil)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := c.Incr(ctx, "foo", 1, nil)
+				require.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	b, err := c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "1000", string(b))
+}
+
+func TestMemoryCacheCompareAndSwap(t *testing.T) {
+	c := newMemCache(time.Minute, 0, 1, map[string]string{
+		"foo": "a",
+	})
+	ctx := context.Background()
+
+	assert.Equal(t, service.ErrValueMismatch, c.CompareAndSwap(ctx, "foo", []byte("b"), []byte("c"), nil))
+	assert.Equal(t, service.ErrKeyNotFound, c.CompareAndSwap(ctx, "bar", []byte("b"), []byte("c"), nil))
+	assert.Equal(t, service.ErrKeyAlreadyExists, c.CompareAndSwap(ctx, "foo", nil, []byte("c"), nil))
+
+	require.NoError(t, c.CompareAndSwap(ctx, "foo", []byte("a"), []byte("b"), nil))
+	require.NoError(t, c.CompareAndSwap(ctx, "bar", nil, []byte("c"), nil))
+
+	b, err := c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(b))
+
+	b, err = c.Get(ctx, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "c", string(b))
+}
+
+func TestMemoryCacheGetMultiAndKeys(t *testing.T) {
+	c := newMemCache(time.Minute, time.Minute, 4, map[string]string{
+		"foo/a": "1",
+		"foo/b": "2",
+		"bar/a": "3",
+	})
+
+	ctx := context.Background()
+
+	ttl := time.Millisecond
+	require.NoError(t, c.Set(ctx, "foo/c", []byte("4"), &ttl))
+	<-time.After(time.Millisecond * 5)
+
+	values, err := c.GetMulti(ctx, "foo/a", "bar/a", "foo/c", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"foo/a": []byte("1"),
+		"bar/a": []byte("3"),
+	}, values)
+
+	keys, err := c.Keys(ctx, "foo/")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo/a", "foo/b"}, keys)
+
+	keys, err = c.Keys(ctx, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo/a", "foo/b", "bar/a"}, keys)
+}
@@ -2,8 +2,11 @@ package pure
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
@@ -35,9 +38,10 @@ For use cases where you wish to cache the result of processors consider using th
 This processor will interpolate functions within the ` + "`key` and `value`" + ` fields individually for each message. This allows you to specify dynamic keys and values based on the contents of the message payloads and metadata. You can find a list of functions [here](/docs/configuration/interpolation#bloblang-queries).`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldString("resource", "The [`cache` resource](/docs/components/caches/about) to target with this processor."),
-			docs.FieldString("operator", "The [operation](#operators) to perform with the cache.").HasOptions("set", "add", "get", "delete"),
+			docs.FieldString("operator", "The [operation](#operators) to perform with the cache.").HasOptions("set", "add", "get", "delete", "incr", "compare_and_swap", "keys"),
 			docs.FieldString("key", "A key to use with the cache.").IsInterpolated(),
 			docs.FieldString("value", "A value to use with the cache (when applicable).").IsInterpolated(),
+			docs.FieldString("old_value", "The value expected to currently be stored under the key when using the `compare_and_swap` operator. When empty the key is expected not to exist.").IsInterpolated().AtVersion("4.14.0").Advanced(),
 			docs.FieldString(
 				"ttl", "The TTL of each individual item as a duration string. After this period an item will be eligible for removal during the next compaction. Not all caches support per-key TTLs, those that do will have a configuration field `default_ttl`, and those that do not will fall back to their generally configured TTL setting.",
 				"60s", "5m", "36h",
@@ -139,10 +143,41 @@ Retrieve the contents of a cached key and replace the original message payload
 with the result. If the key does not exist the action fails with an error, which
 can be detected with [processor error handling](/docs/configuration/error_handling).
 
+When processing a batch of more than one message the keys of the whole batch are
+retrieved with a single request where supported by the cache (` + "`memcached`, `memory` and `redis`" + `),
+which significantly reduces the number of round trips.
+
 ### ` + "`delete`" + `
 
 Delete a key and its contents from the cache.  If the key does not exist the
-action is a no-op and will not fail with an error.`,
+action is a no-op and will not fail with an error.
+
+### ` + "`incr`" + `
+
+Atomically increment the integer stored under a key by the amount given in the
+` + "`value`" + ` field (defaulting to 1 when empty, and negative values
+decrement), and replace the original message payload with the resulting value.
+If the key does not exist it is created with the value of the increment. Only
+supported by caches that implement atomic increments (` + "`memory`, `redis`, `memcached` and `dynamodb`" + `),
+otherwise the action fails with an error.
+
+### ` + "`compare_and_swap`" + `
+
+Set a key in the cache to a value only if it currently holds the contents of the
+` + "`old_value`" + ` field, or when ` + "`old_value`" + ` is empty only if the
+key does not yet exist. If the stored value does not match the action fails with
+a 'value does not match' error, which can be detected with
+[processor error handling](/docs/configuration/error_handling). Only supported by
+caches that implement compare-and-swap (` + "`memory`, `redis`, `memcached` and `dynamodb`" + `).
+
+### ` + "`keys`" + `
+
+List the keys held within the cache that begin with the prefix given by the
+` + "`key`" + ` field (an empty key lists all keys), and replace the original
+message payload with a sorted JSON array of the results. This is intended for
+debugging the contents of a cache and is only supported by caches able to list
+their keys (` + "`memory`, `redis`, `file` and `ristretto`" + `), otherwise the
+action fails with an error.`,
 	})
 	if err != nil {
 		panic(err)
@@ -152,13 +187,15 @@ action is a no-op and will not fail with an error.`,
 //------------------------------------------------------------------------------
 
 type cacheProc struct {
-	key   *field.Expression
-	value *field.Expression
-	ttl   *field.Expression
-
-	mgr       bundle.NewManagement
-	cacheName string
-	operator  cacheOperator
+	key      *field.Expression
+	value    *field.Expression
+	oldValue *field.Expression
+	ttl      *field.Expression
+
+	mgr           bundle.NewManagement
+	cacheName     string
+	operator      cacheOperator
+	multiOperator cacheMultiOperator
 }
 
 func newCache(conf processor.CacheConfig, mgr bundle.NewManagement) (*cacheProc, error) {
@@ -172,6 +209,13 @@ func newCache(conf processor.CacheConfig, mgr bundle.NewManagement) (*cacheProc,
 		return nil, err
 	}
 
+	// Operators that have a batched equivalent use it automatically when
+	// processing batches of more than one message.
+	var multiOp cacheMultiOperator
+	if conf.Operator == "get" {
+		multiOp = newCacheGetMultiOperator()
+	}
+
 	key, err := mgr.BloblEnvironment().NewField(conf.Key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse key expression: %v", err)
@@ -182,6 +226,11 @@ func newCache(conf processor.CacheConfig, mgr bundle.NewManagement) (*cacheProc,
 		return nil, fmt.Errorf("failed to parse value expression: %v", err)
 	}
 
+	oldValue, err := mgr.BloblEnvironment().NewField(conf.OldValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old_value expression: %v", err)
+	}
+
 	ttl, err := mgr.BloblEnvironment().NewField(conf.TTL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ttl expression: %v", err)
@@ -192,48 +241,92 @@ func newCache(conf processor.CacheConfig, mgr bundle.NewManagement) (*cacheProc,
 	}
 
 	return &cacheProc{
-		key:   key,
-		value: value,
-		ttl:   ttl,
-
-		mgr:       mgr,
-		cacheName: cacheName,
-		operator:  op,
+		key:      key,
+		value:    value,
+		oldValue: oldValue,
+		ttl:      ttl,
+
+		mgr:           mgr,
+		cacheName:     cacheName,
+		operator:      op,
+		multiOperator: multiOp,
 	}, nil
 }
 
 //------------------------------------------------------------------------------
 
-type cacheOperator func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error)
+type cacheOperator func(ctx context.Context, cache cache.V1, key string, value, oldValue []byte, ttl *time.Duration) ([]byte, bool, error)
 
 func newCacheSetOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, value, oldValue []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Set(ctx, key, value, ttl)
 		return nil, false, err
 	}
 }
 
 func newCacheAddOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, value, oldValue []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Add(ctx, key, value, ttl)
 		return nil, false, err
 	}
 }
 
 func newCacheGetOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, _ []byte, _ *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, _, _ []byte, _ *time.Duration) ([]byte, bool, error) {
 		result, err := cache.Get(ctx, key)
 		return result, true, err
 	}
 }
 
 func newCacheDeleteOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, _, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Delete(ctx, key)
 		return nil, false, err
 	}
 }
 
+func newCacheIncrOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, value, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
+		delta := int64(1)
+		if len(value) > 0 {
+			var err error
+			if delta, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+				return nil, false, fmt.Errorf("value must be an integer: %w", err)
+			}
+		}
+		result, err := cache.Incr(ctx, c, key, delta, ttl)
+		if err != nil {
+			return nil, false, err
+		}
+		return strconv.AppendInt(nil, result, 10), true, nil
+	}
+}
+
+func newCacheCompareAndSwapOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, value, oldValue []byte, ttl *time.Duration) ([]byte, bool, error) {
+		if len(oldValue) == 0 {
+			oldValue = nil
+		}
+		err := cache.CompareAndSwap(ctx, c, key, oldValue, value, ttl)
+		return nil, false, err
+	}
+}
+
+func newCacheKeysOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, _, _ []byte, _ *time.Duration) ([]byte, bool, error) {
+		keys, err := cache.Keys(ctx, c, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if keys == nil {
+			keys = []string{}
+		}
+		sort.Strings(keys)
+		result, err := json.Marshal(keys)
+		return result, true, err
+	}
+}
+
 func cacheOperatorFromString(operator string) (cacheOperator, error) {
 	switch operator {
 	case "set":
@@ -244,13 +337,83 @@ func cacheOperatorFromString(operator string) (cacheOperator, error) {
 		return newCacheGetOperator(), nil
 	case "delete":
 		return newCacheDeleteOperator(), nil
+	case "incr":
+		return newCacheIncrOperator(), nil
+	case "compare_and_swap":
+		return newCacheCompareAndSwapOperator(), nil
+	case "keys":
+		return newCacheKeysOperator(), nil
 	}
 	return nil, fmt.Errorf("operator not recognised: %v", operator)
 }
 
+// cacheMultiOperator is a batched variant of an operator that obtains a result
+// for multiple keys in a single call.
+type cacheMultiOperator func(ctx context.Context, cache cache.V1, keys []string) (map[string][]byte, error)
+
+func newCacheGetMultiOperator() cacheMultiOperator {
+	return func(ctx context.Context, cache cache.V1, keys []string) (map[string][]byte, error) {
+		return cache.GetMulti(ctx, keys)
+	}
+}
+
 //------------------------------------------------------------------------------
 
+func (c *cacheProc) processBatchMulti(ctx *processor.BatchProcContext, msg message.Batch) ([]message.Batch, error) {
+	keys := make([]string, msg.Len())
+	keyErrs := make([]error, msg.Len())
+	uniqueKeys := make([]string, 0, msg.Len())
+	seenKeys := make(map[string]struct{}, msg.Len())
+	_ = msg.Iter(func(index int, part *message.Part) error {
+		key, err := c.key.String(index, msg)
+		if err != nil {
+			keyErrs[index] = fmt.Errorf("key interpolation error: %w", err)
+			return nil
+		}
+		keys[index] = key
+		if _, exists := seenKeys[key]; !exists {
+			seenKeys[key] = struct{}{}
+			uniqueKeys = append(uniqueKeys, key)
+		}
+		return nil
+	})
+
+	var results map[string][]byte
+	var err error
+	if len(uniqueKeys) > 0 {
+		if cerr := c.mgr.AccessCache(ctx.Context(), c.cacheName, func(cache cache.V1) {
+			results, err = c.multiOperator(ctx.Context(), cache, uniqueKeys)
+		}); cerr != nil {
+			err = cerr
+		}
+	}
+
+	_ = msg.Iter(func(index int, part *message.Part) error {
+		if keyErrs[index] != nil {
+			ctx.OnError(keyErrs[index], index, nil)
+			return nil
+		}
+		if err != nil {
+			ctx.OnError(fmt.Errorf("operator failed for key '%s': %v", keys[index], err), index, nil)
+			return nil
+		}
+		result, exists := results[keys[index]]
+		if !exists {
+			ctx.OnError(fmt.Errorf("operator failed for key '%s': %v", keys[index], component.ErrKeyNotFound), index, nil)
+			return nil
+		}
+		part.SetBytes(result)
+		return nil
+	})
+
+	return []message.Batch{msg}, nil
+}
+
 func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Batch) ([]message.Batch, error) {
+	if c.multiOperator != nil && msg.Len() > 1 {
+		return c.processBatchMulti(ctx, msg)
+	}
+
 	_ = msg.Iter(func(index int, part *message.Part) error {
 		key, err := c.key.String(index, msg)
 		if err != nil {
@@ -266,6 +429,13 @@ func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Ba
 			return nil
 		}
 
+		oldValue, err := c.oldValue.Bytes(index, msg)
+		if err != nil {
+			err = fmt.Errorf("old_value interpolation error: %w", err)
+			ctx.OnError(err, index, nil)
+			return nil
+		}
+
 		var ttl *time.Duration
 		ttls, err := c.ttl.String(index, msg)
 		if err != nil {
@@ -287,15 +457,18 @@ func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Ba
 		var result []byte
 		var useResult bool
 		if cerr := c.mgr.AccessCache(context.Background(), c.cacheName, func(cache cache.V1) {
-			result, useResult, err = c.operator(context.Background(), cache, key, value, ttl)
+			result, useResult, err = c.operator(context.Background(), cache, key, value, oldValue, ttl)
 		}); cerr != nil {
 			err = cerr
 		}
 		if err != nil {
-			if err != component.ErrKeyAlreadyExists {
-				err = fmt.Errorf("operator failed for key '%s': %v", key, err)
-			} else {
+			switch {
+			case errors.Is(err, component.ErrKeyAlreadyExists):
 				err = fmt.Errorf("key already exists: %v", key)
+			case errors.Is(err, component.ErrValueMismatch):
+				err = fmt.Errorf("value does not match for key: %v", key)
+			default:
+				err = fmt.Errorf("operator failed for key '%s': %v", key, err)
 			}
 			ctx.OnError(err, index, nil)
 			return nil
@@ -202,3 +202,160 @@ func TestCacheDelete(t *testing.T) {
 	_, ok = mgr.Caches["foocache"]["3"]
 	require.False(t, ok)
 }
+
+func TestCacheIncr(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "10"},
+		"3": {Value: "nope"},
+	}
+
+	conf := processor.NewConfig()
+	conf.Type = "cache"
+	conf.Cache.Operator = "incr"
+	conf.Cache.Key = "${!json(\"key\")}"
+	conf.Cache.Value = "${!json(\"delta\")}"
+	conf.Cache.Resource = "foocache"
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","delta":5}`),
+		[]byte(`{"key":"2","delta":""}`),
+		[]byte(`{"key":"1","delta":-20}`),
+		[]byte(`{"key":"3","delta":1}`),
+		[]byte(`{"key":"2","delta":"not a number"}`),
+	})
+
+	output, res := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, "15", string(output[0].Get(0).AsBytes()))
+	assert.Equal(t, "1", string(output[0].Get(1).AsBytes()))
+	assert.Equal(t, "-5", string(output[0].Get(2).AsBytes()))
+
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.NoError(t, output[0].Get(1).ErrorGet())
+	assert.NoError(t, output[0].Get(2).ErrorGet())
+	assert.Error(t, output[0].Get(3).ErrorGet())
+	assert.Error(t, output[0].Get(4).ErrorGet())
+
+	assert.Equal(t, "-5", mgr.Caches["foocache"]["1"].Value)
+	assert.Equal(t, "1", mgr.Caches["foocache"]["2"].Value)
+}
+
+func TestCacheCompareAndSwap(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "foo"},
+	}
+
+	conf := processor.NewConfig()
+	conf.Type = "cache"
+	conf.Cache.Operator = "compare_and_swap"
+	conf.Cache.Key = "${!json(\"key\")}"
+	conf.Cache.Value = "${!json(\"new\")}"
+	conf.Cache.OldValue = "${!json(\"old\")}"
+	conf.Cache.Resource = "foocache"
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","old":"foo","new":"bar"}`),
+		[]byte(`{"key":"1","old":"foo","new":"baz"}`),
+		[]byte(`{"key":"2","old":"","new":"first"}`),
+		[]byte(`{"key":"2","old":"","new":"second"}`),
+	})
+
+	output, res := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	if exp, act := message.GetAllBytes(input), message.GetAllBytes(output[0]); !reflect.DeepEqual(exp, act) {
+		t.Errorf("Wrong result messages: %s != %s", act, exp)
+	}
+
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.EqualError(t, output[0].Get(1).ErrorGet(), "value does not match for key: 1")
+	assert.NoError(t, output[0].Get(2).ErrorGet())
+	assert.EqualError(t, output[0].Get(3).ErrorGet(), "key already exists: 2")
+
+	assert.Equal(t, "bar", mgr.Caches["foocache"]["1"].Value)
+	assert.Equal(t, "first", mgr.Caches["foocache"]["2"].Value)
+}
+
+func TestCacheGetMulti(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "foo 1"},
+		"2": {Value: "foo 2"},
+	}
+
+	conf := processor.NewConfig()
+	conf.Type = "cache"
+	conf.Cache.Key = "${!json(\"key\")}"
+	conf.Cache.Resource = "foocache"
+	conf.Cache.Operator = "get"
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"key":"1"}`),
+		[]byte(`{"key":"2"}`),
+		[]byte(`{"key":"1"}`),
+		[]byte(`{"key":"3"}`),
+		[]byte(`not json`),
+	})
+
+	output, res := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, [][]byte{
+		[]byte(`foo 1`),
+		[]byte(`foo 2`),
+		[]byte(`foo 1`),
+		[]byte(`{"key":"3"}`),
+		[]byte(`not json`),
+	}, message.GetAllBytes(output[0]))
+
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.NoError(t, output[0].Get(1).ErrorGet())
+	assert.NoError(t, output[0].Get(2).ErrorGet())
+	assert.EqualError(t, output[0].Get(3).ErrorGet(), "operator failed for key '3': key does not exist")
+	assert.Error(t, output[0].Get(4).ErrorGet())
+}
+
+func TestCacheKeys(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"foo/2": {Value: "foo 2"},
+		"foo/1": {Value: "foo 1"},
+		"bar/1": {Value: "bar 1"},
+	}
+
+	conf := processor.NewConfig()
+	conf.Type = "cache"
+	conf.Cache.Key = "${!content()}"
+	conf.Cache.Resource = "foocache"
+	conf.Cache.Operator = "keys"
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`foo/`),
+		[]byte(``),
+		[]byte(`baz/`),
+	})
+
+	output, res := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, [][]byte{
+		[]byte(`["foo/1","foo/2"]`),
+		[]byte(`["bar/1","foo/1","foo/2"]`),
+		[]byte(`[]`),
+	}, message.GetAllBytes(output[0]))
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"sync"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/docs"
@@ -18,6 +20,8 @@ import (
 	"github.com/golang/protobuf/jsonpb"
 	// nolint:staticcheck // Ignore SA1019 deprecation warning until we can switch to "google.golang.org/protobuf/types/dynamicpb"
 	"github.com/golang/protobuf/proto"
+	// nolint:staticcheck // Ignore SA1019 deprecation warning until we can switch to "google.golang.org/protobuf/types/dynamicpb"
+	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
 
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/desc/protoparse"
@@ -61,11 +65,28 @@ to manipulate the contents of the document within Benthos.
 
 ### ` + "`from_json`" + `
 
-Attempts to create a target protobuf message from a generic JSON structure.`,
+Attempts to create a target protobuf message from a generic JSON structure.
+
+## Descriptor Sets
+
+As an alternative to parsing .proto files it's possible to load compiled
+descriptors with the field ` + "`descriptor_sets`" + `, which lists files containing
+serialised ` + "`FileDescriptorSet`" + ` messages. These can be generated with
+` + "`protoc`" + ` using the flags ` + "`--descriptor_set_out`" + ` and ` + "`--include_imports`" + `.
+Dependencies on well-known types such as ` + "`google/protobuf/timestamp.proto`" + `
+are resolved automatically when they're not included within a set.
+
+## Dynamic Message Types
+
+The field ` + "`message`" + ` supports interpolation functions, and therefore the
+message type can be resolved individually for each message, for example from a
+metadata field. Message types are resolved from all loaded descriptors and are
+cached by name.`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldString("operator", "The [operator](#operators) to execute").HasOptions("to_json", "from_json"),
-			docs.FieldString("message", "The fully qualified name of the protobuf message to convert to/from."),
-			docs.FieldString("import_paths", "A list of directories containing .proto files, including all definitions required for parsing the target message. If left empty the current directory is used. Each directory listed will be walked with all found .proto files imported.").Array(),
+			docs.FieldString("message", "The fully qualified name of the protobuf message to convert to/from.").IsInterpolated(),
+			docs.FieldString("import_paths", "A list of directories containing .proto files, including all definitions required for parsing the target message. If left empty, and `descriptor_sets` is also empty, the current directory is used. Each directory listed will be walked with all found .proto files imported.").Array(),
+			docs.FieldString("descriptor_sets", "A list of files containing serialised `FileDescriptorSet` messages, from which message definitions are loaded in addition to those parsed from `import_paths`.").Array().AtVersion("4.14.0").Advanced(),
 		).ChildDefaultAndTypesFromStruct(processor.NewProtobufConfig()),
 		Examples: []docs.AnnotatedExample{
 			{
@@ -152,6 +173,23 @@ pipeline:
         operator: to_json
         message: testing.Person
         import_paths: [ testing/schema ]
+`,
+			},
+			{
+				Title: "Message Types From Metadata",
+				Summary: `
+If we have a stream of protobuf messages of several types, compiled into a
+descriptor set with ` + "`protoc --include_imports --descriptor_set_out=schema.pb *.proto`" + `,
+where the fully qualified name of the type of each message is stored in the
+metadata field ` + "`message_type`" + `, we can convert them all into JSON documents
+with the following config:`,
+				Config: `
+pipeline:
+  processors:
+    - protobuf:
+        operator: to_json
+        message: ${! meta("message_type") }
+        descriptor_sets: [ schema.pb ]
 `,
 			},
 		},
@@ -161,29 +199,15 @@ pipeline:
 	}
 }
 
-type protobufOperator func(part *message.Part) error
-
-func newProtobufToJSONOperator(f ifs.FS, msg string, importPaths []string) (protobufOperator, error) {
-	if msg == "" {
-		return nil, errors.New("message field must not be empty")
-	}
-
-	descriptors, err := loadDescriptors(f, importPaths)
-	if err != nil {
-		return nil, err
-	}
-
-	m := getMessageFromDescriptors(msg, descriptors)
-	if m == nil {
-		return nil, fmt.Errorf("unable to find message '%v' definition within '%v'", msg, importPaths)
-	}
+type protobufOperator func(md *desc.MessageDescriptor, part *message.Part) error
 
+func newProtobufToJSONOperator(resolver jsonpb.AnyResolver) protobufOperator {
 	marshaller := &jsonpb.Marshaler{
-		AnyResolver: dynamic.AnyResolver(dynamic.NewMessageFactoryWithDefaults(), descriptors...),
+		AnyResolver: resolver,
 	}
 
-	return func(part *message.Part) error {
-		msg := dynamic.NewMessage(m)
+	return func(md *desc.MessageDescriptor, part *message.Part) error {
+		msg := dynamic.NewMessage(md)
 		if err := proto.Unmarshal(part.AsBytes(), msg); err != nil {
 			return fmt.Errorf("failed to unmarshal message: %w", err)
 		}
@@ -195,30 +219,16 @@ func newProtobufToJSONOperator(f ifs.FS, msg string, importPaths []string) (prot
 
 		part.SetBytes(data)
 		return nil
-	}, nil
-}
-
-func newProtobufFromJSONOperator(f ifs.FS, msg string, importPaths []string) (protobufOperator, error) {
-	if msg == "" {
-		return nil, errors.New("message field must not be empty")
-	}
-
-	descriptors, err := loadDescriptors(f, importPaths)
-	if err != nil {
-		return nil, err
-	}
-
-	m := getMessageFromDescriptors(msg, descriptors)
-	if m == nil {
-		return nil, fmt.Errorf("unable to find message '%v' definition within '%v'", msg, importPaths)
 	}
+}
 
+func newProtobufFromJSONOperator(resolver jsonpb.AnyResolver) protobufOperator {
 	unmarshaler := &jsonpb.Unmarshaler{
-		AnyResolver: dynamic.AnyResolver(dynamic.NewMessageFactoryWithDefaults(), descriptors...),
+		AnyResolver: resolver,
 	}
 
-	return func(part *message.Part) error {
-		msg := dynamic.NewMessage(m)
+	return func(md *desc.MessageDescriptor, part *message.Part) error {
+		msg := dynamic.NewMessage(md)
 		if err := msg.UnmarshalJSONPB(unmarshaler, part.AsBytes()); err != nil {
 			return fmt.Errorf("failed to unmarshal JSON message: %w", err)
 		}
@@ -230,20 +240,23 @@ func newProtobufFromJSONOperator(f ifs.FS, msg string, importPaths []string) (pr
 
 		part.SetBytes(data)
 		return nil
-	}, nil
+	}
 }
 
-func strToProtobufOperator(f ifs.FS, opStr, message string, importPaths []string) (protobufOperator, error) {
+func strToProtobufOperator(opStr string, resolver jsonpb.AnyResolver) (protobufOperator, error) {
 	switch opStr {
 	case "to_json":
-		return newProtobufToJSONOperator(f, message, importPaths)
+		return newProtobufToJSONOperator(resolver), nil
 	case "from_json":
-		return newProtobufFromJSONOperator(f, message, importPaths)
+		return newProtobufFromJSONOperator(resolver), nil
 	}
 	return nil, fmt.Errorf("operator not recognised: %v", opStr)
 }
 
-func loadDescriptors(f ifs.FS, importPaths []string) ([]*desc.FileDescriptor, error) {
+// LoadProtobufDescriptors walks a list of directories and parses all .proto
+// files found within them, where imports are resolved relative to the
+// directories. If the list is empty the current directory is used.
+func LoadProtobufDescriptors(f ifs.FS, importPaths []string) ([]*desc.FileDescriptor, error) {
 	var parser protoparse.Parser
 	if len(importPaths) == 0 {
 		importPaths = []string{"."}
@@ -281,6 +294,68 @@ func loadDescriptors(f ifs.FS, importPaths []string) ([]*desc.FileDescriptor, er
 	return fds, err
 }
 
+// loadProtobufDescriptorSets reads a list of files containing serialised
+// FileDescriptorSet messages, such as those emitted by protoc with the flag
+// --descriptor_set_out. Dependencies on well-known types that are missing from
+// the sets are resolved from the types compiled into Benthos.
+func loadProtobufDescriptorSets(f ifs.FS, paths []string) ([]*desc.FileDescriptor, error) {
+	set := &dpb.FileDescriptorSet{}
+	seen := map[string]struct{}{}
+	for _, path := range paths {
+		setBytes, err := ifs.ReadFile(f, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read descriptor set '%v': %w", path, err)
+		}
+		pathSet := &dpb.FileDescriptorSet{}
+		if err := proto.Unmarshal(setBytes, pathSet); err != nil {
+			return nil, fmt.Errorf("failed to parse descriptor set '%v': %w", path, err)
+		}
+		for _, fdp := range pathSet.GetFile() {
+			if _, exists := seen[fdp.GetName()]; exists {
+				continue
+			}
+			seen[fdp.GetName()] = struct{}{}
+			set.File = append(set.File, fdp)
+		}
+	}
+
+	var addMissingDeps func(fdp *dpb.FileDescriptorProto) error
+	addMissingDeps = func(fdp *dpb.FileDescriptorProto) error {
+		for _, dep := range fdp.GetDependency() {
+			if _, exists := seen[dep]; exists {
+				continue
+			}
+			depFD, err := desc.LoadFileDescriptor(dep)
+			if err != nil {
+				return fmt.Errorf("descriptor sets are missing dependency '%v' of '%v'", dep, fdp.GetName())
+			}
+			seen[dep] = struct{}{}
+			depFDP := depFD.AsFileDescriptorProto()
+			set.File = append(set.File, depFDP)
+			if err := addMissingDeps(depFDP); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, fdp := range set.File {
+		if err := addMissingDeps(fdp); err != nil {
+			return nil, err
+		}
+	}
+
+	fdMap, err := desc.CreateFileDescriptorsFromSet(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create descriptors from sets: %w", err)
+	}
+
+	fds := make([]*desc.FileDescriptor, 0, len(fdMap))
+	for _, fdp := range set.File {
+		fds = append(fds, fdMap[fdp.GetName()])
+	}
+	return fds, nil
+}
+
 func getMessageFromDescriptors(message string, fds []*desc.FileDescriptor) *desc.MessageDescriptor {
 	var msg *desc.MessageDescriptor
 	for _, fd := range fds {
@@ -295,23 +370,92 @@ func getMessageFromDescriptors(message string, fds []*desc.FileDescriptor) *desc
 //------------------------------------------------------------------------------
 
 type protobufProc struct {
-	operator protobufOperator
-	log      log.Modular
+	operator    protobufOperator
+	message     *field.Expression
+	descriptors []*desc.FileDescriptor
+
+	messagesMut sync.RWMutex
+	messages    map[string]*desc.MessageDescriptor
+
+	log log.Modular
 }
 
 func newProtobuf(conf processor.ProtobufConfig, mgr bundle.NewManagement) (*protobufProc, error) {
+	if conf.Message == "" {
+		return nil, errors.New("message field must not be empty")
+	}
+
 	p := &protobufProc{
-		log: mgr.Logger(),
+		messages: map[string]*desc.MessageDescriptor{},
+		log:      mgr.Logger(),
 	}
+
 	var err error
-	if p.operator, err = strToProtobufOperator(mgr.FS(), conf.Operator, conf.Message, conf.ImportPaths); err != nil {
+	if p.message, err = mgr.BloblEnvironment().NewField(conf.Message); err != nil {
+		return nil, fmt.Errorf("failed to parse message expression: %v", err)
+	}
+
+	if len(conf.DescriptorSets) > 0 {
+		if p.descriptors, err = loadProtobufDescriptorSets(mgr.FS(), conf.DescriptorSets); err != nil {
+			return nil, err
+		}
+	}
+	if len(conf.ImportPaths) > 0 || len(conf.DescriptorSets) == 0 {
+		fds, err := LoadProtobufDescriptors(mgr.FS(), conf.ImportPaths)
+		if err != nil {
+			return nil, err
+		}
+		p.descriptors = append(p.descriptors, fds...)
+	}
+
+	resolver := dynamic.AnyResolver(dynamic.NewMessageFactoryWithDefaults(), p.descriptors...)
+	if p.operator, err = strToProtobufOperator(conf.Operator, resolver); err != nil {
 		return nil, err
 	}
+
+	// When the message type is static it's resolved upfront so that a missing
+	// definition is reported as a config error.
+	if p.message.NumDynamicExpressions() == 0 {
+		if _, err := p.getMessage(conf.Message); err != nil {
+			return nil, err
+		}
+	}
 	return p, nil
 }
 
+// getMessage returns the descriptor of a message type by its fully qualified
+// name, where descriptors are cached by name as they're resolved.
+func (p *protobufProc) getMessage(name string) (*desc.MessageDescriptor, error) {
+	p.messagesMut.RLock()
+	md, exists := p.messages[name]
+	p.messagesMut.RUnlock()
+	if exists {
+		return md, nil
+	}
+
+	if md = getMessageFromDescriptors(name, p.descriptors); md == nil {
+		return nil, fmt.Errorf("unable to find message '%v' definition", name)
+	}
+
+	p.messagesMut.Lock()
+	p.messages[name] = md
+	p.messagesMut.Unlock()
+	return md, nil
+}
+
 func (p *protobufProc) Process(ctx context.Context, msg *message.Part) ([]*message.Part, error) {
-	if err := p.operator(msg); err != nil {
+	name, err := p.message.String(0, message.Batch{msg})
+	if err != nil {
+		return nil, fmt.Errorf("message interpolation error: %w", err)
+	}
+
+	md, err := p.getMessage(name)
+	if err != nil {
+		p.log.Debugf("Failed to resolve message type: %v", err)
+		return nil, err
+	}
+
+	if err := p.operator(md, msg); err != nil {
 		p.log.Debugf("Operator failed: %v", err)
 		return nil, err
 	}
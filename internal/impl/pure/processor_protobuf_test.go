@@ -2,8 +2,15 @@ package pure_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	// nolint:staticcheck // Ignore SA1019 deprecation warning until we can switch to "google.golang.org/protobuf/types/dynamicpb"
+	"github.com/golang/protobuf/proto"
+	// nolint:staticcheck // Ignore SA1019 deprecation warning until we can switch to "google.golang.org/protobuf/types/dynamicpb"
+	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/jhump/protoreflect/desc/protoparse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -199,3 +206,70 @@ func TestProtobufErrors(t *testing.T) {
 		})
 	}
 }
+
+func writeTestDescriptorSet(t *testing.T) string {
+	t.Helper()
+
+	parser := protoparse.Parser{ImportPaths: []string{"../../../config/test/protobuf/schema"}}
+	fds, err := parser.ParseFiles("envelope.proto", "house.proto", "person.proto")
+	require.NoError(t, err)
+
+	// Well-known types are deliberately omitted from the set, as they should be
+	// resolved regardless.
+	set := &dpb.FileDescriptorSet{}
+	for _, fd := range fds {
+		set.File = append(set.File, fd.AsFileDescriptorProto())
+	}
+
+	setBytes, err := proto.Marshal(set)
+	require.NoError(t, err)
+
+	setPath := filepath.Join(t.TempDir(), "schema.pb")
+	require.NoError(t, os.WriteFile(setPath, setBytes, 0o644))
+	return setPath
+}
+
+func TestProtobufDescriptorSetsDynamicMessage(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "protobuf"
+	conf.Protobuf.Operator = "to_json"
+	conf.Protobuf.Message = `${! meta("message_type") }`
+	conf.Protobuf.DescriptorSets = []string{writeTestDescriptorSet(t)}
+
+	proc, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		{0x0a, 0x04, 0x6a, 0x6f, 0x68, 0x6e, 0x12, 0x05, 0x6f, 0x61, 0x74, 0x65, 0x73, 0x3a, 0x02, 0x08, 0x01},
+		{0x0a, 0x06, 0x0a, 0x04, 0x6a, 0x6f, 0x68, 0x6e, 0x12, 0x03, 0x31, 0x32, 0x33},
+		{0x0a, 0x04, 0x6a, 0x6f, 0x68, 0x6e},
+	})
+	input.Get(0).MetaSetMut("message_type", "testing.Person")
+	input.Get(1).MetaSetMut("message_type", "testing.House")
+	input.Get(2).MetaSetMut("message_type", "testing.Nope")
+
+	msgs, res := proc.ProcessBatch(context.Background(), input)
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+
+	assert.Equal(t, []string{
+		`{"firstName":"john","lastName":"oates","lastUpdated":"1970-01-01T00:00:01Z"}`,
+		`{"people":[{"firstName":"john"}],"address":"123"}`,
+	}, []string{string(msgs[0].Get(0).AsBytes()), string(msgs[0].Get(1).AsBytes())})
+
+	require.NoError(t, msgs[0].Get(0).ErrorGet())
+	require.NoError(t, msgs[0].Get(1).ErrorGet())
+	require.EqualError(t, msgs[0].Get(2).ErrorGet(), "unable to find message 'testing.Nope' definition")
+}
+
+func TestProtobufMissingMessage(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "protobuf"
+	conf.Protobuf.Operator = "to_json"
+	conf.Protobuf.Message = "testing.Nope"
+	conf.Protobuf.DescriptorSets = []string{writeTestDescriptorSet(t)}
+
+	_, err := mock.NewManager().NewProcessor(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to find message 'testing.Nope' definition")
+}
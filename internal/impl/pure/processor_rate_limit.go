@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -31,8 +32,13 @@ Throttles the throughput of a pipeline according to a specified
 ` + "[`rate_limit`](/docs/components/rate_limits/about)" + ` resource. Rate limits are
 shared across components and therefore apply globally to all processing
 pipelines.`,
+		Description: `
+### Keyed Limits
+
+When a ` + "`key`" + ` is specified the rate limit is accessed with the result of interpolating it for each message, which allows rate limits that support it (such as ` + "[`token_bucket`](/docs/components/rate_limits/token_bucket)" + `) to throttle messages separately for each unique key, such as per customer. Rate limits that do not support keys ignore it and apply a single limit to all messages.`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldString("resource", "The target [`rate_limit` resource](/docs/components/rate_limits/about).").HasDefault(""),
+			docs.FieldString("key", "An optional key to access the rate limit with, allowing keyed rate limits to throttle messages separately for each unique key.", `${! json("customer_id") }`, `${! meta("kafka_key") }`).IsInterpolated().HasDefault("").AtVersion("4.14.0"),
 		),
 	})
 	if err != nil {
@@ -42,6 +48,7 @@ pipelines.`,
 
 type rateLimitProc struct {
 	rlName string
+	key    *field.Expression
 	mgr    bundle.NewManagement
 
 	closeChan chan struct{}
@@ -57,15 +64,33 @@ func newRateLimitProc(conf processor.RateLimitConfig, mgr bundle.NewManagement)
 		mgr:       mgr,
 		closeChan: make(chan struct{}),
 	}
+	if conf.Key != "" {
+		var err error
+		if r.key, err = mgr.BloblEnvironment().NewField(conf.Key); err != nil {
+			return nil, fmt.Errorf("failed to parse key expression: %v", err)
+		}
+	}
 	return r, nil
 }
 
 func (r *rateLimitProc) Process(ctx context.Context, msg *message.Part) ([]*message.Part, error) {
+	var key string
+	if r.key != nil {
+		var err error
+		if key, err = r.key.String(0, message.Batch{msg}); err != nil {
+			return nil, fmt.Errorf("key interpolation error: %w", err)
+		}
+	}
+
 	for {
 		var waitFor time.Duration
 		var err error
 		if rerr := r.mgr.AccessRateLimit(ctx, r.rlName, func(rl ratelimit.V1) {
-			waitFor, err = rl.Access(ctx)
+			if r.key != nil {
+				waitFor, err = ratelimit.AccessKey(ctx, rl, key)
+			} else {
+				waitFor, err = rl.Access(ctx)
+			}
 		}); rerr != nil {
 			err = rerr
 		}
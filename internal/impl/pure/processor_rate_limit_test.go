@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
@@ -25,7 +27,7 @@ func TestRateLimitBasic(t *testing.T) {
 	}
 
 	mgr := mock.NewManager()
-	mgr.RateLimits["foo"] = rlFn
+	mgr.RateLimits["foo"] = mock.RateLimit(rlFn)
 
 	conf := processor.NewConfig()
 	conf.Type = "rate_limit"
@@ -65,7 +67,7 @@ func TestRateLimitErroredOut(t *testing.T) {
 	}
 
 	mgr := mock.NewManager()
-	mgr.RateLimits["foo"] = rlFn
+	mgr.RateLimits["foo"] = mock.RateLimit(rlFn)
 
 	conf := processor.NewConfig()
 	conf.Type = "rate_limit"
@@ -115,7 +117,7 @@ func TestRateLimitBlocked(t *testing.T) {
 	}
 
 	mgr := mock.NewManager()
-	mgr.RateLimits["foo"] = rlFn
+	mgr.RateLimits["foo"] = mock.RateLimit(rlFn)
 
 	conf := processor.NewConfig()
 	conf.Type = "rate_limit"
@@ -158,3 +160,37 @@ func TestRateLimitBlocked(t *testing.T) {
 		t.Error("Timed out")
 	}
 }
+
+func TestRateLimitKeyed(t *testing.T) {
+	var keysMut sync.Mutex
+	var keys []string
+	rlFn := func(ctx context.Context, key string) (time.Duration, error) {
+		keysMut.Lock()
+		keys = append(keys, key)
+		keysMut.Unlock()
+		return 0, nil
+	}
+
+	mgr := mock.NewManager()
+	mgr.RateLimits["foo"] = mock.KeyedRateLimit(rlFn)
+
+	conf := processor.NewConfig()
+	conf.Type = "rate_limit"
+	conf.RateLimit.Resource = "foo"
+	conf.RateLimit.Key = `${! json("key") }`
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","value":"foo 1"}`),
+		[]byte(`{"key":"2","value":"foo 2"}`),
+		[]byte(`{"key":"1","value":"foo 3"}`),
+	})
+
+	output, res := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	assert.Equal(t, message.GetAllBytes(input), message.GetAllBytes(output[0]))
+
+	assert.Equal(t, []string{"1", "2", "1"}, keys)
+}
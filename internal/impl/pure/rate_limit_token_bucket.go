@@ -0,0 +1,158 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func tokenBucketRatelimitConfig() *service.ConfigSpec {
+	spec := service.NewConfigSpec().
+		Beta().
+		Version("4.14.0").
+		Summary(`A token bucket rate limit that allows bursts of requests up to the size of the bucket, which is refilled at a constant rate. The limit can be shared across any number of components within the pipeline but does not support distributed rate limits across multiple running instances of Benthos.`).
+		Description(`
+Each access consumes a token from the bucket, and when the bucket is empty requests are throttled until it has been refilled enough to provide another token. This allows short bursts of up to `+"`burst`"+` requests whilst limiting the sustained rate of requests to `+"`refill_rate`"+` per second.
+
+### Keyed Limits
+
+Components that support it, such as the `+"[`rate_limit` processor](/docs/components/processors/rate_limit)"+` and `+"[`http_server` input](/docs/components/inputs/http_server)"+`, are able to access the rate limit with an interpolated key, in which case a separate bucket is tracked for each unique key, allowing you to throttle requests per customer, tenant, etc. Buckets of keys that have not been accessed for long enough to become full are discarded.`).
+		Field(service.NewIntField("burst").
+			Description("The maximum number of tokens that the bucket holds, and therefore the maximum number of requests that can be made in a burst.").
+			Default(1000)).
+		Field(service.NewFloatField("refill_rate").
+			Description("The number of tokens added to the bucket per second, and therefore the sustained number of requests allowed per second.").
+			Default(100).
+			Example(0.5).
+			Example(100)).
+		Example("Per Customer Limit", "Limit each customer to 10 requests per second, with bursts of up to 100 requests, by accessing the rate limit with a key from a `rate_limit` processor.", `
+pipeline:
+  processors:
+    - rate_limit:
+        resource: per_customer
+        key: ${! json("customer_id") }
+
+rate_limit_resources:
+  - label: per_customer
+    token_bucket:
+      burst: 100
+      refill_rate: 10
+`)
+
+	return spec
+}
+
+func init() {
+	err := service.RegisterRateLimit(
+		"token_bucket", tokenBucketRatelimitConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.RateLimit, error) {
+			return newTokenBucketRatelimitFromConfig(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newTokenBucketRatelimitFromConfig(conf *service.ParsedConfig) (*tokenBucketRatelimit, error) {
+	burst, err := conf.FieldInt("burst")
+	if err != nil {
+		return nil, err
+	}
+	refillRate, err := conf.FieldFloat("refill_rate")
+	if err != nil {
+		return nil, err
+	}
+	return newTokenBucketRatelimit(burst, refillRate, time.Now)
+}
+
+//------------------------------------------------------------------------------
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+type tokenBucketRatelimit struct {
+	mut       sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+
+	burst      float64
+	refillRate float64
+	fillPeriod time.Duration
+	clock      func() time.Time
+}
+
+func newTokenBucketRatelimit(burst int, refillRate float64, clock func() time.Time) (*tokenBucketRatelimit, error) {
+	if burst <= 0 {
+		return nil, errors.New("burst must be larger than zero")
+	}
+	if refillRate <= 0 {
+		return nil, errors.New("refill_rate must be larger than zero")
+	}
+	return &tokenBucketRatelimit{
+		buckets:    map[string]*tokenBucket{},
+		lastPrune:  clock(),
+		burst:      float64(burst),
+		refillRate: refillRate,
+		fillPeriod: time.Duration(float64(burst) / refillRate * float64(time.Second)),
+		clock:      clock,
+	}, nil
+}
+
+// refill tops up the tokens of a bucket according to the time elapsed since
+// it was last refilled.
+func (r *tokenBucketRatelimit) refill(b *tokenBucket, now time.Time) {
+	if elapsed := now.Sub(b.lastRefill); elapsed > 0 {
+		b.tokens = math.Min(r.burst, b.tokens+elapsed.Seconds()*r.refillRate)
+		b.lastRefill = now
+	}
+}
+
+// prune removes the buckets of keys that have become full, as a full bucket is
+// equivalent to one that does not exist. Since a bucket becomes full at the
+// latest after the fill period this is performed at most once per period.
+func (r *tokenBucketRatelimit) prune(now time.Time) {
+	if now.Sub(r.lastPrune) < r.fillPeriod {
+		return
+	}
+	for k, b := range r.buckets {
+		if r.refill(b, now); b.tokens >= r.burst {
+			delete(r.buckets, k)
+		}
+	}
+	r.lastPrune = now
+}
+
+func (r *tokenBucketRatelimit) Access(ctx context.Context) (time.Duration, error) {
+	return r.AccessKey(ctx, "")
+}
+
+func (r *tokenBucketRatelimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	now := r.clock()
+	r.prune(now)
+
+	b, exists := r.buckets[key]
+	if !exists {
+		b = &tokenBucket{tokens: r.burst, lastRefill: now}
+		r.buckets[key] = b
+	}
+	r.refill(b, now)
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / r.refillRate * float64(time.Second)), nil
+	}
+	b.tokens--
+	return 0, nil
+}
+
+func (r *tokenBucketRatelimit) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,99 @@
+package pure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucketRateLimitConfErrors(t *testing.T) {
+	for _, c := range []string{
+		`burst: 0`,
+		`refill_rate: -1`,
+	} {
+		conf, err := tokenBucketRatelimitConfig().ParseYAML(c, nil)
+		require.NoError(t, err)
+
+		_, err = newTokenBucketRatelimitFromConfig(conf)
+		require.Error(t, err, c)
+	}
+}
+
+func TestTokenBucketRateLimitBurstAndRefill(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl, err := newTokenBucketRatelimit(3, 2, func() time.Time { return now })
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		period, err := rl.Access(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), period)
+	}
+
+	period, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*500, period)
+
+	now = now.Add(time.Millisecond * 250)
+
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*250, period)
+
+	now = now.Add(time.Millisecond * 250)
+
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	// Refilling never exceeds the burst.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		period, err := rl.Access(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), period)
+	}
+
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Greater(t, period, time.Duration(0))
+}
+
+func TestTokenBucketRateLimitKeyed(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl, err := newTokenBucketRatelimit(2, 1, func() time.Time { return now })
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for _, key := range []string{"foo", "bar"} {
+		for i := 0; i < 2; i++ {
+			period, err := rl.AccessKey(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(0), period, key)
+		}
+		period, err := rl.AccessKey(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, period, key)
+	}
+
+	period, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	assert.Len(t, rl.buckets, 3)
+
+	// Once buckets have had enough time to become full they're pruned.
+	now = now.Add(time.Second * 3)
+
+	period, err = rl.AccessKey(ctx, "baz")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	assert.Len(t, rl.buckets, 1)
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -88,8 +89,47 @@ type redisCache struct {
 	prefix     string
 
 	boffPool sync.Pool
+
+	incrScript *redis.Script
+	casScript  *redis.Script
 }
 
+// Increments a key, and sets the expiry of the key either when a TTL is
+// explicitly provided (ARGV[3] == 1) or when the key has no expiry.
+const redisCacheIncrScript = `
+local current = redis.call("INCRBY", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl > 0 and (ARGV[3] == "1" or redis.call("PTTL", KEYS[1]) < 0) then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+end
+return current
+`
+
+// Sets a key only if its current value matches ARGV[2], or if it does not
+// exist when ARGV[1] == 0.
+const redisCacheCASScript = `
+local current = redis.call("GET", KEYS[1])
+if ARGV[1] == "0" then
+	if current then
+		return -1
+	end
+else
+	if not current then
+		return -2
+	end
+	if current ~= ARGV[2] then
+		return -3
+	end
+end
+local ttl = tonumber(ARGV[4])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[3], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[3])
+end
+return 1
+`
+
 func newRedisCache(
 	defaultTTL time.Duration,
 	prefix string,
@@ -107,6 +147,8 @@ func newRedisCache(
 				return &bo
 			},
 		},
+		incrScript: redis.NewScript(redisCacheIncrScript),
+		casScript:  redis.NewScript(redisCacheCASScript),
 	}, nil
 }
 
@@ -143,6 +185,50 @@ func (r *redisCache) Get(ctx context.Context, key string) ([]byte, error) {
 	}
 }
 
+func (r *redisCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	boff := r.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		r.boffPool.Put(boff)
+	}()
+
+	// A pipeline of GET commands is used rather than MGET as the keys of a
+	// batch are unlikely to share a hash slot within a cluster.
+	for {
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.StringCmd, len(keys))
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, r.prefix+key)
+		}
+
+		_, err := pipe.Exec(ctx)
+		if err == nil || errors.Is(err, redis.Nil) {
+			values := make(map[string][]byte, len(keys))
+			for i, cmd := range cmds {
+				res, cerr := cmd.Result()
+				if cerr != nil {
+					if errors.Is(cerr, redis.Nil) {
+						continue
+					}
+					return nil, cerr
+				}
+				values[keys[i]] = []byte(res)
+			}
+			return values, nil
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return nil, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
 func (r *redisCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
 	boff := r.boffPool.Get().(backoff.BackOff)
 	defer func() {
@@ -248,6 +334,130 @@ func (r *redisCache) Delete(ctx context.Context, key string) error {
 	}
 }
 
+func (r *redisCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	boff := r.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		r.boffPool.Put(boff)
+	}()
+
+	if len(r.prefix) > 0 {
+		key = r.prefix + key
+	}
+
+	t, explicitTTL := r.defaultTTL, 0
+	if ttl != nil {
+		t, explicitTTL = *ttl, 1
+	}
+
+	for {
+		res, err := r.incrScript.Run(ctx, r.client, []string{key}, delta, t.Milliseconds(), explicitTTL).Int64()
+		if err == nil {
+			return res, nil
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return 0, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return 0, err
+		}
+	}
+}
+
+func (r *redisCache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	boff := r.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		r.boffPool.Put(boff)
+	}()
+
+	if len(r.prefix) > 0 {
+		key = r.prefix + key
+	}
+
+	t := r.defaultTTL
+	if ttl != nil {
+		t = *ttl
+	}
+
+	expectExists := 1
+	if oldValue == nil {
+		expectExists = 0
+	}
+
+	for {
+		res, err := r.casScript.Run(ctx, r.client, []string{key}, expectExists, oldValue, newValue, t.Milliseconds()).Int64()
+		if err == nil {
+			switch res {
+			case -1:
+				return service.ErrKeyAlreadyExists
+			case -2:
+				return service.ErrKeyNotFound
+			case -3:
+				return service.ErrValueMismatch
+			}
+			return nil
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// Keys scans for all keys beginning with a prefix, when the client targets a
+// cluster each master node is scanned.
+func (r *redisCache) Keys(ctx context.Context, prefix string) ([]string, error) {
+	match := redisGlobEscape(r.prefix+prefix) + "*"
+
+	var keysMut sync.Mutex
+	var keys []string
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		iter := client.Scan(ctx, 0, match, 100).Iterator()
+		for iter.Next(ctx) {
+			keysMut.Lock()
+			keys = append(keys, strings.TrimPrefix(iter.Val(), r.prefix))
+			keysMut.Unlock()
+		}
+		return iter.Err()
+	}
+
+	var err error
+	if cc, ok := r.client.(*redis.ClusterClient); ok {
+		err = cc.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scan(ctx, client)
+		})
+	} else {
+		err = scan(ctx, r.client)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func redisGlobEscape(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
 func (r *redisCache) Close(ctx context.Context) error {
 	return r.client.Close()
 }
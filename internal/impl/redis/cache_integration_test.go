@@ -61,6 +61,10 @@ cache_resources:
 		integration.CacheTestDoubleAdd(),
 		integration.CacheTestDelete(),
 		integration.CacheTestGetAndSet(50),
+		integration.CacheTestIncr(),
+		integration.CacheTestCompareAndSwap(),
+		integration.CacheTestGetMulti(),
+		integration.CacheTestKeys(),
 	)
 	suite.Run(
 		t, template,
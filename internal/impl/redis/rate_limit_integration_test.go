@@ -63,6 +63,10 @@ func TestIntegrationRedisRateLimit(t *testing.T) {
 	t.Run("testRedisRateLimitRefresh", func(t *testing.T) {
 		testRedisRateLimitRefresh(t, urlStr)
 	})
+
+	t.Run("testRedisTokenBucketRateLimit", func(t *testing.T) {
+		testRedisTokenBucketRateLimit(t, urlStr)
+	})
 }
 
 func testRedisRateLimitBasic(t *testing.T, url string) {
@@ -152,3 +156,40 @@ url: `+url, nil)
 		t.Errorf("Period beyond interval: %v", period)
 	}
 }
+
+func testRedisTokenBucketRateLimit(t *testing.T, url string) {
+	conf, err := redisTokenBucketRatelimitConfig().ParseYAML(`
+key: token_bucket
+burst: 5
+refill_rate: 10
+url: `+url, nil)
+	require.NoError(t, err)
+
+	rl, err := newRedisTokenBucketRatelimitFromConfig(conf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for _, key := range []string{"foo", "bar"} {
+		for i := 0; i < 5; i++ {
+			period, err := rl.AccessKey(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(0), period, key)
+		}
+
+		period, err := rl.AccessKey(ctx, key)
+		require.NoError(t, err)
+		assert.Greater(t, period, time.Duration(0), key)
+		assert.LessOrEqual(t, period, time.Millisecond*100, key)
+	}
+
+	period, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	<-time.After(time.Millisecond * 150)
+
+	period, err = rl.AccessKey(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+}
@@ -31,3 +31,16 @@ key: asdf`, nil)
 	_, err = redisRatelimitConfig().ParseYAML(`url: redis://localhost:6379`, nil)
 	require.Error(t, err)
 }
+
+func TestRedisTokenBucketRateLimitConfErrors(t *testing.T) {
+	for _, c := range []string{
+		"url: redis://localhost:6379\nburst: 0",
+		"url: redis://localhost:6379\nrefill_rate: -1",
+	} {
+		conf, err := redisTokenBucketRatelimitConfig().ParseYAML(c, nil)
+		require.NoError(t, err)
+
+		_, err = newRedisTokenBucketRatelimitFromConfig(conf)
+		require.Error(t, err, c)
+	}
+}
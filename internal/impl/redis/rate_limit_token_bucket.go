@@ -0,0 +1,161 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func redisTokenBucketRatelimitConfig() *service.ConfigSpec {
+	spec := service.NewConfigSpec().
+		Beta().
+		Version("4.14.0").
+		Summary(`A token bucket rate limit stored in Redis, which allows bursts of requests up to the size of the bucket, which is refilled at a constant rate. The rate limit is shared across all instances of Benthos that use the same Redis instance, which must all have a consistent burst and refill rate.`).
+		Description(`
+Each access consumes a token from the bucket, and when the bucket is empty requests are throttled until it has been refilled enough to provide another token. The state of each bucket is updated atomically with a script executed by Redis, and the clock of the Redis server is used in order to avoid issues caused by clock drift between instances.
+
+### Keyed Limits
+
+Components that support it, such as the ` + "[`rate_limit` processor](/docs/components/processors/rate_limit)" + ` and ` + "[`http_server` input](/docs/components/inputs/http_server)" + `, are able to access the rate limit with an interpolated key, in which case a separate bucket is tracked for each unique key under the Redis key ` + "`<key>:<access key>`" + `. Buckets expire once they've had enough time to become full.`)
+
+	for _, f := range clientFields() {
+		spec = spec.Field(f)
+	}
+
+	spec = spec.
+		Field(service.NewIntField("burst").
+			Description("The maximum number of tokens that the bucket holds, and therefore the maximum number of requests that can be made in a burst.").
+			Default(1000).LintRule(`root = if this <= 0 { [ "burst must be larger than zero" ] }`)).
+		Field(service.NewFloatField("refill_rate").
+			Description("The number of tokens added to the bucket per second, and therefore the sustained number of requests allowed per second.").
+			Default(100).LintRule(`root = if this <= 0 { [ "refill_rate must be larger than zero" ] }`)).
+		Field(service.NewStringField("key").
+			Description("The key to store the state of the bucket under, keyed access stores the state of each bucket under this key suffixed with the access key.").
+			Default("benthos_token_bucket"))
+
+	return spec
+}
+
+func init() {
+	err := service.RegisterRateLimit(
+		"redis_token_bucket", redisTokenBucketRatelimitConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.RateLimit, error) {
+			return newRedisTokenBucketRatelimitFromConfig(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Consumes a token from the bucket stored at KEYS[1], where ARGV[1] is the
+// burst and ARGV[2] is the refill rate in tokens per millisecond. Returns zero
+// if a token was consumed, or otherwise the number of milliseconds to wait
+// until a token becomes available.
+const redisTokenBucketScript = `
+redis.replicate_commands()
+
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local wait = 0
+if tokens < 1 then
+	wait = math.ceil((1 - tokens) / rate)
+else
+	tokens = tokens - 1
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate) + 1000)
+return wait
+`
+
+type redisTokenBucketRatelimit struct {
+	burst      int
+	refillRate float64
+	key        string
+
+	client redis.UniversalClient
+
+	accessScript *redis.Script
+}
+
+func newRedisTokenBucketRatelimitFromConfig(conf *service.ParsedConfig) (*redisTokenBucketRatelimit, error) {
+	client, err := getClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	burst, err := conf.FieldInt("burst")
+	if err != nil {
+		return nil, err
+	}
+
+	refillRate, err := conf.FieldFloat("refill_rate")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := conf.FieldString("key")
+	if err != nil {
+		return nil, err
+	}
+
+	if burst <= 0 {
+		return nil, fmt.Errorf("burst must be larger than zero")
+	}
+	if refillRate <= 0 {
+		return nil, fmt.Errorf("refill_rate must be larger than zero")
+	}
+
+	return &redisTokenBucketRatelimit{
+		burst:        burst,
+		refillRate:   refillRate,
+		key:          key,
+		client:       client,
+		accessScript: redis.NewScript(redisTokenBucketScript),
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (r *redisTokenBucketRatelimit) Access(ctx context.Context) (time.Duration, error) {
+	return r.access(ctx, r.key)
+}
+
+func (r *redisTokenBucketRatelimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return r.access(ctx, r.key+":"+key)
+}
+
+func (r *redisTokenBucketRatelimit) access(ctx context.Context, key string) (time.Duration, error) {
+	ratePerMs := strconv.FormatFloat(r.refillRate/1000, 'f', -1, 64)
+
+	waitMs, err := r.accessScript.Run(ctx, r.client, []string{key}, r.burst, ratePerMs).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("accessing redis rate limit: %w", err)
+	}
+	return time.Duration(waitMs) * time.Millisecond, nil
+}
+
+func (r *redisTokenBucketRatelimit) Close(ctx context.Context) error {
+	return nil
+}
@@ -121,7 +121,7 @@ func (s *sftpWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
 		return component.ErrNotConnected
 	}
 
-	return output.IterateBatchedSend(msg, func(i int, p *message.Part) error {
+	err := output.IterateBatchedSend(msg, func(i int, p *message.Part) error {
 		path, err := s.path.String(i, msg)
 		if err != nil {
 			return fmt.Errorf("path interpolation error: %w", err)
@@ -175,6 +175,16 @@ func (s *sftpWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.handleMut.Lock()
+	defer s.handleMut.Unlock()
+	if s.handle != nil {
+		return codec.FlushWriter(ctx, s.handle)
+	}
+	return nil
 }
 
 func (s *sftpWriter) Close(ctx context.Context) (err error) {
@@ -2,16 +2,59 @@ package wasm
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/tetratelabs/wazero/api"
+
+	"github.com/benthosdev/benthos/v4/public/service"
 )
 
 func ptrLen(contentPtr, contentLen uint64) uint64 {
 	return (contentPtr << uint64(32)) | contentLen
 }
 
+// Status codes returned by functions that are able to fail without failing the
+// message being processed, the error of a failed call can be obtained with the
+// v0_last_error function.
+const (
+	statusOK       uint32 = 0
+	statusNotFound uint32 = 1
+	statusError    uint32 = 2
+)
+
+// errResourcesUnavailable is returned when a module attempts to access resources
+// from a context where none are available, such as a Bloblang function.
+var errResourcesUnavailable = errors.New("resources are not available to this module")
+
+func (r *moduleRunner) statusErr(err error) uint32 {
+	r.lastErr = err
+	return statusError
+}
+
+// writeUint64Outbound writes a value to memory owned by the WASM module.
+func (r *moduleRunner) writeUint64Outbound(ptr uint32, v uint64) error {
+	if !r.mod.Memory().WriteUint64Le(ptr, v) {
+		return errors.New("failed to write out-bound memory")
+	}
+	return nil
+}
+
+// returnBytes allocates a byte slice within the memory of the module and
+// returns it as a pointer and length pair.
+func (r *moduleRunner) returnBytes(ctx context.Context, b []byte) (ptrSize uint64) {
+	contentPtr, err := r.allocateBytesInbound(ctx, b)
+	if err != nil {
+		r.funcErr(fmt.Errorf("failed to allocate in-bound memory: %v", err))
+		return
+	}
+	return ptrLen(contentPtr, uint64(len(b)))
+}
+
 var moduleRunnerFunctionCtors = map[string]func(r *moduleRunner) interface{}{}
 
 func registerModuleRunnerFunction(name string, ctor func(r *moduleRunner) interface{}) struct{} {
@@ -107,3 +150,370 @@ var _ = registerModuleRunnerFunction("v0_msg_get_meta", func(r *moduleRunner) in
 		return ptrLen(contentPtr, uint64(len(metaValueBytes)))
 	}
 })
+
+var _ = registerModuleRunnerFunction("v0_msg_delete_meta", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, keyPtr, keySize uint32) {
+		if r.targetMessage == nil {
+			r.funcErr(errors.New("attempted to delete metadata of deleted message"))
+			return
+		}
+
+		keyBytes, err := r.readBytesOutbound(ctx, keyPtr, keySize)
+		if err != nil {
+			r.funcErr(fmt.Errorf("failed to read out-bound meta key memory: %w", err))
+			return
+		}
+		r.targetMessage.MetaDelete(string(keyBytes))
+	}
+})
+
+// Returns the metadata keys of the message separated by null bytes, sorted
+// lexicographically.
+var _ = registerModuleRunnerFunction("v0_msg_meta_keys", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module) (ptrSize uint64) {
+		if r.targetMessage == nil {
+			r.funcErr(errors.New("attempted to read meta of deleted message"))
+			return
+		}
+
+		var keys []string
+		_ = r.targetMessage.MetaWalkMut(func(k string, _ any) error {
+			keys = append(keys, k)
+			return nil
+		})
+		sort.Strings(keys)
+		return r.returnBytes(ctx, []byte(strings.Join(keys, "\x00")))
+	}
+})
+
+var _ = registerModuleRunnerFunction("v0_msg_as_json", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module) (ptrSize uint64) {
+		if r.targetMessage == nil {
+			r.funcErr(errors.New("attempted to read structured contents of deleted message"))
+			return
+		}
+
+		structured, err := r.targetMessage.AsStructured()
+		if err != nil {
+			r.funcErr(fmt.Errorf("failed to get message as structured: %v", err))
+			return
+		}
+
+		jsonBytes, err := json.Marshal(structured)
+		if err != nil {
+			r.funcErr(fmt.Errorf("failed to marshal message as json: %v", err))
+			return
+		}
+		return r.returnBytes(ctx, jsonBytes)
+	}
+})
+
+var _ = registerModuleRunnerFunction("v0_msg_set_json", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, contentPtr, contentSize uint32) {
+		if r.targetMessage == nil {
+			r.funcErr(errors.New("attempted to set structured contents of deleted message"))
+			return
+		}
+
+		jsonBytes, err := r.readBytesOutbound(ctx, contentPtr, contentSize)
+		if err != nil {
+			r.funcErr(fmt.Errorf("failed to read out-bound memory: %w", err))
+			return
+		}
+
+		var structured any
+		if err := json.Unmarshal(jsonBytes, &structured); err != nil {
+			r.funcErr(fmt.Errorf("failed to parse json: %w", err))
+			return
+		}
+		r.targetMessage.SetStructuredMut(structured)
+	}
+})
+
+var _ = registerModuleRunnerFunction("v0_msg_delete", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module) {
+		if r.targetMessage == nil {
+			return
+		}
+		r.runBatch[r.targetIndex] = nil
+		r.targetMessage = nil
+	}
+})
+
+//------------------------------------------------------------------------------
+
+var _ = registerModuleRunnerFunction("v0_batch_len", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module) uint32 {
+		return uint32(len(r.runBatch))
+	}
+})
+
+var _ = registerModuleRunnerFunction("v0_batch_index", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module) uint32 {
+		return uint32(r.targetIndex)
+	}
+})
+
+// Targets a message of the batch by its index, which all message functions
+// then operate on.
+var _ = registerModuleRunnerFunction("v0_batch_select", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, index uint32) uint32 {
+		if int(index) >= len(r.runBatch) {
+			return r.statusErr(fmt.Errorf("index %v is out of bounds of batch length %v", index, len(r.runBatch)))
+		}
+		r.targetIndex = int(index)
+		r.targetMessage = r.runBatch[index]
+		if r.targetMessage == nil {
+			return statusNotFound
+		}
+		return statusOK
+	}
+})
+
+// Appends a copy of a message of the batch to the end of the batch and writes
+// the index of the new message to out-bound memory.
+var _ = registerModuleRunnerFunction("v0_batch_copy", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, index, indexOutPtr uint32) uint32 {
+		if int(index) >= len(r.runBatch) {
+			return r.statusErr(fmt.Errorf("index %v is out of bounds of batch length %v", index, len(r.runBatch)))
+		}
+		source := r.runBatch[index]
+		if source == nil {
+			return statusNotFound
+		}
+		if err := r.writeUint64Outbound(indexOutPtr, uint64(len(r.runBatch))); err != nil {
+			return r.statusErr(err)
+		}
+		r.runBatch = append(r.runBatch, source.Copy())
+		return statusOK
+	}
+})
+
+//------------------------------------------------------------------------------
+
+var _ = registerModuleRunnerFunction("v0_last_error", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module) (ptrSize uint64) {
+		if r.lastErr == nil {
+			return 0
+		}
+		errBytes := []byte(r.lastErr.Error())
+		r.lastErr = nil
+		return r.returnBytes(ctx, errBytes)
+	}
+})
+
+// Log levels of the v0_log function.
+const (
+	logLevelTrace uint32 = iota
+	logLevelDebug
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+)
+
+var _ = registerModuleRunnerFunction("v0_log", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, level, contentPtr, contentSize uint32) {
+		msgBytes, err := r.readBytesOutbound(ctx, contentPtr, contentSize)
+		if err != nil {
+			r.funcErr(fmt.Errorf("failed to read out-bound log memory: %w", err))
+			return
+		}
+
+		switch level {
+		case logLevelTrace:
+			r.log.Trace(string(msgBytes))
+		case logLevelDebug:
+			r.log.Debug(string(msgBytes))
+		case logLevelInfo:
+			r.log.Info(string(msgBytes))
+		case logLevelWarn:
+			r.log.Warn(string(msgBytes))
+		default:
+			r.log.Error(string(msgBytes))
+		}
+	}
+})
+
+//------------------------------------------------------------------------------
+
+// Obtains the value of a key from a cache resource and writes it as a pointer
+// and length pair to out-bound memory.
+var _ = registerModuleRunnerFunction("v0_cache_get", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, namePtr, nameSize, keyPtr, keySize, valueOutPtr uint32) uint32 {
+		name, err := r.readBytesOutbound(ctx, namePtr, nameSize)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to read out-bound cache name memory: %w", err))
+		}
+		key, err := r.readBytesOutbound(ctx, keyPtr, keySize)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to read out-bound cache key memory: %w", err))
+		}
+
+		if r.mgr == nil {
+			return r.statusErr(errResourcesUnavailable)
+		}
+
+		var value []byte
+		var cErr error
+		if err := r.mgr.AccessCache(ctx, string(name), func(c service.Cache) {
+			value, cErr = c.Get(ctx, string(key))
+		}); err != nil {
+			return r.statusErr(fmt.Errorf("cache %s: %w", name, err))
+		}
+		if errors.Is(cErr, service.ErrKeyNotFound) {
+			return statusNotFound
+		}
+		if cErr != nil {
+			return r.statusErr(cErr)
+		}
+
+		contentPtr, err := r.allocateBytesInbound(ctx, value)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to allocate in-bound memory: %w", err))
+		}
+		if err := r.writeUint64Outbound(valueOutPtr, ptrLen(contentPtr, uint64(len(value)))); err != nil {
+			return r.statusErr(err)
+		}
+		return statusOK
+	}
+})
+
+// Sets the value of a key within a cache resource, with a TTL in milliseconds
+// where zero indicates the default TTL of the cache.
+var _ = registerModuleRunnerFunction("v0_cache_set", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, namePtr, nameSize, keyPtr, keySize, valuePtr, valueSize uint32, ttlMillis uint64) uint32 {
+		name, err := r.readBytesOutbound(ctx, namePtr, nameSize)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to read out-bound cache name memory: %w", err))
+		}
+		key, err := r.readBytesOutbound(ctx, keyPtr, keySize)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to read out-bound cache key memory: %w", err))
+		}
+		value, err := r.readBytesOutbound(ctx, valuePtr, valueSize)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to read out-bound cache value memory: %w", err))
+		}
+
+		var ttl *time.Duration
+		if ttlMillis > 0 {
+			d := time.Duration(ttlMillis) * time.Millisecond
+			ttl = &d
+		}
+
+		if r.mgr == nil {
+			return r.statusErr(errResourcesUnavailable)
+		}
+
+		var cErr error
+		if err := r.mgr.AccessCache(ctx, string(name), func(c service.Cache) {
+			cErr = c.Set(ctx, string(key), value, ttl)
+		}); err != nil {
+			return r.statusErr(fmt.Errorf("cache %s: %w", name, err))
+		}
+		if cErr != nil {
+			return r.statusErr(cErr)
+		}
+		return statusOK
+	}
+})
+
+var _ = registerModuleRunnerFunction("v0_cache_delete", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, namePtr, nameSize, keyPtr, keySize uint32) uint32 {
+		name, err := r.readBytesOutbound(ctx, namePtr, nameSize)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to read out-bound cache name memory: %w", err))
+		}
+		key, err := r.readBytesOutbound(ctx, keyPtr, keySize)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to read out-bound cache key memory: %w", err))
+		}
+
+		if r.mgr == nil {
+			return r.statusErr(errResourcesUnavailable)
+		}
+
+		var cErr error
+		if err := r.mgr.AccessCache(ctx, string(name), func(c service.Cache) {
+			cErr = c.Delete(ctx, string(key))
+		}); err != nil {
+			return r.statusErr(fmt.Errorf("cache %s: %w", name, err))
+		}
+		if cErr != nil {
+			return r.statusErr(cErr)
+		}
+		return statusOK
+	}
+})
+
+// Blocks until the rate limit resource grants access.
+var _ = registerModuleRunnerFunction("v0_rate_limit_access", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, namePtr, nameSize uint32) uint32 {
+		name, err := r.readBytesOutbound(ctx, namePtr, nameSize)
+		if err != nil {
+			return r.statusErr(fmt.Errorf("failed to read out-bound rate limit name memory: %w", err))
+		}
+
+		if r.mgr == nil {
+			return r.statusErr(errResourcesUnavailable)
+		}
+
+		for {
+			var waitFor time.Duration
+			var rErr error
+			if err := r.mgr.AccessRateLimit(ctx, string(name), func(rl service.RateLimit) {
+				waitFor, rErr = rl.Access(ctx)
+			}); err != nil {
+				return r.statusErr(fmt.Errorf("rate limit %s: %w", name, err))
+			}
+			if rErr != nil {
+				return r.statusErr(rErr)
+			}
+			if waitFor == 0 {
+				return statusOK
+			}
+			select {
+			case <-time.After(waitFor):
+			case <-ctx.Done():
+				return r.statusErr(ctx.Err())
+			}
+		}
+	}
+})
+
+//------------------------------------------------------------------------------
+
+// Registers a plugin described by a JSON document, which must be called by
+// plugin modules from within their exported `register` function.
+var _ = registerModuleRunnerFunction("v0_plugin_register", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, contentPtr, contentSize uint32) {
+		manifestBytes, err := r.readBytesOutbound(ctx, contentPtr, contentSize)
+		if err != nil {
+			r.funcErr(fmt.Errorf("failed to read out-bound plugin manifest memory: %w", err))
+			return
+		}
+		r.pluginManifest = manifestBytes
+	}
+})
+
+// Returns the config of a plugin component as a JSON object.
+var _ = registerModuleRunnerFunction("v0_plugin_config", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module) (ptrSize uint64) {
+		if r.pluginConfig == nil {
+			return r.returnBytes(ctx, []byte("{}"))
+		}
+		return r.returnBytes(ctx, r.pluginConfig)
+	}
+})
+
+// Sets the error returned by a plugin function that returns an error status.
+var _ = registerModuleRunnerFunction("v0_plugin_error", func(r *moduleRunner) interface{} {
+	return func(ctx context.Context, m api.Module, contentPtr, contentSize uint32) {
+		errBytes, err := r.readBytesOutbound(ctx, contentPtr, contentSize)
+		if err != nil {
+			r.funcErr(fmt.Errorf("failed to read out-bound plugin error memory: %w", err))
+			return
+		}
+		r.pluginErr = errors.New(string(errBytes))
+	}
+})
@@ -0,0 +1,266 @@
+package wasm
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// testModule is a minimal hand assembled WASM module that exports memory, a
+// bump allocator as `malloc`, a no-op `free` and a `process` function that
+// calls `v0_msg_delete`, allowing the host functions to be tested without a
+// WASM toolchain.
+var testModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // magic and version
+
+	// types: (i32)->(i32), (i32)->(), ()->()
+	0x01, 0x0d, 0x03,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x01, 0x7f, 0x00,
+	0x60, 0x00, 0x00,
+
+	// imports: benthos_wasm.v0_msg_delete as func 0
+	0x02, 0x1e, 0x01,
+	0x0c, 'b', 'e', 'n', 't', 'h', 'o', 's', '_', 'w', 'a', 's', 'm',
+	0x0d, 'v', '0', '_', 'm', 's', 'g', '_', 'd', 'e', 'l', 'e', 't', 'e',
+	0x00, 0x02,
+
+	// functions: malloc, free, process
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x02,
+
+	// memory: one page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+
+	// globals: mutable i32 allocation offset starting at 1024
+	0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b,
+
+	// exports
+	0x07, 0x24, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, 'm', 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x04, 'f', 'r', 'e', 'e', 0x00, 0x02,
+	0x07, 'p', 'r', 'o', 'c', 'e', 's', 's', 0x00, 0x03,
+
+	// code
+	0x0a, 0x15, 0x03,
+	0x0b, 0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b,
+	0x02, 0x00, 0x0b,
+	0x04, 0x00, 0x10, 0x00, 0x0b,
+}
+
+type testRunner struct {
+	t   *testing.T
+	ctx context.Context
+	r   *moduleRunner
+}
+
+func newTestRunner(t *testing.T, mgr *service.Resources) *testRunner {
+	t.Helper()
+
+	proc, err := newWazeroAllocProcessor("process", testModule, mgr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+
+	r, err := proc.newModule()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, r.Close(context.Background()))
+	})
+	return &testRunner{t: t, ctx: context.Background(), r: r}
+}
+
+func (tr *testRunner) call(name string, params ...uint64) uint64 {
+	tr.t.Helper()
+
+	fn := tr.r.runtime.Module("benthos_wasm").ExportedFunction(name)
+	require.NotNil(tr.t, fn, name)
+
+	res, err := fn.Call(tr.ctx, params...)
+	require.NoError(tr.t, err)
+	if len(res) == 0 {
+		return 0
+	}
+	return res[0]
+}
+
+func (tr *testRunner) bytes(b string) (ptr, size uint64) {
+	tr.t.Helper()
+
+	contentPtr, err := tr.r.allocateBytesInbound(tr.ctx, []byte(b))
+	require.NoError(tr.t, err)
+	return contentPtr, uint64(len(b))
+}
+
+func (tr *testRunner) outPtr() uint64 {
+	tr.t.Helper()
+
+	ptr, _ := tr.bytes("\x00\x00\x00\x00\x00\x00\x00\x00")
+	return ptr
+}
+
+func (tr *testRunner) readPtrSize(ptrSize uint64) string {
+	tr.t.Helper()
+
+	b, ok := tr.r.mod.Memory().Read(uint32(ptrSize>>32), uint32(ptrSize))
+	require.True(tr.t, ok)
+	return string(b)
+}
+
+func (tr *testRunner) readUint64(ptr uint64) uint64 {
+	tr.t.Helper()
+
+	b, ok := tr.r.mod.Memory().Read(uint32(ptr), 8)
+	require.True(tr.t, ok)
+	return binary.LittleEndian.Uint64(b)
+}
+
+func TestWASMFunctionsMetadataAndJSON(t *testing.T) {
+	tr := newTestRunner(t, service.MockResources())
+
+	msg := service.NewMessage([]byte(`{"id":"foo"}`))
+	msg.MetaSetMut("b", "bar")
+	msg.MetaSetMut("a", "baz")
+	tr.r.setBatch(service.MessageBatch{msg})
+
+	assert.Equal(t, "a\x00b", tr.readPtrSize(tr.call("v0_msg_meta_keys")))
+
+	keyP, keyS := tr.bytes("a")
+	tr.call("v0_msg_delete_meta", keyP, keyS)
+	assert.Equal(t, "b", tr.readPtrSize(tr.call("v0_msg_meta_keys")))
+
+	assert.Equal(t, `{"id":"foo"}`, tr.readPtrSize(tr.call("v0_msg_as_json")))
+
+	contentP, contentS := tr.bytes(`{"id":"bar","n":10}`)
+	tr.call("v0_msg_set_json", contentP, contentS)
+
+	structured, err := msg.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": "bar", "n": 10.0}, structured)
+	require.NoError(t, tr.r.procErr)
+
+	contentP, contentS = tr.bytes(`not json`)
+	tr.call("v0_msg_set_json", contentP, contentS)
+	require.Error(t, tr.r.procErr)
+}
+
+func TestWASMFunctionsBatch(t *testing.T) {
+	tr := newTestRunner(t, service.MockResources())
+
+	tr.r.setBatch(service.MessageBatch{
+		service.NewMessage([]byte("first")),
+		service.NewMessage([]byte("second")),
+	})
+
+	assert.Equal(t, uint64(2), tr.call("v0_batch_len"))
+	assert.Equal(t, uint64(statusOK), tr.call("v0_batch_select", 1))
+	assert.Equal(t, uint64(1), tr.call("v0_batch_index"))
+
+	indexPtr := tr.outPtr()
+	assert.Equal(t, uint64(statusOK), tr.call("v0_batch_copy", 1, indexPtr))
+	assert.Equal(t, uint64(2), tr.readUint64(indexPtr))
+	assert.Equal(t, uint64(3), tr.call("v0_batch_len"))
+
+	assert.Equal(t, uint64(statusOK), tr.call("v0_batch_select", 2))
+	contentP, contentS := tr.bytes("copied")
+	tr.call("v0_msg_set_bytes", contentP, contentS)
+
+	assert.Equal(t, uint64(statusOK), tr.call("v0_batch_select", 0))
+	tr.call("v0_msg_delete")
+	assert.Equal(t, uint64(statusNotFound), tr.call("v0_batch_select", 0))
+	assert.Equal(t, uint64(statusNotFound), tr.call("v0_batch_copy", 0, indexPtr))
+
+	assert.Equal(t, uint64(statusError), tr.call("v0_batch_select", 5))
+	assert.Equal(t, "index 5 is out of bounds of batch length 3", tr.readPtrSize(tr.call("v0_last_error")))
+	assert.Equal(t, uint64(0), tr.call("v0_last_error"))
+
+	var contents []string
+	for _, m := range tr.r.resultBatch() {
+		b, err := m.AsBytes()
+		require.NoError(t, err)
+		contents = append(contents, string(b))
+	}
+	assert.Equal(t, []string{"second", "copied"}, contents)
+}
+
+func TestWASMFunctionsResources(t *testing.T) {
+	var rlCalls int
+	tr := newTestRunner(t, service.MockResources(
+		service.MockResourcesOptAddCache("foocache"),
+		service.MockResourcesOptAddRateLimit("foolimit", func(ctx context.Context) (time.Duration, error) {
+			rlCalls++
+			if rlCalls == 1 {
+				return time.Millisecond, nil
+			}
+			return 0, nil
+		}),
+	))
+	tr.r.setBatch(service.MessageBatch{service.NewMessage(nil)})
+
+	nameP, nameS := tr.bytes("foocache")
+	keyP, keyS := tr.bytes("bar")
+	valueOut := tr.outPtr()
+
+	assert.Equal(t, uint64(statusNotFound), tr.call("v0_cache_get", nameP, nameS, keyP, keyS, valueOut))
+
+	valueP, valueS := tr.bytes("baz")
+	assert.Equal(t, uint64(statusOK), tr.call("v0_cache_set", nameP, nameS, keyP, keyS, valueP, valueS, 0))
+
+	assert.Equal(t, uint64(statusOK), tr.call("v0_cache_get", nameP, nameS, keyP, keyS, valueOut))
+	assert.Equal(t, "baz", tr.readPtrSize(tr.readUint64(valueOut)))
+
+	assert.Equal(t, uint64(statusOK), tr.call("v0_cache_delete", nameP, nameS, keyP, keyS))
+	assert.Equal(t, uint64(statusNotFound), tr.call("v0_cache_get", nameP, nameS, keyP, keyS, valueOut))
+
+	badP, badS := tr.bytes("nope")
+	assert.Equal(t, uint64(statusError), tr.call("v0_cache_get", badP, badS, keyP, keyS, valueOut))
+	assert.Equal(t, "cache nope: cache not found", tr.readPtrSize(tr.call("v0_last_error")))
+
+	rlP, rlS := tr.bytes("foolimit")
+	assert.Equal(t, uint64(statusOK), tr.call("v0_rate_limit_access", rlP, rlS))
+	assert.Equal(t, 2, rlCalls)
+
+	msgP, msgS := tr.bytes("hello world")
+	tr.call("v0_log", uint64(logLevelInfo), msgP, msgS)
+	require.NoError(t, tr.r.procErr)
+}
+
+func TestWASMProcessorModes(t *testing.T) {
+	newBatch := func() service.MessageBatch {
+		return service.MessageBatch{
+			service.NewMessage([]byte("first")),
+			service.NewMessage([]byte("second")),
+		}
+	}
+
+	proc, err := newWazeroAllocProcessor("process", testModule, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+
+	// The test module deletes the targeted message, which in message mode is
+	// every message.
+	outBatches, err := proc.ProcessBatch(context.Background(), newBatch())
+	require.NoError(t, err)
+	require.Len(t, outBatches, 1)
+	assert.Empty(t, outBatches[0])
+
+	// In batch mode only the first message of the batch is targeted.
+	proc.batchMode = true
+	outBatches, err = proc.ProcessBatch(context.Background(), newBatch())
+	require.NoError(t, err)
+	require.Len(t, outBatches, 1)
+	require.Len(t, outBatches[0], 1)
+
+	b, err := outBatches[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(b))
+}
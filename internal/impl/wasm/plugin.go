@@ -0,0 +1,463 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/benthosdev/benthos/v4/internal/cli"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func init() {
+	cli.SetWASMPluginLoader(func(paths ...string) error {
+		for _, p := range paths {
+			wasmBinary, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			if err := RegisterPlugin(service.GlobalEnvironment(), bloblang.GlobalEnvironment(), wasmBinary); err != nil {
+				return fmt.Errorf("%v: %w", p, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Status codes returned by functions exported by plugin modules.
+const (
+	pluginStatusOK    uint32 = 0
+	pluginStatusEnd   uint32 = 1
+	pluginStatusError uint32 = 2
+)
+
+type pluginFieldManifest struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Default     any    `json:"default"`
+}
+
+type pluginParamManifest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// pluginManifest describes a component implemented by a WASM module, and is
+// provided by the module as a JSON document by calling v0_plugin_register from
+// within its exported `register` function.
+type pluginManifest struct {
+	Name        string                `json:"name"`
+	Type        string                `json:"type"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Fields      []pluginFieldManifest `json:"fields"`
+	Params      []pluginParamManifest `json:"params"`
+	Timeout     string                `json:"timeout"`
+}
+
+func (m pluginManifest) configSpec() (*service.ConfigSpec, error) {
+	spec := service.NewConfigSpec().
+		Summary(m.Summary).
+		Description(m.Description)
+
+	for _, f := range m.Fields {
+		var field *service.ConfigField
+		defaultValue := f.Default
+		switch f.Type {
+		case "string":
+			field = service.NewStringField(f.Name)
+		case "int":
+			field = service.NewIntField(f.Name)
+			if fDefault, ok := defaultValue.(float64); ok {
+				defaultValue = int(fDefault)
+			}
+		case "float":
+			field = service.NewFloatField(f.Name)
+		case "bool":
+			field = service.NewBoolField(f.Name)
+		case "", "any":
+			field = service.NewAnyField(f.Name)
+		default:
+			return nil, fmt.Errorf("field %v: unrecognised type: %v", f.Name, f.Type)
+		}
+		field = field.Description(f.Description)
+		if defaultValue != nil {
+			field = field.Default(defaultValue)
+		}
+		spec = spec.Field(field)
+	}
+	return spec, nil
+}
+
+func (m pluginManifest) functionSpec() *bloblang.PluginSpec {
+	spec := bloblang.NewPluginSpec().Description(m.Summary)
+	for _, p := range m.Params {
+		spec = spec.Param(bloblang.NewAnyParam(p.Name).Description(p.Description))
+	}
+	return spec
+}
+
+// pluginConfigJSON returns the fields of a parsed plugin config as a JSON
+// object.
+func pluginConfigJSON(m pluginManifest, conf *service.ParsedConfig) ([]byte, error) {
+	obj := map[string]any{}
+	for _, f := range m.Fields {
+		if !conf.Contains(f.Name) {
+			continue
+		}
+		v, err := conf.FieldAny(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		obj[f.Name] = v
+	}
+	return json.Marshal(obj)
+}
+
+// RegisterPlugin registers the component implemented by a WASM module within
+// a service environment, or a Bloblang environment in the case of functions.
+//
+// The module must export a function `register` which calls v0_plugin_register
+// with a JSON document describing the component, and depending on the type of
+// the component exports functions that implement its lifecycle.
+func RegisterPlugin(env *service.Environment, bEnv *bloblang.Environment, wasmBinary []byte) error {
+	r, err := newModuleRunner(wasmBinary, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer r.Close(context.Background())
+
+	if err := r.callPluginFn(context.Background(), "register", true); err != nil {
+		return err
+	}
+	if r.pluginManifest == nil {
+		return errors.New("module did not register a plugin")
+	}
+
+	var manifest pluginManifest
+	if err := json.Unmarshal(r.pluginManifest, &manifest); err != nil {
+		return fmt.Errorf("failed to parse plugin manifest: %w", err)
+	}
+	if manifest.Name == "" {
+		return errors.New("plugin manifest is missing a name")
+	}
+
+	switch manifest.Type {
+	case "input":
+		spec, err := manifest.configSpec()
+		if err != nil {
+			return err
+		}
+		return env.RegisterBatchInput(manifest.Name, spec, func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			return newPluginInput(manifest, wasmBinary, conf, mgr)
+		})
+	case "output":
+		spec, err := manifest.configSpec()
+		if err != nil {
+			return err
+		}
+		return env.RegisterBatchOutput(manifest.Name, spec, func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchOutput, service.BatchPolicy, int, error) {
+			out, err := newPluginOutput(manifest, wasmBinary, conf, mgr)
+			return out, service.BatchPolicy{}, 1, err
+		})
+	case "function":
+		fn, err := newPluginFunction(manifest, wasmBinary)
+		if err != nil {
+			return err
+		}
+		return bEnv.RegisterFunctionV2(manifest.Name, manifest.functionSpec(), func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			argsSlice := args.AsSlice()
+			return func() (any, error) {
+				return fn.call(argsSlice)
+			}, nil
+		})
+	}
+	return fmt.Errorf("unrecognised plugin type: %v", manifest.Type)
+}
+
+// callPluginFn calls a function exported by a plugin module and converts its
+// status into an error. Functions that are not required are skipped when the
+// module does not export them.
+func (r *moduleRunner) callPluginFn(ctx context.Context, name string, required bool, params ...uint64) error {
+	_, err := r.callPluginFnStatus(ctx, name, required, params...)
+	return err
+}
+
+func (r *moduleRunner) callPluginFnStatus(ctx context.Context, name string, required bool, params ...uint64) (uint32, error) {
+	fn := r.mod.ExportedFunction(name)
+	if fn == nil {
+		if required {
+			return 0, fmt.Errorf("module does not export function %v", name)
+		}
+		return pluginStatusOK, nil
+	}
+
+	r.pluginErr = nil
+	res, err := fn.Call(ctx, params...)
+	for _, fn := range r.afterProcessing {
+		fn()
+	}
+	r.afterProcessing = nil
+	if err != nil {
+		return 0, err
+	}
+	if r.procErr != nil {
+		return 0, r.procErr
+	}
+
+	status := pluginStatusOK
+	if len(res) > 0 {
+		status = api.DecodeU32(res[0])
+	}
+	if status == pluginStatusError {
+		if r.pluginErr != nil {
+			return status, r.pluginErr
+		}
+		return status, fmt.Errorf("function %v failed", name)
+	}
+	return status, nil
+}
+
+//------------------------------------------------------------------------------
+
+// pluginInput calls the function `read` of a module in order to obtain
+// batches, where the module populates the messages of a batch in the same way
+// as a processor operating in batch mode. Each batch read is identified by an
+// ID that is provided to the function `ack` of the module once the batch has
+// been delivered or rejected.
+type pluginInput struct {
+	mut sync.Mutex
+	r   *moduleRunner
+
+	nextID uint64
+}
+
+func newPluginInput(m pluginManifest, wasmBinary []byte, conf *service.ParsedConfig, mgr *service.Resources) (*pluginInput, error) {
+	confBytes, err := pluginConfigJSON(m, conf)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newModuleRunner(wasmBinary, mgr.Logger(), mgr)
+	if err != nil {
+		return nil, err
+	}
+	if r.mod.ExportedFunction("read") == nil {
+		r.Close(context.Background())
+		return nil, errors.New("module does not export function read")
+	}
+	r.pluginConfig = confBytes
+	return &pluginInput{r: r}, nil
+}
+
+func (p *pluginInput) Connect(ctx context.Context) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	return p.r.callPluginFn(ctx, "connect", false)
+}
+
+func (p *pluginInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	p.nextID++
+	id := p.nextID
+
+	p.r.reset()
+	defer p.r.reset()
+
+	p.r.setBatch(service.MessageBatch{service.NewMessage(nil)})
+	status, err := p.r.callPluginFnStatus(ctx, "read", true, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status == pluginStatusEnd {
+		return nil, nil, service.ErrEndOfInput
+	}
+
+	return p.r.resultBatch(), func(ctx context.Context, err error) error {
+		p.mut.Lock()
+		defer p.mut.Unlock()
+
+		var success uint64
+		if err == nil {
+			success = 1
+		}
+		return p.r.callPluginFn(ctx, "ack", false, id, success)
+	}, nil
+}
+
+func (p *pluginInput) Close(ctx context.Context) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	err := p.r.callPluginFn(ctx, "close", false)
+	if cErr := p.r.Close(ctx); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+// pluginOutput calls the function `write` of a module for each batch, where
+// the module reads the messages of the batch in the same way as a processor
+// operating in batch mode.
+type pluginOutput struct {
+	mut sync.Mutex
+	r   *moduleRunner
+}
+
+func newPluginOutput(m pluginManifest, wasmBinary []byte, conf *service.ParsedConfig, mgr *service.Resources) (*pluginOutput, error) {
+	confBytes, err := pluginConfigJSON(m, conf)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newModuleRunner(wasmBinary, mgr.Logger(), mgr)
+	if err != nil {
+		return nil, err
+	}
+	if r.mod.ExportedFunction("write") == nil {
+		r.Close(context.Background())
+		return nil, errors.New("module does not export function write")
+	}
+	r.pluginConfig = confBytes
+	return &pluginOutput{r: r}, nil
+}
+
+func (p *pluginOutput) Connect(ctx context.Context) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	return p.r.callPluginFn(ctx, "connect", false)
+}
+
+func (p *pluginOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	p.r.reset()
+	defer p.r.reset()
+
+	p.r.setBatch(append(service.MessageBatch{}, batch...))
+	return p.r.callPluginFn(ctx, "write", true)
+}
+
+func (p *pluginOutput) Close(ctx context.Context) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	err := p.r.callPluginFn(ctx, "close", false)
+	if cErr := p.r.Close(ctx); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+// pluginFunction calls the function `call` of a module for each execution of
+// a Bloblang function, where the arguments of the function are provided as the
+// contents of a message in the form of a JSON array, and the contents of the
+// message after the call are the result.
+type pluginFunction struct {
+	wasmBinary []byte
+	timeout    time.Duration
+
+	// Idle module instances, instances that do not fit within the pool are
+	// closed.
+	modulePool chan *moduleRunner
+}
+
+// defaultPluginFunctionTimeout is the maximum duration of a call to a function
+// plugin when the manifest does not specify a timeout.
+const defaultPluginFunctionTimeout = time.Second * 5
+
+func newPluginFunction(manifest pluginManifest, wasmBinary []byte) (*pluginFunction, error) {
+	timeout := defaultPluginFunctionTimeout
+	if manifest.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(manifest.Timeout); err != nil {
+			return nil, fmt.Errorf("failed to parse plugin timeout: %w", err)
+		}
+	}
+	return &pluginFunction{
+		wasmBinary: wasmBinary,
+		timeout:    timeout,
+		modulePool: make(chan *moduleRunner, runtime.GOMAXPROCS(0)),
+	}, nil
+}
+
+func (p *pluginFunction) getModule() (*moduleRunner, error) {
+	select {
+	case r := <-p.modulePool:
+		return r, nil
+	default:
+	}
+	// Modules are closed when the context of a call ends, which allows us to
+	// abort calls that exceed the timeout.
+	return newModuleRunnerWithConfig(p.wasmBinary, wazero.NewRuntimeConfig().WithCloseOnContextDone(true), nil, nil)
+}
+
+func (p *pluginFunction) putModule(r *moduleRunner) {
+	select {
+	case p.modulePool <- r:
+	default:
+		_ = r.Close(context.Background())
+	}
+}
+
+func (p *pluginFunction) call(args []any) (any, error) {
+	r, err := p.getModule()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), p.timeout)
+	defer done()
+	defer func() {
+		if ctx.Err() != nil {
+			// The module has been closed by the runtime and cannot be reused.
+			_ = r.Close(context.Background())
+			return
+		}
+		p.putModule(r)
+	}()
+
+	r.reset()
+	defer r.reset()
+
+	if args == nil {
+		args = []any{}
+	}
+	msg := service.NewMessage(nil)
+	msg.SetStructured(args)
+	r.setBatch(service.MessageBatch{msg})
+
+	if err := r.callPluginFn(ctx, "call", true); err != nil {
+		return nil, err
+	}
+
+	res := r.resultBatch()
+	if len(res) == 0 {
+		return nil, nil
+	}
+	if v, err := res[0].AsStructured(); err == nil {
+		return v, nil
+	}
+	b, err := res[0].AsBytes()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
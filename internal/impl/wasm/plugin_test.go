@@ -0,0 +1,369 @@
+package wasm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+
+	_ "github.com/benthosdev/benthos/v4/internal/impl/pure"
+)
+
+// Type indexes of the test module assembler.
+const (
+	typeI32RetI32 byte = iota
+	typeI32
+	typeNone
+	typeI32I32
+	typeRetI32
+	typeI64RetI32
+	typeI64I32
+)
+
+type testImport struct {
+	name    string
+	typeIdx byte
+}
+
+type testFunc struct {
+	export  string
+	typeIdx byte
+	body    []byte
+}
+
+func uleb(v uint64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if v == 0 {
+			return b
+		}
+	}
+}
+
+func wasmName(s string) []byte {
+	return append(uleb(uint64(len(s))), s...)
+}
+
+func wasmVec(items ...[]byte) []byte {
+	b := uleb(uint64(len(items)))
+	for _, item := range items {
+		b = append(b, item...)
+	}
+	return b
+}
+
+func wasmSection(id byte, payload []byte) []byte {
+	return append(append([]byte{id}, uleb(uint64(len(payload)))...), payload...)
+}
+
+func i32Const(v int32) []byte {
+	b := []byte{0x41}
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func callFn(idx int) []byte {
+	return append([]byte{0x10}, uleb(uint64(idx))...)
+}
+
+// assembleTestModule creates a WASM module that imports host functions, exports
+// memory along with a bump allocator and the provided functions, and contains
+// a data segment at offset 16.
+func assembleTestModule(imports []testImport, data []byte, funcs ...testFunc) []byte {
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+	module = append(module, wasmSection(0x01, wasmVec(
+		[]byte{0x60, 0x01, 0x7f, 0x01, 0x7f},
+		[]byte{0x60, 0x01, 0x7f, 0x00},
+		[]byte{0x60, 0x00, 0x00},
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x00},
+		[]byte{0x60, 0x00, 0x01, 0x7f},
+		[]byte{0x60, 0x01, 0x7e, 0x01, 0x7f},
+		[]byte{0x60, 0x02, 0x7e, 0x7f, 0x00},
+	))...)
+
+	var importEntries [][]byte
+	for _, imp := range imports {
+		entry := append(wasmName("benthos_wasm"), wasmName(imp.name)...)
+		importEntries = append(importEntries, append(entry, 0x00, imp.typeIdx))
+	}
+	module = append(module, wasmSection(0x02, wasmVec(importEntries...))...)
+
+	funcTypes := [][]byte{{typeI32RetI32}, {typeI32}}
+	for _, f := range funcs {
+		funcTypes = append(funcTypes, []byte{f.typeIdx})
+	}
+	module = append(module, wasmSection(0x03, wasmVec(funcTypes...))...)
+	module = append(module, wasmSection(0x05, wasmVec([]byte{0x00, 0x01}))...)
+	module = append(module, wasmSection(0x06, wasmVec([]byte{0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b}))...)
+
+	exports := [][]byte{
+		append(wasmName("memory"), 0x02, 0x00),
+		append(wasmName("malloc"), append([]byte{0x00}, uleb(uint64(len(imports)))...)...),
+		append(wasmName("free"), append([]byte{0x00}, uleb(uint64(len(imports)+1))...)...),
+	}
+	for i, f := range funcs {
+		exports = append(exports, append(wasmName(f.export), append([]byte{0x00}, uleb(uint64(len(imports)+2+i))...)...))
+	}
+	module = append(module, wasmSection(0x07, wasmVec(exports...))...)
+
+	bodies := [][]byte{
+		{0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b},
+		{0x00, 0x0b},
+	}
+	for _, f := range funcs {
+		bodies = append(bodies, append(append([]byte{0x00}, f.body...), 0x0b))
+	}
+	var codeEntries [][]byte
+	for _, b := range bodies {
+		codeEntries = append(codeEntries, append(uleb(uint64(len(b))), b...))
+	}
+	module = append(module, wasmSection(0x0a, wasmVec(codeEntries...))...)
+
+	module = append(module, wasmSection(0x0b, wasmVec(
+		append([]byte{0x00, 0x41, 0x10, 0x0b}, append(uleb(uint64(len(data))), data...)...),
+	))...)
+	return module
+}
+
+// testPluginModule creates a module with a register function that registers
+// the provided manifest, along with any other functions.
+func testPluginModule(manifest string, imports []testImport, data string, funcs ...testFunc) []byte {
+	imports = append([]testImport{{name: "v0_plugin_register", typeIdx: typeI32I32}}, imports...)
+
+	var registerBody []byte
+	registerBody = append(registerBody, i32Const(16)...)
+	registerBody = append(registerBody, i32Const(int32(len(manifest)))...)
+	registerBody = append(registerBody, callFn(0)...)
+
+	funcs = append([]testFunc{{export: "register", typeIdx: typeNone, body: registerBody}}, funcs...)
+	return assembleTestModule(imports, []byte(manifest+data), funcs...)
+}
+
+func TestWASMPluginFunction(t *testing.T) {
+	// The call function does nothing, and therefore returns its arguments.
+	module := testPluginModule(`{"name":"wasm_echo","type":"function","params":[{"name":"a"},{"name":"b"}]}`, nil, "",
+		testFunc{export: "call", typeIdx: typeRetI32, body: i32Const(0)},
+	)
+
+	bEnv := bloblang.NewEnvironment()
+	require.NoError(t, RegisterPlugin(service.NewEnvironment(), bEnv, module))
+
+	exec, err := bEnv.Parse(`root = wasm_echo(this.a, 5)`)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				res, err := exec.Query(map[string]any{"a": "foo"})
+				require.NoError(t, err)
+				assert.Equal(t, []any{"foo", int64(5)}, res)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWASMPluginFunctionPool(t *testing.T) {
+	manifest := pluginManifest{Name: "wasm_echo", Type: "function"}
+	module := testPluginModule(`{"name":"wasm_echo","type":"function"}`, nil, "",
+		testFunc{export: "call", typeIdx: typeRetI32, body: i32Const(0)},
+	)
+
+	fn, err := newPluginFunction(manifest, module)
+	require.NoError(t, err)
+	assert.Equal(t, defaultPluginFunctionTimeout, fn.timeout)
+
+	var wg sync.WaitGroup
+	for i := 0; i < cap(fn.modulePool)*4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := fn.call([]any{"foo"})
+			require.NoError(t, err)
+			assert.Equal(t, []any{"foo"}, res)
+		}()
+	}
+	wg.Wait()
+
+	// Idle modules never exceed the capacity of the pool, the remainder are
+	// closed.
+	assert.LessOrEqual(t, len(fn.modulePool), cap(fn.modulePool))
+}
+
+func TestWASMPluginFunctionTimeout(t *testing.T) {
+	// The call function loops forever.
+	module := testPluginModule(`{"name":"wasm_loop","type":"function","timeout":"50ms"}`, nil, "",
+		testFunc{export: "call", typeIdx: typeRetI32, body: append([]byte{
+			0x03, 0x40, // loop
+			0x0c, 0x00, // br 0
+			0x0b, // end
+		}, i32Const(0)...)},
+	)
+
+	bEnv := bloblang.NewEnvironment()
+	require.NoError(t, RegisterPlugin(service.NewEnvironment(), bEnv, module))
+
+	exec, err := bEnv.Parse(`root = wasm_loop()`)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = exec.Query(nil)
+		require.Error(t, err)
+	}
+
+	_, err = newPluginFunction(pluginManifest{Timeout: "nope"}, module)
+	require.Error(t, err)
+}
+
+func TestWASMPluginInput(t *testing.T) {
+	manifest := `{"name":"wasm_hello","type":"input","fields":[{"name":"count","type":"int","default":2}]}`
+	hello := "hello"
+	helloOffset := 16 + len(manifest)
+
+	// Read sets the contents of the message to hello and ends the input after
+	// two batches.
+	var readBody []byte
+	readBody = append(readBody, i32Const(int32(helloOffset))...)
+	readBody = append(readBody, i32Const(int32(len(hello)))...)
+	readBody = append(readBody, callFn(1)...)
+	readBody = append(readBody,
+		0x20, 0x00, // local.get 0
+		0x42, 0x02, // i64.const 2
+		0x56,       // i64.gt_u
+		0x04, 0x7f, // if (result i32)
+		0x41, 0x01, // i32.const 1
+		0x05,       // else
+		0x41, 0x00, // i32.const 0
+		0x0b, // end
+	)
+
+	module := testPluginModule(manifest, []testImport{{name: "v0_msg_set_bytes", typeIdx: typeI32I32}}, hello,
+		testFunc{export: "read", typeIdx: typeI64RetI32, body: readBody},
+		testFunc{export: "ack", typeIdx: typeI64I32},
+	)
+
+	env := service.NewEnvironment()
+	require.NoError(t, RegisterPlugin(env, bloblang.NewEnvironment(), module))
+
+	builder := env.NewStreamBuilder()
+	require.NoError(t, builder.AddInputYAML(`wasm_hello: {}`))
+
+	var mut sync.Mutex
+	var received []string
+	require.NoError(t, builder.AddConsumerFunc(func(ctx context.Context, m *service.Message) error {
+		b, err := m.AsBytes()
+		if err != nil {
+			return err
+		}
+		mut.Lock()
+		received = append(received, string(b))
+		mut.Unlock()
+		return nil
+	}))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	require.NoError(t, strm.Run(ctx))
+
+	mut.Lock()
+	assert.Equal(t, []string{"hello", "hello"}, received)
+	mut.Unlock()
+}
+
+func TestWASMPluginOutputError(t *testing.T) {
+	manifest := `{"name":"wasm_nope","type":"output","fields":[{"name":"target","type":"string"}]}`
+	errMsg := "nope"
+
+	var writeBody []byte
+	writeBody = append(writeBody, i32Const(int32(16+len(manifest)))...)
+	writeBody = append(writeBody, i32Const(int32(len(errMsg)))...)
+	writeBody = append(writeBody, callFn(1)...)
+	writeBody = append(writeBody, i32Const(2)...)
+
+	module := testPluginModule(manifest, []testImport{{name: "v0_plugin_error", typeIdx: typeI32I32}}, errMsg,
+		testFunc{export: "write", typeIdx: typeRetI32, body: writeBody},
+	)
+
+	env := service.NewEnvironment()
+	require.NoError(t, RegisterPlugin(env, bloblang.NewEnvironment(), module))
+
+	var m pluginManifest
+	m.Fields = []pluginFieldManifest{{Name: "target", Type: "string"}}
+	spec, err := m.configSpec()
+	require.NoError(t, err)
+
+	conf, err := spec.ParseYAML(`target: foo`, nil)
+	require.NoError(t, err)
+
+	confBytes, err := pluginConfigJSON(m, conf)
+	require.NoError(t, err)
+	assert.Equal(t, `{"target":"foo"}`, string(confBytes))
+
+	out, err := newPluginOutput(m, module, conf, service.MockResources())
+	require.NoError(t, err)
+
+	require.NoError(t, out.Connect(context.Background()))
+	err = out.WriteBatch(context.Background(), service.MessageBatch{service.NewMessage([]byte("hello"))})
+	require.EqualError(t, err, "nope")
+	require.NoError(t, out.Close(context.Background()))
+}
+
+func TestWASMPluginRegisterErrors(t *testing.T) {
+	tests := map[string]struct {
+		module []byte
+		err    string
+	}{
+		"no register": {
+			module: assembleTestModule(nil, nil),
+			err:    "module does not export function register",
+		},
+		"bad type": {
+			module: testPluginModule(`{"name":"foo","type":"nope"}`, nil, ""),
+			err:    "unrecognised plugin type: nope",
+		},
+		"bad field type": {
+			module: testPluginModule(`{"name":"foo","type":"input","fields":[{"name":"bar","type":"nope"}]}`, nil, ""),
+			err:    "field bar: unrecognised type: nope",
+		},
+		"missing read": {
+			module: testPluginModule(`{"name":"wasm_noread","type":"input"}`, nil, ""),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := RegisterPlugin(service.NewEnvironment(), bloblang.NewEnvironment(), test.module)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -26,6 +26,18 @@ This ecosystem is delicate as WASM doesn't have a single clearly defined way to
 
 These examples, as well as the processor itself, is a work in progress.
 
+### Batch Processing
+
+By default the exported function is called once for each message of a batch. When the field ` + "`mode`" + ` is set to ` + "`batch`" + ` the function is instead called once for each batch, where the module can iterate the messages of the batch, select the message that other functions operate on, copy messages in order to split them, and delete messages in order to drop them from the resulting batch.
+
+### Resources
+
+Modules are able to access [cache resources](/docs/components/caches/about) and [rate limit resources](/docs/components/rate_limits/about) by their label, and emit log messages at the level of their choosing.
+
+### Plugin Components
+
+WASM modules can also implement inputs, outputs and Bloblang functions, which are imported with the ` + "`--wasm-plugins`" + ` flag. The lifecycle of these components is described in [the codebase](https://github.com/benthosdev/benthos/tree/main/public/wasm/README.md#plugin-components).
+
 ### Parallelism
 
 It's not currently possible to execute a single WASM runtime across parallel threads with this processor. Therefore, in order to support parallel processing this processor implements pooling of module runtimes. Ideally your WASM module shouldn't depend on any global state, but if it does then you need to ensure the processor [is only run on a single thread](/docs/configuration/processing_pipelines).
@@ -35,6 +47,14 @@ It's not currently possible to execute a single WASM runtime across parallel thr
 		Field(service.NewStringField("function").
 			Default("process").
 			Description("The name of the function exported by the target WASM module to run for each message.")).
+		Field(service.NewStringAnnotatedEnumField("mode", map[string]string{
+			"message": "The function is called once for each message of a batch.",
+			"batch":   "The function is called once for each batch, and is able to select, copy and delete the messages of the batch.",
+		}).
+			Default("message").
+			Description("Whether the function is called once for each message or once for each batch of messages.").
+			Advanced().
+			Version("4.14.0")).
 		Version("4.11.0")
 }
 
@@ -53,7 +73,9 @@ func init() {
 
 type wazeroAllocProcessor struct {
 	log          *service.Logger
+	mgr          *service.Resources
 	functionName string
+	batchMode    bool
 	wasmBinary   []byte
 	modulePool   sync.Pool
 }
@@ -69,17 +91,28 @@ func newWazeroAllocProcessorFromConfig(conf *service.ParsedConfig, mgr *service.
 		return nil, err
 	}
 
+	mode, err := conf.FieldString("mode")
+	if err != nil {
+		return nil, err
+	}
+
 	fileBytes, err := os.ReadFile(pathStr)
 	if err != nil {
 		return nil, err
 	}
 
-	return newWazeroAllocProcessor(function, fileBytes, mgr)
+	proc, err := newWazeroAllocProcessor(function, fileBytes, mgr)
+	if err != nil {
+		return nil, err
+	}
+	proc.batchMode = mode == "batch"
+	return proc, nil
 }
 
 func newWazeroAllocProcessor(functionName string, wasmBinary []byte, mgr *service.Resources) (*wazeroAllocProcessor, error) {
 	proc := &wazeroAllocProcessor{
 		log:        mgr.Logger(),
+		mgr:        mgr,
 		modulePool: sync.Pool{},
 
 		functionName: functionName,
@@ -96,12 +129,30 @@ func newWazeroAllocProcessor(functionName string, wasmBinary []byte, mgr *servic
 	return proc, nil
 }
 
-func (p *wazeroAllocProcessor) newModule() (mod *moduleRunner, err error) {
+func (p *wazeroAllocProcessor) newModule() (*moduleRunner, error) {
+	mod, err := newModuleRunner(p.wasmBinary, p.log, p.mgr)
+	if err != nil {
+		return nil, err
+	}
+	mod.process = mod.mod.ExportedFunction(p.functionName)
+	return mod, nil
+}
+
+// newModuleRunner instantiates a WASM module within a new runtime, along with
+// the host functions that it is able to import.
+func newModuleRunner(wasmBinary []byte, log *service.Logger, mgr *service.Resources) (*moduleRunner, error) {
+	return newModuleRunnerWithConfig(wasmBinary, wazero.NewRuntimeConfig(), log, mgr)
+}
+
+// newModuleRunnerWithConfig instantiates a WASM module within a new runtime
+// created with the provided config.
+func newModuleRunnerWithConfig(wasmBinary []byte, rConf wazero.RuntimeConfig, log *service.Logger, mgr *service.Resources) (mod *moduleRunner, err error) {
 	ctx := context.Background()
 
-	r := wazero.NewRuntime(ctx)
+	r := wazero.NewRuntimeWithConfig(ctx, rConf)
 	mod = &moduleRunner{
-		log:     p.log,
+		log:     log,
+		mgr:     mgr,
 		runtime: r,
 	}
 	defer func() {
@@ -122,11 +173,10 @@ func (p *wazeroAllocProcessor) newModule() (mod *moduleRunner, err error) {
 		return
 	}
 
-	if mod.mod, err = r.Instantiate(ctx, p.wasmBinary); err != nil {
+	if mod.mod, err = r.Instantiate(ctx, wasmBinary); err != nil {
 		return
 	}
 
-	mod.process = mod.mod.ExportedFunction(p.functionName)
 	mod.goMalloc = mod.mod.ExportedFunction("malloc")
 	mod.goFree = mod.mod.ExportedFunction("free")
 	mod.rustAlloc = mod.mod.ExportedFunction("allocate")
@@ -149,7 +199,12 @@ func (p *wazeroAllocProcessor) ProcessBatch(ctx context.Context, batch service.M
 		p.modulePool.Put(modRunner)
 	}()
 
-	res, err := modRunner.Run(ctx, batch)
+	var res service.MessageBatch
+	if p.batchMode {
+		res, err = modRunner.RunBatch(ctx, batch)
+	} else {
+		res, err = modRunner.Run(ctx, batch)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -172,15 +227,24 @@ func (p *wazeroAllocProcessor) Close(ctx context.Context) error {
 
 type moduleRunner struct {
 	log *service.Logger
+	mgr *service.Resources
 
 	runtime wazero.Runtime
 	mod     api.Module
 
+	// The batch of messages resulting from the current call, where deleted
+	// messages are nil, and the message currently targeted by functions.
 	runBatch        service.MessageBatch
 	targetMessage   *service.Message
 	targetIndex     int
 	afterProcessing []func()
 	procErr         error
+	lastErr         error
+
+	// Used by modules that implement plugin components.
+	pluginManifest []byte
+	pluginConfig   []byte
+	pluginErr      error
 
 	process     api.Function
 	goMalloc    api.Function
@@ -194,9 +258,32 @@ func (r *moduleRunner) reset() {
 	r.targetMessage = nil
 	r.targetIndex = 0
 	r.procErr = nil
+	r.lastErr = nil
 	r.afterProcessing = nil
 }
 
+// setBatch sets the batch of messages that the next call operates on, and
+// targets the first message of the batch.
+func (r *moduleRunner) setBatch(batch service.MessageBatch) {
+	r.runBatch = batch
+	r.targetIndex = 0
+	r.targetMessage = nil
+	if len(batch) > 0 {
+		r.targetMessage = batch[0]
+	}
+}
+
+// resultBatch returns the messages of the batch that were not deleted.
+func (r *moduleRunner) resultBatch() service.MessageBatch {
+	var newBatch service.MessageBatch
+	for _, m := range r.runBatch {
+		if m != nil {
+			newBatch = append(newBatch, m)
+		}
+	}
+	return newBatch
+}
+
 func (r *moduleRunner) funcErr(err error) {
 	r.procErr = err
 	r.log.Error(err.Error())
@@ -262,9 +349,7 @@ func (r *moduleRunner) Run(ctx context.Context, batch service.MessageBatch) (ser
 	var newBatch service.MessageBatch
 	for i := range batch {
 		r.reset()
-		r.runBatch = batch
-		r.targetIndex = i
-		r.targetMessage = batch[i]
+		r.setBatch(service.MessageBatch{batch[i]})
 		_, err := r.process.Call(ctx)
 		for _, fn := range r.afterProcessing {
 			fn()
@@ -272,18 +357,42 @@ func (r *moduleRunner) Run(ctx context.Context, batch service.MessageBatch) (ser
 		if err != nil {
 			return nil, err
 		}
-		newMsg := r.targetMessage
 		if r.procErr != nil {
-			newMsg = batch[i].Copy()
+			newMsg := batch[i].Copy()
 			newMsg.SetError(r.procErr)
-		}
-		if newMsg != nil {
 			newBatch = append(newBatch, newMsg)
+			continue
 		}
+		newBatch = append(newBatch, r.resultBatch()...)
 	}
 	return newBatch, nil
 }
 
+// RunBatch calls the function of the module once for an entire batch, if the
+// call fails then all messages of the batch are marked as failed.
+func (r *moduleRunner) RunBatch(ctx context.Context, batch service.MessageBatch) (service.MessageBatch, error) {
+	defer r.reset()
+
+	r.reset()
+	r.setBatch(append(service.MessageBatch{}, batch...))
+	_, err := r.process.Call(ctx)
+	for _, fn := range r.afterProcessing {
+		fn()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.procErr != nil {
+		newBatch := make(service.MessageBatch, len(batch))
+		for i, m := range batch {
+			newBatch[i] = m.Copy()
+			newBatch[i].SetError(r.procErr)
+		}
+		return newBatch, nil
+	}
+	return r.resultBatch(), nil
+}
+
 func (r *moduleRunner) Close(ctx context.Context) error {
 	_ = r.mod.Close(ctx)
 	return r.runtime.Close(ctx)
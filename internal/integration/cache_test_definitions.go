@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
 )
 
 // CacheTestOpenClose checks that the cache can be started, an item added, and
@@ -122,3 +123,103 @@ func CacheTestGetAndSet(n int) CacheTestDefinition {
 		},
 	)
 }
+
+// CacheTestIncr checks that keys can be atomically incremented.
+func CacheTestIncr() CacheTestDefinition {
+	return namedCacheTest(
+		"can increment keys",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			v, err := cache.Incr(env.ctx, c, "incrkey", 5, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), v)
+
+			v, err = cache.Incr(env.ctx, c, "incrkey", -2, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), v)
+
+			res, err := c.Get(env.ctx, "incrkey")
+			require.NoError(t, err)
+			assert.Equal(t, "3", string(res))
+		},
+	)
+}
+
+// CacheTestCompareAndSwap checks that keys can be atomically swapped.
+func CacheTestCompareAndSwap() CacheTestDefinition {
+	return namedCacheTest(
+		"can compare and swap keys",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			err := cache.CompareAndSwap(env.ctx, c, "caskey", []byte("first"), []byte("second"), nil)
+			require.ErrorIs(t, err, component.ErrKeyNotFound)
+
+			require.NoError(t, cache.CompareAndSwap(env.ctx, c, "caskey", nil, []byte("first"), nil))
+
+			err = cache.CompareAndSwap(env.ctx, c, "caskey", nil, []byte("second"), nil)
+			require.ErrorIs(t, err, component.ErrKeyAlreadyExists)
+
+			err = cache.CompareAndSwap(env.ctx, c, "caskey", []byte("nope"), []byte("second"), nil)
+			require.ErrorIs(t, err, component.ErrValueMismatch)
+
+			require.NoError(t, cache.CompareAndSwap(env.ctx, c, "caskey", []byte("first"), []byte("second"), nil))
+
+			res, err := c.Get(env.ctx, "caskey")
+			require.NoError(t, err)
+			assert.Equal(t, "second", string(res))
+		},
+	)
+}
+
+// CacheTestGetMulti checks that multiple keys can be fetched at once, where
+// missing keys are omitted from the result.
+func CacheTestGetMulti() CacheTestDefinition {
+	return namedCacheTest(
+		"can get multiple keys",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			require.NoError(t, c.Set(env.ctx, "multikey1", []byte("first"), nil))
+			require.NoError(t, c.Set(env.ctx, "multikey2", []byte("second"), nil))
+
+			res, err := c.GetMulti(env.ctx, []string{"multikey1", "multikey2", "multikey3"})
+			require.NoError(t, err)
+			assert.Equal(t, map[string][]byte{
+				"multikey1": []byte("first"),
+				"multikey2": []byte("second"),
+			}, res)
+		},
+	)
+}
+
+// CacheTestKeys checks that the keys of a cache can be listed by a prefix.
+func CacheTestKeys() CacheTestDefinition {
+	return namedCacheTest(
+		"can list keys by prefix",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			require.NoError(t, c.Set(env.ctx, "listfoo1", []byte("first"), nil))
+			require.NoError(t, c.Set(env.ctx, "listfoo2", []byte("second"), nil))
+			require.NoError(t, c.Set(env.ctx, "listbar1", []byte("third"), nil))
+
+			keys, err := cache.Keys(env.ctx, c, "listfoo")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"listfoo1", "listfoo2"}, keys)
+		},
+	)
+}
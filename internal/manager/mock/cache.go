@@ -1,7 +1,11 @@
 package mock
 
 import (
+	"bytes"
 	"context"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
@@ -28,6 +32,31 @@ func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
 	return []byte(i.Value), nil
 }
 
+// GetMulti returns the values of multiple mock cache items.
+func (c *Cache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		i, ok := c.Values[k]
+		if !ok {
+			continue
+		}
+		values[k] = []byte(i.Value)
+	}
+	return values, nil
+}
+
+// Keys returns the keys of mock cache items that begin with a prefix.
+func (c *Cache) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for k := range c.Values {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
 // Set a mock cache item.
 func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
 	c.Values[key] = CacheItem{
@@ -66,6 +95,42 @@ func (c *Cache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Incr increments the integer value of a mock cache item.
+func (c *Cache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	var current int64
+	if i, ok := c.Values[key]; ok {
+		var err error
+		if current, err = strconv.ParseInt(i.Value, 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	current += delta
+	c.Values[key] = CacheItem{
+		Value: strconv.FormatInt(current, 10),
+		TTL:   ttl,
+	}
+	return current, nil
+}
+
+// CompareAndSwap sets a mock cache item if its value matches oldValue.
+func (c *Cache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	i, ok := c.Values[key]
+	if oldValue == nil {
+		if ok {
+			return component.ErrKeyAlreadyExists
+		}
+	} else if !ok {
+		return component.ErrKeyNotFound
+	} else if !bytes.Equal([]byte(i.Value), oldValue) {
+		return component.ErrValueMismatch
+	}
+	c.Values[key] = CacheItem{
+		Value: string(newValue),
+		TTL:   ttl,
+	}
+	return nil
+}
+
 // Close does nothing.
 func (c *Cache) Close(ctx context.Context) error {
 	return nil
@@ -27,7 +27,7 @@ import (
 type Manager struct {
 	Inputs     map[string]*Input
 	Caches     map[string]map[string]CacheItem
-	RateLimits map[string]RateLimit
+	RateLimits map[string]ratelimit.V1
 	Outputs    map[string]OutputWriter
 	Processors map[string]Processor
 	Pipes      map[string]<-chan message.Transaction
@@ -48,7 +48,7 @@ func NewManager() *Manager {
 	return &Manager{
 		Inputs:     map[string]*Input{},
 		Caches:     map[string]map[string]CacheItem{},
-		RateLimits: map[string]RateLimit{},
+		RateLimits: map[string]ratelimit.V1{},
 		Outputs:    map[string]OutputWriter{},
 		Processors: map[string]Processor{},
 		Pipes:      map[string]<-chan message.Transaction{},
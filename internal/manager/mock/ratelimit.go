@@ -17,3 +17,22 @@ func (r RateLimit) Access(ctx context.Context) (time.Duration, error) {
 func (r RateLimit) Close(ctx context.Context) error {
 	return nil
 }
+
+// KeyedRateLimit provides a mock keyed rate limit implementation around a
+// closure, where unkeyed access is performed with an empty key.
+type KeyedRateLimit func(ctx context.Context, key string) (time.Duration, error)
+
+// Access the rate limit with an empty key.
+func (r KeyedRateLimit) Access(ctx context.Context) (time.Duration, error) {
+	return r(ctx, "")
+}
+
+// AccessKey accesses the rate limit with a key.
+func (r KeyedRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return r(ctx, key)
+}
+
+// Close does nothing.
+func (r KeyedRateLimit) Close(ctx context.Context) error {
+	return nil
+}
@@ -36,12 +36,33 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 	if !enableCrud {
 		return
 	}
+
+	// Endpoints are registered as path prefixes and so the more specific
+	// paths of a stream must be registered before the stream itself.
 	m.manager.RegisterEndpoint(
-		"/streams",
-		"GET: List all streams along with their status and uptimes."+
-			" POST: Post an object of stream ids to stream configs, all"+
-			" streams will be replaced by this new set.",
-		m.HandleStreamsCRUD,
+		"/streams/{id}/stats",
+		"GET a structured JSON object containing metrics for the stream.",
+		m.HandleStreamStats,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/versions",
+		"GET a JSON array of all versions of the config of the stream, ordered from oldest to newest.",
+		m.HandleStreamVersions,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/pause",
+		"POST: Stop the stream from consuming messages from its input without shutting it down.",
+		m.HandleStreamPause,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/resume",
+		"POST: Resume consuming messages from the input of a paused stream.",
+		m.HandleStreamResume,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/drain",
+		"POST: Stop the stream from consuming messages from its input and wait for all in-flight messages to be flushed before stopping it.",
+		m.HandleStreamDrain,
 	)
 	m.manager.RegisterEndpoint(
 		"/streams/{id}",
@@ -51,9 +72,11 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 		m.HandleStreamCRUD,
 	)
 	m.manager.RegisterEndpoint(
-		"/streams/{id}/stats",
-		"GET a structured JSON object containing metrics for the stream.",
-		m.HandleStreamStats,
+		"/streams",
+		"GET: List all streams along with their status and uptimes."+
+			" POST: Post an object of stream ids to stream configs, all"+
+			" streams will be replaced by this new set.",
+		m.HandleStreamsCRUD,
 	)
 	m.manager.RegisterEndpoint(
 		"/resources/{type}/{id}",
@@ -116,9 +139,11 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	type confInfo struct {
-		Active    bool    `json:"active"`
-		Uptime    float64 `json:"uptime"`
-		UptimeStr string  `json:"uptime_str"`
+		Active    bool        `json:"active"`
+		State     StreamState `json:"state"`
+		Version   int         `json:"version"`
+		Uptime    float64     `json:"uptime"`
+		UptimeStr string      `json:"uptime_str"`
 	}
 	infos := map[string]confInfo{}
 
@@ -126,6 +151,8 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	for id, strInfo := range m.streams {
 		infos[id] = confInfo{
 			Active:    strInfo.IsRunning(),
+			State:     strInfo.State(),
+			Version:   strInfo.Version(),
 			Uptime:    strInfo.Uptime().Seconds(),
 			UptimeStr: strInfo.Uptime().String(),
 		}
@@ -208,7 +235,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 
 	for i, id := range toDelete {
 		go func(sid string, j int) {
-			errDelete[j] = m.Delete(r.Context(), sid)
+			errDelete[j] = m.deletePersisted(r.Context(), sid)
 			wg.Done()
 		}(id, i)
 	}
@@ -216,7 +243,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	for id, conf := range toUpdate {
 		newConf := conf
 		go func(sid string, sconf *stream.Config, j int) {
-			errUpdate[j] = m.Update(r.Context(), sid, *sconf)
+			errUpdate[j] = m.updatePersisted(r.Context(), sid, *sconf)
 			wg.Done()
 		}(id, &newConf, i)
 		i++
@@ -225,7 +252,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	for id, conf := range toCreate {
 		newConf := conf
 		go func(sid string, sconf *stream.Config, j int) {
-			errCreate[j] = m.Create(sid, *sconf)
+			errCreate[j] = m.createPersisted(r.Context(), sid, *sconf)
 			wg.Done()
 		}(id, &newConf, i)
 		i++
@@ -363,7 +390,7 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write(errBytes)
 			return
 		}
-		serverErr = m.Create(id, conf)
+		serverErr = m.createPersisted(r.Context(), id, conf)
 	case "GET":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
@@ -371,12 +398,16 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 
 			var bodyBytes []byte
 			if bodyBytes, serverErr = json.Marshal(struct {
-				Active    bool    `json:"active"`
-				Uptime    float64 `json:"uptime"`
-				UptimeStr string  `json:"uptime_str"`
-				Config    any     `json:"config"`
+				Active    bool        `json:"active"`
+				State     StreamState `json:"state"`
+				Version   int         `json:"version"`
+				Uptime    float64     `json:"uptime"`
+				UptimeStr string      `json:"uptime_str"`
+				Config    any         `json:"config"`
 			}{
 				Active:    info.IsRunning(),
+				State:     info.State(),
+				Version:   info.Version(),
 				Uptime:    info.Uptime().Seconds(),
 				UptimeStr: info.Uptime().String(),
 				Config:    sanit,
@@ -400,16 +431,16 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write(errBytes)
 			return
 		}
-		serverErr = m.Update(r.Context(), id, conf)
+		serverErr = m.updatePersisted(r.Context(), id, conf)
 	case "DELETE":
-		serverErr = m.Delete(r.Context(), id)
+		serverErr = m.deletePersisted(r.Context(), id)
 	case "PATCH":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
 			if conf, requestErr = patchConfig(info.Config()); requestErr != nil {
 				return
 			}
-			serverErr = m.Update(r.Context(), id, conf)
+			serverErr = m.updatePersisted(r.Context(), id, conf)
 		}
 	default:
 		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
@@ -621,14 +652,123 @@ func (m *Type) HandleStreamStats(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleStreamVersions is an http.HandleFunc for obtaining the config versions
+// of a stream.
+func (m *Type) HandleStreamVersions(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Errorf("Stream versions Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debugf("Stream request versions Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "GET" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	var info *StreamStatus
+	if info, serverErr = m.Read(id); serverErr == ErrStreamDoesNotExist {
+		serverErr = nil
+		http.Error(w, "Stream not found", http.StatusNotFound)
+		return
+	} else if serverErr != nil {
+		return
+	}
+
+	var jBytes []byte
+	if jBytes, serverErr = json.Marshal(info.Versions()); serverErr != nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(jBytes)
+}
+
+// HandleStreamPause is an http.HandleFunc for pausing a stream.
+func (m *Type) HandleStreamPause(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamLifecycle(w, r, func(id string) error {
+		return m.Pause(id)
+	})
+}
+
+// HandleStreamResume is an http.HandleFunc for resuming a paused stream.
+func (m *Type) HandleStreamResume(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamLifecycle(w, r, func(id string) error {
+		return m.Resume(id)
+	})
+}
+
+// HandleStreamDrain is an http.HandleFunc for draining a stream.
+func (m *Type) HandleStreamDrain(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamLifecycle(w, r, func(id string) error {
+		return m.Drain(id)
+	})
+}
+
+func (m *Type) handleStreamLifecycle(w http.ResponseWriter, r *http.Request, fn func(id string) error) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Errorf("Stream lifecycle Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debugf("Stream request lifecycle Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	serverErr = fn(id)
+	switch serverErr {
+	case ErrStreamDoesNotExist:
+		serverErr = nil
+		http.Error(w, "Stream not found", http.StatusNotFound)
+	case ErrStreamNotRunning, ErrStreamNotPaused:
+		requestErr, serverErr = serverErr, nil
+	}
+}
+
 // HandleStreamReady is an http.HandleFunc for providing a ready check across
-// all streams.
+// all streams. Streams that are paused, draining or stopped are not expected
+// to be connected and are therefore ignored.
 func (m *Type) HandleStreamReady(w http.ResponseWriter, r *http.Request) {
 	var notReady []string
 
 	m.lock.Lock()
 	for k, v := range m.streams {
-		if !v.IsReady() && v.IsRunning() {
+		if v.State() == StreamStateRunning && !v.IsReady() {
 			notReady = append(notReady, k)
 		}
 	}
@@ -2,6 +2,7 @@ package manager_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,6 +21,9 @@ import (
 	"github.com/stretchr/testify/require"
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/api"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/log"
 	bmanager "github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
 	"github.com/benthosdev/benthos/v4/internal/message"
@@ -36,6 +40,10 @@ func router(m *manager.Type) *mux.Router {
 	router.HandleFunc("/streams", m.HandleStreamsCRUD)
 	router.HandleFunc("/streams/{id}", m.HandleStreamCRUD)
 	router.HandleFunc("/streams/{id}/stats", m.HandleStreamStats)
+	router.HandleFunc("/streams/{id}/versions", m.HandleStreamVersions)
+	router.HandleFunc("/streams/{id}/pause", m.HandleStreamPause)
+	router.HandleFunc("/streams/{id}/resume", m.HandleStreamResume)
+	router.HandleFunc("/streams/{id}/drain", m.HandleStreamDrain)
 	router.HandleFunc("/resources/{type}/{id}", m.HandleResourceCRUD)
 	return router
 }
@@ -88,6 +96,8 @@ func genYAMLRequest(verb, url string, payload any) *http.Request {
 
 type listItemBody struct {
 	Active    bool    `json:"active"`
+	State     string  `json:"state"`
+	Version   int     `json:"version"`
 	Uptime    float64 `json:"uptime"`
 	UptimeStr string  `json:"uptime_str"`
 }
@@ -104,6 +114,8 @@ func parseListBody(data *bytes.Buffer) listBody {
 
 type getBody struct {
 	Active    bool    `json:"active"`
+	State     string  `json:"state"`
+	Version   int     `json:"version"`
 	Uptime    float64 `json:"uptime"`
 	UptimeStr string  `json:"uptime_str"`
 	Config    any     `json:"config"`
@@ -147,6 +159,51 @@ func TestTypeAPIDisabled(t *testing.T) {
 	assert.Contains(t, r.endpoints, "/ready")
 }
 
+func TestTypeAPIRouting(t *testing.T) {
+	apiConf := api.NewConfig()
+	apiServer, err := api.New("", "", apiConf, nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	rMgr, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetAPIReg(apiServer))
+	require.NoError(t, err)
+
+	mgr := manager.New(rMgr)
+
+	conf := stream.NewConfig()
+	conf.Input.Type = "generate"
+	conf.Input.Generate.Mapping = "root = deleted()"
+	conf.Output.Type = "drop"
+	require.NoError(t, mgr.Create("foo", conf))
+
+	for _, test := range []struct {
+		verb, path string
+		check      func(t *testing.T, body []byte)
+	}{
+		{verb: "GET", path: "/streams", check: func(t *testing.T, body []byte) {
+			assert.Contains(t, string(body), `"foo"`)
+		}},
+		{verb: "GET", path: "/streams/foo", check: func(t *testing.T, body []byte) {
+			assert.Contains(t, string(body), `"config"`)
+		}},
+		{verb: "GET", path: "/streams/foo/stats", check: func(t *testing.T, body []byte) {
+			assert.Contains(t, string(body), `"uptime_ns"`)
+		}},
+		{verb: "GET", path: "/streams/foo/versions", check: func(t *testing.T, body []byte) {
+			assert.Contains(t, string(body), `"created_at"`)
+		}},
+		{verb: "POST", path: "/streams/foo/pause"},
+		{verb: "POST", path: "/streams/foo/resume"},
+	} {
+		request := genRequest(test.verb, test.path, nil)
+		response := httptest.NewRecorder()
+		apiServer.Handler().ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, test.path)
+		if test.check != nil {
+			test.check(t, response.Body.Bytes())
+		}
+	}
+}
+
 func TestTypeAPIBadMethods(t *testing.T) {
 	mgr := manager.New(mock.NewManager())
 
@@ -228,6 +285,7 @@ func TestTypeAPIBasicOperations(t *testing.T) {
 
 	info := parseGetBody(t, response.Body)
 	assert.True(t, info.Active)
+	assert.Equal(t, 1, info.Version)
 
 	assert.Equal(t, "root = deleted()", gabs.Wrap(info.Config).S("input", "generate", "mapping").Data())
 
@@ -246,9 +304,27 @@ func TestTypeAPIBasicOperations(t *testing.T) {
 
 	info = parseGetBody(t, response.Body)
 	assert.True(t, info.Active)
+	assert.Equal(t, 2, info.Version)
 
 	assert.Equal(t, map[string]any{}, gabs.Wrap(info.Config).S("buffer", "memory").Data())
 
+	request = genRequest("GET", "/streams/foo/versions", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	versions, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+	require.Len(t, versions.Children(), 2)
+	assert.Equal(t, float64(1), versions.Index(0).S("version").Data())
+	assert.Equal(t, float64(2), versions.Index(1).S("version").Data())
+	assert.Equal(t, map[string]any{}, versions.Index(1).S("config", "buffer", "memory").Data())
+
+	request = genRequest("GET", "/streams/bar/versions", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+
 	request = genRequest("DELETE", "/streams/foo", conf)
 	response = httptest.NewRecorder()
 	r.ServeHTTP(response, request)
@@ -979,4 +1055,127 @@ output:
 		r.ServeHTTP(response, request)
 		return response.Code == http.StatusServiceUnavailable
 	}, time.Second*10, time.Millisecond*50)
+
+	request = genRequest("POST", "/streams/bar/pause", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// Paused streams are not expected to be connected.
+	request = genRequest("GET", "/ready", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+}
+
+func TestTypeAPIPauseResumeDrain(t *testing.T) {
+	bmgr, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	inChan := make(chan message.Transaction)
+	bmgr.SetPipe("feed_in", inChan)
+
+	mgr := manager.New(bmgr)
+	r := router(mgr)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := stream.NewConfig()
+	conf.Input.Type = "inproc"
+	conf.Input.Inproc = "feed_in"
+	conf.Output.Type = "inproc"
+	conf.Output.Inproc = "feed_out"
+	require.NoError(t, mgr.Create("foo", conf))
+
+	outChan, err := bmgr.GetPipe("feed_out")
+	require.NoError(t, err)
+
+	sendMsg := func(content string) chan error {
+		t.Helper()
+		resChan := make(chan error, 1)
+		select {
+		case inChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(content)}), resChan):
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+		return resChan
+	}
+	readMsg := func() string {
+		t.Helper()
+		select {
+		case tran := <-outChan:
+			require.NoError(t, tran.Ack(ctx, nil))
+			return string(tran.Payload.Get(0).AsBytes())
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+		return ""
+	}
+	getState := func() string {
+		t.Helper()
+		request := genRequest("GET", "/streams/foo", nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		return parseGetBody(t, response.Body).State
+	}
+	post := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		request := genRequest("POST", path, nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+
+	assert.Equal(t, "running", getState())
+
+	assert.Equal(t, http.StatusNotFound, post("/streams/bar/pause").Code)
+	assert.Equal(t, http.StatusBadRequest, post("/streams/foo/resume").Code)
+
+	response := post("/streams/foo/pause")
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "paused", getState())
+
+	sendMsg("first")
+	select {
+	case <-outChan:
+		t.Fatal("Received message from paused stream")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	response = post("/streams/foo/resume")
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "running", getState())
+	assert.Equal(t, "first", readMsg())
+
+	// Drain blocks until in-flight messages are delivered.
+	resChan := sendMsg("second")
+	drainDone := make(chan *httptest.ResponseRecorder)
+	go func() {
+		drainDone <- post("/streams/foo/drain")
+	}()
+
+	assert.Eventually(t, func() bool {
+		return getState() == "draining"
+	}, time.Second*10, time.Millisecond*10)
+
+	assert.Equal(t, "second", readMsg())
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+
+	select {
+	case response = <-drainDone:
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "stopped", getState())
+
+	assert.Equal(t, http.StatusBadRequest, post("/streams/foo/pause").Code)
+	assert.Equal(t, http.StatusBadRequest, post("/streams/foo/drain").Code)
 }
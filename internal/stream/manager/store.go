@@ -0,0 +1,184 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+// StreamVersion is a version of the config of a stream.
+type StreamVersion struct {
+	Version   int       `json:"version" yaml:"version"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	Config    any       `json:"config" yaml:"config"`
+}
+
+func newStreamVersion(version int, conf stream.Config) (StreamVersion, error) {
+	sanit, err := conf.Sanitised()
+	if err != nil {
+		return StreamVersion{}, err
+	}
+	return StreamVersion{
+		Version:   version,
+		CreatedAt: time.Now().UTC(),
+		Config:    sanit,
+	}, nil
+}
+
+// StreamConfig parses the config of the version into a stream config.
+func (v StreamVersion) StreamConfig() (stream.Config, error) {
+	conf := stream.NewConfig()
+
+	confBytes, err := yaml.Marshal(v.Config)
+	if err != nil {
+		return conf, err
+	}
+	err = yaml.Unmarshal(confBytes, &conf)
+	return conf, err
+}
+
+// sameConfig returns true if the config of two versions is equivalent.
+func (v StreamVersion) sameConfig(other StreamVersion) bool {
+	aBytes, err := yaml.Marshal(v.Config)
+	if err != nil {
+		return false
+	}
+	bBytes, err := yaml.Marshal(other.Config)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
+
+//------------------------------------------------------------------------------
+
+// Store is a persistence backend for the streams of a manager, allowing them to
+// survive restarts.
+type Store interface {
+	// Put persists a new version of the config of a stream.
+	Put(ctx context.Context, id string, version StreamVersion) error
+
+	// Delete removes a stream along with all of its versions.
+	Delete(ctx context.Context, id string) error
+
+	// Load returns all versions of all persisted streams, where the versions
+	// of each stream are ordered from oldest to newest.
+	Load(ctx context.Context) (map[string][]StreamVersion, error)
+}
+
+//------------------------------------------------------------------------------
+
+// DirStore is a Store that writes each version of a stream config as a YAML
+// file within a directory dedicated to the stream.
+type DirStore struct {
+	dir string
+	log log.Modular
+}
+
+// NewDirStore creates a Store that persists streams within a directory, which
+// is created if it does not already exist.
+func NewDirStore(dir string, log log.Modular) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return &DirStore{dir: dir, log: log}, nil
+}
+
+func (d *DirStore) streamDir(id string) string {
+	return filepath.Join(d.dir, url.PathEscape(id))
+}
+
+// Put writes a version of a stream config to the directory of the stream.
+func (d *DirStore) Put(ctx context.Context, id string, version StreamVersion) error {
+	sDir := d.streamDir(id)
+	if err := os.MkdirAll(sDir, 0o755); err != nil {
+		return err
+	}
+
+	vBytes, err := yaml.Marshal(version)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash never leaves a
+	// partially written version behind.
+	target := filepath.Join(sDir, strconv.Itoa(version.Version)+".yaml")
+	tmpTarget := target + ".tmp"
+	if err := os.WriteFile(tmpTarget, vBytes, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpTarget, target)
+}
+
+// Delete removes the directory of a stream.
+func (d *DirStore) Delete(ctx context.Context, id string) error {
+	return os.RemoveAll(d.streamDir(id))
+}
+
+// Load reads all stream versions from the directory. Stream directories and
+// version files that cannot be read or parsed are logged and skipped.
+func (d *DirStore) Load(ctx context.Context) (map[string][]StreamVersion, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := map[string][]StreamVersion{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		id, err := url.PathUnescape(e.Name())
+		if err != nil {
+			d.log.Errorf("Skipping invalid stream directory '%v': %v\n", e.Name(), err)
+			continue
+		}
+
+		sDir := filepath.Join(d.dir, e.Name())
+		vEntries, err := os.ReadDir(sDir)
+		if err != nil {
+			d.log.Errorf("Skipping unreadable stream directory '%v': %v\n", sDir, err)
+			continue
+		}
+
+		var versions []StreamVersion
+		for _, ve := range vEntries {
+			if ve.IsDir() || !strings.HasSuffix(ve.Name(), ".yaml") {
+				continue
+			}
+			vPath := filepath.Join(sDir, ve.Name())
+			vBytes, err := os.ReadFile(vPath)
+			if err != nil {
+				d.log.Errorf("Skipping unreadable stream version '%v': %v\n", vPath, err)
+				continue
+			}
+			var v StreamVersion
+			if err := yaml.Unmarshal(vBytes, &v); err != nil {
+				d.log.Errorf("Skipping stream version '%v' as it failed to parse: %v\n", vPath, err)
+				continue
+			}
+			versions = append(versions, v)
+		}
+		if len(versions) == 0 {
+			continue
+		}
+
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i].Version < versions[j].Version
+		})
+		streams[id] = versions
+	}
+	return streams, nil
+}
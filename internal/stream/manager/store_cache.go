@@ -0,0 +1,173 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+)
+
+// CacheStore is a Store that writes streams to a cache resource. The versions
+// of each stream are stored as a JSON array under a key made of the prefix and
+// the stream ID, and the IDs of all streams are stored as a JSON array under
+// the key made of the prefix followed by `index`.
+type CacheStore struct {
+	mgr       bundle.NewManagement
+	cacheName string
+	prefix    string
+
+	// The index of stream IDs is updated with a read-modify-write and
+	// therefore writes are serialised within this process.
+	mut sync.Mutex
+}
+
+// NewCacheStore creates a Store that persists streams within a cache resource.
+func NewCacheStore(mgr bundle.NewManagement, cacheName, prefix string) (*CacheStore, error) {
+	if !mgr.ProbeCache(cacheName) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", cacheName)
+	}
+	return &CacheStore{
+		mgr:       mgr,
+		cacheName: cacheName,
+		prefix:    prefix,
+	}, nil
+}
+
+func (c *CacheStore) indexKey() string {
+	return c.prefix + "index"
+}
+
+func (c *CacheStore) streamKey(id string) string {
+	return c.prefix + "stream_" + id
+}
+
+// getJSON reads a JSON value from a key of the cache, returning false if the key
+// does not exist.
+func (c *CacheStore) getJSON(ctx context.Context, key string, v any) (bool, error) {
+	var vBytes []byte
+	var err error
+	if cerr := c.mgr.AccessCache(ctx, c.cacheName, func(ca cache.V1) {
+		vBytes, err = ca.Get(ctx, key)
+	}); cerr != nil {
+		return false, cerr
+	}
+	if err != nil {
+		if errors.Is(err, component.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal(vBytes, v); err != nil {
+		return false, fmt.Errorf("failed to parse key '%v': %w", key, err)
+	}
+	return true, nil
+}
+
+func (c *CacheStore) setJSON(ctx context.Context, key string, v any) error {
+	vBytes, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if cerr := c.mgr.AccessCache(ctx, c.cacheName, func(ca cache.V1) {
+		err = ca.Set(ctx, key, vBytes, nil)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+func (c *CacheStore) readIndex(ctx context.Context) ([]string, error) {
+	var ids []string
+	if _, err := c.getJSON(ctx, c.indexKey(), &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Put appends a version of a stream config to the versions stored in the
+// cache.
+func (c *CacheStore) Put(ctx context.Context, id string, version StreamVersion) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var versions []StreamVersion
+	if _, err := c.getJSON(ctx, c.streamKey(id), &versions); err != nil {
+		return err
+	}
+	versions = append(versions, version)
+	if err := c.setJSON(ctx, c.streamKey(id), versions); err != nil {
+		return err
+	}
+
+	ids, err := c.readIndex(ctx)
+	if err != nil {
+		return err
+	}
+	for _, existing := range ids {
+		if existing == id {
+			return nil
+		}
+	}
+	return c.setJSON(ctx, c.indexKey(), append(ids, id))
+}
+
+// Delete removes a stream from the cache.
+func (c *CacheStore) Delete(ctx context.Context, id string) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	ids, err := c.readIndex(ctx)
+	if err != nil {
+		return err
+	}
+	newIDs := make([]string, 0, len(ids))
+	for _, existing := range ids {
+		if existing != id {
+			newIDs = append(newIDs, existing)
+		}
+	}
+	if err := c.setJSON(ctx, c.indexKey(), newIDs); err != nil {
+		return err
+	}
+
+	if cerr := c.mgr.AccessCache(ctx, c.cacheName, func(ca cache.V1) {
+		err = ca.Delete(ctx, c.streamKey(id))
+	}); cerr != nil {
+		return cerr
+	}
+	if err != nil && !errors.Is(err, component.ErrKeyNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Load reads all streams listed within the index from the cache. Streams that
+// cannot be read or parsed are logged and skipped.
+func (c *CacheStore) Load(ctx context.Context) (map[string][]StreamVersion, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	ids, err := c.readIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := map[string][]StreamVersion{}
+	for _, id := range ids {
+		var versions []StreamVersion
+		exists, err := c.getJSON(ctx, c.streamKey(id), &versions)
+		if err != nil {
+			c.mgr.Logger().Errorf("Skipping stream '%v' as it failed to load: %v\n", id, err)
+			continue
+		}
+		if exists && len(versions) > 0 {
+			streams[id] = versions
+		}
+	}
+	return streams, nil
+}
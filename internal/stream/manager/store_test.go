@@ -0,0 +1,263 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/log"
+	bmanager "github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+)
+
+func testStoreRoundTrip(t *testing.T, store Store) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	fooConf := harmlessConf()
+	barConf := harmlessConf()
+	barConf.Buffer.Type = "memory"
+
+	fooV1, err := newStreamVersion(1, fooConf)
+	require.NoError(t, err)
+	fooV2, err := newStreamVersion(2, barConf)
+	require.NoError(t, err)
+	barV1, err := newStreamVersion(1, barConf)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "foo", fooV1))
+	require.NoError(t, store.Put(ctx, "foo", fooV2))
+	require.NoError(t, store.Put(ctx, "bar/baz", barV1))
+
+	streams, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Len(t, streams, 2)
+
+	require.Len(t, streams["foo"], 2)
+	assert.Equal(t, 1, streams["foo"][0].Version)
+	assert.Equal(t, 2, streams["foo"][1].Version)
+	assert.True(t, streams["foo"][0].sameConfig(fooV1))
+	assert.True(t, streams["foo"][1].sameConfig(fooV2))
+
+	require.Len(t, streams["bar/baz"], 1)
+	assert.True(t, streams["bar/baz"][0].sameConfig(barV1))
+
+	conf, err := streams["bar/baz"][0].StreamConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "memory", conf.Buffer.Type)
+	assert.Equal(t, "generate", conf.Input.Type)
+
+	require.NoError(t, store.Delete(ctx, "foo"))
+	require.NoError(t, store.Delete(ctx, "does not exist"))
+
+	streams, err = store.Load(ctx)
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	assert.Contains(t, streams, "bar/baz")
+}
+
+func TestDirStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "streams")
+
+	store, err := NewDirStore(dir, log.Noop())
+	require.NoError(t, err)
+
+	testStoreRoundTrip(t, store)
+
+	_, err = os.Stat(filepath.Join(dir, "bar%2Fbaz", "1.yaml"))
+	require.NoError(t, err)
+}
+
+func TestDirStoreSkipsBadEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewDirStore(dir, log.Noop())
+	require.NoError(t, err)
+
+	fooV1, err := newStreamVersion(1, harmlessConf())
+	require.NoError(t, err)
+	require.NoError(t, store.Put(context.Background(), "foo", fooV1))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bar"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bar", "1.yaml"), []byte("version: [nope"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo", "2.yaml"), []byte("version: [nope"), 0o644))
+
+	streams, err := store.Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	require.Len(t, streams["foo"], 1)
+	assert.Equal(t, 1, streams["foo"][0].Version)
+}
+
+func TestCacheStore(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{}
+
+	_, err := NewCacheStore(mgr, "barcache", "benthos_streams_")
+	require.Error(t, err)
+
+	store, err := NewCacheStore(mgr, "foocache", "benthos_streams_")
+	require.NoError(t, err)
+
+	testStoreRoundTrip(t, store)
+
+	assert.Contains(t, mgr.Caches["foocache"], "benthos_streams_index")
+	assert.Contains(t, mgr.Caches["foocache"], "benthos_streams_stream_bar/baz")
+	assert.NotContains(t, mgr.Caches["foocache"], "benthos_streams_stream_foo")
+
+	// Streams that fail to parse are skipped.
+	mgr.Caches["foocache"]["benthos_streams_index"] = mock.CacheItem{Value: `["bar/baz","buz"]`}
+	mgr.Caches["foocache"]["benthos_streams_stream_buz"] = mock.CacheItem{Value: `[{"version":`}
+
+	streams, err := store.Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	assert.Contains(t, streams, "bar/baz")
+}
+
+func TestTypeStorePersistence(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	store, err := NewDirStore(t.TempDir(), log.Noop())
+	require.NoError(t, err)
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptStore(store))
+
+	updatedConf := harmlessConf()
+	updatedConf.Buffer.Type = "memory"
+
+	require.NoError(t, mgr.createPersisted(ctx, "foo", harmlessConf()))
+	require.NoError(t, mgr.updatePersisted(ctx, "foo", updatedConf))
+	require.NoError(t, mgr.updatePersisted(ctx, "foo", updatedConf))
+	require.NoError(t, mgr.createPersisted(ctx, "bar", harmlessConf()))
+	require.NoError(t, mgr.createPersisted(ctx, "baz", harmlessConf()))
+	require.NoError(t, mgr.deletePersisted(ctx, "baz"))
+
+	// Streams created and updated outside of the REST API are not persisted.
+	require.NoError(t, mgr.Create("buz", harmlessConf()))
+	require.NoError(t, mgr.Update(ctx, "bar", updatedConf))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.Version())
+	require.Len(t, info.Versions(), 2)
+
+	require.NoError(t, mgr.Stop(ctx))
+
+	// Stopping the manager must not remove any persisted streams.
+	res, err = bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr = New(res, OptAPIEnabled(false), OptStore(store))
+	require.NoError(t, mgr.Load(ctx))
+
+	_, err = mgr.Read("baz")
+	require.Equal(t, ErrStreamDoesNotExist, err)
+
+	_, err = mgr.Read("buz")
+	require.Equal(t, ErrStreamDoesNotExist, err)
+
+	info, err = mgr.Read("bar")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+	assert.Equal(t, 1, info.Version())
+	assert.Equal(t, "none", info.Config().Buffer.Type)
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+	assert.Equal(t, 2, info.Version())
+	assert.Equal(t, "memory", info.Config().Buffer.Type)
+	require.Len(t, info.Versions(), 2)
+	assert.Equal(t, 1, info.Versions()[0].Version)
+
+	// An update with the config a stream was restored from is a no-op.
+	require.NoError(t, mgr.Update(ctx, "foo", updatedConf))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.Version())
+
+	require.NoError(t, mgr.updatePersisted(ctx, "foo", harmlessConf()))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 3, info.Version())
+
+	require.NoError(t, mgr.Stop(ctx))
+
+	streams, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Len(t, streams, 2)
+	require.Len(t, streams["foo"], 3)
+}
+
+type failingStore struct {
+	Store
+	err error
+}
+
+func (f *failingStore) Put(ctx context.Context, id string, version StreamVersion) error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.Store.Put(ctx, id, version)
+}
+
+func TestTypeStorePersistFailure(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	dirStore, err := NewDirStore(t.TempDir(), log.Noop())
+	require.NoError(t, err)
+	store := &failingStore{Store: dirStore}
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptStore(store))
+	t.Cleanup(func() {
+		_ = mgr.Stop(context.Background())
+	})
+
+	store.err = errors.New("nope")
+
+	// A stream that fails to persist is torn down and may be created again.
+	require.Error(t, mgr.createPersisted(ctx, "foo", harmlessConf()))
+	_, err = mgr.Read("foo")
+	require.Equal(t, ErrStreamDoesNotExist, err)
+
+	store.err = nil
+	require.NoError(t, mgr.createPersisted(ctx, "foo", harmlessConf()))
+
+	// An update that fails to persist rolls back to the previous version.
+	updatedConf := harmlessConf()
+	updatedConf.Buffer.Type = "memory"
+
+	store.err = errors.New("nope")
+	require.Error(t, mgr.updatePersisted(ctx, "foo", updatedConf))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+	assert.Equal(t, 1, info.Version())
+	assert.Equal(t, "none", info.Config().Buffer.Type)
+
+	store.err = nil
+	require.NoError(t, mgr.updatePersisted(ctx, "foo", updatedConf))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.Version())
+}
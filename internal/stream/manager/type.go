@@ -16,10 +16,24 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
 
+// StreamState describes the current lifecycle state of a managed stream.
+type StreamState string
+
+// The possible lifecycle states of a managed stream.
+var (
+	StreamStateRunning  StreamState = "running"
+	StreamStatePaused   StreamState = "paused"
+	StreamStateDraining StreamState = "draining"
+	StreamStateStopped  StreamState = "stopped"
+)
+
 // StreamStatus tracks a stream along with information regarding its internals.
 type StreamStatus struct {
 	stoppedAfter int64
+	draining     bool
+	stateMut     sync.Mutex
 	config       stream.Config
+	versions     []StreamVersion
 	strm         *stream.Type
 	metrics      *metrics.Local
 	createdAt    time.Time
@@ -43,6 +57,24 @@ func (s *StreamStatus) IsRunning() bool {
 	return atomic.LoadInt64(&s.stoppedAfter) == 0
 }
 
+// State returns the current lifecycle state of the stream.
+func (s *StreamStatus) State() StreamState {
+	if !s.IsRunning() {
+		return StreamStateStopped
+	}
+
+	s.stateMut.Lock()
+	defer s.stateMut.Unlock()
+
+	if s.draining {
+		return StreamStateDraining
+	}
+	if s.strm.IsPaused() {
+		return StreamStatePaused
+	}
+	return StreamStateRunning
+}
+
 // IsReady returns a boolean indicating whether the stream is connected at both
 // the input and output level.
 func (s *StreamStatus) IsReady() bool {
@@ -62,6 +94,21 @@ func (s *StreamStatus) Config() stream.Config {
 	return s.config
 }
 
+// Version returns the version of the current configuration of the stream,
+// which is incremented each time the stream is updated.
+func (s *StreamStatus) Version() int {
+	if len(s.versions) == 0 {
+		return 0
+	}
+	return s.versions[len(s.versions)-1].Version
+}
+
+// Versions returns all versions of the configuration of the stream ordered from
+// oldest to newest.
+func (s *StreamStatus) Versions() []StreamVersion {
+	return s.versions
+}
+
 // Metrics returns a metrics aggregator of the stream.
 func (s *StreamStatus) Metrics() *metrics.Local {
 	return s.metrics
@@ -69,7 +116,7 @@ func (s *StreamStatus) Metrics() *metrics.Local {
 
 // setClosed sets the flag indicating that the stream is closed.
 func (s *StreamStatus) setClosed() {
-	atomic.SwapInt64(&s.stoppedAfter, int64(time.Since(s.createdAt)))
+	atomic.CompareAndSwapInt64(&s.stoppedAfter, 0, int64(time.Since(s.createdAt)))
 }
 
 //------------------------------------------------------------------------------
@@ -86,8 +133,10 @@ type Type struct {
 	closed  bool
 	streams map[string]*StreamStatus
 
-	manager    bundle.NewManagement
-	apiEnabled bool
+	manager      bundle.NewManagement
+	apiEnabled   bool
+	store        Store
+	drainTimeout time.Duration
 
 	lock sync.Mutex
 }
@@ -95,9 +144,10 @@ type Type struct {
 // New creates a new stream manager.Type.
 func New(mgr bundle.NewManagement, opts ...func(*Type)) *Type {
 	t := &Type{
-		streams:    map[string]*StreamStatus{},
-		apiEnabled: true,
-		manager:    mgr,
+		streams:      map[string]*StreamStatus{},
+		apiEnabled:   true,
+		manager:      mgr,
+		drainTimeout: time.Second * 30,
 	}
 	for _, opt := range opts {
 		opt(t)
@@ -116,28 +166,82 @@ func OptAPIEnabled(b bool) func(*Type) {
 	}
 }
 
+// OptStore sets a persistence backend that stream creations, updates and
+// deletions made via the REST API are written through to. Streams previously
+// written to the store can be restored with Load.
+func OptStore(store Store) func(*Type) {
+	return func(t *Type) {
+		t.store = store
+	}
+}
+
+// OptDrainTimeout sets the maximum period of time to wait for a stream to drain
+// before it is stopped ungracefully. The default is 30 seconds.
+func OptDrainTimeout(d time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.drainTimeout = d
+	}
+}
+
 //------------------------------------------------------------------------------
 
+// discardTimeout is the maximum period of time to wait for a stream that is
+// being stopped ungracefully to close.
+const discardTimeout = time.Second * 5
+
 // Errors specifically returned by a stream manager.
 var (
 	ErrStreamExists       = errors.New("stream already exists")
 	ErrStreamDoesNotExist = errors.New("stream does not exist")
+	ErrStreamNotRunning   = errors.New("stream is not running")
+	ErrStreamNotPaused    = errors.New("stream is not paused")
 )
 
 //------------------------------------------------------------------------------
 
 // Create attempts to construct and run a new stream under a unique ID. If the
 // ID already exists an error is returned.
+//
+// Streams created this way are not written to the store of the manager, only
+// streams created via the REST API are persisted.
 func (m *Type) Create(id string, conf stream.Config) error {
+	version, err := newStreamVersion(1, conf)
+	if err != nil {
+		return err
+	}
+	_, err = m.create(id, conf, []StreamVersion{version})
+	return err
+}
+
+// createPersisted creates a stream and writes it to the store of the manager.
+// If the stream fails to be persisted it is torn down and removed so that a
+// subsequent attempt may succeed.
+func (m *Type) createPersisted(ctx context.Context, id string, conf stream.Config) error {
+	version, err := newStreamVersion(1, conf)
+	if err != nil {
+		return err
+	}
+	wrapper, err := m.create(id, conf, []StreamVersion{version})
+	if err != nil {
+		return err
+	}
+	if err := m.persist(ctx, id, version); err != nil {
+		m.discard(id, wrapper)
+		return err
+	}
+	return nil
+}
+
+func (m *Type) create(id string, conf stream.Config, versions []StreamVersion) (*StreamStatus, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
 	if m.closed {
-		return component.ErrTypeClosed
+		return nil, component.ErrTypeClosed
 	}
 
 	if _, exists := m.streams[id]; exists {
-		return ErrStreamExists
+		return nil, ErrStreamExists
 	}
 
 	strmFlatMetrics := metrics.NewLocal()
@@ -149,15 +253,45 @@ func (m *Type) Create(id string, conf stream.Config) error {
 	// This seems a bit wonky but we can't rule out a race condition between
 	// the stream terminating and setClosed and actually initialising a status.
 	wrapper := newStreamStatus(conf, strmFlatMetrics)
+	wrapper.versions = versions
 	strm, err := stream.New(conf, sMgr, stream.OptOnClose(func() {
 		wrapper.setClosed()
 	}))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	wrapper.setStream(strm)
 	m.streams[id] = wrapper
+	return wrapper, nil
+}
+
+// discard stops a stream without waiting for in-flight messages and removes it
+// from the manager, this is used for rolling back streams that could not be
+// persisted.
+func (m *Type) discard(id string, wrapper *StreamStatus) {
+	ctx, done := context.WithTimeout(context.Background(), discardTimeout)
+	defer done()
+
+	if err := wrapper.strm.StopUnordered(ctx); err != nil {
+		m.manager.Logger().Errorf("Failed to stop stream '%v' cleanly: %v\n", id, err)
+	}
+
+	m.lock.Lock()
+	if m.streams[id] == wrapper {
+		delete(m.streams, id)
+	}
+	m.lock.Unlock()
+}
+
+// persist writes a new version of a stream to the store, if one is set.
+func (m *Type) persist(ctx context.Context, id string, version StreamVersion) error {
+	if m.store == nil {
+		return nil
+	}
+	if err := m.store.Put(ctx, id, version); err != nil {
+		return fmt.Errorf("failed to persist stream: %w", err)
+	}
 	return nil
 }
 
@@ -181,33 +315,100 @@ func (m *Type) Read(id string) (*StreamStatus, error) {
 
 // Update attempts to stop an existing stream and replace it with a new version
 // of the same stream.
+//
+// Streams updated this way are not written to the store of the manager, only
+// streams updated via the REST API are persisted.
 func (m *Type) Update(ctx context.Context, id string, conf stream.Config) error {
+	_, _, err := m.update(ctx, id, conf)
+	return err
+}
+
+// updatePersisted updates a stream and writes the new version to the store of
+// the manager. If the new version fails to be persisted the stream is rolled
+// back to its previous version.
+func (m *Type) updatePersisted(ctx context.Context, id string, conf stream.Config) error {
+	prev, wrapper, err := m.update(ctx, id, conf)
+	if err != nil || wrapper == nil {
+		return err
+	}
+	if err := m.persist(ctx, id, wrapper.versions[len(wrapper.versions)-1]); err != nil {
+		m.discard(id, wrapper)
+		if _, rerr := m.create(id, prev.config, prev.versions); rerr != nil {
+			m.manager.Logger().Errorf("Failed to restore previous version of stream '%v': %v\n", id, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// update replaces a stream with a new version, returning both the status of
+// the replaced stream and of its replacement. If the config is unchanged then
+// nil statuses are returned.
+func (m *Type) update(ctx context.Context, id string, conf stream.Config) (prev, wrapper *StreamStatus, err error) {
 	m.lock.Lock()
-	wrapper, exists := m.streams[id]
+	prev, exists := m.streams[id]
 	closed := m.closed
 	m.lock.Unlock()
 
 	if closed {
-		return component.ErrTypeClosed
+		return nil, nil, component.ErrTypeClosed
 	}
 	if !exists {
-		return ErrStreamDoesNotExist
+		return nil, nil, ErrStreamDoesNotExist
 	}
 
-	if reflect.DeepEqual(wrapper.config, conf) {
-		return nil
+	if reflect.DeepEqual(prev.config, conf) {
+		return nil, nil, nil
 	}
 
-	if err := m.Delete(ctx, id); err != nil {
-		return err
+	version, err := newStreamVersion(prev.Version()+1, conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(prev.versions) > 0 && prev.versions[len(prev.versions)-1].sameConfig(version) {
+		// Streams restored from a store are parsed from a sanitised config and
+		// therefore need comparing in their sanitised form.
+		return nil, nil, nil
+	}
+	versions := make([]StreamVersion, 0, len(prev.versions)+1)
+	versions = append(versions, prev.versions...)
+	versions = append(versions, version)
+
+	if err := m.remove(ctx, id); err != nil {
+		return nil, nil, err
 	}
-	return m.Create(id, conf)
+	if wrapper, err = m.create(id, conf, versions); err != nil {
+		return nil, nil, err
+	}
+	return prev, wrapper, nil
 }
 
 // Delete attempts to stop and remove a stream by its ID. Returns an error if
 // the stream was not found, or if clean shutdown fails in the specified period
 // of time.
+//
+// Streams deleted this way are not removed from the store of the manager, only
+// streams deleted via the REST API are.
 func (m *Type) Delete(ctx context.Context, id string) error {
+	return m.remove(ctx, id)
+}
+
+// deletePersisted deletes a stream and removes it from the store of the
+// manager.
+func (m *Type) deletePersisted(ctx context.Context, id string) error {
+	if err := m.remove(ctx, id); err != nil {
+		return err
+	}
+	if m.store == nil {
+		return nil
+	}
+	if err := m.store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to persist stream deletion: %w", err)
+	}
+	return nil
+}
+
+func (m *Type) remove(ctx context.Context, id string) error {
 	m.lock.Lock()
 	if m.closed {
 		m.lock.Unlock()
@@ -231,6 +432,112 @@ func (m *Type) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Load creates all streams persisted within the store of the manager from
+// their latest versions, retaining their version histories. Streams that fail
+// to be parsed or created are logged and skipped. Returns an error if the
+// manager does not have a store or if the store cannot be read.
+func (m *Type) Load(ctx context.Context) error {
+	if m.store == nil {
+		return errors.New("stream manager does not have a store")
+	}
+
+	streams, err := m.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load streams: %w", err)
+	}
+
+	for id, versions := range streams {
+		conf, err := versions[len(versions)-1].StreamConfig()
+		if err != nil {
+			m.manager.Logger().Errorf("Skipping persisted stream '%v' as it failed to parse: %v\n", id, err)
+			continue
+		}
+		if _, err := m.create(id, conf, versions); err != nil {
+			m.manager.Logger().Errorf("Skipping persisted stream '%v' as it failed to be created: %v\n", id, err)
+		}
+	}
+	return nil
+}
+
+// Pause stops a stream identified by its ID from pulling messages from its
+// input without shutting it down. Returns an error if the stream was not found
+// or is not running.
+func (m *Type) Pause(id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+
+	wrapper.stateMut.Lock()
+	defer wrapper.stateMut.Unlock()
+
+	if wrapper.draining || !wrapper.IsRunning() {
+		return ErrStreamNotRunning
+	}
+	wrapper.strm.Pause()
+	return nil
+}
+
+// Resume continues pulling messages from the input of a paused stream
+// identified by its ID. Returns an error if the stream was not found or is not
+// paused.
+func (m *Type) Resume(id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+
+	wrapper.stateMut.Lock()
+	defer wrapper.stateMut.Unlock()
+
+	if wrapper.draining || !wrapper.IsRunning() || !wrapper.strm.IsPaused() {
+		return ErrStreamNotPaused
+	}
+	wrapper.strm.Resume()
+	return nil
+}
+
+// Drain stops a stream identified by its ID from consuming any further
+// messages and waits for all in-flight messages to be flushed through the
+// stream, after which the stream is stopped but remains registered until it is
+// deleted or updated. A paused stream is drained without consuming any further
+// messages from its input.
+//
+// If in-flight messages are not flushed within the drain timeout of the manager
+// then the stream is stopped ungracefully and an error is returned. Returns an
+// error if the stream was not found or is not running.
+func (m *Type) Drain(id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+
+	wrapper.stateMut.Lock()
+	if wrapper.draining || !wrapper.IsRunning() {
+		wrapper.stateMut.Unlock()
+		return ErrStreamNotRunning
+	}
+	wrapper.draining = true
+	wrapper.stateMut.Unlock()
+
+	// A drain is bounded by the manager rather than the caller so that a
+	// stream is never left half stopped.
+	ctx, done := context.WithTimeout(context.Background(), m.drainTimeout)
+	defer done()
+
+	if err = wrapper.strm.StopGracefully(ctx); err != nil {
+		err = fmt.Errorf("failed to drain stream gracefully: %w", err)
+
+		uCtx, uDone := context.WithTimeout(context.Background(), discardTimeout)
+		defer uDone()
+		if uerr := wrapper.strm.StopUnordered(uCtx); uerr != nil {
+			m.manager.Logger().Errorf("Failed to stop stream '%v' after a failed drain: %v\n", id, uerr)
+		}
+	}
+	wrapper.setClosed()
+	return err
+}
+
 //------------------------------------------------------------------------------
 
 // Stop attempts to gracefully shut down all active streams and close the
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	bmanager "github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
@@ -105,3 +106,35 @@ func TestTypeBasicClose(t *testing.T) {
 		t.Errorf("Unexpected error: %v != %v", act, exp)
 	}
 }
+
+func TestTypeDrainTimeout(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptDrainTimeout(time.Millisecond*100))
+
+	// The message is held by the pipeline for longer than the drain timeout
+	// and therefore the drain cannot complete gracefully.
+	conf := harmlessConf()
+	conf.Input.Generate.Mapping = `root = "hello"`
+	conf.Input.Generate.Count = 1
+	procConf := processor.NewConfig()
+	procConf.Type = "sleep"
+	procConf.Sleep.Duration = "10s"
+	conf.Pipeline.Processors = append(conf.Pipeline.Processors, procConf)
+	require.NoError(t, mgr.Create("foo", conf))
+
+	time.Sleep(time.Millisecond * 50)
+	require.Error(t, mgr.Drain("foo"))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	require.False(t, info.IsRunning())
+	require.Equal(t, StreamStateStopped, info.State())
+
+	require.Equal(t, ErrStreamNotRunning, mgr.Drain("foo"))
+	require.NoError(t, mgr.Stop(ctx))
+}
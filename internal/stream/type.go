@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"runtime/pprof"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -29,6 +30,15 @@ type Type struct {
 
 	manager bundle.NewManagement
 
+	pauseMut   sync.Mutex
+	paused     bool
+	pauseChan  chan struct{}
+	resumeChan chan struct{}
+	gateClose  chan struct{}
+	gateOnce   sync.Once
+	gateDrain  chan struct{}
+	drainOnce  sync.Once
+
 	onClose func()
 	closed  uint32
 }
@@ -36,10 +46,14 @@ type Type struct {
 // New creates a new stream.Type.
 func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, error) {
 	t := &Type{
-		conf:    conf,
-		manager: mgr,
-		onClose: func() {},
-		closed:  0,
+		conf:       conf,
+		manager:    mgr,
+		onClose:    func() {},
+		closed:     0,
+		pauseChan:  make(chan struct{}),
+		resumeChan: make(chan struct{}),
+		gateClose:  make(chan struct{}),
+		gateDrain:  make(chan struct{}),
 	}
 	for _, opt := range opts {
 		opt(t)
@@ -95,6 +109,84 @@ func (t *Type) IsReady() bool {
 	return t.inputLayer.Connected() && t.outputLayer.Connected()
 }
 
+// Pause stops the stream from pulling messages from the input layer without
+// shutting any components down. Messages already consumed continue to flow
+// through the remaining layers of the stream.
+func (t *Type) Pause() {
+	t.pauseMut.Lock()
+	defer t.pauseMut.Unlock()
+	if !t.paused {
+		t.paused = true
+		close(t.pauseChan)
+		t.resumeChan = make(chan struct{})
+	}
+}
+
+// Resume continues pulling messages from the input layer of a paused stream.
+func (t *Type) Resume() {
+	t.pauseMut.Lock()
+	defer t.pauseMut.Unlock()
+	if t.paused {
+		t.paused = false
+		close(t.resumeChan)
+		t.pauseChan = make(chan struct{})
+	}
+}
+
+// IsPaused returns a boolean indicating whether the stream is paused.
+func (t *Type) IsPaused() bool {
+	t.pauseMut.Lock()
+	defer t.pauseMut.Unlock()
+	return t.paused
+}
+
+// gateInput forwards transactions from the input layer, blocking whilst the
+// stream is paused in order to apply back pressure to the input. If the stream
+// is drained whilst paused the gate closes without consuming any further
+// transactions.
+func (t *Type) gateInput(inChan <-chan message.Transaction) <-chan message.Transaction {
+	outChan := make(chan message.Transaction)
+	go func() {
+		defer close(outChan)
+		for {
+			t.pauseMut.Lock()
+			paused, pauseChan, resumeChan := t.paused, t.pauseChan, t.resumeChan
+			t.pauseMut.Unlock()
+
+			if paused {
+				select {
+				case <-resumeChan:
+				case <-t.gateDrain:
+					return
+				case <-t.gateClose:
+					return
+				}
+				continue
+			}
+
+			var tran message.Transaction
+			var open bool
+			select {
+			case tran, open = <-inChan:
+				if !open {
+					return
+				}
+			case <-pauseChan:
+				continue
+			case <-t.gateClose:
+				return
+			}
+
+			select {
+			case outChan <- tran:
+			case <-t.gateClose:
+				return
+			}
+		}
+	}()
+	return outChan
+}
+
 func (t *Type) start() (err error) {
 	// Constructors
 	iMgr := t.manager.IntoPath("input")
@@ -121,7 +213,7 @@ func (t *Type) start() (err error) {
 	// Start chaining components
 	var nextTranChan <-chan message.Transaction
 
-	nextTranChan = t.inputLayer.TransactionChan()
+	nextTranChan = t.gateInput(t.inputLayer.TransactionChan())
 	if t.bufferLayer != nil {
 		if err = t.bufferLayer.Consume(nextTranChan); err != nil {
 			return
@@ -155,10 +247,20 @@ func (t *Type) start() (err error) {
 // closing the input layer and waiting for all other layers to terminate by
 // proxy. This should guarantee that all in-flight and buffered data is resolved
 // before shutting down.
+//
+// A paused stream remains paused, where messages already consumed are flushed
+// but no further messages are consumed from the input.
 func (t *Type) StopGracefully(ctx context.Context) (err error) {
+	paused := t.IsPaused()
+
 	t.inputLayer.TriggerStopConsuming()
-	if err = t.inputLayer.WaitForClose(ctx); err != nil {
-		return
+	t.drainOnce.Do(func() {
+		close(t.gateDrain)
+	})
+	if !paused {
+		if err = t.inputLayer.WaitForClose(ctx); err != nil {
+			return
+		}
 	}
 
 	// If we have a buffer then wait right here. We want to try and allow the
@@ -180,6 +282,15 @@ func (t *Type) StopGracefully(ctx context.Context) (err error) {
 	if err = t.outputLayer.WaitForClose(ctx); err != nil {
 		return
 	}
+
+	if paused {
+		// The input of a paused stream may be holding a message that can no
+		// longer be delivered, which is left unacknowledged.
+		t.inputLayer.TriggerCloseNow()
+		if err = t.inputLayer.WaitForClose(ctx); err != nil {
+			return
+		}
+	}
 	return nil
 }
 
@@ -187,6 +298,9 @@ func (t *Type) StopGracefully(ctx context.Context) (err error) {
 // the stream to gracefully wind down in the order of component layers. This
 // should only be attempted if both stopGracefully and stopOrdered failed.
 func (t *Type) StopUnordered(ctx context.Context) (err error) {
+	t.gateOnce.Do(func() {
+		close(t.gateClose)
+	})
 	t.inputLayer.TriggerCloseNow()
 	if t.bufferLayer != nil {
 		t.bufferLayer.TriggerCloseNow()
@@ -142,6 +142,81 @@ func TestTypeCloseUnordered(t *testing.T) {
 	assert.NoError(t, strm.StopUnordered(ctx))
 }
 
+func TestTypePauseResume(t *testing.T) {
+	conf := stream.NewConfig()
+	conf.Input.Type = "inproc"
+	conf.Input.Inproc = "foo_in"
+	conf.Output.Type = "inproc"
+	conf.Output.Inproc = "foo_out"
+
+	newMgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	inChan := make(chan message.Transaction)
+	newMgr.SetPipe("foo_in", inChan)
+
+	strm, err := stream.New(conf, newMgr)
+	require.NoError(t, err)
+
+	outChan, err := newMgr.GetPipe("foo_out")
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	sendMsg := func(content string) {
+		t.Helper()
+		select {
+		case inChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(content)}), make(chan error, 1)):
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+	}
+	readMsg := func() string {
+		t.Helper()
+		select {
+		case tran := <-outChan:
+			require.NoError(t, tran.Ack(ctx, nil))
+			return string(tran.Payload.Get(0).AsBytes())
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+		return ""
+	}
+
+	sendMsg("first")
+	assert.Equal(t, "first", readMsg())
+
+	strm.Pause()
+	assert.True(t, strm.IsPaused())
+
+	sendMsg("second")
+	select {
+	case <-outChan:
+		t.Fatal("Received message from paused stream")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	strm.Resume()
+	assert.False(t, strm.IsPaused())
+	assert.Equal(t, "second", readMsg())
+
+	// Paused streams can still be stopped gracefully, without consuming any
+	// further messages.
+	strm.Pause()
+	sendMsg("third")
+	require.NoError(t, strm.StopGracefully(ctx))
+	assert.True(t, strm.IsPaused())
+
+	select {
+	case tran, open := <-outChan:
+		if open {
+			t.Fatalf("Received message from drained paused stream: %s", tran.Payload.Get(0).AsBytes())
+		}
+	case <-time.After(time.Millisecond * 100):
+	}
+}
+
 type mockAPIReg struct {
 	server *httptest.Server
 }
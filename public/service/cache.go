@@ -14,6 +14,8 @@ import (
 var (
 	ErrKeyAlreadyExists = errors.New("key already exists")
 	ErrKeyNotFound      = errors.New("key does not exist")
+	ErrValueMismatch    = errors.New("value does not match")
+	ErrNotSupported     = errors.New("operation not supported")
 )
 
 // Cache is an interface implemented by Benthos caches.
@@ -46,6 +48,51 @@ type CacheItem struct {
 	TTL   *time.Duration
 }
 
+// CacheMultiGetter is an optional interface that a Cache implementation can
+// satisfy in order to fetch multiple keys in as few requests as possible. When
+// a cache does not implement this interface keys are fetched individually.
+// Caches obtained via Resources.AccessCache always implement this interface.
+type CacheMultiGetter interface {
+	// GetMulti returns the values of multiple keys, keys that do not exist are
+	// omitted from the result.
+	GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error)
+}
+
+// CacheKeyLister is an optional interface that a Cache implementation can
+// satisfy in order to support listing the keys it holds, which is useful for
+// debugging cache contents. Caches obtained via Resources.AccessCache always
+// implement this interface, but return ErrNotSupported when the underlying
+// cache does not.
+type CacheKeyLister interface {
+	// Keys returns the keys currently held within the cache that begin with a
+	// prefix, an empty prefix matches all keys.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
+
+// CacheIncrementer is an optional interface that a Cache implementation can
+// satisfy in order to support atomic increments. Caches obtained via
+// Resources.AccessCache always implement this interface, but return
+// ErrNotSupported when the underlying cache does not.
+type CacheIncrementer interface {
+	// Incr atomically adds a delta to the integer value of a key and returns
+	// the result, a key that does not exist is treated as having a value of
+	// zero. The value of the key is stored as a base 10 string.
+	Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+// CacheCompareAndSwapper is an optional interface that a Cache implementation
+// can satisfy in order to support atomic compare-and-swap operations. Caches
+// obtained via Resources.AccessCache always implement this interface, but
+// return ErrNotSupported when the underlying cache does not.
+type CacheCompareAndSwapper interface {
+	// CompareAndSwap sets the value of a key to newValue only if its current
+	// value is equal to oldValue, where a nil oldValue indicates that the key
+	// must not exist. Returns ErrValueMismatch if the current value does not
+	// match, ErrKeyNotFound if the key does not exist, and
+	// ErrKeyAlreadyExists if the key was expected not to exist.
+	CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error
+}
+
 // batchedCache represents a cache where the underlying implementation is able
 // to benefit from batched set requests. This interface is optional for caches
 // and when implemented will automatically be utilised where possible.
@@ -59,16 +106,56 @@ type batchedCache interface {
 
 // Implements types.Cache.
 type airGapCache struct {
-	c  Cache
-	cm batchedCache
+	c   Cache
+	cm  batchedCache
+	cmg CacheMultiGetter
+	ckl CacheKeyLister
+	ci  CacheIncrementer
+	cas CacheCompareAndSwapper
 }
 
 func newAirGapCache(c Cache, stats metrics.Type) cache.V1 {
 	ag := &airGapCache{c: c, cm: nil}
 	ag.cm, _ = c.(batchedCache)
+	ag.cmg, _ = c.(CacheMultiGetter)
+	ag.ckl, _ = c.(CacheKeyLister)
+	ag.ci, _ = c.(CacheIncrementer)
+	ag.cas, _ = c.(CacheCompareAndSwapper)
 	return cache.MetricsForCache(ag, stats)
 }
 
+// toComponentCacheErr converts the errors of public cache implementations into
+// their internal equivalents.
+func toComponentCacheErr(err error) error {
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		return component.ErrKeyNotFound
+	case errors.Is(err, ErrKeyAlreadyExists):
+		return component.ErrKeyAlreadyExists
+	case errors.Is(err, ErrValueMismatch):
+		return component.ErrValueMismatch
+	case errors.Is(err, ErrNotSupported):
+		return component.ErrNotSupported
+	}
+	return err
+}
+
+// fromComponentCacheErr converts the errors of internal cache implementations
+// into their public equivalents.
+func fromComponentCacheErr(err error) error {
+	switch {
+	case errors.Is(err, component.ErrKeyNotFound):
+		return ErrKeyNotFound
+	case errors.Is(err, component.ErrKeyAlreadyExists):
+		return ErrKeyAlreadyExists
+	case errors.Is(err, component.ErrValueMismatch):
+		return ErrValueMismatch
+	case errors.Is(err, component.ErrNotSupported):
+		return ErrNotSupported
+	}
+	return err
+}
+
 func (a *airGapCache) Get(ctx context.Context, key string) ([]byte, error) {
 	b, err := a.c.Get(ctx, key)
 	if errors.Is(err, ErrKeyNotFound) {
@@ -77,6 +164,25 @@ func (a *airGapCache) Get(ctx context.Context, key string) ([]byte, error) {
 	return b, err
 }
 
+func (a *airGapCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	if a.cmg != nil {
+		values, err := a.cmg.GetMulti(ctx, keys...)
+		return values, toComponentCacheErr(err)
+	}
+	values := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		b, err := a.c.Get(ctx, k)
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				continue
+			}
+			return nil, toComponentCacheErr(err)
+		}
+		values[k] = b
+	}
+	return values, nil
+}
+
 func (a *airGapCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
 	return a.c.Set(ctx, key, value, ttl)
 }
@@ -113,6 +219,29 @@ func (a *airGapCache) Delete(ctx context.Context, key string) error {
 	return a.c.Delete(ctx, key)
 }
 
+func (a *airGapCache) Keys(ctx context.Context, prefix string) ([]string, error) {
+	if a.ckl == nil {
+		return nil, component.ErrNotSupported
+	}
+	keys, err := a.ckl.Keys(ctx, prefix)
+	return keys, toComponentCacheErr(err)
+}
+
+func (a *airGapCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if a.ci == nil {
+		return 0, component.ErrNotSupported
+	}
+	v, err := a.ci.Incr(ctx, key, delta, ttl)
+	return v, toComponentCacheErr(err)
+}
+
+func (a *airGapCache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	if a.cas == nil {
+		return component.ErrNotSupported
+	}
+	return toComponentCacheErr(a.cas.CompareAndSwap(ctx, key, oldValue, newValue, ttl))
+}
+
 func (a *airGapCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
@@ -136,6 +265,10 @@ func (r *reverseAirGapCache) Get(ctx context.Context, key string) ([]byte, error
 	return b, err
 }
 
+func (r *reverseAirGapCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	return r.c.GetMulti(ctx, keys)
+}
+
 func (r *reverseAirGapCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
 	return r.c.Set(ctx, key, value, ttl)
 }
@@ -151,6 +284,20 @@ func (r *reverseAirGapCache) Delete(ctx context.Context, key string) error {
 	return r.c.Delete(ctx, key)
 }
 
+func (r *reverseAirGapCache) Keys(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := cache.Keys(ctx, r.c, prefix)
+	return keys, fromComponentCacheErr(err)
+}
+
+func (r *reverseAirGapCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	v, err := cache.Incr(ctx, r.c, key, delta, ttl)
+	return v, fromComponentCacheErr(err)
+}
+
+func (r *reverseAirGapCache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte, ttl *time.Duration) error {
+	return fromComponentCacheErr(cache.CompareAndSwap(ctx, r.c, key, oldValue, newValue, ttl))
+}
+
 func (r *reverseAirGapCache) Close(ctx context.Context) error {
 	return r.c.Close(ctx)
 }
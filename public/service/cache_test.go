@@ -119,6 +119,81 @@ func TestCacheAirGapGet(t *testing.T) {
 	assert.EqualError(t, err, "key does not exist")
 }
 
+// getMultiCache returns public cache errors, and optionally implements
+// CacheMultiGetter.
+type getMultiCache struct {
+	*closableCache
+}
+
+func (c *getMultiCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	i, ok := c.m[key]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return i.b, nil
+}
+
+type getMultiCachePassthrough struct {
+	*getMultiCache
+}
+
+func (c *getMultiCachePassthrough) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	values := map[string][]byte{}
+	for _, k := range keys {
+		if i, ok := c.m[k]; ok {
+			values[k] = i.b
+		}
+	}
+	return values, nil
+}
+
+func TestCacheAirGapGetMulti(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name string
+		c    func(*getMultiCache) Cache
+	}{
+		{
+			name: "fallback",
+			c:    func(c *getMultiCache) Cache { return c },
+		},
+		{
+			name: "passthrough",
+			c:    func(c *getMultiCache) Cache { return &getMultiCachePassthrough{getMultiCache: c} },
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			rl := &getMultiCache{
+				closableCache: &closableCache{
+					m: map[string]testCacheItem{
+						"foo": {b: []byte("bar")},
+						"baz": {b: []byte("buz")},
+					},
+				},
+			}
+			agrl := newAirGapCache(test.c(rl), metrics.Noop())
+
+			values, err := agrl.GetMulti(ctx, []string{"foo", "baz", "not exist"})
+			assert.NoError(t, err)
+			assert.Equal(t, map[string][]byte{
+				"foo": []byte("bar"),
+				"baz": []byte("buz"),
+			}, values)
+
+			rl.err = ErrNotSupported
+			_, err = agrl.GetMulti(ctx, []string{"foo"})
+			assert.Equal(t, component.ErrNotSupported, err)
+		})
+	}
+}
+
 func TestCacheAirGapSet(t *testing.T) {
 	ctx := context.Background()
 	rl := &closableCache{
@@ -316,6 +391,10 @@ func (c *closableCacheType) Get(ctx context.Context, key string) ([]byte, error)
 	return i.b, nil
 }
 
+func (c *closableCacheType) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (c *closableCacheType) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
 	if c.err != nil {
 		return c.err
@@ -19,6 +19,20 @@ type RateLimit interface {
 	Closer
 }
 
+// KeyedRateLimit is an optional interface that a RateLimit implementation can
+// satisfy in order to track a separate limit for each of any number of keys,
+// such as a limit per customer. Rate limits obtained via
+// Resources.AccessRateLimit always implement this interface, and rate limits
+// that are not keyed ignore the key, in which case the limit is shared by all
+// keys.
+type KeyedRateLimit interface {
+	// AccessKey accesses the rate limited resource identified by a key.
+	// Returns a duration or an error if the rate limit check fails. The
+	// returned duration is either zero (meaning the resource may be accessed)
+	// or a reasonable length of time to wait before requesting again.
+	AccessKey(ctx context.Context, key string) (time.Duration, error)
+}
+
 //------------------------------------------------------------------------------
 
 func newAirGapRateLimit(c RateLimit, stats metrics.Type) ratelimit.V1 {
@@ -40,6 +54,10 @@ func (a *reverseAirGapRateLimit) Access(ctx context.Context) (time.Duration, err
 	return a.r.Access(ctx)
 }
 
+func (a *reverseAirGapRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return ratelimit.AccessKey(ctx, a.r, key)
+}
+
 func (a *reverseAirGapRateLimit) Close(ctx context.Context) error {
 	return a.r.Close(ctx)
 }
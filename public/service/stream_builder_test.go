@@ -873,6 +873,15 @@ dynamic:
                 codec: lines
                 max_buffer: 1000000
                 delete_on_finish: false
+                follow:
+                    enabled: false
+                    poll_interval: 1s
+                    cache: ""
+                watcher:
+                    enabled: false
+                    minimum_age: 1s
+                    poll_interval: 1s
+                    cache: ""
     prefix: ""`,
 				},
 				{
@@ -884,7 +893,16 @@ file:
         - aaa.txt
     codec: lines
     max_buffer: 1000000
-    delete_on_finish: false`,
+    delete_on_finish: false
+    follow:
+        enabled: false
+        poll_interval: 1s
+        cache: ""
+    watcher:
+        enabled: false
+        minimum_age: 1s
+        poll_interval: 1s
+        cache: ""`,
 				},
 				{
 					typeStr: "buffer",
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/transaction"
+)
+
+// SyncResponseStore collects the responses of messages that were consumed by an
+// input, which are added by the sync_response output and processor. This
+// allows inputs to implement [synchronous responses](https://www.benthos.dev/docs/guides/sync_responses).
+type SyncResponseStore struct {
+	s transaction.ResultStore
+}
+
+// NewSyncResponseStore creates a new empty store of synchronous responses.
+func NewSyncResponseStore() *SyncResponseStore {
+	return &SyncResponseStore{s: transaction.NewResultStore()}
+}
+
+// Read returns the batches of response messages that have been added to the
+// store so far.
+func (s *SyncResponseStore) Read() []MessageBatch {
+	var batches []MessageBatch
+	for _, b := range s.s.Get() {
+		batch := make(MessageBatch, len(b))
+		for i, p := range b {
+			batch[i] = newMessageFromPart(p)
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// WithSyncResponseStore returns a copy of the batch where each message is
+// associated with a store, which then collects any responses made to the
+// messages once they have been consumed.
+func (b MessageBatch) WithSyncResponseStore(store *SyncResponseStore) MessageBatch {
+	parts := make(message.Batch, len(b))
+	for i, m := range b {
+		parts[i] = m.part
+	}
+	transaction.AddResultStore(parts, store.s)
+
+	newBatch := make(MessageBatch, len(parts))
+	for i, p := range parts {
+		newBatch[i] = newMessageFromPart(p)
+	}
+	return newBatch
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncResponseStore(t *testing.T) {
+	builder := NewStreamBuilder()
+	require.NoError(t, builder.SetYAML(`
+pipeline:
+  processors:
+    - mapping: 'root = content().uppercase()'
+output:
+  sync_response: {}
+`))
+
+	sendFn, err := builder.AddBatchProducerFunc()
+	require.NoError(t, err)
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	go func() {
+		_ = strm.Run(ctx)
+	}()
+
+	store := NewSyncResponseStore()
+	batch := MessageBatch{
+		NewMessage([]byte("foo")),
+		NewMessage([]byte("bar")),
+	}
+	require.NoError(t, sendFn(ctx, batch.WithSyncResponseStore(store)))
+
+	responses := store.Read()
+	require.Len(t, responses, 1)
+	require.Len(t, responses[0], 2)
+
+	for i, exp := range []string{"FOO", "BAR"} {
+		b, err := responses[0][i].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, exp, string(b))
+	}
+
+	b, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(b))
+
+	require.NoError(t, strm.StopWithin(time.Second*5))
+}
@@ -0,0 +1,96 @@
+//go:build tinygo
+
+package tinygo
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// ErrMsgDeleted is returned when selecting or copying a message of a batch that
+// has been deleted.
+var ErrMsgDeleted = errors.New("message has been deleted")
+
+// Status codes returned by host functions that are able to fail.
+const (
+	statusOK       uint32 = 0
+	statusNotFound uint32 = 1
+)
+
+//go:wasm-module benthos_wasm
+//export v0_last_error
+func _v0_last_error() (ptrSize uint64)
+
+// statusToErr converts the status code of a host function into an error.
+func statusToErr(status uint32, notFoundErr error) error {
+	switch status {
+	case statusOK:
+		return nil
+	case statusNotFound:
+		return notFoundErr
+	}
+	if errBytes := ptrSizeToBytes(_v0_last_error()); len(errBytes) > 0 {
+		return errors.New(string(errBytes))
+	}
+	return errors.New("unknown error")
+}
+
+//go:wasm-module benthos_wasm
+//export v0_msg_delete
+func _v0_msg_delete()
+
+// DeleteMsg removes the message currently being processed from the resulting
+// batch.
+func DeleteMsg() error {
+	_v0_msg_delete()
+	return nil
+}
+
+//go:wasm-module benthos_wasm
+//export v0_batch_len
+func _v0_batch_len() uint32
+
+// BatchLen returns the number of messages within the batch currently being
+// processed, including messages that have been deleted. When the processor is
+// configured to call the module for each message the batch consists of the
+// message being processed along with any copies made of it.
+func BatchLen() int {
+	return int(_v0_batch_len())
+}
+
+//go:wasm-module benthos_wasm
+//export v0_batch_index
+func _v0_batch_index() uint32
+
+// BatchIndex returns the index of the message currently being processed within
+// its batch.
+func BatchIndex() int {
+	return int(_v0_batch_index())
+}
+
+//go:wasm-module benthos_wasm
+//export v0_batch_select
+func _v0_batch_select(index uint32) uint32
+
+// SelectMsg selects a message of the batch by its index, which all message
+// functions then operate on. Returns ErrMsgDeleted if the message has been
+// deleted.
+func SelectMsg(index int) error {
+	return statusToErr(_v0_batch_select(uint32(index)), ErrMsgDeleted)
+}
+
+//go:wasm-module benthos_wasm
+//export v0_batch_copy
+func _v0_batch_copy(index, indexOutPtr uint32) uint32
+
+// CopyMsg appends a copy of a message of the batch to the end of the batch and
+// returns the index of the copy, which can be used in order to split a message
+// into many.
+func CopyMsg(index int) (int, error) {
+	var newIndex uint64
+	status := _v0_batch_copy(uint32(index), uint32(uintptr(unsafe.Pointer(&newIndex))))
+	if err := statusToErr(status, ErrMsgDeleted); err != nil {
+		return 0, err
+	}
+	return int(newIndex), nil
+}
@@ -0,0 +1,57 @@
+//go:build tinygo
+
+package tinygo
+
+import (
+	"strings"
+)
+
+//go:wasm-module benthos_wasm
+//export v0_msg_get_meta
+func _v0_msg_get_meta(keyPtr, keySize uint32) (ptrSize uint64)
+
+// GetMsgMeta returns the value of a metadata key of the message currently
+// being processed, or an empty string if the key does not exist.
+func GetMsgMeta(key string) (string, error) {
+	keyP, keyS := bytesToPtr([]byte(key))
+	return string(ptrSizeToBytes(_v0_msg_get_meta(keyP, keyS))), nil
+}
+
+//go:wasm-module benthos_wasm
+//export v0_msg_set_meta
+func _v0_msg_set_meta(keyPtr, keySize, valuePtr, valueSize uint32)
+
+// SetMsgMeta sets a metadata key of the message currently being processed to
+// the value provided.
+func SetMsgMeta(key, value string) error {
+	keyP, keyS := bytesToPtr([]byte(key))
+	valueP, valueS := bytesToPtr([]byte(value))
+	_v0_msg_set_meta(keyP, keyS, valueP, valueS)
+	return nil
+}
+
+//go:wasm-module benthos_wasm
+//export v0_msg_delete_meta
+func _v0_msg_delete_meta(keyPtr, keySize uint32)
+
+// DeleteMsgMeta removes a metadata key from the message currently being
+// processed.
+func DeleteMsgMeta(key string) error {
+	keyP, keyS := bytesToPtr([]byte(key))
+	_v0_msg_delete_meta(keyP, keyS)
+	return nil
+}
+
+//go:wasm-module benthos_wasm
+//export v0_msg_meta_keys
+func _v0_msg_meta_keys() (ptrSize uint64)
+
+// GetMsgMetaKeys returns the metadata keys of the message currently being
+// processed in lexicographical order.
+func GetMsgMetaKeys() ([]string, error) {
+	keysBytes := ptrSizeToBytes(_v0_msg_meta_keys())
+	if len(keysBytes) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(keysBytes), "\x00"), nil
+}
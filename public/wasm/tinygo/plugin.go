@@ -0,0 +1,43 @@
+//go:build tinygo
+
+package tinygo
+
+// Status codes returned by the functions `read`, `write`, `call`, `connect`
+// and `close` that are exported by plugin modules.
+const (
+	PluginStatusOK    uint32 = 0
+	PluginStatusEnd   uint32 = 1
+	PluginStatusError uint32 = 2
+)
+
+//go:wasm-module benthos_wasm
+//export v0_plugin_register
+func _v0_plugin_register(manifestPtr, manifestSize uint32)
+
+// RegisterPlugin registers the component implemented by the module, and must
+// be called from within a function `register` exported by the module. The
+// manifest is a JSON document describing the component, including its name,
+// type (input, output or function) and config fields.
+func RegisterPlugin(manifest []byte) {
+	_v0_plugin_register(bytesToPtr(manifest))
+}
+
+//go:wasm-module benthos_wasm
+//export v0_plugin_config
+func _v0_plugin_config() (ptrSize uint64)
+
+// PluginConfig returns the config of the component as a JSON object.
+func PluginConfig() []byte {
+	return ptrSizeToBytes(_v0_plugin_config())
+}
+
+//go:wasm-module benthos_wasm
+//export v0_plugin_error
+func _v0_plugin_error(errPtr, errSize uint32)
+
+// PluginError sets the error reported by the current call of the module and
+// returns PluginStatusError, which should then be returned by the function.
+func PluginError(err error) uint32 {
+	_v0_plugin_error(bytesToPtr([]byte(err.Error())))
+	return PluginStatusError
+}
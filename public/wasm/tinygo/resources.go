@@ -0,0 +1,86 @@
+//go:build tinygo
+
+package tinygo
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+)
+
+// ErrKeyNotFound is returned when a key does not exist within a cache.
+var ErrKeyNotFound = errors.New("key does not exist")
+
+//go:wasm-module benthos_wasm
+//export v0_cache_get
+func _v0_cache_get(namePtr, nameSize, keyPtr, keySize, valueOutPtr uint32) uint32
+
+// CacheGet returns the value of a key from a cache resource, or ErrKeyNotFound
+// if the key does not exist.
+func CacheGet(resource, key string) ([]byte, error) {
+	nameP, nameS := bytesToPtr([]byte(resource))
+	keyP, keyS := bytesToPtr([]byte(key))
+
+	var ptrSize uint64
+	status := _v0_cache_get(nameP, nameS, keyP, keyS, uint32(uintptr(unsafe.Pointer(&ptrSize))))
+	if err := statusToErr(status, ErrKeyNotFound); err != nil {
+		return nil, err
+	}
+	return ptrSizeToBytes(ptrSize), nil
+}
+
+//go:wasm-module benthos_wasm
+//export v0_cache_set
+func _v0_cache_set(namePtr, nameSize, keyPtr, keySize, valuePtr, valueSize uint32, ttlMillis uint64) uint32
+
+// CacheSet sets the value of a key within a cache resource. A zero TTL results
+// in the default TTL of the cache being used.
+func CacheSet(resource, key string, value []byte, ttl time.Duration) error {
+	nameP, nameS := bytesToPtr([]byte(resource))
+	keyP, keyS := bytesToPtr([]byte(key))
+	valueP, valueS := bytesToPtr(value)
+	return statusToErr(_v0_cache_set(nameP, nameS, keyP, keyS, valueP, valueS, uint64(ttl.Milliseconds())), nil)
+}
+
+//go:wasm-module benthos_wasm
+//export v0_cache_delete
+func _v0_cache_delete(namePtr, nameSize, keyPtr, keySize uint32) uint32
+
+// CacheDelete removes a key from a cache resource.
+func CacheDelete(resource, key string) error {
+	nameP, nameS := bytesToPtr([]byte(resource))
+	keyP, keyS := bytesToPtr([]byte(key))
+	return statusToErr(_v0_cache_delete(nameP, nameS, keyP, keyS), nil)
+}
+
+//go:wasm-module benthos_wasm
+//export v0_rate_limit_access
+func _v0_rate_limit_access(namePtr, nameSize uint32) uint32
+
+// RateLimitAccess blocks until a rate limit resource grants access.
+func RateLimitAccess(resource string) error {
+	nameP, nameS := bytesToPtr([]byte(resource))
+	return statusToErr(_v0_rate_limit_access(nameP, nameS), nil)
+}
+
+// LogLevel describes the level of a log message emitted with Log.
+type LogLevel uint32
+
+// Levels of log messages.
+const (
+	LogLevelTrace LogLevel = iota
+	LogLevelDebug
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+//go:wasm-module benthos_wasm
+//export v0_log
+func _v0_log(level, ptr, size uint32)
+
+// Log emits a message with the logger of the processor.
+func Log(level LogLevel, message string) {
+	msgP, msgS := bytesToPtr([]byte(message))
+	_v0_log(uint32(level), msgP, msgS)
+}
@@ -0,0 +1,53 @@
+//go:build tinygo
+
+package tinygo
+
+import (
+	"encoding/json"
+)
+
+//go:wasm-module benthos_wasm
+//export v0_msg_as_json
+func _v0_msg_as_json() (ptrSize uint64)
+
+// GetMsgAsJSON returns the contents of the message currently being processed
+// as a JSON document, which fails the message if it cannot be parsed as JSON.
+func GetMsgAsJSON() ([]byte, error) {
+	return ptrSizeToBytes(_v0_msg_as_json()), nil
+}
+
+// GetMsgAsStructured returns the contents of the message currently being
+// processed parsed as a JSON document.
+func GetMsgAsStructured() (any, error) {
+	jsonBytes, err := GetMsgAsJSON()
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(jsonBytes, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+//go:wasm-module benthos_wasm
+//export v0_msg_set_json
+func _v0_msg_set_json(ptr, size uint32)
+
+// SetMsgJSON sets the contents of the message currently being processed to a
+// JSON document, which fails the message if the document is invalid.
+func SetMsgJSON(b []byte) error {
+	resP, resS := bytesToPtr(b)
+	_v0_msg_set_json(resP, resS)
+	return nil
+}
+
+// SetMsgStructured sets the contents of the message currently being processed
+// to a value serialised as a JSON document.
+func SetMsgStructured(v any) error {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return SetMsgJSON(jsonBytes)
+}
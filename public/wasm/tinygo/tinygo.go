@@ -45,6 +45,12 @@ func GetMsgAsBytes() ([]byte, error) {
 	return ptrToBytes(contentPtr, contentSize), nil
 }
 
+// ptrSizeToBytes returns a byte slice from a pointer and length pair packed
+// into a uint64.
+func ptrSizeToBytes(ptrSize uint64) []byte {
+	return ptrToBytes(uint32(ptrSize>>32), uint32(ptrSize))
+}
+
 // ptrToBytes returns a byte slice from WebAssembly compatible numeric types
 // representing its pointer and length.
 func ptrToBytes(ptr, size uint32) []byte {
@@ -60,6 +66,9 @@ func ptrToBytes(ptr, size uint32) []byte {
 // bytesToPtr returns a pointer and size pair for the given byte slice in a way
 // compatible with WebAssembly numeric types.
 func bytesToPtr(buf []byte) (uint32, uint32) {
+	if len(buf) == 0 {
+		return 0, 0
+	}
 	ptr := &buf[0]
 	unsafePtr := uintptr(unsafe.Pointer(ptr))
 	return uint32(unsafePtr), uint32(len(buf))